@@ -23,11 +23,13 @@ import (
 	_ "github.com/influxdata/platform/query/builtin"
 	"github.com/influxdata/platform/query/control"
 	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/functions"
 	"github.com/influxdata/platform/task"
 	taskbackend "github.com/influxdata/platform/task/backend"
 	taskbolt "github.com/influxdata/platform/task/backend/bolt"
 	"github.com/influxdata/platform/task/backend/coordinator"
 	taskexecutor "github.com/influxdata/platform/task/backend/executor"
+	tasknotify "github.com/influxdata/platform/task/backend/notify"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -41,8 +43,14 @@ var (
 	httpBindAddress   string
 	authorizationPath string
 	boltPath          string
+	storageWriteAddr  string
+	taskMetricsLimit  int
 )
 
+// idpdTaskNodeID identifies idpd to its task lease manager.
+// The leases are kept across restarts, so the ID must not change between them.
+const idpdTaskNodeID = "idpd"
+
 func init() {
 	viper.SetEnvPrefix("INFLUX")
 
@@ -63,6 +71,18 @@ func init() {
 	if h := viper.GetString("BOLT_PATH"); h != "" {
 		boltPath = h
 	}
+
+	platformCmd.Flags().StringVar(&storageWriteAddr, "storage-write-addr", "", "address of the storage service that to() writes points into")
+	viper.BindEnv("STORAGE_WRITE_ADDR")
+	if h := viper.GetString("STORAGE_WRITE_ADDR"); h != "" {
+		storageWriteAddr = h
+	}
+
+	platformCmd.Flags().IntVar(&taskMetricsLimit, "task-metrics-limit", 100, "number of tasks labelled individually in the task scheduler metrics")
+	viper.BindEnv("TASK_METRICS_LIMIT")
+	if h := viper.GetInt("TASK_METRICS_LIMIT"); h != 0 {
+		taskMetricsLimit = h
+	}
 }
 
 var platformCmd = &cobra.Command{
@@ -120,9 +140,22 @@ func platformF(cmd *cobra.Command, args []string) {
 
 	var queryService query.QueryService
 	{
+		deps := make(execute.Dependencies)
+		if storageWriteAddr != "" {
+			if err := functions.InjectToDependencies(deps, functions.ToDependencies{
+				PointsWriter:       &http.PointsWriter{Addr: storageWriteAddr},
+				BucketLookup:       query.FromBucketService(bucketSvc),
+				OrganizationLookup: query.FromOrganizationService(orgSvc),
+			}); err != nil {
+				logger.Fatal("failed to configure to()", zap.Error(err))
+			}
+		} else {
+			logger.Info("to() is disabled because no storage write address is configured")
+		}
+
 		// TODO(lh): this is temporary until query endpoint is added here.
 		config := control.Config{
-			ExecutorDependencies: make(execute.Dependencies),
+			ExecutorDependencies: deps,
 			ConcurrencyQuota:     runtime.NumCPU() * 2,
 			MemoryBytesQuota:     0,
 			Verbose:              false,
@@ -134,19 +167,59 @@ func platformF(cmd *cobra.Command, args []string) {
 	}
 
 	var taskSvc platform.TaskService
+	var taskDryRunSvc platform.TaskDryRunService
+	var taskNotificationSvc platform.TaskNotificationService
 	{
 		boltStore, err := taskbolt.New(c.DB(), "tasks")
 		if err != nil {
 			logger.Fatal("failed opening task bolt", zap.Error(err))
 		}
 
-		executor := taskexecutor.NewQueryServiceExecutor(logger, queryService, boltStore)
+		executor := taskexecutor.NewQueryServiceExecutor(logger, queryService, boltStore, authSvc)
+		taskDryRunSvc = taskexecutor.NewDryRunService(queryService, bucketSvc)
+
+		runStore, err := taskbolt.NewRunStore(c.DB(), "tasks")
+		if err != nil {
+			logger.Fatal("failed opening task run bolt", zap.Error(err))
+		}
+
+		notificationStore, err := taskbolt.NewNotificationStore(c.DB(), "tasks")
+		if err != nil {
+			logger.Fatal("failed opening task notification bolt", zap.Error(err))
+		}
+		notifier := tasknotify.NewWebhookNotifier(logger, runStore, tasknotify.WithNotificationStore(notificationStore))
+		defer notifier.Close()
+		taskNotificationSvc = notifier
 
-		// TODO(lh): Replace NopLogWriter with real log writer
-		scheduler := taskbackend.NewScheduler(boltStore, executor, taskbackend.NopLogWriter{}, time.Now().UTC().Unix())
+		scheduler := taskbackend.NewScheduler(boltStore, executor, runStore, time.Now().UTC().Unix(),
+			taskbackend.WithRunNotifier(notifier),
+			taskbackend.WithTaskMetricsLimit(taskMetricsLimit),
+		)
+		reg.MustRegister(scheduler.(prom.PrometheusCollector).PrometheusCollectors()...)
 
-		// TODO(lh): Replace NopLogReader with real log reader
-		taskSvc = task.PlatformAdapter(coordinator.New(scheduler, boltStore), taskbackend.NopLogReader{})
+		leaseStore, err := taskbolt.NewLeaseStore(c.DB(), "tasks")
+		if err != nil {
+			logger.Fatal("failed opening task lease bolt", zap.Error(err))
+		}
+		// The bolt file cannot be opened by another process, so this is the only node scheduling its tasks,
+		// and there is no other node to fail over to. The lease manager ticks the scheduler,
+		// and frees the runs left unfinished by the previous process when the node restarts.
+		// Scheduling on several hosts needs a Store and a LeaseStore that all the nodes share.
+		leaseManager := taskbackend.NewLeaseManager(idpdTaskNodeID, leaseStore, boltStore, scheduler,
+			taskbackend.WithLeaseLogger(logger),
+		)
+		if err := leaseManager.Sync(context.Background(), time.Now().UTC().Unix()); err != nil {
+			logger.Error("failed to sync task leases", zap.Error(err))
+		}
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		go func() {
+			for t := range ticker.C {
+				leaseManager.Tick(t.UTC().Unix())
+			}
+		}()
+
+		taskSvc = task.PlatformAdapter(coordinator.New(leaseManager, boltStore), runStore)
 	}
 
 	chronografSvc, err := server.NewServiceV2(context.TODO(), c.DB())
@@ -190,6 +263,9 @@ func platformF(cmd *cobra.Command, args []string) {
 
 		taskHandler := http.NewTaskHandler()
 		taskHandler.TaskService = taskSvc
+		taskHandler.TaskDryRunService = taskDryRunSvc
+		taskHandler.TaskNotificationService = taskNotificationSvc
+		taskHandler.AuthorizationService = authSvc
 
 		// TODO(desa): what to do about idpe.
 		chronografHandler := http.NewChronografHandler(chronografSvc)
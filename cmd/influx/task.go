@@ -0,0 +1,868 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/cmd/influx/internal"
+	"github.com/influxdata/platform/http"
+	"github.com/influxdata/platform/query/repl"
+	"github.com/influxdata/platform/task/options"
+	"github.com/spf13/cobra"
+)
+
+// Task Command
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Task related commands",
+	Run:   taskF,
+}
+
+func taskF(cmd *cobra.Command, args []string) {
+	cmd.Usage()
+}
+
+func newTaskService() *http.TaskService {
+	return &http.TaskService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+}
+
+// taskOrgID returns the ID of the organization given either by name or by ID.
+func taskOrgID(org, orgID string) (platform.ID, error) {
+	if (org == "") == (orgID == "") {
+		return nil, fmt.Errorf("must specify exactly one of org or org-id")
+	}
+
+	if orgID != "" {
+		var id platform.ID
+		if err := id.DecodeFromString(orgID); err != nil {
+			return nil, fmt.Errorf("error parsing organization id: %v", err)
+		}
+		return id, nil
+	}
+
+	s := &http.OrganizationService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}
+	o, err := s.FindOrganization(context.Background(), platform.OrganizationFilter{Name: &org})
+	if err != nil {
+		return nil, err
+	}
+	return o.ID, nil
+}
+
+func decodeID(name, s string) platform.ID {
+	var id platform.ID
+	if err := id.DecodeFromString(s); err != nil {
+		fmt.Printf("error parsing %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	return id
+}
+
+func writeTasks(tasks ...*platform.Task) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+		"OrganizationID",
+		"Status",
+		"Every",
+		"Cron",
+	)
+	for _, t := range tasks {
+		w.Write(map[string]interface{}{
+			"ID":             t.ID.String(),
+			"Name":           t.Name,
+			"OrganizationID": t.Organization.String(),
+			"Status":         t.Status,
+			"Every":          t.Every,
+			"Cron":           t.Cron,
+		})
+	}
+	w.Flush()
+}
+
+// Create Command
+type TaskCreateFlags struct {
+	org    string
+	orgID  string
+	userID string
+}
+
+var taskCreateFlags TaskCreateFlags
+
+func init() {
+	taskCreateCmd := &cobra.Command{
+		Use:   "create [script literal or @/path/to/task.flux]",
+		Short: "Create task",
+		Args:  cobra.ExactArgs(1),
+		Run:   taskCreateF,
+	}
+
+	taskCreateCmd.Flags().StringVarP(&taskCreateFlags.org, "org", "o", "", "name of the organization that owns the task")
+	taskCreateCmd.Flags().StringVarP(&taskCreateFlags.orgID, "org-id", "", "", "id of the organization that owns the task")
+	taskCreateCmd.Flags().StringVarP(&taskCreateFlags.userID, "user-id", "", "", "id of the user that owns the task (required)")
+	taskCreateCmd.MarkFlagRequired("user-id")
+
+	taskCmd.AddCommand(taskCreateCmd)
+}
+
+func taskCreateF(cmd *cobra.Command, args []string) {
+	orgID, err := taskOrgID(taskCreateFlags.org, taskCreateFlags.orgID)
+	if err != nil {
+		fmt.Println(err)
+		cmd.Usage()
+		os.Exit(1)
+	}
+
+	flux, err := repl.LoadQuery(args[0])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	t := &platform.Task{
+		Organization: orgID,
+		Owner:        platform.User{ID: decodeID("user id", taskCreateFlags.userID)},
+		Flux:         flux,
+	}
+	if err := newTaskService().CreateTask(context.Background(), t); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	writeTasks(t)
+}
+
+// Find Command
+type TaskFindFlags struct {
+	id     string
+	org    string
+	orgID  string
+	userID string
+	status string
+}
+
+var taskFindFlags TaskFindFlags
+
+func init() {
+	taskFindCmd := &cobra.Command{
+		Use:   "find",
+		Short: "Find tasks",
+		Run:   taskFindF,
+	}
+
+	taskFindCmd.Flags().StringVarP(&taskFindFlags.id, "id", "i", "", "task ID")
+	taskFindCmd.Flags().StringVarP(&taskFindFlags.org, "org", "o", "", "task organization name")
+	taskFindCmd.Flags().StringVarP(&taskFindFlags.orgID, "org-id", "", "", "task organization ID")
+	taskFindCmd.Flags().StringVarP(&taskFindFlags.userID, "user-id", "", "", "task owner ID")
+	taskFindCmd.Flags().StringVarP(&taskFindFlags.status, "status", "", "", "task status, enabled or disabled")
+
+	taskCmd.AddCommand(taskFindCmd)
+}
+
+func taskFindF(cmd *cobra.Command, args []string) {
+	s := newTaskService()
+
+	if taskFindFlags.id != "" {
+		t, err := s.FindTaskByID(context.Background(), decodeID("task id", taskFindFlags.id))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		writeTasks(t)
+		return
+	}
+
+	filter := platform.TaskFilter{Status: taskFindFlags.status}
+	if taskFindFlags.org != "" || taskFindFlags.orgID != "" {
+		orgID, err := taskOrgID(taskFindFlags.org, taskFindFlags.orgID)
+		if err != nil {
+			fmt.Println(err)
+			cmd.Usage()
+			os.Exit(1)
+		}
+		filter.Organization = &orgID
+	}
+	if taskFindFlags.userID != "" {
+		userID := decodeID("user id", taskFindFlags.userID)
+		filter.User = &userID
+	}
+
+	tasks, err := findAllTasks(context.Background(), s, filter)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	writeTasks(tasks...)
+}
+
+// findAllTasks pages through every task matching filter.
+func findAllTasks(ctx context.Context, s platform.TaskService, filter platform.TaskFilter) ([]*platform.Task, error) {
+	const pageSize = 100 // According to the platform.TaskService.FindTasks API.
+
+	var all []*platform.Task
+	for {
+		tasks, _, err := s.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, tasks...)
+		if len(tasks) < pageSize {
+			return all, nil
+		}
+		after := tasks[len(tasks)-1].ID
+		filter.After = &after
+	}
+}
+
+// Update Command
+type TaskUpdateFlags struct {
+	id     string
+	status string
+}
+
+var taskUpdateFlags TaskUpdateFlags
+
+func init() {
+	taskUpdateCmd := &cobra.Command{
+		Use:   "update [script literal or @/path/to/task.flux]",
+		Short: "Update task",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   taskUpdateF,
+	}
+
+	taskUpdateCmd.Flags().StringVarP(&taskUpdateFlags.id, "id", "i", "", "task ID (required)")
+	taskUpdateCmd.Flags().StringVarP(&taskUpdateFlags.status, "status", "", "", "new task status, enabled or disabled")
+	taskUpdateCmd.MarkFlagRequired("id")
+
+	taskCmd.AddCommand(taskUpdateCmd)
+}
+
+func taskUpdateF(cmd *cobra.Command, args []string) {
+	var upd platform.TaskUpdate
+	if len(args) == 1 {
+		flux, err := repl.LoadQuery(args[0])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		upd.Flux = &flux
+	}
+	if taskUpdateFlags.status != "" {
+		upd.Status = &taskUpdateFlags.status
+	}
+	if upd.Flux == nil && upd.Status == nil {
+		fmt.Println("must specify a script or a status")
+		cmd.Usage()
+		os.Exit(1)
+	}
+
+	updateTask(decodeID("task id", taskUpdateFlags.id), upd)
+}
+
+func updateTask(id platform.ID, upd platform.TaskUpdate) {
+	s := newTaskService()
+	ctx := context.Background()
+	if _, err := s.UpdateTask(ctx, id, upd); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	t, err := s.FindTaskByID(ctx, id)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	writeTasks(t)
+}
+
+// Enable and Disable Commands
+var taskStatusFlags struct {
+	id string
+}
+
+func init() {
+	for _, status := range []string{"enabled", "disabled"} {
+		status := status
+		use := strings.TrimSuffix(status, "d")
+		statusCmd := &cobra.Command{
+			Use:   use,
+			Short: strings.Title(use) + " task",
+			Run: func(cmd *cobra.Command, args []string) {
+				updateTask(decodeID("task id", taskStatusFlags.id), platform.TaskUpdate{Status: &status})
+			},
+		}
+
+		statusCmd.Flags().StringVarP(&taskStatusFlags.id, "id", "i", "", "task ID (required)")
+		statusCmd.MarkFlagRequired("id")
+
+		taskCmd.AddCommand(statusCmd)
+	}
+}
+
+// Delete Command
+type TaskDeleteFlags struct {
+	id string
+}
+
+var taskDeleteFlags TaskDeleteFlags
+
+func init() {
+	taskDeleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete task",
+		Run:   taskDeleteF,
+	}
+
+	taskDeleteCmd.Flags().StringVarP(&taskDeleteFlags.id, "id", "i", "", "task ID (required)")
+	taskDeleteCmd.MarkFlagRequired("id")
+
+	taskCmd.AddCommand(taskDeleteCmd)
+}
+
+func taskDeleteF(cmd *cobra.Command, args []string) {
+	s := newTaskService()
+	ctx := context.Background()
+	id := decodeID("task id", taskDeleteFlags.id)
+
+	t, err := s.FindTaskByID(ctx, id)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if err := s.DeleteTask(ctx, id); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+		"OrganizationID",
+		"Deleted",
+	)
+	w.Write(map[string]interface{}{
+		"ID":             t.ID.String(),
+		"Name":           t.Name,
+		"OrganizationID": t.Organization.String(),
+		"Deleted":        true,
+	})
+	w.Flush()
+}
+
+// Runs Command
+type TaskRunsFlags struct {
+	taskID     string
+	limit      int
+	afterTime  string
+	beforeTime string
+}
+
+var taskRunsFlags TaskRunsFlags
+
+func init() {
+	taskRunsCmd := &cobra.Command{
+		Use:   "runs",
+		Short: "Find runs of a task",
+		Run:   taskRunsF,
+	}
+
+	taskRunsCmd.Flags().StringVarP(&taskRunsFlags.taskID, "task-id", "", "", "task ID (required)")
+	taskRunsCmd.Flags().IntVarP(&taskRunsFlags.limit, "limit", "", 0, "maximum number of runs to return, between 1 and 100")
+	taskRunsCmd.Flags().StringVarP(&taskRunsFlags.afterTime, "after-time", "", "", "only runs scheduled after this RFC3339 time")
+	taskRunsCmd.Flags().StringVarP(&taskRunsFlags.beforeTime, "before-time", "", "", "only runs scheduled before this RFC3339 time")
+	taskRunsCmd.MarkFlagRequired("task-id")
+
+	taskCmd.AddCommand(taskRunsCmd)
+}
+
+func taskRunsF(cmd *cobra.Command, args []string) {
+	taskID := decodeID("task id", taskRunsFlags.taskID)
+	runs, _, err := newTaskService().FindRuns(context.Background(), platform.RunFilter{
+		Task:       &taskID,
+		Limit:      taskRunsFlags.limit,
+		AfterTime:  taskRunsFlags.afterTime,
+		BeforeTime: taskRunsFlags.beforeTime,
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	writeRuns(runs...)
+}
+
+func writeRuns(runs ...*platform.Run) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Status",
+		"QueuedAt",
+		"StartTime",
+		"EndTime",
+	)
+	for _, r := range runs {
+		w.Write(map[string]interface{}{
+			"ID":        r.ID.String(),
+			"Status":    r.Status,
+			"QueuedAt":  r.QueuedAt,
+			"StartTime": r.StartTime,
+			"EndTime":   r.EndTime,
+		})
+	}
+	w.Flush()
+}
+
+// Logs Command
+type TaskLogsFlags struct {
+	taskID string
+	runID  string
+}
+
+var taskLogsFlags TaskLogsFlags
+
+func init() {
+	taskLogsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the logs of a task or of one of its runs",
+		Run:   taskLogsF,
+	}
+
+	taskLogsCmd.Flags().StringVarP(&taskLogsFlags.taskID, "task-id", "", "", "task ID (required)")
+	taskLogsCmd.Flags().StringVarP(&taskLogsFlags.runID, "run-id", "", "", "run ID")
+	taskLogsCmd.MarkFlagRequired("task-id")
+
+	taskCmd.AddCommand(taskLogsCmd)
+}
+
+func taskLogsF(cmd *cobra.Command, args []string) {
+	taskID := decodeID("task id", taskLogsFlags.taskID)
+	filter := platform.LogFilter{Task: &taskID}
+	if taskLogsFlags.runID != "" {
+		runID := decodeID("run id", taskLogsFlags.runID)
+		filter.Run = &runID
+	}
+
+	logs, _, err := newTaskService().FindLogs(context.Background(), filter)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	for _, l := range logs {
+		fmt.Println(*l)
+	}
+}
+
+// Retry Command
+type TaskRetryFlags struct {
+	taskID string
+	runID  string
+}
+
+var taskRetryFlags TaskRetryFlags
+
+func init() {
+	taskRetryCmd := &cobra.Command{
+		Use:   "retry",
+		Short: "Retry a run of a task",
+		Run:   taskRetryF,
+	}
+
+	taskRetryCmd.Flags().StringVarP(&taskRetryFlags.taskID, "task-id", "", "", "task ID (required)")
+	taskRetryCmd.Flags().StringVarP(&taskRetryFlags.runID, "run-id", "", "", "run ID (required)")
+	taskRetryCmd.MarkFlagRequired("task-id")
+	taskRetryCmd.MarkFlagRequired("run-id")
+
+	taskCmd.AddCommand(taskRetryCmd)
+}
+
+func taskRetryF(cmd *cobra.Command, args []string) {
+	r, err := newTaskService().RetryRun(context.Background(), decodeID("task id", taskRetryFlags.taskID), decodeID("run id", taskRetryFlags.runID))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	writeRuns(r)
+}
+
+// taskMeta is the metadata stored next to a task's script by export, in a file with the extension .json.
+type taskMeta struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// taskFile is a task read from a directory by import.
+type taskFile struct {
+	path string
+	meta taskMeta
+	flux string
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// taskFileName returns the name of the file, without extension, that holds the task with the given name.
+func taskFileName(name string) string {
+	return unsafeFileChars.ReplaceAllString(name, "_")
+}
+
+// Export Command
+type TaskExportFlags struct {
+	org   string
+	orgID string
+	dir   string
+}
+
+var taskExportFlags TaskExportFlags
+
+func init() {
+	taskExportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the tasks of an organization to a directory",
+		Long: `Write the script of each task of an organization to <name>.flux in a directory,
+		and the task's metadata to <name>.json.`,
+		Run: taskExportF,
+	}
+
+	taskExportCmd.Flags().StringVarP(&taskExportFlags.org, "org", "o", "", "name of the organization to export")
+	taskExportCmd.Flags().StringVarP(&taskExportFlags.orgID, "org-id", "", "", "id of the organization to export")
+	taskExportCmd.Flags().StringVarP(&taskExportFlags.dir, "dir", "d", "", "directory to write the tasks to (required)")
+	taskExportCmd.MarkFlagRequired("dir")
+
+	taskCmd.AddCommand(taskExportCmd)
+}
+
+func taskExportF(cmd *cobra.Command, args []string) {
+	orgID, err := taskOrgID(taskExportFlags.org, taskExportFlags.orgID)
+	if err != nil {
+		fmt.Println(err)
+		cmd.Usage()
+		os.Exit(1)
+	}
+
+	tasks, err := findAllTasks(context.Background(), newTaskService(), platform.TaskFilter{Organization: &orgID})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	files, err := exportTasks(taskExportFlags.dir, tasks)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+		"Status",
+		"File",
+	)
+	for i, t := range tasks {
+		w.Write(map[string]interface{}{
+			"ID":     t.ID.String(),
+			"Name":   t.Name,
+			"Status": t.Status,
+			"File":   files[i],
+		})
+	}
+	w.Flush()
+}
+
+// exportTasks writes the script and metadata of each task to dir, returning the path of each script.
+func exportTasks(dir string, tasks []*platform.Task) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	files := make([]string, len(tasks))
+	seen := make(map[string]string, len(tasks))
+	for i, t := range tasks {
+		base := taskFileName(t.Name)
+		if other, ok := seen[base]; ok {
+			return nil, fmt.Errorf("tasks %q and %q would both be exported to %s.flux; rename one of them", other, t.Name, base)
+		}
+		seen[base] = t.Name
+
+		meta, err := json.MarshalIndent(taskMeta{Name: t.Name, Status: t.Status}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		files[i] = filepath.Join(dir, base+".flux")
+		if err := ioutil.WriteFile(files[i], []byte(t.Flux), 0644); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, base+".json"), append(meta, '\n'), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Import Command
+type TaskImportFlags struct {
+	org    string
+	orgID  string
+	userID string
+	dir    string
+	prune  bool
+	dryRun bool
+}
+
+var taskImportFlags TaskImportFlags
+
+func init() {
+	taskImportCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Sync the tasks of an organization with a directory",
+		Long: `Create or update a task for each <name>.flux file in a directory, as written by export.
+		Tasks are matched by name. The status of a task is read from <name>.json, and defaults to enabled.
+		Importing the same directory twice makes no further changes.`,
+		Run: taskImportF,
+	}
+
+	taskImportCmd.Flags().StringVarP(&taskImportFlags.org, "org", "o", "", "name of the organization to import into")
+	taskImportCmd.Flags().StringVarP(&taskImportFlags.orgID, "org-id", "", "", "id of the organization to import into")
+	taskImportCmd.Flags().StringVarP(&taskImportFlags.userID, "user-id", "", "", "id of the user that owns created tasks (required)")
+	taskImportCmd.Flags().StringVarP(&taskImportFlags.dir, "dir", "d", "", "directory to read the tasks from (required)")
+	taskImportCmd.Flags().BoolVarP(&taskImportFlags.prune, "prune", "", false, "delete tasks of the organization that are not in the directory")
+	taskImportCmd.Flags().BoolVarP(&taskImportFlags.dryRun, "dry-run", "", false, "print the changes without making them")
+	taskImportCmd.MarkFlagRequired("user-id")
+	taskImportCmd.MarkFlagRequired("dir")
+
+	taskCmd.AddCommand(taskImportCmd)
+}
+
+func taskImportF(cmd *cobra.Command, args []string) {
+	orgID, err := taskOrgID(taskImportFlags.org, taskImportFlags.orgID)
+	if err != nil {
+		fmt.Println(err)
+		cmd.Usage()
+		os.Exit(1)
+	}
+	userID := decodeID("user id", taskImportFlags.userID)
+
+	files, err := readTaskDir(taskImportFlags.dir)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	s := newTaskService()
+	ctx := context.Background()
+	tasks, err := findAllTasks(ctx, s, platform.TaskFilter{Organization: &orgID})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	actions, err := planTaskImport(tasks, files, taskImportFlags.prune)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+		"Action",
+	)
+	defer w.Flush()
+	for _, a := range actions {
+		action := a.String()
+		if !taskImportFlags.dryRun {
+			if err := a.apply(ctx, s, orgID, userID); err != nil {
+				w.Flush()
+				fmt.Printf("error importing task %q: %v\n", a.name, err)
+				os.Exit(1)
+			}
+		}
+
+		var id string
+		if a.task != nil {
+			id = a.task.ID.String()
+		}
+		w.Write(map[string]interface{}{
+			"ID":     id,
+			"Name":   a.name,
+			"Action": action,
+		})
+	}
+}
+
+// readTaskDir reads each .flux file in dir, with its metadata.
+func readTaskDir(dir string) ([]taskFile, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.flux"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]taskFile, 0, len(paths))
+	names := make(map[string]string, len(paths))
+	for _, p := range paths {
+		flux, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		opts, err := options.FromScript(string(flux))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+
+		f := taskFile{
+			path: p,
+			meta: taskMeta{Name: opts.Name, Status: "enabled"},
+			flux: string(flux),
+		}
+		metaPath := strings.TrimSuffix(p, ".flux") + ".json"
+		if b, err := ioutil.ReadFile(metaPath); err == nil {
+			if err := json.Unmarshal(b, &f.meta); err != nil {
+				return nil, fmt.Errorf("%s: %v", metaPath, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		if f.meta.Name != opts.Name {
+			return nil, fmt.Errorf("%s: name %q does not match the task name %q in %s", metaPath, f.meta.Name, opts.Name, p)
+		}
+		switch f.meta.Status {
+		case "enabled", "disabled":
+		case "":
+			f.meta.Status = "enabled"
+		default:
+			return nil, fmt.Errorf("%s: status must be enabled or disabled", metaPath)
+		}
+		if other, ok := names[opts.Name]; ok {
+			return nil, fmt.Errorf("%s and %s both define the task %q", other, p, opts.Name)
+		}
+		names[opts.Name] = p
+
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// taskImportAction is a change made by import to bring one task in line with the directory.
+type taskImportAction struct {
+	name string
+
+	// task is the existing task, or nil if the task is to be created.
+	task *platform.Task
+	// file is the task read from the directory, or nil if the task is to be deleted.
+	file *taskFile
+
+	updateFlux   bool
+	updateStatus bool
+}
+
+func (a taskImportAction) String() string {
+	switch {
+	case a.file == nil:
+		return "delete"
+	case a.task == nil:
+		return "create"
+	}
+
+	var changes []string
+	if a.updateFlux {
+		changes = append(changes, "update script")
+	}
+	if a.updateStatus {
+		changes = append(changes, "set status "+a.file.meta.Status)
+	}
+	if len(changes) == 0 {
+		return "unchanged"
+	}
+	return strings.Join(changes, ", ")
+}
+
+// planTaskImport matches the existing tasks of an organization to the files of a directory by name,
+// and returns the changes needed to sync them, sorted by task name.
+func planTaskImport(tasks []*platform.Task, files []taskFile, prune bool) ([]taskImportAction, error) {
+	byName := make(map[string]*platform.Task, len(tasks))
+	for _, t := range tasks {
+		if _, ok := byName[t.Name]; ok {
+			return nil, fmt.Errorf("found more than one task named %q in the organization", t.Name)
+		}
+		byName[t.Name] = t
+	}
+
+	var actions []taskImportAction
+	for i := range files {
+		f := &files[i]
+		a := taskImportAction{name: f.meta.Name, file: f}
+		if t, ok := byName[f.meta.Name]; ok {
+			a.task = t
+			a.updateFlux = t.Flux != f.flux
+			a.updateStatus = t.Status != f.meta.Status
+			delete(byName, f.meta.Name)
+		}
+		actions = append(actions, a)
+	}
+
+	if prune {
+		for name, t := range byName {
+			actions = append(actions, taskImportAction{name: name, task: t})
+		}
+	}
+
+	sort.Slice(actions, func(i, j int) bool { return actions[i].name < actions[j].name })
+	return actions, nil
+}
+
+// apply makes the change described by a.
+func (a *taskImportAction) apply(ctx context.Context, s platform.TaskService, orgID, userID platform.ID) error {
+	switch {
+	case a.file == nil:
+		return s.DeleteTask(ctx, a.task.ID)
+	case a.task == nil:
+		a.task = &platform.Task{
+			Organization: orgID,
+			Owner:        platform.User{ID: userID},
+			Flux:         a.file.flux,
+		}
+		if err := s.CreateTask(ctx, a.task); err != nil {
+			a.task = nil
+			return err
+		}
+		if a.file.meta.Status != "enabled" {
+			_, err := s.UpdateTask(ctx, a.task.ID, platform.TaskUpdate{Status: &a.file.meta.Status})
+			return err
+		}
+		return nil
+	}
+
+	var upd platform.TaskUpdate
+	if a.updateFlux {
+		upd.Flux = &a.file.flux
+	}
+	if a.updateStatus {
+		upd.Status = &a.file.meta.Status
+	}
+	if upd.Flux == nil && upd.Status == nil {
+		return nil
+	}
+	_, err := s.UpdateTask(ctx, a.task.ID, upd)
+	return err
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	idpctx "github.com/influxdata/platform/context"
+	"github.com/influxdata/platform/query/functions"
+)
+
+func TestPointsWriter_WritePoints(t *testing.T) {
+	var gotAuth, gotQuery, gotBody string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotQuery = r.URL.RawQuery
+		b, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	w := &PointsWriter{Addr: ts.URL}
+	points := []functions.Point{{
+		Measurement: "cpu",
+		Tags:        map[string]string{"host": "a", "dc": "west"},
+		Fields:      map[string]interface{}{"usage": 1.5, "count": int64(2)},
+		Time:        time.Unix(0, 1000),
+	}}
+
+	if _, err := w.WritePoints(context.Background(), platform.ID{1}, platform.ID{2}, points); err == nil {
+		t.Fatal("expected an error writing without an authorization")
+	}
+
+	ctx := idpctx.SetAuthorization(context.Background(), &platform.Authorization{Token: "secret"})
+	n, err := w.WritePoints(ctx, platform.ID{1}, platform.ID{2}, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Token secret" {
+		t.Errorf("unexpected authorization header %q", gotAuth)
+	}
+	if exp := "bucket=02&org=01&precision=ns"; gotQuery != exp {
+		t.Errorf("unexpected query %q, expected %q", gotQuery, exp)
+	}
+	if exp := "cpu,dc=west,host=a count=2i,usage=1.5 1000\n"; gotBody != exp {
+		t.Errorf("unexpected body %q, expected %q", gotBody, exp)
+	}
+	if n != int64(len(gotBody)) {
+		t.Errorf("unexpected bytes written %d, expected %d", n, len(gotBody))
+	}
+}
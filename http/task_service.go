@@ -1,12 +1,20 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"path"
+	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/influxdata/platform"
+	idpctx "github.com/influxdata/platform/context"
 	kerrors "github.com/influxdata/platform/kit/errors"
 	"github.com/julienschmidt/httprouter"
 )
@@ -14,22 +22,46 @@ import (
 // TaskHandler represents an HTTP API handler for tasks.
 type TaskHandler struct {
 	*httprouter.Router
-	TaskService platform.TaskService
+
+	// validateRouter serves taskValidatePath.
+	// httprouter does not allow a static path segment where other routes use :tid,
+	// so that path is routed separately from Router.
+	validateRouter *httprouter.Router
+
+	TaskService       platform.TaskService
+	TaskDryRunService platform.TaskDryRunService
+
+	// TaskNotificationService reports the failure counts and notification deliveries of tasks.
+	TaskNotificationService platform.TaskNotificationService
+
+	// AuthorizationService finds the authorization of the token that created a task.
+	// Runs of the task execute with that authorization.
+	AuthorizationService platform.AuthorizationService
 }
 
 // NewTaskHandler returns a new instance of TaskHandler.
 func NewTaskHandler() *TaskHandler {
 	h := &TaskHandler{
-		Router: httprouter.New(),
+		Router:         httprouter.New(),
+		validateRouter: httprouter.New(),
 	}
 
 	h.HandlerFunc("GET", "/v1/tasks", h.handleGetTasks)
 	h.HandlerFunc("POST", "/v1/tasks", h.handlePostTask)
 
+	h.validateRouter.HandlerFunc("POST", taskValidatePath, h.handleValidateTask)
+	h.HandlerFunc("POST", "/v1/tasks/:tid/dryrun", h.handleDryRunTask)
+
 	h.HandlerFunc("GET", "/v1/tasks/:tid", h.handleGetTask)
 	h.HandlerFunc("PATCH", "/v1/tasks/:tid", h.handleUpdateTask)
 	h.HandlerFunc("DELETE", "/v1/tasks/:tid", h.handleDeleteTask)
 
+	h.HandlerFunc("GET", "/v1/tasks/:tid/revisions", h.handleGetRevisions)
+	h.HandlerFunc("POST", "/v1/tasks/:tid/revisions/:rev/rollback", h.handleRollbackTask)
+
+	h.HandlerFunc("GET", "/v1/tasks/:tid/dependencies", h.handleGetDependencies)
+	h.HandlerFunc("GET", "/v1/tasks/:tid/notifications", h.handleGetNotifications)
+
 	h.HandlerFunc("GET", "/v1/tasks/:tid/logs", h.handleGetLogs)
 	h.HandlerFunc("GET", "/v1/tasks/:tid/runs/:rid/logs", h.handleGetLogs)
 
@@ -40,6 +72,17 @@ func NewTaskHandler() *TaskHandler {
 	return h
 }
 
+const taskValidatePath = "/v1/tasks/validate"
+
+// ServeHTTP routes taskValidatePath to its own router, and every other path to h.Router.
+func (h *TaskHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == taskValidatePath {
+		h.validateRouter.ServeHTTP(w, r)
+		return
+	}
+	h.Router.ServeHTTP(w, r)
+}
+
 func (h *TaskHandler) handleGetTasks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -90,6 +133,43 @@ func decodeGetTasksRequest(ctx context.Context, r *http.Request) (*getTasksReque
 		}
 	}
 
+	req.filter.NamePrefix = qp.Get("namePrefix")
+	if re := qp.Get("nameRegex"); re != "" {
+		if _, err := regexp.Compile(re); err != nil {
+			return nil, kerrors.InvalidDataf("invalid nameRegex: %v", err)
+		}
+		req.filter.NameRegex = re
+	}
+
+	switch status := qp.Get("status"); status {
+	case "", "enabled", "disabled":
+		req.filter.Status = status
+	default:
+		return nil, kerrors.InvalidDataf("status must be enabled or disabled")
+	}
+
+	switch status := qp.Get("lastRunStatus"); status {
+	case "", "success", "failed":
+		req.filter.LastRunStatus = status
+	default:
+		return nil, kerrors.InvalidDataf("lastRunStatus must be success or failed")
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *string
+	}{
+		{"lastRunAfterTime", &req.filter.LastRunAfterTime},
+		{"lastRunBeforeTime", &req.filter.LastRunBeforeTime},
+	} {
+		if t := qp.Get(p.name); t != "" {
+			if _, err := time.Parse(time.RFC3339, t); err != nil {
+				return nil, kerrors.InvalidDataf("%s must be an RFC3339 time", p.name)
+			}
+			*p.dst = t
+		}
+	}
+
 	return req, nil
 }
 
@@ -102,6 +182,18 @@ func (h *TaskHandler) handlePostTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Runs of the task execute with the authorization of the request that created it,
+	// never with one named in the request body.
+	req.Task.AuthorizationID = nil
+	a, err := h.requestAuthorization(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if a != nil {
+		req.Task.AuthorizationID = a.ID
+	}
+
 	if err := h.TaskService.CreateTask(ctx, req.Task); err != nil {
 		EncodeError(ctx, err, w)
 		return
@@ -113,6 +205,19 @@ func (h *TaskHandler) handlePostTask(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// requestAuthorization returns the authorization of the request's token.
+// It returns nil if h has no AuthorizationService or the request has no token.
+func (h *TaskHandler) requestAuthorization(ctx context.Context) (*platform.Authorization, error) {
+	if h.AuthorizationService == nil {
+		return nil, nil
+	}
+	tok, err := idpctx.GetToken(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	return h.AuthorizationService.FindAuthorizationByToken(ctx, tok)
+}
+
 type postTaskRequest struct {
 	Task *platform.Task
 }
@@ -127,6 +232,119 @@ func decodePostTaskRequest(ctx context.Context, r *http.Request) (*postTaskReque
 	}, nil
 }
 
+func (h *TaskHandler) handleValidateTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeValidateTaskRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	h.dryRun(ctx, w, req.DryRun)
+}
+
+type validateTaskRequest struct {
+	DryRun platform.TaskDryRun
+}
+
+func decodeValidateTaskRequest(ctx context.Context, r *http.Request) (*validateTaskRequest, error) {
+	var dr platform.TaskDryRun
+	if err := json.NewDecoder(r.Body).Decode(&dr); err != nil {
+		return nil, err
+	}
+	if len(dr.Organization) == 0 {
+		return nil, kerrors.InvalidDataf("you must provide an organization ID")
+	}
+	if dr.Flux == "" {
+		return nil, kerrors.InvalidDataf("you must provide a flux script")
+	}
+	if dr.Now != "" {
+		if _, err := time.Parse(time.RFC3339, dr.Now); err != nil {
+			return nil, kerrors.InvalidDataf("now must be an RFC3339 time")
+		}
+	}
+
+	return &validateTaskRequest{
+		DryRun: dr,
+	}, nil
+}
+
+func (h *TaskHandler) handleDryRunTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeDryRunTaskRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	task, err := h.TaskService.FindTaskByID(ctx, req.TaskID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	h.dryRun(ctx, w, platform.TaskDryRun{
+		Organization: task.Organization,
+		Flux:         task.Flux,
+		Now:          req.Now,
+	})
+}
+
+type dryRunTaskRequest struct {
+	TaskID platform.ID
+	Now    string
+}
+
+func decodeDryRunTaskRequest(ctx context.Context, r *http.Request) (*dryRunTaskRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("tid")
+	if id == "" {
+		return nil, kerrors.InvalidDataf("you must provide a task ID")
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		Now string `json:"now"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+	}
+	if body.Now == "" {
+		// A dry run of an existing task always executes a run.
+		body.Now = time.Now().UTC().Format(time.RFC3339)
+	} else if _, err := time.Parse(time.RFC3339, body.Now); err != nil {
+		return nil, kerrors.InvalidDataf("now must be an RFC3339 time")
+	}
+
+	return &dryRunTaskRequest{
+		TaskID: i,
+		Now:    body.Now,
+	}, nil
+}
+
+// dryRun writes the result of dr to w.
+// A script that fails to validate or run is reported as invalid data, rather than as a server error.
+func (h *TaskHandler) dryRun(ctx context.Context, w http.ResponseWriter, dr platform.TaskDryRun) {
+	res, err := h.TaskDryRunService.DryRunTask(ctx, dr)
+	if err != nil {
+		EncodeError(ctx, kerrors.InvalidDataf("%v", err), w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
 func (h *TaskHandler) handleGetTask(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -180,6 +398,16 @@ func (h *TaskHandler) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The author of a new script revision is the user of the request's authorization.
+	a, err := h.requestAuthorization(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if a != nil {
+		req.Update.Author = a.UserID
+	}
+
 	task, err := h.TaskService.UpdateTask(ctx, req.TaskID, req.Update)
 	if err != nil {
 		EncodeError(ctx, err, w)
@@ -258,6 +486,197 @@ func decodeDeleteTaskRequest(ctx context.Context, r *http.Request) (*deleteTaskR
 	}, nil
 }
 
+func (h *TaskHandler) handleGetRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetRevisionsRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	revs, err := h.TaskService.FindTaskRevisions(ctx, req.TaskID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, revs); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+type getRevisionsRequest struct {
+	TaskID platform.ID
+}
+
+func decodeGetRevisionsRequest(ctx context.Context, r *http.Request) (*getRevisionsRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("tid")
+	if id == "" {
+		return nil, kerrors.InvalidDataf("you must provide a task ID")
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	return &getRevisionsRequest{
+		TaskID: i,
+	}, nil
+}
+
+func (h *TaskHandler) handleRollbackTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeRollbackTaskRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	// The author of the new script revision is the user of the request's authorization.
+	var author platform.ID
+	a, err := h.requestAuthorization(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if a != nil {
+		author = a.UserID
+	}
+
+	task, err := h.TaskService.RollbackTask(ctx, req.TaskID, req.Revision, author)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, task); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+type rollbackTaskRequest struct {
+	TaskID   platform.ID
+	Revision int64
+}
+
+func decodeRollbackTaskRequest(ctx context.Context, r *http.Request) (*rollbackTaskRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("tid")
+	if id == "" {
+		return nil, kerrors.InvalidDataf("you must provide a task ID")
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	rev, err := strconv.ParseInt(params.ByName("rev"), 10, 64)
+	if err != nil || rev < 1 {
+		return nil, kerrors.InvalidDataf("revision must be a positive integer")
+	}
+
+	return &rollbackTaskRequest{
+		TaskID:   i,
+		Revision: rev,
+	}, nil
+}
+
+func (h *TaskHandler) handleGetDependencies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetDependenciesRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	deps, err := h.TaskService.FindTaskDependencies(ctx, req.TaskID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, deps); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+type getDependenciesRequest struct {
+	TaskID platform.ID
+}
+
+func decodeGetDependenciesRequest(ctx context.Context, r *http.Request) (*getDependenciesRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("tid")
+	if id == "" {
+		return nil, kerrors.InvalidDataf("you must provide a task ID")
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	return &getDependenciesRequest{
+		TaskID: i,
+	}, nil
+}
+
+func (h *TaskHandler) handleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetNotificationsRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if _, err := h.TaskService.FindTaskByID(ctx, req.TaskID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	n, err := h.TaskNotificationService.FindTaskNotifications(ctx, req.TaskID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, n); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+}
+
+type getNotificationsRequest struct {
+	TaskID platform.ID
+}
+
+func decodeGetNotificationsRequest(ctx context.Context, r *http.Request) (*getNotificationsRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("tid")
+	if id == "" {
+		return nil, kerrors.InvalidDataf("you must provide a task ID")
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return nil, err
+	}
+
+	return &getNotificationsRequest{
+		TaskID: i,
+	}, nil
+}
+
 func (h *TaskHandler) handleGetLogs(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -388,7 +807,7 @@ func (h *TaskHandler) handleGetRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	run, err := h.TaskService.FindRunByID(ctx, req.RunID)
+	run, err := h.TaskService.FindRunByID(ctx, req.TaskID, req.RunID)
 	if err != nil {
 		EncodeError(ctx, err, w)
 		return
@@ -401,23 +820,32 @@ func (h *TaskHandler) handleGetRun(w http.ResponseWriter, r *http.Request) {
 }
 
 type getRunRequest struct {
-	RunID platform.ID
+	TaskID platform.ID
+	RunID  platform.ID
 }
 
 func decodeGetRunRequest(ctx context.Context, r *http.Request) (*getRunRequest, error) {
 	params := httprouter.ParamsFromContext(ctx)
-	id := params.ByName("rid")
-	if id == "" {
+	tid := params.ByName("tid")
+	if tid == "" {
+		return nil, kerrors.InvalidDataf("you must provide a task ID")
+	}
+	rid := params.ByName("rid")
+	if rid == "" {
 		return nil, kerrors.InvalidDataf("you must provide a run ID")
 	}
 
-	var i platform.ID
-	if err := i.DecodeFromString(id); err != nil {
+	var ti, ri platform.ID
+	if err := ti.DecodeFromString(tid); err != nil {
+		return nil, err
+	}
+	if err := ri.DecodeFromString(rid); err != nil {
 		return nil, err
 	}
 
 	return &getRunRequest{
-		RunID: i,
+		TaskID: ti,
+		RunID:  ri,
 	}, nil
 }
 
@@ -430,7 +858,7 @@ func (h *TaskHandler) handleRetryRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	run, err := h.TaskService.RetryRun(ctx, req.RunID)
+	run, err := h.TaskService.RetryRun(ctx, req.TaskID, req.RunID)
 	if err != nil {
 		EncodeError(ctx, err, w)
 		return
@@ -443,22 +871,256 @@ func (h *TaskHandler) handleRetryRun(w http.ResponseWriter, r *http.Request) {
 }
 
 type retryRunRequest struct {
-	RunID platform.ID
+	TaskID platform.ID
+	RunID  platform.ID
 }
 
 func decodeRetryRunRequest(ctx context.Context, r *http.Request) (*retryRunRequest, error) {
 	params := httprouter.ParamsFromContext(ctx)
-	id := params.ByName("rid")
-	if id == "" {
+	tid := params.ByName("tid")
+	if tid == "" {
+		return nil, kerrors.InvalidDataf("you must provide a task ID")
+	}
+	rid := params.ByName("rid")
+	if rid == "" {
 		return nil, kerrors.InvalidDataf("you must provide a run ID")
 	}
 
-	var i platform.ID
-	if err := i.DecodeFromString(id); err != nil {
+	var ti, ri platform.ID
+	if err := ti.DecodeFromString(tid); err != nil {
+		return nil, err
+	}
+	if err := ri.DecodeFromString(rid); err != nil {
 		return nil, err
 	}
 
 	return &retryRunRequest{
-		RunID: i,
+		TaskID: ti,
+		RunID:  ri,
 	}, nil
 }
+
+const (
+	tasksPath = "/v1/tasks"
+)
+
+// TaskService connects to Influx via HTTP using tokens to manage tasks.
+type TaskService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var (
+	_ platform.TaskService             = (*TaskService)(nil)
+	_ platform.TaskNotificationService = (*TaskService)(nil)
+)
+
+// FindTaskByID returns a single task.
+func (s *TaskService) FindTaskByID(ctx context.Context, id platform.ID) (*platform.Task, error) {
+	var t platform.Task
+	if err := s.do(ctx, "GET", taskIDPath(id), nil, nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// FindTasks returns a list of tasks that match filter and the total count of matching tasks.
+// At most 100 tasks are returned; use filter.After to page through the rest.
+func (s *TaskService) FindTasks(ctx context.Context, filter platform.TaskFilter) ([]*platform.Task, int, error) {
+	query := url.Values{}
+	if filter.After != nil {
+		query.Add("after", filter.After.String())
+	}
+	if filter.Organization != nil {
+		query.Add("organization", filter.Organization.String())
+	}
+	if filter.User != nil {
+		query.Add("user", filter.User.String())
+	}
+	for _, p := range []struct{ name, value string }{
+		{"namePrefix", filter.NamePrefix},
+		{"nameRegex", filter.NameRegex},
+		{"status", filter.Status},
+		{"lastRunStatus", filter.LastRunStatus},
+		{"lastRunAfterTime", filter.LastRunAfterTime},
+		{"lastRunBeforeTime", filter.LastRunBeforeTime},
+	} {
+		if p.value != "" {
+			query.Add(p.name, p.value)
+		}
+	}
+
+	var ts []*platform.Task
+	if err := s.do(ctx, "GET", tasksPath, query, nil, &ts); err != nil {
+		return nil, 0, err
+	}
+	return ts, len(ts), nil
+}
+
+// CreateTask creates a new task and sets t.ID with the new identifier.
+func (s *TaskService) CreateTask(ctx context.Context, t *platform.Task) error {
+	return s.do(ctx, "POST", tasksPath, nil, t, t)
+}
+
+// UpdateTask updates a single task with changeset.
+func (s *TaskService) UpdateTask(ctx context.Context, id platform.ID, upd platform.TaskUpdate) (*platform.Task, error) {
+	var t platform.Task
+	if err := s.do(ctx, "PATCH", taskIDPath(id), nil, upd, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteTask removes a task by ID and purges all associated data and scheduled runs.
+func (s *TaskService) DeleteTask(ctx context.Context, id platform.ID) error {
+	return s.do(ctx, "DELETE", taskIDPath(id), nil, nil, nil)
+}
+
+// FindLogs returns the logs of a task, or of a single run if filter.Run is set.
+func (s *TaskService) FindLogs(ctx context.Context, filter platform.LogFilter) ([]*platform.Log, int, error) {
+	if filter.Task == nil {
+		return nil, 0, errors.New("task ID is required to find logs")
+	}
+
+	p := path.Join(taskIDPath(*filter.Task), "logs")
+	if filter.Run != nil {
+		p = path.Join(taskIDPath(*filter.Task), "runs", filter.Run.String(), "logs")
+	}
+
+	var logs []*platform.Log
+	if err := s.do(ctx, "GET", p, nil, nil, &logs); err != nil {
+		return nil, 0, err
+	}
+	return logs, len(logs), nil
+}
+
+// FindRuns returns a list of runs that match filter and the total count of returned runs.
+func (s *TaskService) FindRuns(ctx context.Context, filter platform.RunFilter) ([]*platform.Run, int, error) {
+	if filter.Task == nil {
+		return nil, 0, errors.New("task ID is required to find runs")
+	}
+
+	query := url.Values{}
+	if filter.After != nil {
+		query.Add("after", filter.After.String())
+	}
+	if filter.Limit != 0 {
+		query.Add("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.AfterTime != "" {
+		query.Add("afterTime", filter.AfterTime)
+	}
+	if filter.BeforeTime != "" {
+		query.Add("beforeTime", filter.BeforeTime)
+	}
+
+	var runs []*platform.Run
+	if err := s.do(ctx, "GET", path.Join(taskIDPath(*filter.Task), "runs"), query, nil, &runs); err != nil {
+		return nil, 0, err
+	}
+	return runs, len(runs), nil
+}
+
+// FindRunByID returns a single run of a task.
+func (s *TaskService) FindRunByID(ctx context.Context, taskID, runID platform.ID) (*platform.Run, error) {
+	var r platform.Run
+	if err := s.do(ctx, "GET", path.Join(taskIDPath(taskID), "runs", runID.String()), nil, nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// RetryRun creates and returns a new run which retries the given run.
+func (s *TaskService) RetryRun(ctx context.Context, taskID, runID platform.ID) (*platform.Run, error) {
+	var r platform.Run
+	if err := s.do(ctx, "POST", path.Join(taskIDPath(taskID), "runs", runID.String(), "retry"), nil, nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// FindTaskRevisions returns every revision of a task's script, oldest first.
+func (s *TaskService) FindTaskRevisions(ctx context.Context, id platform.ID) ([]*platform.TaskRevision, error) {
+	var revs []*platform.TaskRevision
+	if err := s.do(ctx, "GET", path.Join(taskIDPath(id), "revisions"), nil, nil, &revs); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// RollbackTask makes the script of an earlier revision the task's current script.
+// The server records the user of s.Token's authorization as the author of the new revision, so author is not sent.
+func (s *TaskService) RollbackTask(ctx context.Context, id platform.ID, revision int64, author platform.ID) (*platform.Task, error) {
+	var t platform.Task
+	p := path.Join(taskIDPath(id), "revisions", strconv.FormatInt(revision, 10), "rollback")
+	if err := s.do(ctx, "POST", p, nil, nil, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// FindTaskDependencies returns the tasks that trigger, or are triggered by, a task.
+func (s *TaskService) FindTaskDependencies(ctx context.Context, id platform.ID) (*platform.TaskDependencies, error) {
+	var deps platform.TaskDependencies
+	if err := s.do(ctx, "GET", path.Join(taskIDPath(id), "dependencies"), nil, nil, &deps); err != nil {
+		return nil, err
+	}
+	return &deps, nil
+}
+
+// FindTaskNotifications returns the consecutive failure count of a task and the recent deliveries to its notification endpoints.
+func (s *TaskService) FindTaskNotifications(ctx context.Context, id platform.ID) (*platform.TaskNotifications, error) {
+	var n platform.TaskNotifications
+	if err := s.do(ctx, "GET", path.Join(taskIDPath(id), "notifications"), nil, nil, &n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into out, if out is not nil.
+func (s *TaskService) do(ctx context.Context, method, p string, query url.Values, body, out interface{}) error {
+	u, err := newURL(s.Addr, p)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = query.Encode()
+
+	var r io.Reader
+	if body != nil {
+		octets, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(octets)
+	}
+
+	req, err := http.NewRequest(method, u.String(), r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", s.Token)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func taskIDPath(id platform.ID) string {
+	return path.Join(tasksPath, id.String())
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/platform"
+	idpctx "github.com/influxdata/platform/context"
+)
+
+// authorTaskService records the author of each script revision it is asked to store.
+type authorTaskService struct {
+	platform.TaskService
+	author platform.ID
+}
+
+func (s *authorTaskService) UpdateTask(ctx context.Context, id platform.ID, upd platform.TaskUpdate) (*platform.Task, error) {
+	s.author = upd.Author
+	return &platform.Task{ID: id}, nil
+}
+
+func (s *authorTaskService) RollbackTask(ctx context.Context, id platform.ID, revision int64, author platform.ID) (*platform.Task, error) {
+	s.author = author
+	return &platform.Task{ID: id}, nil
+}
+
+type tokenAuthorizationService struct {
+	platform.AuthorizationService
+	auth *platform.Authorization
+}
+
+func (s *tokenAuthorizationService) FindAuthorizationByToken(ctx context.Context, t string) (*platform.Authorization, error) {
+	if t != s.auth.Token {
+		return nil, errors.New("authorization not found")
+	}
+	return s.auth, nil
+}
+
+func TestTaskHandler_RevisionAuthor(t *testing.T) {
+	user := platform.ID("user")
+	for _, tc := range []struct {
+		name, method, path, body string
+	}{
+		{name: "update", method: "PATCH", path: "/v1/tasks/01", body: `{"flux": "option task = {name: \"a\", every: 1m}", "author": "6661"}`},
+		{name: "rollback", method: "POST", path: "/v1/tasks/01/revisions/1/rollback", body: `{"author": "6661"}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := &authorTaskService{}
+			h := NewTaskHandler()
+			h.TaskService = ts
+			h.AuthorizationService = &tokenAuthorizationService{
+				auth: &platform.Authorization{ID: platform.ID("auth"), Token: "secret", UserID: user},
+			}
+
+			r := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			r = r.WithContext(idpctx.SetToken(r.Context(), "secret"))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+			}
+			// The author named in the body is ignored.
+			if ts.author.String() != user.String() {
+				t.Fatalf("expected author %s from the authorization, got %s", user.String(), ts.author.String())
+			}
+		})
+	}
+}
+
+// dryRunTaskService records the dry runs it is asked to perform.
+type dryRunTaskService struct {
+	dr *platform.TaskDryRun
+}
+
+func (s *dryRunTaskService) DryRunTask(ctx context.Context, dr platform.TaskDryRun) (*platform.TaskDryRunResult, error) {
+	s.dr = &dr
+	return &platform.TaskDryRunResult{}, nil
+}
+
+func TestTaskHandler_ValidateRoute(t *testing.T) {
+	ds := &dryRunTaskService{}
+	h := NewTaskHandler()
+	h.TaskDryRunService = ds
+
+	r := httptest.NewRequest("POST", "/v1/tasks/validate", strings.NewReader(`{"organizationId": "6f7267", "flux": "option task = {name: \"a\", every: 1m}"}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	if ds.dr == nil {
+		t.Fatal("expected the script to be validated")
+	}
+
+	// Only the validate path is routed to the validate handler.
+	ds.dr = nil
+	r = httptest.NewRequest("POST", "/v1/tasks/01", strings.NewReader(`{"organizationId": "6f7267", "flux": "option task = {name: \"a\", every: 1m}"}`))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code == http.StatusOK || ds.dr != nil {
+		t.Fatalf("expected POST to a task not to be validated, got status %d", w.Code)
+	}
+}
@@ -393,6 +393,7 @@ const (
 	NotEqualOperator
 	RegexpMatchOperator
 	NotRegexpMatchOperator
+	ExistsOperator
 	opEnd
 )
 
@@ -850,6 +851,7 @@ var OperatorTokens = map[OperatorKind]string{
 	NotEqualOperator:         "!=",
 	RegexpMatchOperator:      "=~",
 	NotRegexpMatchOperator:   "!~",
+	ExistsOperator:           "exists",
 }
 
 // LogicalOperatorTokens converts LogicalOperatorKind to string
@@ -174,25 +174,35 @@ func RegisterBuiltIn(name, script string) {
 
 // RegisterFunction adds a new builtin top level function.
 func RegisterFunction(name string, c CreateOperationSpec, sig semantic.FunctionSignature) {
-	f := function{
+	RegisterBuiltInValue(name, NewOperationFunction(name, c, sig))
+}
+
+// RegisterFunctionWithSideEffect adds a new builtin top level function that produces side effects.
+// For example, the builtin functions yield(), toKafka(), and toHTTP() all produce side effects.
+func RegisterFunctionWithSideEffect(name string, c CreateOperationSpec, sig semantic.FunctionSignature) {
+	RegisterBuiltInValue(name, NewOperationFunctionWithSideEffect(name, c, sig))
+}
+
+// NewOperationFunction returns a function that creates an operation when called.
+// Unlike RegisterFunction it does not add the function to the builtin scope,
+// so that it can be made a property of a builtin package object instead.
+func NewOperationFunction(name string, c CreateOperationSpec, sig semantic.FunctionSignature) values.Function {
+	return &function{
 		t:             semantic.NewFunctionType(sig),
 		name:          name,
 		createOpSpec:  c,
 		hasSideEffect: false,
 	}
-	RegisterBuiltInValue(name, &f)
 }
 
-// RegisterFunctionWithSideEffect adds a new builtin top level function that produces side effects.
-// For example, the builtin functions yield(), toKafka(), and toHTTP() all produce side effects.
-func RegisterFunctionWithSideEffect(name string, c CreateOperationSpec, sig semantic.FunctionSignature) {
-	f := function{
+// NewOperationFunctionWithSideEffect returns a function that creates an operation producing side effects when called.
+func NewOperationFunctionWithSideEffect(name string, c CreateOperationSpec, sig semantic.FunctionSignature) values.Function {
+	return &function{
 		t:             semantic.NewFunctionType(sig),
 		name:          name,
 		createOpSpec:  c,
 		hasSideEffect: true,
 	}
-	RegisterBuiltInValue(name, &f)
 }
 
 // RegisterBuiltInValue adds the value to the builtin scope.
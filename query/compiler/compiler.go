@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/influxdata/platform/query/ast"
 	"github.com/influxdata/platform/query/semantic"
 	"github.com/influxdata/platform/query/values"
 )
@@ -87,6 +88,19 @@ func compile(n semantic.Node, builtIns Scope) (Evaluator, error) {
 			t:          semantic.NewObjectType(propertyTypes),
 			properties: properties,
 		}, nil
+	case *semantic.ArrayExpression:
+		elements := make([]Evaluator, len(n.Elements))
+		for i, e := range n.Elements {
+			node, err := compile(e, builtIns)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = node
+		}
+		return &arrayEvaluator{
+			t:        n.Type(),
+			elements: elements,
+		}, nil
 	case *semantic.IdentifierExpression:
 		if v, ok := builtIns[n.Name]; ok {
 			//Resolve any built in identifiers now
@@ -139,6 +153,9 @@ func compile(n semantic.Node, builtIns Scope) (Evaluator, error) {
 			time: values.ConvertTime(n.Value),
 		}, nil
 	case *semantic.UnaryExpression:
+		if n.Operator == ast.ExistsOperator {
+			return compileExists(n, builtIns)
+		}
 		node, err := compile(n.Argument, builtIns)
 		if err != nil {
 			return nil, err
@@ -230,6 +247,29 @@ func compile(n semantic.Node, builtIns Scope) (Evaluator, error) {
 	}
 }
 
+// compileExists compiles an exists expression.
+// Only object properties can be missing, any other operand always exists.
+func compileExists(n *semantic.UnaryExpression, builtIns Scope) (Evaluator, error) {
+	if m, ok := n.Argument.(*semantic.MemberExpression); ok {
+		object, err := compile(m.Object, builtIns)
+		if err != nil {
+			return nil, err
+		}
+		return &existsEvaluator{
+			t:        n.Type(),
+			object:   object,
+			property: m.Property,
+		}, nil
+	}
+	if _, err := compile(n.Argument, builtIns); err != nil {
+		return nil, err
+	}
+	return &booleanEvaluator{
+		t: n.Type(),
+		b: true,
+	}, nil
+}
+
 // CompilationCache caches compilation results based on the types of the input parameters.
 type CompilationCache struct {
 	fn   *semantic.FunctionExpression
@@ -35,6 +35,8 @@ func ValueEqual(x, y values.Value) bool {
 		return x.Str() == y.Str()
 	case semantic.Time:
 		return x.Time() == y.Time()
+	case semantic.Array:
+		return x.Equal(y)
 	case semantic.Object:
 		return cmp.Equal(x.Object(), y.Object(), CmpOptions...)
 	default:
@@ -240,6 +242,54 @@ func TestCompileAndEval(t *testing.T) {
 			want:    values.NewIntValue(5),
 			wantErr: false,
 		},
+		{
+			name: "array expression",
+			fn: &semantic.FunctionExpression{
+				Params: []*semantic.FunctionParam{
+					{Key: &semantic.Identifier{Name: "r"}},
+				},
+				Body: &semantic.ArrayExpression{
+					Elements: []semantic.Expression{
+						&semantic.IntegerLiteral{Value: 1},
+						&semantic.IdentifierExpression{Name: "r"},
+					},
+				},
+			},
+			types: map[string]semantic.Type{
+				"r": semantic.Int,
+			},
+			scope: map[string]values.Value{
+				"r": values.NewIntValue(4),
+			},
+			want: values.NewArrayWithBacking(semantic.Int, []values.Value{
+				values.NewIntValue(1),
+				values.NewIntValue(4),
+			}),
+			wantErr: false,
+		},
+		{
+			name: "exists property",
+			fn: &semantic.FunctionExpression{
+				Params: []*semantic.FunctionParam{
+					{Key: &semantic.Identifier{Name: "r"}},
+				},
+				Body: &semantic.UnaryExpression{
+					Operator: ast.ExistsOperator,
+					Argument: &semantic.MemberExpression{
+						Object:   &semantic.IdentifierExpression{Name: "r"},
+						Property: "a",
+					},
+				},
+			},
+			types: map[string]semantic.Type{
+				"r": semantic.NewObjectType(map[string]semantic.Type{"a": semantic.Int}),
+			},
+			scope: map[string]values.Value{
+				"r": existsTestObject(),
+			},
+			want:    values.NewBoolValue(true),
+			wantErr: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -261,3 +311,67 @@ func TestCompileAndEval(t *testing.T) {
 		})
 	}
 }
+
+func existsTestObject() values.Object {
+	obj := values.NewObject()
+	obj.Set("a", values.NewIntValue(1))
+	return obj
+}
+
+func TestCompileAndEval_BuiltinPackage(t *testing.T) {
+	// A package object exposes functions and constants as properties, i.e. pkg.double(x: r) + pkg.one.
+	double := values.NewFunction(
+		"double",
+		semantic.NewFunctionType(semantic.FunctionSignature{
+			Params:     map[string]semantic.Type{"x": semantic.Float},
+			ReturnType: semantic.Float,
+		}),
+		func(args values.Object) (values.Value, error) {
+			x, _ := args.Get("x")
+			return values.NewFloatValue(2 * x.Float()), nil
+		},
+		false,
+	)
+	pkg := values.NewObject()
+	pkg.Set("double", double)
+	pkg.Set("one", values.NewFloatValue(1))
+
+	fn := &semantic.FunctionExpression{
+		Params: []*semantic.FunctionParam{
+			{Key: &semantic.Identifier{Name: "r"}},
+		},
+		Body: &semantic.BinaryExpression{
+			Operator: ast.AdditionOperator,
+			Left: &semantic.CallExpression{
+				Callee: &semantic.MemberExpression{
+					Object:   &semantic.IdentifierExpression{Name: "pkg"},
+					Property: "double",
+				},
+				Arguments: &semantic.ObjectExpression{
+					Properties: []*semantic.Property{
+						{Key: &semantic.Identifier{Name: "x"}, Value: &semantic.IdentifierExpression{Name: "r"}},
+					},
+				},
+			},
+			Right: &semantic.MemberExpression{
+				Object:   &semantic.IdentifierExpression{Name: "pkg"},
+				Property: "one",
+			},
+		},
+	}
+	scope := compiler.Scope{"pkg": pkg}
+	decls := semantic.DeclarationScope{
+		"pkg": semantic.NewExternalVariableDeclaration("pkg", pkg.Type()),
+	}
+	f, err := compiler.Compile(fn, map[string]semantic.Type{"r": semantic.Float}, scope, decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.Eval(map[string]values.Value{"r": values.NewFloatValue(4)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := values.NewFloatValue(9); !cmp.Equal(want, got, CmpOptions...) {
+		t.Errorf("unexpected value -want/+got\n%s", cmp.Diff(want, got, CmpOptions...))
+	}
+}
@@ -421,6 +421,53 @@ func (e *objEvaluator) EvalFunction(scope Scope) values.Function {
 	panic(values.UnexpectedKind(e.t.Kind(), semantic.Function))
 }
 
+type arrayEvaluator struct {
+	t        semantic.Type
+	elements []Evaluator
+}
+
+func (e *arrayEvaluator) Type() semantic.Type {
+	return e.t
+}
+
+func (e *arrayEvaluator) EvalString(scope Scope) string {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.String))
+}
+func (e *arrayEvaluator) EvalInt(scope Scope) int64 {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Int))
+}
+func (e *arrayEvaluator) EvalUInt(scope Scope) uint64 {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.UInt))
+}
+func (e *arrayEvaluator) EvalFloat(scope Scope) float64 {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Float))
+}
+func (e *arrayEvaluator) EvalBool(scope Scope) bool {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Bool))
+}
+func (e *arrayEvaluator) EvalTime(scope Scope) values.Time {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Time))
+}
+func (e *arrayEvaluator) EvalDuration(scope Scope) values.Duration {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Duration))
+}
+func (e *arrayEvaluator) EvalRegexp(scope Scope) *regexp.Regexp {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Regexp))
+}
+func (e *arrayEvaluator) EvalArray(scope Scope) values.Array {
+	elements := make([]values.Value, len(e.elements))
+	for i, node := range e.elements {
+		elements[i] = eval(node, scope)
+	}
+	return values.NewArrayWithBacking(e.t.ElementType(), elements)
+}
+func (e *arrayEvaluator) EvalObject(scope Scope) values.Object {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Object))
+}
+func (e *arrayEvaluator) EvalFunction(scope Scope) values.Function {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Function))
+}
+
 type logicalEvaluator struct {
 	t           semantic.Type
 	operator    ast.LogicalOperatorKind
@@ -574,6 +621,55 @@ func (e *unaryEvaluator) EvalFunction(scope Scope) values.Function {
 	panic(values.UnexpectedKind(e.t.Kind(), semantic.Function))
 }
 
+type existsEvaluator struct {
+	t        semantic.Type
+	object   Evaluator
+	property string
+}
+
+func (e *existsEvaluator) Type() semantic.Type {
+	return e.t
+}
+
+func (e *existsEvaluator) EvalString(scope Scope) string {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.String))
+}
+func (e *existsEvaluator) EvalInt(scope Scope) int64 {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Int))
+}
+func (e *existsEvaluator) EvalUInt(scope Scope) uint64 {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.UInt))
+}
+func (e *existsEvaluator) EvalFloat(scope Scope) float64 {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Float))
+}
+func (e *existsEvaluator) EvalBool(scope Scope) bool {
+	obj := e.object.EvalObject(scope)
+	if n, ok := obj.(values.NullableObject); ok {
+		return n.Exists(e.property)
+	}
+	_, ok := obj.Get(e.property)
+	return ok
+}
+func (e *existsEvaluator) EvalTime(scope Scope) values.Time {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Time))
+}
+func (e *existsEvaluator) EvalDuration(scope Scope) values.Duration {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Duration))
+}
+func (e *existsEvaluator) EvalRegexp(scope Scope) *regexp.Regexp {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Regexp))
+}
+func (e *existsEvaluator) EvalArray(scope Scope) values.Array {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Array))
+}
+func (e *existsEvaluator) EvalObject(scope Scope) values.Object {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Object))
+}
+func (e *existsEvaluator) EvalFunction(scope Scope) values.Function {
+	panic(values.UnexpectedKind(e.t.Kind(), semantic.Function))
+}
+
 type integerEvaluator struct {
 	t semantic.Type
 	i int64
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/platform"
+	idpctx "github.com/influxdata/platform/context"
 	"github.com/influxdata/platform/query"
 	"github.com/influxdata/platform/query/execute"
 	"github.com/influxdata/platform/query/plan"
@@ -71,6 +72,10 @@ func New(c Config) *Controller {
 // Query submits a query for execution returning immediately.
 // Done must be called on any returned Query objects.
 func (c *Controller) Query(ctx context.Context, req *query.Request) (query.Query, error) {
+	if req.Authorization != nil {
+		// Carry the authorization through to execution so that sinks can check their permissions.
+		ctx = idpctx.SetAuthorization(ctx, req.Authorization)
+	}
 	q := c.createQuery(ctx, req.OrganizationID)
 	err := c.compileQuery(q, req.Compiler)
 	if err != nil {
@@ -262,7 +267,9 @@ func (c *Controller) processQuery(q *Query) (pop bool, err error) {
 			return true, errors.New("failed to transition query into executing state")
 		}
 		q.alloc = new(execute.Allocator)
-		r, err := c.executor.Execute(q.executeCtx, q.orgID, q.plan, q.alloc)
+		q.writes = new(execute.WriteCounter)
+		ctx := execute.ContextWithWriteCounter(q.executeCtx, q.writes)
+		r, err := c.executor.Execute(ctx, q.orgID, q.plan, q.alloc)
 		if err != nil {
 			return true, errors.Wrap(err, "failed to execute query")
 		}
@@ -341,7 +348,8 @@ type Query struct {
 	concurrency int
 	memory      int64
 
-	alloc *execute.Allocator
+	alloc  *execute.Allocator
+	writes *execute.WriteCounter
 }
 
 // ID reports an ephemeral unique ID for the query.
@@ -461,6 +469,8 @@ func (q *Query) Statistics() query.Statistics {
 	if q.alloc != nil {
 		stats.MaxAllocated = q.alloc.Max()
 	}
+	stats.BytesWritten = q.writes.BytesWritten()
+	stats.PointsWritten = q.writes.PointsWritten()
 	return stats
 }
 
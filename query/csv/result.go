@@ -580,6 +580,11 @@ func (d *tableDecoder) appendRecord(record []string) error {
 			}
 			continue
 		}
+		if record[j] == "" && c.Type != query.TString {
+			// Empty values without a default are null, except for strings where the empty value is the empty string.
+			d.builder.AppendNil(j)
+			continue
+		}
 		if err := decodeValueInto(j, c, record[j], d.builder); err != nil {
 			return err
 		}
@@ -967,6 +972,9 @@ func encodeValue(value values.Value, c colMeta) (string, error) {
 }
 
 func encodeValueFrom(i, j int, c colMeta, cr query.ColReader) (string, error) {
+	if cr.IsNull(i, j) {
+		return "", nil
+	}
 	switch c.Type {
 	case query.TBool:
 		return strconv.FormatBool(cr.Bools(j)[i]), nil
@@ -154,6 +154,7 @@ func (t *aggregateTransformation) Process(id DatasetID, tbl query.Table) error {
 
 			tj := tableColMap[j]
 			c := tbl.Cols()[tj]
+			cr := SkipNulls(tj, cr)
 
 			switch c.Type {
 			case query.TBool:
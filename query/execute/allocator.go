@@ -44,14 +44,25 @@ func (a *Allocator) Max() int64 {
 }
 
 func (a *Allocator) account(n, size int) {
+	if err := a.Account(n, size); err != nil {
+		panic(err)
+	}
+}
+
+// Account informs the allocator that memory is being held outside of the slices it makes,
+// such as data buffered by a source before it is decoded.
+// Unlike the allocating methods, it returns an AllocError instead of panicking when the limit would be exceeded,
+// in which case nothing is accounted.
+func (a *Allocator) Account(n, size int) error {
 	if want := a.count(n, size); want > a.Limit {
 		allocated := a.count(-n, size)
-		panic(AllocError{
+		return AllocError{
 			Limit:     a.Limit,
 			Allocated: allocated,
 			Wanted:    want - allocated,
-		})
+		}
 	}
+	return nil
 }
 
 // Bools makes a slice of bool values.
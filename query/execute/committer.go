@@ -0,0 +1,91 @@
+package execute
+
+import (
+	"context"
+	"sync"
+)
+
+// Commit is work a source defers until the query that registered it has completed successfully,
+// such as acknowledging the messages it consumed from a queue.
+type Commit interface {
+	// Commit completes the work.
+	Commit(ctx context.Context) error
+	// Close releases the resources held by the commit.
+	// It is called once the commit is no longer needed, whether or not Commit was called.
+	Close() error
+}
+
+// Committer collects the commits registered by the sources of a query.
+// Whoever runs the query decides whether it succeeded, and either commits or discards them.
+// A nil Committer is valid and discards every commit added to it.
+type Committer struct {
+	mu      sync.Mutex
+	commits []Commit
+}
+
+// Add registers cm to be committed once the query has succeeded.
+func (c *Committer) Add(cm Commit) {
+	if c == nil {
+		cm.Close()
+		return
+	}
+	c.mu.Lock()
+	c.commits = append(c.commits, cm)
+	c.mu.Unlock()
+}
+
+// Commit commits and then closes every registered commit.
+// All commits are attempted; the first error encountered is returned.
+func (c *Committer) Commit(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	var err error
+	for _, cm := range c.take() {
+		if cerr := cm.Commit(ctx); cerr != nil && err == nil {
+			err = cerr
+		}
+		if cerr := cm.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Discard closes every registered commit without committing it.
+// It is a no-op once the commits have been committed.
+func (c *Committer) Discard() error {
+	if c == nil {
+		return nil
+	}
+	var err error
+	for _, cm := range c.take() {
+		if cerr := cm.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// take removes and returns the registered commits.
+func (c *Committer) take() []Commit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	commits := c.commits
+	c.commits = nil
+	return commits
+}
+
+type committerKey struct{}
+
+// ContextWithCommitter returns a context that carries c.
+// Sources of queries executed with the returned context register their commits with c.
+func ContextWithCommitter(ctx context.Context, c *Committer) context.Context {
+	return context.WithValue(ctx, committerKey{}, c)
+}
+
+// CommitterFromContext returns the Committer carried by ctx, or nil if there is none.
+func CommitterFromContext(ctx context.Context) *Committer {
+	c, _ := ctx.Value(committerKey{}).(*Committer)
+	return c
+}
@@ -0,0 +1,92 @@
+package execute_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/influxdata/platform/query/execute"
+)
+
+type commitMock struct {
+	commits, closes int
+	err             error
+}
+
+func (c *commitMock) Commit(context.Context) error {
+	c.commits++
+	return c.err
+}
+
+func (c *commitMock) Close() error {
+	c.closes++
+	return nil
+}
+
+func TestCommitter_Commit(t *testing.T) {
+	c := new(execute.Committer)
+	failed := &commitMock{err: errors.New("commit failed")}
+	ok := new(commitMock)
+	c.Add(failed)
+	c.Add(ok)
+
+	if err := c.Commit(context.Background()); err == nil || err.Error() != "commit failed" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cm := range []*commitMock{failed, ok} {
+		if cm.commits != 1 || cm.closes != 1 {
+			t.Errorf("expected one commit and close, got %d commits and %d closes", cm.commits, cm.closes)
+		}
+	}
+
+	// Commits are only committed once.
+	if err := c.Commit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	if ok.commits != 1 || ok.closes != 1 {
+		t.Errorf("expected one commit and close, got %d commits and %d closes", ok.commits, ok.closes)
+	}
+}
+
+func TestCommitter_Discard(t *testing.T) {
+	c := new(execute.Committer)
+	cm := new(commitMock)
+	c.Add(cm)
+
+	if err := c.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Commit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if cm.commits != 0 || cm.closes != 1 {
+		t.Errorf("expected no commit and one close, got %d commits and %d closes", cm.commits, cm.closes)
+	}
+}
+
+func TestCommitter_Nil(t *testing.T) {
+	var c *execute.Committer
+	cm := new(commitMock)
+	c.Add(cm)
+
+	if err := c.Commit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if cm.commits != 0 || cm.closes != 1 {
+		t.Errorf("expected no commit and one close, got %d commits and %d closes", cm.commits, cm.closes)
+	}
+}
+
+func TestCommitterFromContext(t *testing.T) {
+	if c := execute.CommitterFromContext(context.Background()); c != nil {
+		t.Fatalf("expected no committer, got %v", c)
+	}
+	c := new(execute.Committer)
+	ctx := execute.ContextWithCommitter(context.Background(), c)
+	if got := execute.CommitterFromContext(ctx); got != c {
+		t.Fatalf("unexpected committer %v", got)
+	}
+}
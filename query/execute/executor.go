@@ -6,6 +6,7 @@ import (
 	"runtime/debug"
 
 	"github.com/influxdata/platform"
+	idpctx "github.com/influxdata/platform/context"
 	"github.com/influxdata/platform/query"
 	"github.com/influxdata/platform/query/plan"
 	"github.com/pkg/errors"
@@ -27,12 +28,16 @@ func NewExecutor(deps Dependencies) Executor {
 }
 
 type executionState struct {
+	ctx  context.Context
 	p    *plan.PlanSpec
 	deps Dependencies
 
 	orgID platform.ID
+	auth  *platform.Authorization
 
-	alloc *Allocator
+	alloc   *Allocator
+	writes  *WriteCounter
+	commits *Committer
 
 	resources query.ResourceManagement
 
@@ -68,11 +73,17 @@ func (e *executor) createExecutionState(ctx context.Context, orgID platform.ID,
 	}
 	// Set allocation limit
 	a.Limit = p.Resources.MemoryBytesQuota
+	// Queries without an authorization run with a nil one.
+	auth, _ := idpctx.GetAuthorization(ctx)
 	es := &executionState{
+		ctx:       ctx,
 		orgID:     orgID,
+		auth:      auth,
 		p:         p,
 		deps:      e.deps,
 		alloc:     a,
+		writes:    WriteCounterFromContext(ctx),
+		commits:   CommitterFromContext(ctx),
 		resources: p.Resources,
 		results:   make(map[string]query.Result, len(p.Results)),
 		// TODO(nathanielc): Have the planner specify the dispatcher throughput
@@ -217,10 +228,18 @@ type executionContext struct {
 }
 
 // Satisfy the ExecutionContext interface
+func (ec executionContext) Context() context.Context {
+	return ec.es.ctx
+}
+
 func (ec executionContext) OrganizationID() platform.ID {
 	return ec.es.orgID
 }
 
+func (ec executionContext) Authorization() *platform.Authorization {
+	return ec.es.auth
+}
+
 func (ec executionContext) ResolveTime(qt query.Time) Time {
 	return Time(qt.Time(ec.es.p.Now).UnixNano())
 }
@@ -232,6 +251,14 @@ func (ec executionContext) Allocator() *Allocator {
 	return ec.es.alloc
 }
 
+func (ec executionContext) WriteCounter() *WriteCounter {
+	return ec.es.writes
+}
+
+func (ec executionContext) Committer() *Committer {
+	return ec.es.commits
+}
+
 func (ec executionContext) Parents() []DatasetID {
 	return ec.parents
 }
@@ -0,0 +1,222 @@
+package execute
+
+import "github.com/influxdata/platform/query"
+
+// nulls is a bitmap recording which rows of a column are null.
+// The bitmap is allocated lazily, a column without any null values has no bitmap.
+type nulls struct {
+	bits []uint64
+}
+
+// IsNull reports whether row i is null.
+func (n *nulls) IsNull(i int) bool {
+	w := i / 64
+	if w >= len(n.bits) {
+		return false
+	}
+	return n.bits[w]&(1<<uint(i%64)) != 0
+}
+
+// setNull marks row i as null or not null.
+func (n *nulls) setNull(i int, null bool) {
+	w := i / 64
+	if w >= len(n.bits) {
+		if !null {
+			return
+		}
+		bits := make([]uint64, w+1)
+		copy(bits, n.bits)
+		n.bits = bits
+	}
+	if null {
+		n.bits[w] |= 1 << uint(i%64)
+	} else {
+		n.bits[w] &^= 1 << uint(i%64)
+	}
+}
+
+// hasNulls reports whether any row is null, without visiting every row.
+func (n *nulls) hasNulls() bool {
+	for _, w := range n.bits {
+		if w != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// any reports whether either row i or j is null.
+func (n *nulls) any(i, j int) bool {
+	return n.bits != nil && (n.IsNull(i) || n.IsNull(j))
+}
+
+// less orders null rows before non-null rows.
+func (n *nulls) less(i, j int) bool {
+	return n.IsNull(i) && !n.IsNull(j)
+}
+
+func (n *nulls) swap(i, j int) {
+	if n.bits == nil {
+		return
+	}
+	ni, nj := n.IsNull(i), n.IsNull(j)
+	n.setNull(i, nj)
+	n.setNull(j, ni)
+}
+
+func (n *nulls) clear() {
+	n.bits = nil
+}
+
+func (n *nulls) copy() nulls {
+	if n.bits == nil {
+		return nulls{}
+	}
+	bits := make([]uint64, len(n.bits))
+	copy(bits, n.bits)
+	return nulls{bits: bits}
+}
+
+// nullsReader is implemented by column readers that can report whether a column has null values
+// without checking every row.
+type nullsReader interface {
+	HasNulls(j int) bool
+}
+
+// hasNulls reports whether any value of column j in cr is null.
+func hasNulls(j int, cr query.ColReader) bool {
+	if nr, ok := cr.(nullsReader); ok {
+		return nr.HasNulls(j)
+	}
+	for i, l := 0, cr.Len(); i < l; i++ {
+		if cr.IsNull(i, j) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipNulls returns a ColReader with only the rows of cr where column j is not null.
+// If column j has no null values cr is returned directly.
+func SkipNulls(j int, cr query.ColReader) query.ColReader {
+	if !hasNulls(j, cr) {
+		return cr
+	}
+	rows := make([]int, 0, cr.Len())
+	for i, l := 0, cr.Len(); i < l; i++ {
+		if !cr.IsNull(i, j) {
+			rows = append(rows, i)
+		}
+	}
+	return &rowsColReader{
+		ColReader: cr,
+		rows:      rows,
+		cols:      make(map[int]interface{}),
+	}
+}
+
+// rowsColReader is a view of a subset of the rows of a ColReader.
+// The column slices are gathered on first access.
+type rowsColReader struct {
+	query.ColReader
+	rows []int
+	cols map[int]interface{}
+}
+
+func (cr *rowsColReader) Len() int {
+	return len(cr.rows)
+}
+
+func (cr *rowsColReader) IsNull(i, j int) bool {
+	return cr.ColReader.IsNull(cr.rows[i], j)
+}
+
+func (cr *rowsColReader) HasNulls(j int) bool {
+	if !hasNulls(j, cr.ColReader) {
+		return false
+	}
+	for _, r := range cr.rows {
+		if cr.ColReader.IsNull(r, j) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cr *rowsColReader) Bools(j int) []bool {
+	if vs, ok := cr.cols[j]; ok {
+		return vs.([]bool)
+	}
+	src := cr.ColReader.Bools(j)
+	vs := make([]bool, len(cr.rows))
+	for i, r := range cr.rows {
+		vs[i] = src[r]
+	}
+	cr.cols[j] = vs
+	return vs
+}
+
+func (cr *rowsColReader) Ints(j int) []int64 {
+	if vs, ok := cr.cols[j]; ok {
+		return vs.([]int64)
+	}
+	src := cr.ColReader.Ints(j)
+	vs := make([]int64, len(cr.rows))
+	for i, r := range cr.rows {
+		vs[i] = src[r]
+	}
+	cr.cols[j] = vs
+	return vs
+}
+
+func (cr *rowsColReader) UInts(j int) []uint64 {
+	if vs, ok := cr.cols[j]; ok {
+		return vs.([]uint64)
+	}
+	src := cr.ColReader.UInts(j)
+	vs := make([]uint64, len(cr.rows))
+	for i, r := range cr.rows {
+		vs[i] = src[r]
+	}
+	cr.cols[j] = vs
+	return vs
+}
+
+func (cr *rowsColReader) Floats(j int) []float64 {
+	if vs, ok := cr.cols[j]; ok {
+		return vs.([]float64)
+	}
+	src := cr.ColReader.Floats(j)
+	vs := make([]float64, len(cr.rows))
+	for i, r := range cr.rows {
+		vs[i] = src[r]
+	}
+	cr.cols[j] = vs
+	return vs
+}
+
+func (cr *rowsColReader) Strings(j int) []string {
+	if vs, ok := cr.cols[j]; ok {
+		return vs.([]string)
+	}
+	src := cr.ColReader.Strings(j)
+	vs := make([]string, len(cr.rows))
+	for i, r := range cr.rows {
+		vs[i] = src[r]
+	}
+	cr.cols[j] = vs
+	return vs
+}
+
+func (cr *rowsColReader) Times(j int) []Time {
+	if vs, ok := cr.cols[j]; ok {
+		return vs.([]Time)
+	}
+	src := cr.ColReader.Times(j)
+	vs := make([]Time, len(cr.rows))
+	for i, r := range cr.rows {
+		vs[i] = src[r]
+	}
+	cr.cols[j] = vs
+	return vs
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 
 	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/ast"
 	"github.com/influxdata/platform/query/compiler"
 	"github.com/influxdata/platform/query/semantic"
 	"github.com/influxdata/platform/query/values"
@@ -23,6 +24,11 @@ type rowFn struct {
 
 	recordCols map[string]int
 	references []string
+
+	// propertyRefs are the columns that each property of the returned object is computed from,
+	// not counting columns that are only tested with the exists operator.
+	// A function that does not return an object literal has a single property, the default value column.
+	propertyRefs map[string][]string
 }
 
 func newRowFn(fn *semantic.FunctionExpression) (rowFn, error) {
@@ -30,12 +36,22 @@ func newRowFn(fn *semantic.FunctionExpression) (rowFn, error) {
 		return rowFn{}, fmt.Errorf("function should only have a single parameter, got %d", len(fn.Params))
 	}
 	scope, decls := query.BuiltIns()
+	recordName := fn.Params[0].Key.Name
+	propertyRefs := make(map[string][]string)
+	if obj, ok := fn.Body.(*semantic.ObjectExpression); ok {
+		for _, p := range obj.Properties {
+			propertyRefs[p.Key.Name] = findValueReferences(recordName, p.Value)
+		}
+	} else {
+		propertyRefs[DefaultValueColLabel] = findValueReferences(recordName, fn.Body)
+	}
 	return rowFn{
 		compilationCache: compiler.NewCompilationCache(fn, scope, decls),
 		scope:            make(compiler.Scope, 1),
-		recordName:       fn.Params[0].Key.Name,
+		recordName:       recordName,
 		references:       findColReferences(fn),
 		recordCols:       make(map[string]int),
+		propertyRefs:     propertyRefs,
 	}, nil
 }
 
@@ -114,8 +130,13 @@ func ConvertFromKind(k semantic.Kind) query.DataType {
 
 func (f *rowFn) eval(row int, cr query.ColReader) (values.Value, error) {
 	for _, r := range f.references {
-		f.record.Set(r, ValueForRow(row, f.recordCols[r], cr))
+		j := f.recordCols[r]
+		f.record.Set(r, ValueForRow(row, j, cr))
+		if cr.IsNull(row, j) {
+			f.record.SetNull(r)
+		}
 	}
+	f.record.nullRead = false
 	f.scope[f.recordName] = f.record
 	return f.preparedFn.Eval(f.scope)
 }
@@ -145,11 +166,16 @@ func (f *RowPredicateFn) Prepare(cols []query.ColMeta) error {
 	return nil
 }
 
+// Eval evaluates the predicate for the row.
+// A predicate that reads a null value, other than by testing it with the exists operator, does not match the row.
 func (f *RowPredicateFn) Eval(row int, cr query.ColReader) (bool, error) {
 	v, err := f.rowFn.eval(row, cr)
 	if err != nil {
 		return false, err
 	}
+	if f.record.nullRead {
+		return false, nil
+	}
 	return v.Bool(), nil
 }
 
@@ -192,6 +218,17 @@ func (f *RowMapFn) Type() semantic.Type {
 	return f.preparedFn.Type()
 }
 
+// IsNull reports whether the property of the object returned by the last call to Eval is null,
+// because it is computed from a null value.
+func (f *RowMapFn) IsNull(property string) bool {
+	for _, r := range f.propertyRefs[property] {
+		if f.record.nulls[r] {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *RowMapFn) Eval(row int, cr query.ColReader) (values.Object, error) {
 	v, err := f.rowFn.eval(row, cr)
 	if err != nil {
@@ -268,15 +305,42 @@ func (c *colReferenceVisitor) Visit(node semantic.Node) semantic.Visitor {
 
 func (c *colReferenceVisitor) Done() {}
 
+// findValueReferences returns the properties of the record that the value of the expression is computed from,
+// not counting properties that are only tested with the exists operator.
+func findValueReferences(recordName string, expr semantic.Node) []string {
+	v := &valueReferenceVisitor{
+		colReferenceVisitor: colReferenceVisitor{recordName: recordName},
+	}
+	semantic.Walk(v, expr)
+	return v.refs
+}
+
+type valueReferenceVisitor struct {
+	colReferenceVisitor
+}
+
+func (c *valueReferenceVisitor) Visit(node semantic.Node) semantic.Visitor {
+	if ue, ok := node.(*semantic.UnaryExpression); ok && ue.Operator == ast.ExistsOperator {
+		return nil
+	}
+	c.colReferenceVisitor.Visit(node)
+	return c
+}
+
 type Record struct {
 	t      semantic.Type
 	values map[string]values.Value
+	nulls  map[string]bool
+
+	// nullRead records whether Get has been called for a null property.
+	nullRead bool
 }
 
 func NewRecord(t semantic.Type) *Record {
 	return &Record{
 		t:      t,
 		values: make(map[string]values.Value),
+		nulls:  make(map[string]bool),
 	}
 }
 func (r *Record) Type() semantic.Type {
@@ -335,11 +399,30 @@ func (r *Record) Equal(rhs values.Value) bool {
 
 func (r *Record) Set(name string, v values.Value) {
 	r.values[name] = v
+	delete(r.nulls, name)
+}
+
+// SetNull marks the property as null.
+// Get reports a null property as missing, but still returns its last value
+// so that expressions which do not check for existence can be evaluated.
+func (r *Record) SetNull(name string) {
+	r.nulls[name] = true
 }
 func (r *Record) Get(name string) (values.Value, bool) {
 	v, ok := r.values[name]
+	if ok && r.nulls[name] {
+		r.nullRead = true
+		return v, false
+	}
 	return v, ok
 }
+
+// Exists reports whether the property has a value that is not null.
+// Unlike Get, it does not count as reading a null property.
+func (r *Record) Exists(name string) bool {
+	_, ok := r.values[name]
+	return ok && !r.nulls[name]
+}
 func (r *Record) Len() int {
 	return len(r.values)
 }
@@ -123,6 +123,7 @@ func (t *indexSelectorTransformation) Process(id DatasetID, tbl query.Table) err
 	}
 
 	return tbl.Do(func(cr query.ColReader) error {
+		cr = SkipNulls(valueIdx, cr)
 		switch valueCol.Type {
 		case query.TBool:
 			selected := s.(DoBoolIndexSelector).DoBool(cr.Bools(valueIdx))
@@ -178,6 +179,7 @@ func (t *rowSelectorTransformation) Process(id DatasetID, tbl query.Table) error
 	}
 
 	tbl.Do(func(cr query.ColReader) error {
+		cr = SkipNulls(valueIdx, cr)
 		switch valueCol.Type {
 		case query.TBool:
 			rower.(DoBoolRowSelector).DoBool(cr.Bools(valueIdx), cr)
@@ -203,25 +205,9 @@ func (t *indexSelectorTransformation) appendSelected(selected []int, builder Tab
 	if len(selected) == 0 {
 		return
 	}
-	cols := builder.Cols()
-	for j, c := range cols {
+	for j := range builder.Cols() {
 		for _, i := range selected {
-			switch c.Type {
-			case query.TBool:
-				builder.AppendBool(j, cr.Bools(j)[i])
-			case query.TInt:
-				builder.AppendInt(j, cr.Ints(j)[i])
-			case query.TUInt:
-				builder.AppendUInt(j, cr.UInts(j)[i])
-			case query.TFloat:
-				builder.AppendFloat(j, cr.Floats(j)[i])
-			case query.TString:
-				builder.AppendString(j, cr.Strings(j)[i])
-			case query.TTime:
-				builder.AppendTime(j, cr.Times(j)[i])
-			default:
-				PanicUnknownType(c.Type)
-			}
+			AppendColValue(j, j, i, cr, builder)
 		}
 	}
 }
@@ -231,6 +217,10 @@ func (t *rowSelectorTransformation) appendRows(builder TableBuilder, rows []Row)
 	for j, c := range cols {
 		for _, row := range rows {
 			v := row.Values[j]
+			if v == nil {
+				builder.AppendNil(j)
+				continue
+			}
 			switch c.Type {
 			case query.TBool:
 				builder.AppendBool(j, v.(bool))
@@ -307,6 +297,7 @@ type DoStringRowSelector interface {
 	DoString(vs []string, cr query.ColReader)
 }
 
+// Row is a single row of a table, null values are nil.
 type Row struct {
 	Values []interface{}
 }
@@ -315,6 +306,9 @@ func ReadRow(i int, cr query.ColReader) (row Row) {
 	cols := cr.Cols()
 	row.Values = make([]interface{}, len(cols))
 	for j, c := range cols {
+		if cr.IsNull(i, j) {
+			continue
+		}
 		switch c.Type {
 		case query.TBool:
 			row.Values[j] = cr.Bools(j)[i]
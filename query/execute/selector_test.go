@@ -59,6 +59,38 @@ func TestRowSelector_Process(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: "null values",
+			config: execute.SelectorConfig{
+				Column: "_value",
+			},
+			data: []*executetest.Table{{
+				KeyCols: []string{"_start", "_stop"},
+				ColMeta: []query.ColMeta{
+					{Label: "_start", Type: query.TTime},
+					{Label: "_stop", Type: query.TTime},
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), execute.Time(100), execute.Time(1), nil},
+					{execute.Time(0), execute.Time(100), execute.Time(10), 1.0},
+					{execute.Time(0), execute.Time(100), execute.Time(20), 2.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols: []string{"_start", "_stop"},
+				ColMeta: []query.ColMeta{
+					{Label: "_start", Type: query.TTime},
+					{Label: "_stop", Type: query.TTime},
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), execute.Time(100), execute.Time(10), 1.0},
+				},
+			}},
+		},
 		{
 			name: "single custom column",
 			config: execute.SelectorConfig{
@@ -256,6 +288,38 @@ func TestIndexSelector_Process(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: "null values",
+			config: execute.SelectorConfig{
+				Column: "_value",
+			},
+			data: []*executetest.Table{{
+				KeyCols: []string{"_start", "_stop"},
+				ColMeta: []query.ColMeta{
+					{Label: "_start", Type: query.TTime},
+					{Label: "_stop", Type: query.TTime},
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), execute.Time(100), execute.Time(1), nil},
+					{execute.Time(0), execute.Time(100), execute.Time(10), 1.0},
+					{execute.Time(0), execute.Time(100), execute.Time(20), 2.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols: []string{"_start", "_stop"},
+				ColMeta: []query.ColMeta{
+					{Label: "_start", Type: query.TTime},
+					{Label: "_stop", Type: query.TTime},
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), execute.Time(100), execute.Time(10), 1.0},
+				},
+			}},
+		},
 		{
 			name: "multiple tables",
 			config: execute.SelectorConfig{
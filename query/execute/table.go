@@ -138,6 +138,12 @@ func AppendCols(cr query.ColReader, builder TableBuilder, colMap []int) {
 // AppendCol append a column from cr onto builder
 // The indexes bj and cj are builder and col reader indexes respectively.
 func AppendCol(bj, cj int, cr query.ColReader, builder TableBuilder) {
+	if hasNulls(cj, cr) {
+		for i := 0; i < cr.Len(); i++ {
+			AppendColValue(bj, cj, i, cr, builder)
+		}
+		return
+	}
 	c := cr.Cols()[cj]
 	switch c.Type {
 	case query.TBool:
@@ -157,69 +163,50 @@ func AppendCol(bj, cj int, cr query.ColReader, builder TableBuilder) {
 	}
 }
 
+// AppendColValue appends the value in row i of column cj from cr onto column bj of builder.
+// Null values are appended as nulls.
+func AppendColValue(bj, cj, i int, cr query.ColReader, builder TableBuilder) {
+	if cr.IsNull(i, cj) {
+		builder.AppendNil(bj)
+		return
+	}
+	c := cr.Cols()[cj]
+	switch c.Type {
+	case query.TBool:
+		builder.AppendBool(bj, cr.Bools(cj)[i])
+	case query.TInt:
+		builder.AppendInt(bj, cr.Ints(cj)[i])
+	case query.TUInt:
+		builder.AppendUInt(bj, cr.UInts(cj)[i])
+	case query.TFloat:
+		builder.AppendFloat(bj, cr.Floats(cj)[i])
+	case query.TString:
+		builder.AppendString(bj, cr.Strings(cj)[i])
+	case query.TTime:
+		builder.AppendTime(bj, cr.Times(cj)[i])
+	default:
+		PanicUnknownType(c.Type)
+	}
+}
+
 // AppendMappedRecord appends the record from cr onto builder assuming matching columns.
 func AppendRecord(i int, cr query.ColReader, builder TableBuilder) {
-	for j, c := range builder.Cols() {
-		switch c.Type {
-		case query.TBool:
-			builder.AppendBool(j, cr.Bools(j)[i])
-		case query.TInt:
-			builder.AppendInt(j, cr.Ints(j)[i])
-		case query.TUInt:
-			builder.AppendUInt(j, cr.UInts(j)[i])
-		case query.TFloat:
-			builder.AppendFloat(j, cr.Floats(j)[i])
-		case query.TString:
-			builder.AppendString(j, cr.Strings(j)[i])
-		case query.TTime:
-			builder.AppendTime(j, cr.Times(j)[i])
-		default:
-			PanicUnknownType(c.Type)
-		}
+	for j := range builder.Cols() {
+		AppendColValue(j, j, i, cr, builder)
 	}
 }
 
 // AppendMappedRecord appends the records from cr onto builder, using colMap as a map of builder index to cr index.
 func AppendMappedRecord(i int, cr query.ColReader, builder TableBuilder, colMap []int) {
-	for j, c := range builder.Cols() {
-		switch c.Type {
-		case query.TBool:
-			builder.AppendBool(j, cr.Bools(colMap[j])[i])
-		case query.TInt:
-			builder.AppendInt(j, cr.Ints(colMap[j])[i])
-		case query.TUInt:
-			builder.AppendUInt(j, cr.UInts(colMap[j])[i])
-		case query.TFloat:
-			builder.AppendFloat(j, cr.Floats(colMap[j])[i])
-		case query.TString:
-			builder.AppendString(j, cr.Strings(colMap[j])[i])
-		case query.TTime:
-			builder.AppendTime(j, cr.Times(colMap[j])[i])
-		default:
-			PanicUnknownType(c.Type)
-		}
+	for j := range builder.Cols() {
+		AppendColValue(j, colMap[j], i, cr, builder)
 	}
 }
 
 // AppendRecordForCols appends the only the columns provided from cr onto builder.
 func AppendRecordForCols(i int, cr query.ColReader, builder TableBuilder, cols []query.ColMeta) {
-	for j, c := range cols {
-		switch c.Type {
-		case query.TBool:
-			builder.AppendBool(j, cr.Bools(j)[i])
-		case query.TInt:
-			builder.AppendInt(j, cr.Ints(j)[i])
-		case query.TUInt:
-			builder.AppendUInt(j, cr.UInts(j)[i])
-		case query.TFloat:
-			builder.AppendFloat(j, cr.Floats(j)[i])
-		case query.TString:
-			builder.AppendString(j, cr.Strings(j)[i])
-		case query.TTime:
-			builder.AppendTime(j, cr.Times(j)[i])
-		default:
-			PanicUnknownType(c.Type)
-		}
+	for j := range cols {
+		AppendColValue(j, j, i, cr, builder)
 	}
 }
 
@@ -301,6 +288,11 @@ type TableBuilder interface {
 	AppendStrings(j int, values []string)
 	AppendTimes(j int, values []Time)
 
+	// SetNil marks the value at the specified coordinates as null.
+	SetNil(i, j int)
+	// AppendNil appends a null value to the column.
+	AppendNil(j int)
+
 	// Sort the rows of the by the values of the columns in the order listed.
 	Sort(cols []string, desc bool)
 
@@ -381,7 +373,9 @@ func (b ColListTableBuilder) AddCol(c query.ColMeta) int {
 
 func (b ColListTableBuilder) SetBool(i int, j int, value bool) {
 	b.checkColType(j, query.TBool)
-	b.table.cols[j].(*boolColumn).data[i] = value
+	col := b.table.cols[j].(*boolColumn)
+	col.data[i] = value
+	col.setNull(i, false)
 }
 func (b ColListTableBuilder) AppendBool(j int, value bool) {
 	b.checkColType(j, query.TBool)
@@ -398,7 +392,9 @@ func (b ColListTableBuilder) AppendBools(j int, values []bool) {
 
 func (b ColListTableBuilder) SetInt(i int, j int, value int64) {
 	b.checkColType(j, query.TInt)
-	b.table.cols[j].(*intColumn).data[i] = value
+	col := b.table.cols[j].(*intColumn)
+	col.data[i] = value
+	col.setNull(i, false)
 }
 func (b ColListTableBuilder) AppendInt(j int, value int64) {
 	b.checkColType(j, query.TInt)
@@ -415,7 +411,9 @@ func (b ColListTableBuilder) AppendInts(j int, values []int64) {
 
 func (b ColListTableBuilder) SetUInt(i int, j int, value uint64) {
 	b.checkColType(j, query.TUInt)
-	b.table.cols[j].(*uintColumn).data[i] = value
+	col := b.table.cols[j].(*uintColumn)
+	col.data[i] = value
+	col.setNull(i, false)
 }
 func (b ColListTableBuilder) AppendUInt(j int, value uint64) {
 	b.checkColType(j, query.TUInt)
@@ -432,7 +430,9 @@ func (b ColListTableBuilder) AppendUInts(j int, values []uint64) {
 
 func (b ColListTableBuilder) SetFloat(i int, j int, value float64) {
 	b.checkColType(j, query.TFloat)
-	b.table.cols[j].(*floatColumn).data[i] = value
+	col := b.table.cols[j].(*floatColumn)
+	col.data[i] = value
+	col.setNull(i, false)
 }
 func (b ColListTableBuilder) AppendFloat(j int, value float64) {
 	b.checkColType(j, query.TFloat)
@@ -449,7 +449,9 @@ func (b ColListTableBuilder) AppendFloats(j int, values []float64) {
 
 func (b ColListTableBuilder) SetString(i int, j int, value string) {
 	b.checkColType(j, query.TString)
-	b.table.cols[j].(*stringColumn).data[i] = value
+	col := b.table.cols[j].(*stringColumn)
+	col.data[i] = value
+	col.setNull(i, false)
 }
 func (b ColListTableBuilder) AppendString(j int, value string) {
 	meta := b.table.cols[j].Meta()
@@ -467,7 +469,9 @@ func (b ColListTableBuilder) AppendStrings(j int, values []string) {
 
 func (b ColListTableBuilder) SetTime(i int, j int, value Time) {
 	b.checkColType(j, query.TTime)
-	b.table.cols[j].(*timeColumn).data[i] = value
+	col := b.table.cols[j].(*timeColumn)
+	col.data[i] = value
+	col.setNull(i, false)
 }
 func (b ColListTableBuilder) AppendTime(j int, value Time) {
 	b.checkColType(j, query.TTime)
@@ -482,6 +486,38 @@ func (b ColListTableBuilder) AppendTimes(j int, values []Time) {
 	b.table.nrows = len(col.data)
 }
 
+// SetNil marks the value at the specified coordinates as null.
+func (b ColListTableBuilder) SetNil(i, j int) {
+	b.table.cols[j].setNull(i, true)
+}
+
+// AppendNil appends a null value to the column.
+func (b ColListTableBuilder) AppendNil(j int) {
+	switch col := b.table.cols[j].(type) {
+	case *boolColumn:
+		col.data = b.alloc.AppendBools(col.data, false)
+		b.table.nrows = len(col.data)
+	case *intColumn:
+		col.data = b.alloc.AppendInts(col.data, 0)
+		b.table.nrows = len(col.data)
+	case *uintColumn:
+		col.data = b.alloc.AppendUInts(col.data, 0)
+		b.table.nrows = len(col.data)
+	case *floatColumn:
+		col.data = b.alloc.AppendFloats(col.data, 0)
+		b.table.nrows = len(col.data)
+	case *stringColumn:
+		col.data = b.alloc.AppendStrings(col.data, "")
+		b.table.nrows = len(col.data)
+	case *timeColumn:
+		col.data = b.alloc.AppendTimes(col.data, 0)
+		b.table.nrows = len(col.data)
+	default:
+		PanicUnknownType(b.table.colMeta[j].Type)
+	}
+	b.table.cols[j].setNull(b.table.nrows-1, true)
+}
+
 func (b ColListTableBuilder) checkColType(j int, typ query.DataType) {
 	CheckColType(b.table.colMeta[j], typ)
 }
@@ -597,6 +633,15 @@ func (t *ColListTable) Times(j int) []Time {
 	return t.cols[j].(*timeColumn).data
 }
 
+func (t *ColListTable) IsNull(i, j int) bool {
+	return t.cols[j].IsNull(i)
+}
+
+// HasNulls reports whether column j has any null values.
+func (t *ColListTable) HasNulls(j int) bool {
+	return t.cols[j].hasNulls()
+}
+
 func (t *ColListTable) Copy() *ColListTable {
 	cpy := new(ColListTable)
 	cpy.key = t.key
@@ -649,12 +694,16 @@ type column interface {
 	Equal(i, j int) bool
 	Less(i, j int) bool
 	Swap(i, j int)
+	IsNull(i int) bool
+	hasNulls() bool
+	setNull(i int, null bool)
 }
 
 type boolColumn struct {
 	query.ColMeta
 	data  []bool
 	alloc *Allocator
+	nulls
 }
 
 func (c *boolColumn) Meta() query.ColMeta {
@@ -664,11 +713,13 @@ func (c *boolColumn) Meta() query.ColMeta {
 func (c *boolColumn) Clear() {
 	c.alloc.Free(len(c.data), boolSize)
 	c.data = c.data[0:0]
+	c.nulls.clear()
 }
 func (c *boolColumn) Copy() column {
 	cpy := &boolColumn{
 		ColMeta: c.ColMeta,
 		alloc:   c.alloc,
+		nulls:   c.nulls.copy(),
 	}
 	l := len(c.data)
 	cpy.data = c.alloc.Bools(l, l)
@@ -676,9 +727,15 @@ func (c *boolColumn) Copy() column {
 	return cpy
 }
 func (c *boolColumn) Equal(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.IsNull(i) == c.IsNull(j)
+	}
 	return c.data[i] == c.data[j]
 }
 func (c *boolColumn) Less(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.nulls.less(i, j)
+	}
 	if c.data[i] == c.data[j] {
 		return false
 	}
@@ -686,12 +743,14 @@ func (c *boolColumn) Less(i, j int) bool {
 }
 func (c *boolColumn) Swap(i, j int) {
 	c.data[i], c.data[j] = c.data[j], c.data[i]
+	c.nulls.swap(i, j)
 }
 
 type intColumn struct {
 	query.ColMeta
 	data  []int64
 	alloc *Allocator
+	nulls
 }
 
 func (c *intColumn) Meta() query.ColMeta {
@@ -701,11 +760,13 @@ func (c *intColumn) Meta() query.ColMeta {
 func (c *intColumn) Clear() {
 	c.alloc.Free(len(c.data), int64Size)
 	c.data = c.data[0:0]
+	c.nulls.clear()
 }
 func (c *intColumn) Copy() column {
 	cpy := &intColumn{
 		ColMeta: c.ColMeta,
 		alloc:   c.alloc,
+		nulls:   c.nulls.copy(),
 	}
 	l := len(c.data)
 	cpy.data = c.alloc.Ints(l, l)
@@ -713,19 +774,27 @@ func (c *intColumn) Copy() column {
 	return cpy
 }
 func (c *intColumn) Equal(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.IsNull(i) == c.IsNull(j)
+	}
 	return c.data[i] == c.data[j]
 }
 func (c *intColumn) Less(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.nulls.less(i, j)
+	}
 	return c.data[i] < c.data[j]
 }
 func (c *intColumn) Swap(i, j int) {
 	c.data[i], c.data[j] = c.data[j], c.data[i]
+	c.nulls.swap(i, j)
 }
 
 type uintColumn struct {
 	query.ColMeta
 	data  []uint64
 	alloc *Allocator
+	nulls
 }
 
 func (c *uintColumn) Meta() query.ColMeta {
@@ -735,11 +804,13 @@ func (c *uintColumn) Meta() query.ColMeta {
 func (c *uintColumn) Clear() {
 	c.alloc.Free(len(c.data), uint64Size)
 	c.data = c.data[0:0]
+	c.nulls.clear()
 }
 func (c *uintColumn) Copy() column {
 	cpy := &uintColumn{
 		ColMeta: c.ColMeta,
 		alloc:   c.alloc,
+		nulls:   c.nulls.copy(),
 	}
 	l := len(c.data)
 	cpy.data = c.alloc.UInts(l, l)
@@ -747,19 +818,27 @@ func (c *uintColumn) Copy() column {
 	return cpy
 }
 func (c *uintColumn) Equal(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.IsNull(i) == c.IsNull(j)
+	}
 	return c.data[i] == c.data[j]
 }
 func (c *uintColumn) Less(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.nulls.less(i, j)
+	}
 	return c.data[i] < c.data[j]
 }
 func (c *uintColumn) Swap(i, j int) {
 	c.data[i], c.data[j] = c.data[j], c.data[i]
+	c.nulls.swap(i, j)
 }
 
 type floatColumn struct {
 	query.ColMeta
 	data  []float64
 	alloc *Allocator
+	nulls
 }
 
 func (c *floatColumn) Meta() query.ColMeta {
@@ -769,11 +848,13 @@ func (c *floatColumn) Meta() query.ColMeta {
 func (c *floatColumn) Clear() {
 	c.alloc.Free(len(c.data), float64Size)
 	c.data = c.data[0:0]
+	c.nulls.clear()
 }
 func (c *floatColumn) Copy() column {
 	cpy := &floatColumn{
 		ColMeta: c.ColMeta,
 		alloc:   c.alloc,
+		nulls:   c.nulls.copy(),
 	}
 	l := len(c.data)
 	cpy.data = c.alloc.Floats(l, l)
@@ -781,19 +862,27 @@ func (c *floatColumn) Copy() column {
 	return cpy
 }
 func (c *floatColumn) Equal(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.IsNull(i) == c.IsNull(j)
+	}
 	return c.data[i] == c.data[j]
 }
 func (c *floatColumn) Less(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.nulls.less(i, j)
+	}
 	return c.data[i] < c.data[j]
 }
 func (c *floatColumn) Swap(i, j int) {
 	c.data[i], c.data[j] = c.data[j], c.data[i]
+	c.nulls.swap(i, j)
 }
 
 type stringColumn struct {
 	query.ColMeta
 	data  []string
 	alloc *Allocator
+	nulls
 }
 
 func (c *stringColumn) Meta() query.ColMeta {
@@ -803,11 +892,13 @@ func (c *stringColumn) Meta() query.ColMeta {
 func (c *stringColumn) Clear() {
 	c.alloc.Free(len(c.data), stringSize)
 	c.data = c.data[0:0]
+	c.nulls.clear()
 }
 func (c *stringColumn) Copy() column {
 	cpy := &stringColumn{
 		ColMeta: c.ColMeta,
 		alloc:   c.alloc,
+		nulls:   c.nulls.copy(),
 	}
 
 	l := len(c.data)
@@ -816,19 +907,27 @@ func (c *stringColumn) Copy() column {
 	return cpy
 }
 func (c *stringColumn) Equal(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.IsNull(i) == c.IsNull(j)
+	}
 	return c.data[i] == c.data[j]
 }
 func (c *stringColumn) Less(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.nulls.less(i, j)
+	}
 	return c.data[i] < c.data[j]
 }
 func (c *stringColumn) Swap(i, j int) {
 	c.data[i], c.data[j] = c.data[j], c.data[i]
+	c.nulls.swap(i, j)
 }
 
 type timeColumn struct {
 	query.ColMeta
 	data  []Time
 	alloc *Allocator
+	nulls
 }
 
 func (c *timeColumn) Meta() query.ColMeta {
@@ -838,11 +937,13 @@ func (c *timeColumn) Meta() query.ColMeta {
 func (c *timeColumn) Clear() {
 	c.alloc.Free(len(c.data), timeSize)
 	c.data = c.data[0:0]
+	c.nulls.clear()
 }
 func (c *timeColumn) Copy() column {
 	cpy := &timeColumn{
 		ColMeta: c.ColMeta,
 		alloc:   c.alloc,
+		nulls:   c.nulls.copy(),
 	}
 	l := len(c.data)
 	cpy.data = c.alloc.Times(l, l)
@@ -850,13 +951,20 @@ func (c *timeColumn) Copy() column {
 	return cpy
 }
 func (c *timeColumn) Equal(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.IsNull(i) == c.IsNull(j)
+	}
 	return c.data[i] == c.data[j]
 }
 func (c *timeColumn) Less(i, j int) bool {
+	if c.nulls.any(i, j) {
+		return c.nulls.less(i, j)
+	}
 	return c.data[i] < c.data[j]
 }
 func (c *timeColumn) Swap(i, j int) {
 	c.data[i], c.data[j] = c.data[j], c.data[i]
+	c.nulls.swap(i, j)
 }
 
 type TableBuilderCache interface {
@@ -1,6 +1,7 @@
 package execute
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/influxdata/platform"
@@ -17,11 +18,22 @@ type Transformation interface {
 }
 
 type Administration interface {
+	// Context returns the context of the query, which is done once the query is canceled.
+	Context() context.Context
 	OrganizationID() platform.ID
+	// Authorization returns the authorization the query is run with.
+	// It is nil when the query was not submitted with one.
+	Authorization() *platform.Authorization
 
 	ResolveTime(qt query.Time) Time
 	Bounds() Bounds
 	Allocator() *Allocator
+	// WriteCounter returns the counter sinks report their writes to.
+	// It may be nil, which discards the counts.
+	WriteCounter() *WriteCounter
+	// Committer returns the committer sources register their deferred work with.
+	// It may be nil, which discards the commits.
+	Committer() *Committer
 	Parents() []DatasetID
 	ConvertID(plan.ProcedureID) DatasetID
 
@@ -0,0 +1,60 @@
+package execute
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// WriteCounter tracks the amount of data a query has written to systems outside of the query engine.
+// Sinks report to the counter available from their Administration.
+// A nil WriteCounter is valid and discards all counts.
+type WriteCounter struct {
+	bytesWritten  int64
+	pointsWritten int64
+}
+
+// AddBytes records that n bytes were written.
+func (c *WriteCounter) AddBytes(n int64) {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.bytesWritten, n)
+}
+
+// BytesWritten reports the total number of bytes written.
+func (c *WriteCounter) BytesWritten() int64 {
+	if c == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&c.bytesWritten)
+}
+
+// AddPoints records that n points were written.
+func (c *WriteCounter) AddPoints(n int64) {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.pointsWritten, n)
+}
+
+// PointsWritten reports the total number of points written.
+func (c *WriteCounter) PointsWritten() int64 {
+	if c == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&c.pointsWritten)
+}
+
+type writeCounterKey struct{}
+
+// ContextWithWriteCounter returns a context that carries c.
+// Queries executed with the returned context report their writes to c.
+func ContextWithWriteCounter(ctx context.Context, c *WriteCounter) context.Context {
+	return context.WithValue(ctx, writeCounterKey{}, c)
+}
+
+// WriteCounterFromContext returns the WriteCounter carried by ctx, or nil if there is none.
+func WriteCounterFromContext(ctx context.Context) *WriteCounter {
+	c, _ := ctx.Value(writeCounterKey{}).(*WriteCounter)
+	return c
+}
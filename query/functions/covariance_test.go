@@ -90,7 +90,9 @@ func TestCovariance_NewQuery(t *testing.T) {
 					{
 						ID: "join2",
 						Spec: &functions.JoinOpSpec{
-							On: []string{"host"},
+							On:     []string{"host"},
+							Method: functions.JoinMethodInner,
+							Fill:   functions.JoinFillNull,
 							TableNames: map[query.OperationID]string{
 								"from0": "x",
 								"from1": "y",
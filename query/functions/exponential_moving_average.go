@@ -0,0 +1,329 @@
+package functions
+
+import (
+	"fmt"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+)
+
+const (
+	ExponentialMovingAverageKind = "exponentialMovingAverage"
+	DoubleEMAKind                = "doubleEMA"
+	TripleEMAKind                = "tripleEMA"
+)
+
+// ExponentialMovingAverageOpSpec computes an exponential moving average with a smoothing factor of 2/(N+1).
+type ExponentialMovingAverageOpSpec struct {
+	N      int64  `json:"n"`
+	Column string `json:"column"`
+}
+
+// DoubleEMAOpSpec computes a double exponential moving average: 2*EMA - EMA(EMA).
+type DoubleEMAOpSpec struct {
+	N      int64  `json:"n"`
+	Column string `json:"column"`
+}
+
+// TripleEMAOpSpec computes a triple exponential moving average: 3*EMA - 3*EMA(EMA) + EMA(EMA(EMA)).
+type TripleEMAOpSpec struct {
+	N      int64  `json:"n"`
+	Column string `json:"column"`
+}
+
+var exponentialMovingAverageSignature = query.DefaultFunctionSignature()
+
+func init() {
+	exponentialMovingAverageSignature.Params["n"] = semantic.Int
+	exponentialMovingAverageSignature.Params["column"] = semantic.String
+
+	query.RegisterFunction(ExponentialMovingAverageKind, createExponentialMovingAverageOpSpec, exponentialMovingAverageSignature)
+	query.RegisterOpSpec(ExponentialMovingAverageKind, newExponentialMovingAverageOp)
+	plan.RegisterProcedureSpec(ExponentialMovingAverageKind, newExponentialMovingAverageProcedure, ExponentialMovingAverageKind)
+	execute.RegisterTransformation(ExponentialMovingAverageKind, createExponentialMovingAverageTransformation)
+
+	query.RegisterFunction(DoubleEMAKind, createDoubleEMAOpSpec, exponentialMovingAverageSignature)
+	query.RegisterOpSpec(DoubleEMAKind, newDoubleEMAOp)
+	plan.RegisterProcedureSpec(DoubleEMAKind, newDoubleEMAProcedure, DoubleEMAKind)
+	execute.RegisterTransformation(DoubleEMAKind, createDoubleEMATransformation)
+
+	query.RegisterFunction(TripleEMAKind, createTripleEMAOpSpec, exponentialMovingAverageSignature)
+	query.RegisterOpSpec(TripleEMAKind, newTripleEMAOp)
+	plan.RegisterProcedureSpec(TripleEMAKind, newTripleEMAProcedure, TripleEMAKind)
+	execute.RegisterTransformation(TripleEMAKind, createTripleEMATransformation)
+}
+
+func createExponentialMovingAverageOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	n, column, err := getMovingAverageArgs(ExponentialMovingAverageKind, args)
+	if err != nil {
+		return nil, err
+	}
+	return &ExponentialMovingAverageOpSpec{
+		N:      n,
+		Column: column,
+	}, nil
+}
+
+func createDoubleEMAOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	n, column, err := getMovingAverageArgs(DoubleEMAKind, args)
+	if err != nil {
+		return nil, err
+	}
+	return &DoubleEMAOpSpec{
+		N:      n,
+		Column: column,
+	}, nil
+}
+
+func createTripleEMAOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	n, column, err := getMovingAverageArgs(TripleEMAKind, args)
+	if err != nil {
+		return nil, err
+	}
+	return &TripleEMAOpSpec{
+		N:      n,
+		Column: column,
+	}, nil
+}
+
+func newExponentialMovingAverageOp() query.OperationSpec {
+	return new(ExponentialMovingAverageOpSpec)
+}
+
+func (s *ExponentialMovingAverageOpSpec) Kind() query.OperationKind {
+	return ExponentialMovingAverageKind
+}
+
+func newDoubleEMAOp() query.OperationSpec {
+	return new(DoubleEMAOpSpec)
+}
+
+func (s *DoubleEMAOpSpec) Kind() query.OperationKind {
+	return DoubleEMAKind
+}
+
+func newTripleEMAOp() query.OperationSpec {
+	return new(TripleEMAOpSpec)
+}
+
+func (s *TripleEMAOpSpec) Kind() query.OperationKind {
+	return TripleEMAKind
+}
+
+type ExponentialMovingAverageProcedureSpec struct {
+	N      int64
+	Column string
+}
+
+type DoubleEMAProcedureSpec struct {
+	N      int64
+	Column string
+}
+
+type TripleEMAProcedureSpec struct {
+	N      int64
+	Column string
+}
+
+func newExponentialMovingAverageProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*ExponentialMovingAverageOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return &ExponentialMovingAverageProcedureSpec{
+		N:      spec.N,
+		Column: spec.Column,
+	}, nil
+}
+
+func newDoubleEMAProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*DoubleEMAOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return &DoubleEMAProcedureSpec{
+		N:      spec.N,
+		Column: spec.Column,
+	}, nil
+}
+
+func newTripleEMAProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*TripleEMAOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return &TripleEMAProcedureSpec{
+		N:      spec.N,
+		Column: spec.Column,
+	}, nil
+}
+
+func (s *ExponentialMovingAverageProcedureSpec) Kind() plan.ProcedureKind {
+	return ExponentialMovingAverageKind
+}
+func (s *ExponentialMovingAverageProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(ExponentialMovingAverageProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+func (s *DoubleEMAProcedureSpec) Kind() plan.ProcedureKind {
+	return DoubleEMAKind
+}
+func (s *DoubleEMAProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(DoubleEMAProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+func (s *TripleEMAProcedureSpec) Kind() plan.ProcedureKind {
+	return TripleEMAKind
+}
+func (s *TripleEMAProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(TripleEMAProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+func createExponentialMovingAverageTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*ExponentialMovingAverageProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewExponentialMovingAverageTransformation(d, cache, s)
+	return t, d, nil
+}
+
+func createDoubleEMATransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*DoubleEMAProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewDoubleEMATransformation(d, cache, s)
+	return t, d, nil
+}
+
+func createTripleEMATransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*TripleEMAProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewTripleEMATransformation(d, cache, s)
+	return t, d, nil
+}
+
+func NewExponentialMovingAverageTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *ExponentialMovingAverageProcedureSpec) *averageTransformation {
+	n := spec.N
+	return newAverageTransformation(d, cache, ExponentialMovingAverageKind, spec.Column, func() averager {
+		return newExponentialAverager(n)
+	})
+}
+
+func NewDoubleEMATransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *DoubleEMAProcedureSpec) *averageTransformation {
+	n := spec.N
+	return newAverageTransformation(d, cache, DoubleEMAKind, spec.Column, func() averager {
+		return &doubleExponentialAverager{
+			ema:    newExponentialAverager(n),
+			emaEMA: newExponentialAverager(n),
+		}
+	})
+}
+
+func NewTripleEMATransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *TripleEMAProcedureSpec) *averageTransformation {
+	n := spec.N
+	return newAverageTransformation(d, cache, TripleEMAKind, spec.Column, func() averager {
+		return &tripleExponentialAverager{
+			ema:       newExponentialAverager(n),
+			emaEMA:    newExponentialAverager(n),
+			emaEMAEMA: newExponentialAverager(n),
+		}
+	})
+}
+
+// exponentialAverager computes an exponential moving average.
+// The average is seeded with the mean of the first n values.
+type exponentialAverager struct {
+	n     int64
+	alpha float64
+	count int64
+	value float64
+}
+
+func newExponentialAverager(n int64) *exponentialAverager {
+	return &exponentialAverager{
+		n:     n,
+		alpha: 2 / float64(n+1),
+	}
+}
+
+func (a *exponentialAverager) add(v float64) (float64, bool) {
+	if a.count < a.n {
+		a.count++
+		a.value += v
+		if a.count < a.n {
+			return 0, false
+		}
+		a.value /= float64(a.n)
+		return a.value, true
+	}
+	a.value = a.alpha*v + (1-a.alpha)*a.value
+	return a.value, true
+}
+
+// doubleExponentialAverager computes 2*EMA - EMA(EMA).
+type doubleExponentialAverager struct {
+	ema    *exponentialAverager
+	emaEMA *exponentialAverager
+}
+
+func (a *doubleExponentialAverager) add(v float64) (float64, bool) {
+	e1, ok := a.ema.add(v)
+	if !ok {
+		return 0, false
+	}
+	e2, ok := a.emaEMA.add(e1)
+	if !ok {
+		return 0, false
+	}
+	return 2*e1 - e2, true
+}
+
+// tripleExponentialAverager computes 3*EMA - 3*EMA(EMA) + EMA(EMA(EMA)).
+type tripleExponentialAverager struct {
+	ema       *exponentialAverager
+	emaEMA    *exponentialAverager
+	emaEMAEMA *exponentialAverager
+}
+
+func (a *tripleExponentialAverager) add(v float64) (float64, bool) {
+	e1, ok := a.ema.add(v)
+	if !ok {
+		return 0, false
+	}
+	e2, ok := a.emaEMA.add(e1)
+	if !ok {
+		return 0, false
+	}
+	e3, ok := a.emaEMAEMA.add(e2)
+	if !ok {
+		return 0, false
+	}
+	return 3*e1 - 3*e2 + e3, true
+}
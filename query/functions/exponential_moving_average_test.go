@@ -0,0 +1,262 @@
+package functions_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/querytest"
+)
+
+func TestExponentialMovingAverage_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "exponential moving average",
+			Raw:  `from(db:"mydb") |> exponentialMovingAverage(n: 5)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "exponentialMovingAverage1",
+						Spec: &functions.ExponentialMovingAverageOpSpec{
+							N:      5,
+							Column: "_value",
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "exponentialMovingAverage1"},
+				},
+			},
+		},
+		{
+			Name: "double exponential moving average",
+			Raw:  `from(db:"mydb") |> doubleEMA(n: 5, column: "x")`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "doubleEMA1",
+						Spec: &functions.DoubleEMAOpSpec{
+							N:      5,
+							Column: "x",
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "doubleEMA1"},
+				},
+			},
+		},
+		{
+			Name: "triple exponential moving average",
+			Raw:  `from(db:"mydb") |> tripleEMA(n: 5)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "tripleEMA1",
+						Spec: &functions.TripleEMAOpSpec{
+							N:      5,
+							Column: "_value",
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "tripleEMA1"},
+				},
+			},
+		},
+		{
+			Name:    "negative n",
+			Raw:     `from(db:"mydb") |> exponentialMovingAverage(n: -1)`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestExponentialMovingAverageOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"exponentialMovingAverage","kind":"exponentialMovingAverage","spec":{"n":3,"column":"_value"}}`)
+	op := &query.Operation{
+		ID: "exponentialMovingAverage",
+		Spec: &functions.ExponentialMovingAverageOpSpec{
+			N:      3,
+			Column: "_value",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestDoubleEMAOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"doubleEMA","kind":"doubleEMA","spec":{"n":3,"column":"_value"}}`)
+	op := &query.Operation{
+		ID: "doubleEMA",
+		Spec: &functions.DoubleEMAOpSpec{
+			N:      3,
+			Column: "_value",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestTripleEMAOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"tripleEMA","kind":"tripleEMA","spec":{"n":3,"column":"_value"}}`)
+	op := &query.Operation{
+		ID: "tripleEMA",
+		Spec: &functions.TripleEMAOpSpec{
+			N:      3,
+			Column: "_value",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestExponentialMovingAverage_Process(t *testing.T) {
+	testCases := []struct {
+		name   string
+		create func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation
+		data   []query.Table
+		want   []*executetest.Table
+	}{
+		{
+			name: "exponential moving average",
+			create: func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+				return functions.NewExponentialMovingAverageTransformation(d, c, &functions.ExponentialMovingAverageProcedureSpec{
+					N:      3,
+					Column: "_value",
+				})
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+					{execute.Time(2), 2.0},
+					{execute.Time(3), 3.0},
+					{execute.Time(4), nil},
+					{execute.Time(5), 6.0},
+					{execute.Time(6), 2.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(3), 2.0},
+					{execute.Time(5), 4.0},
+					{execute.Time(6), 3.0},
+				},
+			}},
+		},
+		{
+			name: "double exponential moving average",
+			create: func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+				return functions.NewDoubleEMATransformation(d, c, &functions.DoubleEMAProcedureSpec{
+					N:      3,
+					Column: "_value",
+				})
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(1)},
+					{execute.Time(2), int64(2)},
+					{execute.Time(3), int64(3)},
+					{execute.Time(4), int64(6)},
+					{execute.Time(5), int64(2)},
+					{execute.Time(6), int64(4)},
+					{execute.Time(7), int64(8)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(5), 3.0},
+					{execute.Time(6), 3.75},
+					{execute.Time(7), 7.0},
+				},
+			}},
+		},
+		{
+			name: "triple exponential moving average",
+			create: func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+				return functions.NewTripleEMATransformation(d, c, &functions.TripleEMAProcedureSpec{
+					N:      3,
+					Column: "_value",
+				})
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 3.0},
+					{execute.Time(2), 6.0},
+					{execute.Time(3), 6.0},
+					{execute.Time(4), 2.0},
+					{execute.Time(5), 5.0},
+					{execute.Time(6), 8.0},
+					{execute.Time(7), 5.0},
+					{execute.Time(8), 1.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(7), 5.5},
+					{execute.Time(8), 1.4921875},
+				},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				nil,
+				tc.create,
+			)
+		})
+	}
+}
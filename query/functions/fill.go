@@ -0,0 +1,247 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+)
+
+const FillKind = "fill"
+
+// FillOpSpec replaces the null values of a column.
+// The fill value is encoded as a string together with its type so the spec can be marshaled.
+type FillOpSpec struct {
+	Column      string `json:"column"`
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	UsePrevious bool   `json:"usePrevious"`
+}
+
+var fillSignature = query.DefaultFunctionSignature()
+
+func init() {
+	fillSignature.Params["column"] = semantic.String
+	fillSignature.Params["usePrevious"] = semantic.Bool
+
+	query.RegisterFunction(FillKind, createFillOpSpec, fillSignature)
+	query.RegisterOpSpec(FillKind, newFillOp)
+	plan.RegisterProcedureSpec(FillKind, newFillProcedure, FillKind)
+	execute.RegisterTransformation(FillKind, createFillTransformation)
+}
+
+func createFillOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := new(FillOpSpec)
+	if col, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = col
+	} else {
+		spec.Column = execute.DefaultValueColLabel
+	}
+
+	if usePrevious, ok, err := args.GetBool("usePrevious"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.UsePrevious = usePrevious
+	}
+
+	value, ok := args.Get("value")
+	switch {
+	case ok && spec.UsePrevious:
+		return nil, errors.New("fill requires exactly one of value or usePrevious")
+	case !ok && !spec.UsePrevious:
+		return nil, errors.New("fill requires either value or usePrevious")
+	case ok:
+		typ, v, err := encodeFillValue(value)
+		if err != nil {
+			return nil, err
+		}
+		spec.Type = typ
+		spec.Value = v
+	}
+	return spec, nil
+}
+
+func newFillOp() query.OperationSpec {
+	return new(FillOpSpec)
+}
+
+func (s *FillOpSpec) Kind() query.OperationKind {
+	return FillKind
+}
+
+func encodeFillValue(v values.Value) (string, string, error) {
+	switch k := v.Type().Kind(); k {
+	case semantic.Bool:
+		return query.TBool.String(), strconv.FormatBool(v.Bool()), nil
+	case semantic.Int:
+		return query.TInt.String(), strconv.FormatInt(v.Int(), 10), nil
+	case semantic.UInt:
+		return query.TUInt.String(), strconv.FormatUint(v.UInt(), 10), nil
+	case semantic.Float:
+		return query.TFloat.String(), strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case semantic.String:
+		return query.TString.String(), v.Str(), nil
+	case semantic.Time:
+		return query.TTime.String(), v.Time().String(), nil
+	default:
+		return "", "", fmt.Errorf("fill value must be a bool, int, uint, float, string or time, got %v", k)
+	}
+}
+
+func decodeFillValue(typ, value string) (query.DataType, values.Value, error) {
+	switch typ {
+	case query.TBool.String():
+		v, err := strconv.ParseBool(value)
+		return query.TBool, values.NewBoolValue(v), err
+	case query.TInt.String():
+		v, err := strconv.ParseInt(value, 10, 64)
+		return query.TInt, values.NewIntValue(v), err
+	case query.TUInt.String():
+		v, err := strconv.ParseUint(value, 10, 64)
+		return query.TUInt, values.NewUIntValue(v), err
+	case query.TFloat.String():
+		v, err := strconv.ParseFloat(value, 64)
+		return query.TFloat, values.NewFloatValue(v), err
+	case query.TString.String():
+		return query.TString, values.NewStringValue(value), nil
+	case query.TTime.String():
+		v, err := values.ParseTime(value)
+		return query.TTime, values.NewTimeValue(v), err
+	default:
+		return query.TInvalid, nil, fmt.Errorf("unsupported fill value type %q", typ)
+	}
+}
+
+type FillProcedureSpec struct {
+	Column      string
+	Type        query.DataType
+	Value       values.Value
+	UsePrevious bool
+}
+
+func newFillProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	s, ok := qs.(*FillOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	p := &FillProcedureSpec{
+		Column:      s.Column,
+		UsePrevious: s.UsePrevious,
+	}
+	if !s.UsePrevious {
+		typ, v, err := decodeFillValue(s.Type, s.Value)
+		if err != nil {
+			return nil, err
+		}
+		p.Type = typ
+		p.Value = v
+	}
+	return p, nil
+}
+
+func (s *FillProcedureSpec) Kind() plan.ProcedureKind {
+	return FillKind
+}
+func (s *FillProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(FillProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+func createFillTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*FillProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewFillTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type fillTransformation struct {
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	spec *FillProcedureSpec
+}
+
+func NewFillTransformation(
+	d execute.Dataset,
+	cache execute.TableBuilderCache,
+	spec *FillProcedureSpec,
+) execute.Transformation {
+	return &fillTransformation{
+		d:     d,
+		cache: cache,
+		spec:  spec,
+	}
+}
+
+func (t *fillTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *fillTransformation) Process(id execute.DatasetID, tbl query.Table) error {
+	idx := execute.ColIdx(t.spec.Column, tbl.Cols())
+	if idx < 0 {
+		return fmt.Errorf("fill error: column %q doesn't exist", t.spec.Column)
+	}
+	col := tbl.Cols()[idx]
+	if !t.spec.UsePrevious && col.Type != t.spec.Type {
+		return fmt.Errorf("fill error: cannot fill column %q of type %v with a %v value", col.Label, col.Type, t.spec.Type)
+	}
+
+	builder, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return fmt.Errorf("fill found duplicate table with key: %v", tbl.Key())
+	}
+	execute.AddTableCols(tbl, builder)
+
+	// The previous value is carried across the chunks of a single table.
+	fill := t.spec.Value
+	return tbl.Do(func(cr query.ColReader) error {
+		for j := range cr.Cols() {
+			if j != idx {
+				execute.AppendCol(j, j, cr, builder)
+			}
+		}
+		for i, l := 0, cr.Len(); i < l; i++ {
+			if !cr.IsNull(i, idx) {
+				if t.spec.UsePrevious {
+					fill = execute.ValueForRow(i, idx, cr)
+				}
+				execute.AppendColValue(idx, idx, i, cr, builder)
+				continue
+			}
+			if fill == nil {
+				// No previous value to fill with.
+				builder.AppendNil(idx)
+				continue
+			}
+			execute.AppendValue(builder, idx, fill)
+		}
+		return nil
+	})
+}
+
+func (t *fillTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *fillTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *fillTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
@@ -0,0 +1,211 @@
+package functions_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/querytest"
+	"github.com/influxdata/platform/query/values"
+)
+
+func TestFill_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "fill with value",
+			Raw:  `from(db:"testdb") |> fill(value: 0.0)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "testdb",
+						},
+					},
+					{
+						ID: "fill1",
+						Spec: &functions.FillOpSpec{
+							Column: "_value",
+							Type:   "float",
+							Value:  "0",
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "fill1"},
+				},
+			},
+		},
+		{
+			Name: "fill with previous",
+			Raw:  `from(db:"testdb") |> fill(column: "host", usePrevious: true)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "testdb",
+						},
+					},
+					{
+						ID: "fill1",
+						Spec: &functions.FillOpSpec{
+							Column:      "host",
+							UsePrevious: true,
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "fill1"},
+				},
+			},
+		},
+		{
+			Name:    "missing value",
+			Raw:     `from(db:"testdb") |> fill()`,
+			WantErr: true,
+		},
+		{
+			Name:    "value and previous",
+			Raw:     `from(db:"testdb") |> fill(value: 0.0, usePrevious: true)`,
+			WantErr: true,
+		},
+		{
+			Name:    "unsupported value type",
+			Raw:     `from(db:"testdb") |> fill(value: 1h)`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestFillOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"fill","kind":"fill","spec":{"column":"_value","type":"int","value":"-1","usePrevious":false}}`)
+	op := &query.Operation{
+		ID: "fill",
+		Spec: &functions.FillOpSpec{
+			Column: "_value",
+			Type:   "int",
+			Value:  "-1",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestFill_Process(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *functions.FillProcedureSpec
+		data    []query.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "fill with value",
+			spec: &functions.FillProcedureSpec{
+				Column: "_value",
+				Type:   query.TFloat,
+				Value:  values.NewFloatValue(-1),
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 2.0},
+					{execute.Time(2), nil},
+					{execute.Time(3), 4.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 2.0},
+					{execute.Time(2), -1.0},
+					{execute.Time(3), 4.0},
+				},
+			}},
+		},
+		{
+			name: "fill with previous",
+			spec: &functions.FillProcedureSpec{
+				Column:      "host",
+				UsePrevious: true,
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "host", Type: query.TString},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), nil, 1.0},
+					{execute.Time(2), "a", nil},
+					{execute.Time(3), nil, 3.0},
+					{execute.Time(4), "b", 4.0},
+					{execute.Time(5), nil, 5.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "host", Type: query.TString},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), nil, 1.0},
+					{execute.Time(2), "a", nil},
+					{execute.Time(3), "a", 3.0},
+					{execute.Time(4), "b", 4.0},
+					{execute.Time(5), "b", 5.0},
+				},
+			}},
+		},
+		{
+			name: "mismatched type",
+			spec: &functions.FillProcedureSpec{
+				Column: "_value",
+				Type:   query.TInt,
+				Value:  values.NewIntValue(0),
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), nil},
+				},
+			}},
+			want:    []*executetest.Table(nil),
+			wantErr: errors.New(`fill error: cannot fill column "_value" of type float with a int value`),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return functions.NewFillTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
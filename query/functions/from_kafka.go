@@ -0,0 +1,467 @@
+package functions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+	"github.com/pkg/errors"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	// FromKafkaKind is the Kind for the fromKafka Flux function
+	FromKafkaKind = "fromKafka"
+
+	// DefaultKafkaFetchTimeout is how long fromKafka waits for a message before it considers the topic consumed.
+	DefaultKafkaFetchTimeout = query.Duration(time.Second)
+
+	// DefaultKafkaMaxMessages is the most messages fromKafka consumes in a single query.
+	DefaultKafkaMaxMessages = 10000
+
+	kafkaFormatLineProtocol = "lineprotocol"
+	kafkaFormatJSON         = "json"
+)
+
+type FromKafkaOpSpec struct {
+	Brokers     []string       `json:"brokers"`
+	Topic       string         `json:"topic"`
+	Group       string         `json:"group"`
+	Format      string         `json:"format"`
+	Timeout     query.Duration `json:"timeout"`
+	MaxMessages int64          `json:"maxMessages"`
+}
+
+var fromKafkaSignature = semantic.FunctionSignature{
+	Params: map[string]semantic.Type{
+		"brokers":     semantic.NewArrayType(semantic.String),
+		"topic":       semantic.String,
+		"group":       semantic.String,
+		"format":      semantic.String,
+		"timeout":     semantic.Duration,
+		"maxMessages": semantic.Int,
+	},
+	ReturnType: query.TableObjectType,
+}
+
+func init() {
+	query.RegisterFunction(FromKafkaKind, createFromKafkaOpSpec, fromKafkaSignature)
+	query.RegisterOpSpec(FromKafkaKind, newFromKafkaOp)
+	plan.RegisterProcedureSpec(FromKafkaKind, newFromKafkaProcedure, FromKafkaKind)
+	execute.RegisterSource(FromKafkaKind, createFromKafkaSource)
+}
+
+// DefaultKafkaReaderFactory makes the KafkaReader of a fromKafka source, it is injectable for testing.
+var DefaultKafkaReaderFactory = func(conf kafka.ReaderConfig) KafkaReader {
+	return kafka.NewReader(conf)
+}
+
+// KafkaReader is an interface for what we need from DefaultKafkaReaderFactory
+type KafkaReader interface {
+	FetchMessage(context.Context) (kafka.Message, error)
+	CommitMessages(context.Context, ...kafka.Message) error
+	Close() error
+}
+
+// ReadArgs loads a query.Arguments into FromKafkaOpSpec.
+// If the format isn't set, it defaults to line protocol.
+// If the timeout isn't set, it defaults to DefaultKafkaFetchTimeout.
+// If the maximum number of messages isn't set, it defaults to DefaultKafkaMaxMessages.
+func (s *FromKafkaOpSpec) ReadArgs(args query.Arguments) error {
+	brokers, err := args.GetRequiredArray("brokers", semantic.String)
+	if err != nil {
+		return err
+	}
+	if brokers.Len() < 1 {
+		return errors.New("at least one broker is required")
+	}
+	s.Brokers = make([]string, brokers.Len())
+	for i := range s.Brokers {
+		s.Brokers[i] = brokers.Get(i).Str()
+	}
+
+	if s.Topic, err = args.GetRequiredString("topic"); err != nil {
+		return err
+	}
+	if s.Topic == "" {
+		return errors.New("invalid topic name")
+	}
+	if s.Group, err = args.GetRequiredString("group"); err != nil {
+		return err
+	}
+	if s.Group == "" {
+		return errors.New("invalid group name")
+	}
+
+	format, ok, err := args.GetString("format")
+	if err != nil {
+		return err
+	}
+	s.Format = kafkaFormatLineProtocol
+	if ok {
+		switch format {
+		case kafkaFormatLineProtocol, kafkaFormatJSON:
+			s.Format = format
+		default:
+			return fmt.Errorf("format must be %q or %q, got %q", kafkaFormatLineProtocol, kafkaFormatJSON, format)
+		}
+	}
+
+	timeout, ok, err := args.GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	s.Timeout = DefaultKafkaFetchTimeout
+	if ok {
+		if timeout <= 0 {
+			return errors.New("timeout must be greater than zero")
+		}
+		s.Timeout = timeout
+	}
+
+	maxMessages, ok, err := args.GetInt("maxMessages")
+	if err != nil {
+		return err
+	}
+	s.MaxMessages = DefaultKafkaMaxMessages
+	if ok {
+		if maxMessages <= 0 {
+			return errors.New("maxMessages must be greater than zero")
+		}
+		s.MaxMessages = maxMessages
+	}
+	return nil
+}
+
+func createFromKafkaOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	s := new(FromKafkaOpSpec)
+	if err := s.ReadArgs(args); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func newFromKafkaOp() query.OperationSpec {
+	return new(FromKafkaOpSpec)
+}
+
+func (s *FromKafkaOpSpec) Kind() query.OperationKind {
+	return FromKafkaKind
+}
+
+type FromKafkaProcedureSpec struct {
+	Brokers     []string
+	Topic       string
+	Group       string
+	Format      string
+	Timeout     query.Duration
+	MaxMessages int64
+}
+
+func newFromKafkaProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*FromKafkaOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+
+	return &FromKafkaProcedureSpec{
+		Brokers:     spec.Brokers,
+		Topic:       spec.Topic,
+		Group:       spec.Group,
+		Format:      spec.Format,
+		Timeout:     spec.Timeout,
+		MaxMessages: spec.MaxMessages,
+	}, nil
+}
+
+func (s *FromKafkaProcedureSpec) Kind() plan.ProcedureKind {
+	return FromKafkaKind
+}
+
+func (s *FromKafkaProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(FromKafkaProcedureSpec)
+	*ns = *s
+	ns.Brokers = append([]string(nil), s.Brokers...)
+	return ns
+}
+
+func createFromKafkaSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*FromKafkaProcedureSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", prSpec)
+	}
+	return NewKafkaSource(dsid, spec, a.Allocator(), a.Committer()), nil
+}
+
+// KafkaSource consumes the messages available on a topic and outputs a table for each series they contain.
+// The offsets of the messages are committed with the Committer of the query,
+// so they are only consumed once the query has succeeded.
+// The messages are buffered before they are decoded, so their size is accounted with the Allocator of the query.
+type KafkaSource struct {
+	id      execute.DatasetID
+	spec    *FromKafkaProcedureSpec
+	alloc   *execute.Allocator
+	commits *execute.Committer
+	ts      []execute.Transformation
+}
+
+func NewKafkaSource(id execute.DatasetID, spec *FromKafkaProcedureSpec, alloc *execute.Allocator, commits *execute.Committer) *KafkaSource {
+	return &KafkaSource{
+		id:      id,
+		spec:    spec,
+		alloc:   alloc,
+		commits: commits,
+	}
+}
+
+func (s *KafkaSource) AddTransformation(t execute.Transformation) {
+	s.ts = append(s.ts, t)
+}
+
+func (s *KafkaSource) Run(ctx context.Context) {
+	err := s.run(ctx)
+	for _, t := range s.ts {
+		t.Finish(s.id, err)
+	}
+}
+
+func (s *KafkaSource) run(ctx context.Context) error {
+	r := DefaultKafkaReaderFactory(kafka.ReaderConfig{
+		Brokers: s.spec.Brokers,
+		GroupID: s.spec.Group,
+		Topic:   s.spec.Topic,
+	})
+	msgs, buffered, err := s.fetch(ctx, r)
+	if err != nil {
+		r.Close()
+		return err
+	}
+	// The reader is closed by the committer, whether or not the query succeeds.
+	s.commits.Add(&kafkaCommit{r: r, msgs: msgs})
+
+	tables := newKafkaSeriesTables(s.alloc)
+	for i, m := range msgs {
+		if err := tables.decode(m, s.spec.Format); err != nil {
+			s.alloc.Free(buffered, 1)
+			return errors.Wrapf(err, "failed to decode message at offset %d of partition %d", m.Offset, m.Partition)
+		}
+		// Only the offset of the message is needed to commit it.
+		msgs[i].Key, msgs[i].Value = nil, nil
+	}
+	s.alloc.Free(buffered, 1)
+	for _, b := range tables.builders {
+		tbl, err := b.Table()
+		if err != nil {
+			return err
+		}
+		for _, t := range s.ts {
+			if err := t.Process(s.id, tbl); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fetch reads messages until none arrives within the timeout of the spec,
+// or until the maximum number of messages of the spec has been read.
+// It returns the messages along with the number of bytes they buffer, which are accounted with the allocator.
+// On error, the buffered bytes have already been freed.
+func (s *KafkaSource) fetch(ctx context.Context, r KafkaReader) (msgs []kafka.Message, buffered int, err error) {
+	defer func() {
+		if err != nil {
+			s.alloc.Free(buffered, 1)
+			msgs, buffered = nil, 0
+		}
+	}()
+	for int64(len(msgs)) < s.spec.MaxMessages {
+		fctx, cancel := context.WithTimeout(ctx, time.Duration(s.spec.Timeout))
+		m, err := r.FetchMessage(fctx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return msgs, buffered, ctx.Err()
+			}
+			if fctx.Err() == context.DeadlineExceeded {
+				return msgs, buffered, nil
+			}
+			return msgs, buffered, errors.Wrap(err, "failed to fetch kafka message")
+		}
+		n := len(m.Key) + len(m.Value)
+		if err := s.alloc.Account(n, 1); err != nil {
+			return msgs, buffered, errors.Wrap(err, "failed to buffer kafka message")
+		}
+		buffered += n
+		msgs = append(msgs, m)
+	}
+	return msgs, buffered, nil
+}
+
+// kafkaCommit commits the offsets of the messages a KafkaSource consumed.
+type kafkaCommit struct {
+	r    KafkaReader
+	msgs []kafka.Message
+}
+
+func (c *kafkaCommit) Commit(ctx context.Context) error {
+	if len(c.msgs) == 0 {
+		return nil
+	}
+	return errors.Wrap(c.r.CommitMessages(ctx, c.msgs...), "failed to commit kafka offsets")
+}
+
+func (c *kafkaCommit) Close() error {
+	return c.r.Close()
+}
+
+// kafkaJSONMetric is the JSON encoding of a message.
+// The timestamp is in nanoseconds since the Unix epoch.
+type kafkaJSONMetric struct {
+	Name      string                 `json:"name"`
+	Tags      map[string]string      `json:"tags"`
+	Fields    map[string]interface{} `json:"fields"`
+	Timestamp *int64                 `json:"timestamp"`
+}
+
+// kafkaSeriesTables builds a table for each series found in a set of messages.
+// A series is identified by its measurement, tags and field.
+type kafkaSeriesTables struct {
+	alloc    *execute.Allocator
+	index    map[string]int
+	builders []*execute.ColListTableBuilder
+}
+
+func newKafkaSeriesTables(alloc *execute.Allocator) *kafkaSeriesTables {
+	return &kafkaSeriesTables{
+		alloc: alloc,
+		index: make(map[string]int),
+	}
+}
+
+// decode adds the values of m to the tables of their series.
+// Values without a timestamp are given the time of the message.
+func (t *kafkaSeriesTables) decode(m kafka.Message, format string) error {
+	switch format {
+	case kafkaFormatJSON:
+		var metric kafkaJSONMetric
+		if err := json.Unmarshal(m.Value, &metric); err != nil {
+			return err
+		}
+		if metric.Name == "" {
+			return errors.New("missing name")
+		}
+		ts := m.Time
+		if metric.Timestamp != nil {
+			ts = time.Unix(0, *metric.Timestamp)
+		}
+		for k, v := range metric.Fields {
+			if err := t.append(metric.Name, metric.Tags, k, ts, v); err != nil {
+				return err
+			}
+		}
+	default:
+		points, err := models.ParsePointsWithPrecision(m.Value, m.Time, "n")
+		if err != nil {
+			return err
+		}
+		for _, p := range points {
+			fields, err := p.Fields()
+			if err != nil {
+				return err
+			}
+			tags := make(map[string]string, len(p.Tags()))
+			for _, tag := range p.Tags() {
+				tags[string(tag.Key)] = string(tag.Value)
+			}
+			for k, v := range fields {
+				if err := t.append(string(p.Name()), tags, k, p.Time(), v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (t *kafkaSeriesTables) append(measurement string, tags map[string]string, field string, ts time.Time, v interface{}) error {
+	var typ query.DataType
+	switch v.(type) {
+	case float64:
+		typ = query.TFloat
+	case int64:
+		typ = query.TInt
+	case uint64:
+		typ = query.TUInt
+	case string:
+		typ = query.TString
+	case bool:
+		typ = query.TBool
+	default:
+		return fmt.Errorf("field %q has unsupported value %v of type %T", field, v, v)
+	}
+
+	key := kafkaSeriesKey(measurement, tags, field)
+	i, ok := t.index[key.String()]
+	if !ok {
+		b := execute.NewColListTableBuilder(key, t.alloc)
+		b.AddCol(query.ColMeta{Label: execute.DefaultTimeColLabel, Type: query.TTime})
+		b.AddCol(query.ColMeta{Label: execute.DefaultValueColLabel, Type: typ})
+		execute.AddTableKeyCols(key, b)
+		i = len(t.builders)
+		t.index[key.String()] = i
+		t.builders = append(t.builders, b)
+	}
+	b := t.builders[i]
+	if valueTyp := b.Cols()[1].Type; valueTyp != typ {
+		return fmt.Errorf("field %q of series %v has values of type %v and %v", field, key, valueTyp, typ)
+	}
+
+	b.AppendTime(0, execute.Time(ts.UnixNano()))
+	switch v := v.(type) {
+	case float64:
+		b.AppendFloat(1, v)
+	case int64:
+		b.AppendInt(1, v)
+	case uint64:
+		b.AppendUInt(1, v)
+	case string:
+		b.AppendString(1, v)
+	case bool:
+		b.AppendBool(1, v)
+	}
+	execute.AppendKeyValues(key, b)
+	return nil
+}
+
+// kafkaSeriesKey returns the group key of a series.
+// The key columns are sorted by label.
+func kafkaSeriesKey(measurement string, tags map[string]string, field string) query.GroupKey {
+	labels := make(map[string]string, len(tags)+2)
+	for k, v := range tags {
+		labels[k] = v
+	}
+	labels[measurementColLabel] = measurement
+	labels[fieldColLabel] = field
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	cols := make([]query.ColMeta, len(keys))
+	vs := make([]values.Value, len(keys))
+	for j, k := range keys {
+		cols[j] = query.ColMeta{Label: k, Type: query.TString}
+		vs[j] = values.NewStringValue(labels[k])
+	}
+	return execute.NewGroupKey(cols, vs)
+}
@@ -0,0 +1,371 @@
+package functions_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/functions/kafkatest"
+	"github.com/influxdata/platform/query/querytest"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+func TestFromKafka_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "defaults",
+			Raw:  `fromKafka(brokers:["broker:9092"], topic:"telegraf", group:"downsample")`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "fromKafka0",
+						Spec: &functions.FromKafkaOpSpec{
+							Brokers:     []string{"broker:9092"},
+							Topic:       "telegraf",
+							Group:       "downsample",
+							Format:      "lineprotocol",
+							Timeout:     functions.DefaultKafkaFetchTimeout,
+							MaxMessages: functions.DefaultKafkaMaxMessages,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "all arguments",
+			Raw:  `fromKafka(brokers:["broker1:9092", "broker2:9092"], topic:"telegraf", group:"downsample", format:"json", timeout:5s, maxMessages:100)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "fromKafka0",
+						Spec: &functions.FromKafkaOpSpec{
+							Brokers:     []string{"broker1:9092", "broker2:9092"},
+							Topic:       "telegraf",
+							Group:       "downsample",
+							Format:      "json",
+							Timeout:     query.Duration(5 * time.Second),
+							MaxMessages: 100,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:    "no brokers",
+			Raw:     `fromKafka(brokers:[], topic:"telegraf", group:"downsample")`,
+			WantErr: true,
+		},
+		{
+			Name:    "missing group",
+			Raw:     `fromKafka(brokers:["broker:9092"], topic:"telegraf")`,
+			WantErr: true,
+		},
+		{
+			Name:    "no messages",
+			Raw:     `fromKafka(brokers:["broker:9092"], topic:"telegraf", group:"downsample", maxMessages:0)`,
+			WantErr: true,
+		},
+		{
+			Name:    "unknown format",
+			Raw:     `fromKafka(brokers:["broker:9092"], topic:"telegraf", group:"downsample", format:"avro")`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+// useKafkaBroker makes fromKafka sources read from b until the returned function is called.
+func useKafkaBroker(b *kafkatest.Broker) func() {
+	factory := functions.DefaultKafkaReaderFactory
+	functions.DefaultKafkaReaderFactory = func(conf kafka.ReaderConfig) functions.KafkaReader {
+		return b.NewReader(conf)
+	}
+	return func() { functions.DefaultKafkaReaderFactory = factory }
+}
+
+// runKafkaSource runs a fromKafka source that registers its commits with c.
+func runKafkaSource(format string, c *execute.Committer) ([]*executetest.Table, error) {
+	return runKafkaSourceSpec(&functions.FromKafkaProcedureSpec{
+		Format:      format,
+		MaxMessages: functions.DefaultKafkaMaxMessages,
+	}, executetest.UnlimitedAllocator, c)
+}
+
+// runKafkaSourceSpec runs a fromKafka source with the format and maximum number of messages of spec.
+func runKafkaSourceSpec(spec *functions.FromKafkaProcedureSpec, a *execute.Allocator, c *execute.Committer) ([]*executetest.Table, error) {
+	s := functions.NewKafkaSource(executetest.RandomDatasetID(), &functions.FromKafkaProcedureSpec{
+		Brokers:     []string{"broker:9092"},
+		Topic:       "telegraf",
+		Group:       "downsample",
+		Format:      spec.Format,
+		Timeout:     query.Duration(10 * time.Millisecond),
+		MaxMessages: spec.MaxMessages,
+	}, a, c)
+	tc := new(tableCollector)
+	s.AddTransformation(tc)
+	s.Run(context.Background())
+	return tc.tables, tc.err
+}
+
+func TestKafkaSource_Run(t *testing.T) {
+	seriesCols := func(typ query.DataType) []query.ColMeta {
+		return []query.ColMeta{
+			{Label: "_time", Type: query.TTime},
+			{Label: "_value", Type: typ},
+			{Label: "_field", Type: query.TString},
+			{Label: "_measurement", Type: query.TString},
+			{Label: "host", Type: query.TString},
+		}
+	}
+	keyCols := []string{"_field", "_measurement", "host"}
+
+	testCases := []struct {
+		name     string
+		format   string
+		messages []string
+		want     []*executetest.Table
+		wantErr  error
+	}{
+		{
+			name:   "line protocol",
+			format: "lineprotocol",
+			messages: []string{
+				"cpu,host=a usage=1.5 1000\ncpu,host=b usage=2 1000",
+				"cpu,host=a usage=2.5,count=3i 2000",
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: keyCols,
+					ColMeta: seriesCols(query.TFloat),
+					Data: [][]interface{}{
+						{execute.Time(1000), 1.5, "usage", "cpu", "a"},
+						{execute.Time(2000), 2.5, "usage", "cpu", "a"},
+					},
+				},
+				{
+					KeyCols: keyCols,
+					ColMeta: seriesCols(query.TFloat),
+					Data: [][]interface{}{
+						{execute.Time(1000), 2.0, "usage", "cpu", "b"},
+					},
+				},
+				{
+					KeyCols: keyCols,
+					ColMeta: seriesCols(query.TInt),
+					Data: [][]interface{}{
+						{execute.Time(2000), int64(3), "count", "cpu", "a"},
+					},
+				},
+			},
+		},
+		{
+			name:   "json",
+			format: "json",
+			messages: []string{
+				`{"name":"cpu","tags":{"host":"a"},"fields":{"usage":1.5,"active":true},"timestamp":1000}`,
+				`{"name":"cpu","tags":{"host":"a"},"fields":{"usage":2.5},"timestamp":2000}`,
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: keyCols,
+					ColMeta: seriesCols(query.TFloat),
+					Data: [][]interface{}{
+						{execute.Time(1000), 1.5, "usage", "cpu", "a"},
+						{execute.Time(2000), 2.5, "usage", "cpu", "a"},
+					},
+				},
+				{
+					KeyCols: keyCols,
+					ColMeta: seriesCols(query.TBool),
+					Data: [][]interface{}{
+						{execute.Time(1000), true, "active", "cpu", "a"},
+					},
+				},
+			},
+		},
+		{
+			name:   "conflicting types",
+			format: "lineprotocol",
+			messages: []string{
+				"cpu,host=a usage=1.5 1000",
+				`cpu,host=a usage="high" 2000`,
+			},
+			wantErr: errors.New(`failed to decode message at offset 1 of partition 0: field "usage" of series {_field=usage,_measurement=cpu,host=a} has values of type float and string`),
+		},
+		{
+			name:     "invalid json",
+			format:   "json",
+			messages: []string{`{"name":`},
+			wantErr:  errors.New("failed to decode message at offset 0 of partition 0: unexpected end of JSON input"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			b := kafkatest.NewBroker()
+			defer useKafkaBroker(b)()
+			for _, m := range tc.messages {
+				b.Produce("telegraf", []byte(m))
+			}
+
+			got, err := runKafkaSource(tc.format, nil)
+			if tc.wantErr != nil {
+				if err == nil || err.Error() != tc.wantErr.Error() {
+					t.Fatalf("unexpected error -want/+got\n%s", cmp.Diff(tc.wantErr, err))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			executetest.NormalizeTables(got)
+			executetest.NormalizeTables(tc.want)
+			sort.Sort(executetest.SortedTables(got))
+			sort.Sort(executetest.SortedTables(tc.want))
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestKafkaSource_Commit(t *testing.T) {
+	b := kafkatest.NewBroker()
+	defer useKafkaBroker(b)()
+	b.Produce("telegraf", []byte("cpu usage=1 1000"), []byte("cpu usage=2 2000"))
+
+	rows := func(tables []*executetest.Table) int {
+		n := 0
+		for _, tbl := range tables {
+			n += len(tbl.Data)
+		}
+		return n
+	}
+
+	// A query without a committer does not consume the messages.
+	tables, err := runKafkaSource("lineprotocol", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rows(tables); got != 2 {
+		t.Fatalf("expected 2 rows, got %d", got)
+	}
+	if got := b.CommittedOffset("downsample", "telegraf"); got != 0 {
+		t.Fatalf("expected no committed offset, got %d", got)
+	}
+
+	// A failed query discards its commits.
+	c := new(execute.Committer)
+	if _, err := runKafkaSource("lineprotocol", c); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.CommittedOffset("downsample", "telegraf"); got != 0 {
+		t.Fatalf("expected no committed offset, got %d", got)
+	}
+
+	// A successful query commits the offsets of the messages it read.
+	c = new(execute.Committer)
+	tables, err = runKafkaSource("lineprotocol", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rows(tables); got != 2 {
+		t.Fatalf("expected 2 rows, got %d", got)
+	}
+	if err := c.Commit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.CommittedOffset("downsample", "telegraf"); got != 2 {
+		t.Fatalf("expected committed offset 2, got %d", got)
+	}
+
+	// The next query only reads the messages produced since.
+	b.Produce("telegraf", []byte("cpu usage=3 3000"))
+	c = new(execute.Committer)
+	tables, err = runKafkaSource("lineprotocol", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*executetest.Table{{
+		KeyCols: []string{"_field", "_measurement"},
+		ColMeta: []query.ColMeta{
+			{Label: "_time", Type: query.TTime},
+			{Label: "_value", Type: query.TFloat},
+			{Label: "_field", Type: query.TString},
+			{Label: "_measurement", Type: query.TString},
+		},
+		Data: [][]interface{}{
+			{execute.Time(3000), 3.0, "usage", "cpu"},
+		},
+	}}
+	executetest.NormalizeTables(tables)
+	executetest.NormalizeTables(want)
+	if !cmp.Equal(want, tables) {
+		t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(want, tables))
+	}
+	if err := c.Commit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.CommittedOffset("downsample", "telegraf"); got != 3 {
+		t.Fatalf("expected committed offset 3, got %d", got)
+	}
+}
+
+func TestKafkaSource_Limits(t *testing.T) {
+	b := kafkatest.NewBroker()
+	defer useKafkaBroker(b)()
+	b.Produce("telegraf", []byte("cpu usage=1 1000"), []byte("cpu usage=2 2000"), []byte("cpu usage=3 3000"))
+
+	// A query consumes at most the maximum number of messages, the rest are left for the next one.
+	c := new(execute.Committer)
+	spec := &functions.FromKafkaProcedureSpec{Format: "lineprotocol", MaxMessages: 2}
+	tables, err := runKafkaSourceSpec(spec, executetest.UnlimitedAllocator, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || len(tables[0].Data) != 2 {
+		t.Fatalf("expected 2 rows, got %v", tables)
+	}
+	if err := c.Commit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.CommittedOffset("downsample", "telegraf"); got != 2 {
+		t.Fatalf("expected committed offset 2, got %d", got)
+	}
+
+	// The buffered messages count towards the memory limit of the query.
+	a := &execute.Allocator{Limit: 8}
+	c = new(execute.Committer)
+	spec = &functions.FromKafkaProcedureSpec{Format: "lineprotocol", MaxMessages: functions.DefaultKafkaMaxMessages}
+	if _, err := runKafkaSourceSpec(spec, a, c); err == nil || !strings.Contains(err.Error(), "allocation limit reached") {
+		t.Fatalf("expected an allocation error, got %v", err)
+	}
+	if err := c.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.CommittedOffset("downsample", "telegraf"); got != 2 {
+		t.Fatalf("expected committed offset 2, got %d", got)
+	}
+	// Nothing remains accounted once the source has failed.
+	if err := a.Account(8, 1); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,416 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/interpreter"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+)
+
+const HistogramKind = "histogram"
+
+// HistogramOpSpec computes a cumulative histogram of the values of a column.
+type HistogramOpSpec struct {
+	Column           string    `json:"column"`
+	UpperBoundColumn string    `json:"upperBoundColumn"`
+	CountColumn      string    `json:"countColumn"`
+	Bins             []float64 `json:"bins"`
+	Normalize        bool      `json:"normalize"`
+}
+
+var histogramSignature = query.DefaultFunctionSignature()
+
+var binsSignature = semantic.FunctionSignature{
+	Params: map[string]semantic.Type{
+		"start":    semantic.Float,
+		"count":    semantic.Int,
+		"infinity": semantic.Bool,
+	},
+	ReturnType: semantic.NewArrayType(semantic.Float),
+}
+
+func init() {
+	histogramSignature.Params["column"] = semantic.String
+	histogramSignature.Params["upperBoundColumn"] = semantic.String
+	histogramSignature.Params["countColumn"] = semantic.String
+	histogramSignature.Params["bins"] = semantic.NewArrayType(semantic.Float)
+	histogramSignature.Params["normalize"] = semantic.Bool
+
+	query.RegisterFunction(HistogramKind, createHistogramOpSpec, histogramSignature)
+	query.RegisterBuiltInValue("linearBins", linearBins{})
+	query.RegisterBuiltInValue("logarithmicBins", logarithmicBins{})
+	query.RegisterOpSpec(HistogramKind, newHistogramOp)
+	plan.RegisterProcedureSpec(HistogramKind, newHistogramProcedure, HistogramKind)
+	execute.RegisterTransformation(HistogramKind, createHistogramTransformation)
+}
+
+func createHistogramOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := &HistogramOpSpec{
+		Column:           execute.DefaultValueColLabel,
+		UpperBoundColumn: DefaultUpperBoundColumnLabel,
+		CountColumn:      execute.DefaultValueColLabel,
+	}
+	if col, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = col
+	}
+	if col, ok, err := args.GetString("upperBoundColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.UpperBoundColumn = col
+	}
+	if col, ok, err := args.GetString("countColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.CountColumn = col
+	}
+	if spec.UpperBoundColumn == spec.CountColumn {
+		return nil, fmt.Errorf("histogram error: upperBoundColumn and countColumn must be different, both are %q", spec.CountColumn)
+	}
+
+	bins, err := args.GetRequiredArray("bins", semantic.Float)
+	if err != nil {
+		return nil, err
+	}
+	spec.Bins = make([]float64, bins.Len())
+	bins.Range(func(i int, v values.Value) {
+		spec.Bins[i] = v.Float()
+	})
+	if len(spec.Bins) == 0 {
+		return nil, errors.New("histogram error: bins must contain at least one bin")
+	}
+	if !sort.Float64sAreSorted(spec.Bins) {
+		return nil, errors.New("histogram error: bins must be sorted in ascending order")
+	}
+
+	if normalize, ok, err := args.GetBool("normalize"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Normalize = normalize
+	}
+	return spec, nil
+}
+
+func newHistogramOp() query.OperationSpec {
+	return new(HistogramOpSpec)
+}
+
+func (s *HistogramOpSpec) Kind() query.OperationKind {
+	return HistogramKind
+}
+
+type HistogramProcedureSpec struct {
+	HistogramOpSpec
+}
+
+func newHistogramProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*HistogramOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+
+	return &HistogramProcedureSpec{
+		HistogramOpSpec: *spec,
+	}, nil
+}
+
+func (s *HistogramProcedureSpec) Kind() plan.ProcedureKind {
+	return HistogramKind
+}
+func (s *HistogramProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(HistogramProcedureSpec)
+	*ns = *s
+	ns.Bins = append([]float64(nil), s.Bins...)
+	return ns
+}
+
+func createHistogramTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*HistogramProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewHistogramTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type histogramTransformation struct {
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	spec HistogramProcedureSpec
+}
+
+func NewHistogramTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *HistogramProcedureSpec) *histogramTransformation {
+	return &histogramTransformation{
+		d:     d,
+		cache: cache,
+		spec:  *spec,
+	}
+}
+
+func (t *histogramTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *histogramTransformation) Process(id execute.DatasetID, tbl query.Table) error {
+	valueIdx := execute.ColIdx(t.spec.Column, tbl.Cols())
+	if valueIdx < 0 {
+		return fmt.Errorf("histogram error: column %q doesn't exist", t.spec.Column)
+	}
+	typ := tbl.Cols()[valueIdx].Type
+	switch typ {
+	case query.TInt, query.TUInt, query.TFloat:
+	default:
+		return fmt.Errorf("histogram error: column %q must be numeric, got %v", t.spec.Column, typ)
+	}
+
+	key := tbl.Key()
+	builder, created := t.cache.TableBuilder(key)
+	if !created {
+		return fmt.Errorf("histogram found duplicate table with key: %v", key)
+	}
+	execute.AddTableKeyCols(key, builder)
+	for _, label := range []string{t.spec.UpperBoundColumn, t.spec.CountColumn} {
+		if execute.HasCol(label, builder.Cols()) {
+			return fmt.Errorf("histogram error: column %q is part of the group key", label)
+		}
+	}
+	boundIdx := builder.AddCol(query.ColMeta{Label: t.spec.UpperBoundColumn, Type: query.TFloat})
+	countIdx := builder.AddCol(query.ColMeta{Label: t.spec.CountColumn, Type: query.TFloat})
+
+	// counts holds the number of values in each bin, values greater than the last bin are only part of the total.
+	counts := make([]float64, len(t.spec.Bins))
+	total := 0.0
+	err := tbl.Do(func(cr query.ColReader) error {
+		cr = execute.SkipNulls(valueIdx, cr)
+		for i, l := 0, cr.Len(); i < l; i++ {
+			var v float64
+			switch typ {
+			case query.TInt:
+				v = float64(cr.Ints(valueIdx)[i])
+			case query.TUInt:
+				v = float64(cr.UInts(valueIdx)[i])
+			case query.TFloat:
+				v = cr.Floats(valueIdx)[i]
+			}
+			total++
+			if b := sort.SearchFloat64s(t.spec.Bins, v); b < len(counts) {
+				counts[b]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cumulative := 0.0
+	for b, bound := range t.spec.Bins {
+		cumulative += counts[b]
+		count := cumulative
+		if t.spec.Normalize && total > 0 {
+			count /= total
+		}
+		execute.AppendKeyValues(key, builder)
+		builder.AppendFloat(boundIdx, bound)
+		builder.AppendFloat(countIdx, count)
+	}
+	return nil
+}
+
+func (t *histogramTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *histogramTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *histogramTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+// linearBins is a function that produces count bins, starting at start and separated by width.
+type linearBins struct{}
+
+func (b linearBins) Type() semantic.Type {
+	sig := binsSignature
+	sig.Params = map[string]semantic.Type{"width": semantic.Float}
+	for k, v := range binsSignature.Params {
+		sig.Params[k] = v
+	}
+	return semantic.NewFunctionType(sig)
+}
+
+func (b linearBins) Str() string {
+	panic(values.UnexpectedKind(semantic.Function, semantic.String))
+}
+func (b linearBins) Int() int64 {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Int))
+}
+func (b linearBins) UInt() uint64 {
+	panic(values.UnexpectedKind(semantic.Function, semantic.UInt))
+}
+func (b linearBins) Float() float64 {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Float))
+}
+func (b linearBins) Bool() bool {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Bool))
+}
+func (b linearBins) Time() values.Time {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Time))
+}
+func (b linearBins) Duration() values.Duration {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Duration))
+}
+func (b linearBins) Regexp() *regexp.Regexp {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Regexp))
+}
+func (b linearBins) Array() values.Array {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Array))
+}
+func (b linearBins) Object() values.Object {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Object))
+}
+func (b linearBins) Function() values.Function {
+	return b
+}
+func (b linearBins) Equal(rhs values.Value) bool {
+	_, ok := rhs.(linearBins)
+	return ok
+}
+func (b linearBins) HasSideEffect() bool {
+	return false
+}
+
+func (b linearBins) Call(args values.Object) (values.Value, error) {
+	a := interpreter.NewArguments(args)
+	start, err := a.GetRequiredFloat("start")
+	if err != nil {
+		return nil, err
+	}
+	width, err := a.GetRequiredFloat("width")
+	if err != nil {
+		return nil, err
+	}
+	if width <= 0 {
+		return nil, errors.New("linearBins width must be positive")
+	}
+	return newBins(a, func(i int) float64 {
+		return start + float64(i)*width
+	})
+}
+
+// logarithmicBins is a function that produces count bins, starting at start and growing by factor.
+type logarithmicBins struct{}
+
+func (b logarithmicBins) Type() semantic.Type {
+	sig := binsSignature
+	sig.Params = map[string]semantic.Type{"factor": semantic.Float}
+	for k, v := range binsSignature.Params {
+		sig.Params[k] = v
+	}
+	return semantic.NewFunctionType(sig)
+}
+
+func (b logarithmicBins) Str() string {
+	panic(values.UnexpectedKind(semantic.Function, semantic.String))
+}
+func (b logarithmicBins) Int() int64 {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Int))
+}
+func (b logarithmicBins) UInt() uint64 {
+	panic(values.UnexpectedKind(semantic.Function, semantic.UInt))
+}
+func (b logarithmicBins) Float() float64 {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Float))
+}
+func (b logarithmicBins) Bool() bool {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Bool))
+}
+func (b logarithmicBins) Time() values.Time {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Time))
+}
+func (b logarithmicBins) Duration() values.Duration {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Duration))
+}
+func (b logarithmicBins) Regexp() *regexp.Regexp {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Regexp))
+}
+func (b logarithmicBins) Array() values.Array {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Array))
+}
+func (b logarithmicBins) Object() values.Object {
+	panic(values.UnexpectedKind(semantic.Function, semantic.Object))
+}
+func (b logarithmicBins) Function() values.Function {
+	return b
+}
+func (b logarithmicBins) Equal(rhs values.Value) bool {
+	_, ok := rhs.(logarithmicBins)
+	return ok
+}
+func (b logarithmicBins) HasSideEffect() bool {
+	return false
+}
+
+func (b logarithmicBins) Call(args values.Object) (values.Value, error) {
+	a := interpreter.NewArguments(args)
+	start, err := a.GetRequiredFloat("start")
+	if err != nil {
+		return nil, err
+	}
+	if start <= 0 {
+		return nil, errors.New("logarithmicBins start must be positive")
+	}
+	factor, err := a.GetRequiredFloat("factor")
+	if err != nil {
+		return nil, err
+	}
+	if factor <= 1 {
+		return nil, errors.New("logarithmicBins factor must be greater than 1")
+	}
+	return newBins(a, func(i int) float64 {
+		return start * math.Pow(factor, float64(i))
+	})
+}
+
+// newBins reads the count and infinity arguments and returns the array of bins produced by bin.
+// By default a final +Inf bin is added so that every value is counted.
+func newBins(a interpreter.Arguments, bin func(i int) float64) (values.Value, error) {
+	count, err := a.GetRequiredInt("count")
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 {
+		return nil, errors.New("bins count must be positive")
+	}
+	infinity := true
+	if inf, ok, err := a.GetBool("infinity"); err != nil {
+		return nil, err
+	} else if ok {
+		infinity = inf
+	}
+
+	elements := make([]values.Value, count, count+1)
+	for i := range elements {
+		elements[i] = values.NewFloatValue(bin(i))
+	}
+	if infinity {
+		elements = append(elements, values.NewFloatValue(math.Inf(1)))
+	}
+	return values.NewArrayWithBacking(semantic.Float, elements), nil
+}
@@ -0,0 +1,266 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+)
+
+const HistogramQuantileKind = "histogramQuantile"
+
+// DefaultUpperBoundColumnLabel is the label of the column holding the upper bound of each bucket of a histogram.
+const DefaultUpperBoundColumnLabel = "le"
+
+// HistogramQuantileOpSpec computes a quantile from a cumulative histogram.
+type HistogramQuantileOpSpec struct {
+	Quantile         float64 `json:"quantile"`
+	CountColumn      string  `json:"countColumn"`
+	UpperBoundColumn string  `json:"upperBoundColumn"`
+	ValueColumn      string  `json:"valueColumn"`
+	MinValue         float64 `json:"minValue"`
+}
+
+var histogramQuantileSignature = query.DefaultFunctionSignature()
+
+func init() {
+	histogramQuantileSignature.Params["quantile"] = semantic.Float
+	histogramQuantileSignature.Params["countColumn"] = semantic.String
+	histogramQuantileSignature.Params["upperBoundColumn"] = semantic.String
+	histogramQuantileSignature.Params["valueColumn"] = semantic.String
+	histogramQuantileSignature.Params["minValue"] = semantic.Float
+
+	query.RegisterFunction(HistogramQuantileKind, createHistogramQuantileOpSpec, histogramQuantileSignature)
+	query.RegisterOpSpec(HistogramQuantileKind, newHistogramQuantileOp)
+	plan.RegisterProcedureSpec(HistogramQuantileKind, newHistogramQuantileProcedure, HistogramQuantileKind)
+	execute.RegisterTransformation(HistogramQuantileKind, createHistogramQuantileTransformation)
+}
+
+func createHistogramQuantileOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := &HistogramQuantileOpSpec{
+		CountColumn:      execute.DefaultValueColLabel,
+		UpperBoundColumn: DefaultUpperBoundColumnLabel,
+		ValueColumn:      execute.DefaultValueColLabel,
+	}
+	q, err := args.GetRequiredFloat("quantile")
+	if err != nil {
+		return nil, err
+	}
+	if q < 0 || q > 1 {
+		return nil, errors.New("histogramQuantile error: quantile must be between 0 and 1")
+	}
+	spec.Quantile = q
+
+	if col, ok, err := args.GetString("countColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.CountColumn = col
+	}
+	if col, ok, err := args.GetString("upperBoundColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.UpperBoundColumn = col
+	}
+	if col, ok, err := args.GetString("valueColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.ValueColumn = col
+	}
+	if min, ok, err := args.GetFloat("minValue"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.MinValue = min
+	}
+	return spec, nil
+}
+
+func newHistogramQuantileOp() query.OperationSpec {
+	return new(HistogramQuantileOpSpec)
+}
+
+func (s *HistogramQuantileOpSpec) Kind() query.OperationKind {
+	return HistogramQuantileKind
+}
+
+type HistogramQuantileProcedureSpec struct {
+	Quantile         float64
+	CountColumn      string
+	UpperBoundColumn string
+	ValueColumn      string
+	MinValue         float64
+}
+
+func newHistogramQuantileProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*HistogramQuantileOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+
+	return &HistogramQuantileProcedureSpec{
+		Quantile:         spec.Quantile,
+		CountColumn:      spec.CountColumn,
+		UpperBoundColumn: spec.UpperBoundColumn,
+		ValueColumn:      spec.ValueColumn,
+		MinValue:         spec.MinValue,
+	}, nil
+}
+
+func (s *HistogramQuantileProcedureSpec) Kind() plan.ProcedureKind {
+	return HistogramQuantileKind
+}
+func (s *HistogramQuantileProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(HistogramQuantileProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+func createHistogramQuantileTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*HistogramQuantileProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewHistogramQuantileTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type histogramQuantileTransformation struct {
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	spec HistogramQuantileProcedureSpec
+}
+
+// histogramBucket is a single record of a cumulative histogram.
+type histogramBucket struct {
+	upperBound float64
+	count      float64
+}
+
+func NewHistogramQuantileTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *HistogramQuantileProcedureSpec) *histogramQuantileTransformation {
+	return &histogramQuantileTransformation{
+		d:     d,
+		cache: cache,
+		spec:  *spec,
+	}
+}
+
+func (t *histogramQuantileTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *histogramQuantileTransformation) Process(id execute.DatasetID, tbl query.Table) error {
+	countIdx := execute.ColIdx(t.spec.CountColumn, tbl.Cols())
+	if countIdx < 0 {
+		return fmt.Errorf("histogramQuantile error: column %q doesn't exist", t.spec.CountColumn)
+	}
+	boundIdx := execute.ColIdx(t.spec.UpperBoundColumn, tbl.Cols())
+	if boundIdx < 0 {
+		return fmt.Errorf("histogramQuantile error: column %q doesn't exist", t.spec.UpperBoundColumn)
+	}
+	for _, j := range []int{countIdx, boundIdx} {
+		if c := tbl.Cols()[j]; c.Type != query.TFloat {
+			return fmt.Errorf("histogramQuantile error: column %q must be of type float, got %v", c.Label, c.Type)
+		}
+	}
+
+	var buckets []histogramBucket
+	err := tbl.Do(func(cr query.ColReader) error {
+		for i, l := 0, cr.Len(); i < l; i++ {
+			if cr.IsNull(i, countIdx) || cr.IsNull(i, boundIdx) {
+				continue
+			}
+			buckets = append(buckets, histogramBucket{
+				upperBound: cr.Floats(boundIdx)[i],
+				count:      cr.Floats(countIdx)[i],
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	q, ok, err := t.computeQuantile(buckets)
+	if err != nil {
+		return err
+	}
+
+	key := tbl.Key()
+	builder, created := t.cache.TableBuilder(key)
+	if !created {
+		return fmt.Errorf("histogramQuantile found duplicate table with key: %v", key)
+	}
+	execute.AddTableKeyCols(key, builder)
+	if execute.HasCol(t.spec.ValueColumn, builder.Cols()) {
+		return fmt.Errorf("histogramQuantile error: column %q is part of the group key", t.spec.ValueColumn)
+	}
+	valueIdx := builder.AddCol(query.ColMeta{Label: t.spec.ValueColumn, Type: query.TFloat})
+	execute.AppendKeyValues(key, builder)
+	if !ok {
+		builder.AppendNil(valueIdx)
+		return nil
+	}
+	builder.AppendFloat(valueIdx, q)
+	return nil
+}
+
+// computeQuantile interpolates the quantile linearly within the bucket that contains it.
+// The lower bound of the first bucket is the minimum value of the spec.
+// If the quantile falls within the +Inf bucket the upper bound of the previous bucket is returned.
+// The second return value is false when the histogram is empty.
+func (t *histogramQuantileTransformation) computeQuantile(buckets []histogramBucket) (float64, bool, error) {
+	if len(buckets) == 0 {
+		return 0, false, nil
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].upperBound < buckets[j].upperBound
+	})
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].count < buckets[i-1].count {
+			return 0, false, errors.New("histogramQuantile error: histogram bucket counts are not monotonic")
+		}
+	}
+
+	total := buckets[len(buckets)-1].count
+	if total == 0 {
+		return 0, false, nil
+	}
+	rank := t.spec.Quantile * total
+	b := sort.Search(len(buckets), func(i int) bool {
+		return buckets[i].count >= rank
+	})
+
+	lowerBound, lowerCount := t.spec.MinValue, 0.0
+	if b > 0 {
+		lowerBound, lowerCount = buckets[b-1].upperBound, buckets[b-1].count
+	}
+	upperBound, upperCount := buckets[b].upperBound, buckets[b].count
+	switch {
+	case math.IsInf(upperBound, 1):
+		return lowerBound, true, nil
+	case upperCount == lowerCount:
+		return lowerBound, true, nil
+	}
+	return lowerBound + (upperBound-lowerBound)*(rank-lowerCount)/(upperCount-lowerCount), true, nil
+}
+
+func (t *histogramQuantileTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *histogramQuantileTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *histogramQuantileTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
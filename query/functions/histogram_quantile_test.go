@@ -0,0 +1,224 @@
+package functions_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/querytest"
+)
+
+func TestHistogramQuantile_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "histogramQuantile",
+			Raw:  `from(db:"testdb") |> histogramQuantile(quantile:0.9, minValue:-1.0)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "testdb",
+						},
+					},
+					{
+						ID: "histogramQuantile1",
+						Spec: &functions.HistogramQuantileOpSpec{
+							Quantile:         0.9,
+							CountColumn:      "_value",
+							UpperBoundColumn: "le",
+							ValueColumn:      "_value",
+							MinValue:         -1,
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "histogramQuantile1"},
+				},
+			},
+		},
+		{
+			Name:    "missing quantile",
+			Raw:     `from(db:"testdb") |> histogramQuantile()`,
+			WantErr: true,
+		},
+		{
+			Name:    "quantile out of range",
+			Raw:     `from(db:"testdb") |> histogramQuantile(quantile:1.5)`,
+			WantErr: true,
+		},
+		{
+			Name:    "quantile of wrong type",
+			Raw:     `from(db:"testdb") |> histogramQuantile(quantile:"0.5")`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestHistogramQuantileOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"histogramQuantile","kind":"histogramQuantile","spec":{"quantile":0.9,"countColumn":"_value","upperBoundColumn":"le","valueColumn":"_value","minValue":0}}`)
+	op := &query.Operation{
+		ID: "histogramQuantile",
+		Spec: &functions.HistogramQuantileOpSpec{
+			Quantile:         0.9,
+			CountColumn:      "_value",
+			UpperBoundColumn: "le",
+			ValueColumn:      "_value",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestHistogramQuantile_Process(t *testing.T) {
+	histogram := func(rows ...[]interface{}) []query.Table {
+		return []query.Table{&executetest.Table{
+			KeyCols: []string{"host"},
+			ColMeta: []query.ColMeta{
+				{Label: "host", Type: query.TString},
+				{Label: "le", Type: query.TFloat},
+				{Label: "_value", Type: query.TFloat},
+			},
+			Data: rows,
+		}}
+	}
+	result := func(v interface{}) []*executetest.Table {
+		return []*executetest.Table{{
+			KeyCols: []string{"host"},
+			ColMeta: []query.ColMeta{
+				{Label: "host", Type: query.TString},
+				{Label: "_value", Type: query.TFloat},
+			},
+			Data: [][]interface{}{
+				{"a", v},
+			},
+		}}
+	}
+	testCases := []struct {
+		name    string
+		spec    *functions.HistogramQuantileProcedureSpec
+		data    []query.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "interpolated",
+			spec: &functions.HistogramQuantileProcedureSpec{
+				Quantile:         0.9,
+				CountColumn:      "_value",
+				UpperBoundColumn: "le",
+				ValueColumn:      "_value",
+			},
+			data: histogram(
+				[]interface{}{"a", 0.1, 1.0},
+				[]interface{}{"a", 0.2, 2.0},
+				[]interface{}{"a", 0.3, 3.0},
+				[]interface{}{"a", 0.4, 4.0},
+				[]interface{}{"a", 0.5, 5.0},
+				[]interface{}{"a", 0.6, 6.0},
+				[]interface{}{"a", 0.7, 7.0},
+				[]interface{}{"a", 0.8, 8.0},
+				[]interface{}{"a", 0.9, 9.0},
+				[]interface{}{"a", 1.0, 10.0},
+				[]interface{}{"a", math.Inf(1), 10.0},
+			),
+			want: result(0.9),
+		},
+		{
+			name: "unsorted buckets",
+			spec: &functions.HistogramQuantileProcedureSpec{
+				Quantile:         0.5,
+				CountColumn:      "_value",
+				UpperBoundColumn: "le",
+				ValueColumn:      "_value",
+			},
+			data: histogram(
+				[]interface{}{"a", 20.0, 4.0},
+				[]interface{}{"a", 10.0, 2.0},
+			),
+			want: result(10.0),
+		},
+		{
+			name: "min value",
+			spec: &functions.HistogramQuantileProcedureSpec{
+				Quantile:         0.25,
+				CountColumn:      "_value",
+				UpperBoundColumn: "le",
+				ValueColumn:      "_value",
+				MinValue:         -10,
+			},
+			data: histogram(
+				[]interface{}{"a", 0.0, 2.0},
+				[]interface{}{"a", 10.0, 4.0},
+			),
+			want: result(-5.0),
+		},
+		{
+			name: "infinite bucket",
+			spec: &functions.HistogramQuantileProcedureSpec{
+				Quantile:         0.99,
+				CountColumn:      "_value",
+				UpperBoundColumn: "le",
+				ValueColumn:      "_value",
+			},
+			data: histogram(
+				[]interface{}{"a", 1.0, 5.0},
+				[]interface{}{"a", math.Inf(1), 10.0},
+			),
+			want: result(1.0),
+		},
+		{
+			name: "empty histogram",
+			spec: &functions.HistogramQuantileProcedureSpec{
+				Quantile:         0.5,
+				CountColumn:      "_value",
+				UpperBoundColumn: "le",
+				ValueColumn:      "_value",
+			},
+			data: histogram(
+				[]interface{}{"a", 1.0, 0.0},
+				[]interface{}{"a", math.Inf(1), 0.0},
+			),
+			want: result(nil),
+		},
+		{
+			name: "not monotonic",
+			spec: &functions.HistogramQuantileProcedureSpec{
+				Quantile:         0.5,
+				CountColumn:      "_value",
+				UpperBoundColumn: "le",
+				ValueColumn:      "_value",
+			},
+			data: histogram(
+				[]interface{}{"a", 1.0, 5.0},
+				[]interface{}{"a", 2.0, 4.0},
+			),
+			want:    []*executetest.Table(nil),
+			wantErr: errors.New("histogramQuantile error: histogram bucket counts are not monotonic"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return functions.NewHistogramQuantileTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package functions_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/querytest"
+)
+
+func TestHistogram_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "histogram",
+			Raw:  `from(db:"testdb") |> histogram(bins:[0.0,1.0,2.0])`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "testdb",
+						},
+					},
+					{
+						ID: "histogram1",
+						Spec: &functions.HistogramOpSpec{
+							Column:           "_value",
+							UpperBoundColumn: "le",
+							CountColumn:      "_value",
+							Bins:             []float64{0, 1, 2},
+							Normalize:        false,
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "histogram1"},
+				},
+			},
+		},
+		{
+			Name: "histogram with linear bins",
+			Raw:  `from(db:"testdb") |> histogram(bins:linearBins(start:0.0, width:10.0, count:3), normalize:true)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "testdb",
+						},
+					},
+					{
+						ID: "histogram1",
+						Spec: &functions.HistogramOpSpec{
+							Column:           "_value",
+							UpperBoundColumn: "le",
+							CountColumn:      "_value",
+							Bins:             []float64{0, 10, 20, math.Inf(1)},
+							Normalize:        true,
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "histogram1"},
+				},
+			},
+		},
+		{
+			Name: "histogram with logarithmic bins",
+			Raw:  `from(db:"testdb") |> histogram(bins:logarithmicBins(start:1.0, factor:10.0, count:3, infinity:false))`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "testdb",
+						},
+					},
+					{
+						ID: "histogram1",
+						Spec: &functions.HistogramOpSpec{
+							Column:           "_value",
+							UpperBoundColumn: "le",
+							CountColumn:      "_value",
+							Bins:             []float64{1, 10, 100},
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "histogram1"},
+				},
+			},
+		},
+		{
+			Name:    "missing bins",
+			Raw:     `from(db:"testdb") |> histogram()`,
+			WantErr: true,
+		},
+		{
+			Name:    "bins of wrong type",
+			Raw:     `from(db:"testdb") |> histogram(bins:[1,2,3])`,
+			WantErr: true,
+		},
+		{
+			Name:    "unsorted bins",
+			Raw:     `from(db:"testdb") |> histogram(bins:[2.0,1.0])`,
+			WantErr: true,
+		},
+		{
+			Name:    "non positive width",
+			Raw:     `from(db:"testdb") |> histogram(bins:linearBins(start:0.0, width:0.0, count:3))`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestHistogramOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"histogram","kind":"histogram","spec":{"column":"_value","upperBoundColumn":"le","countColumn":"_value","bins":[0,1,2],"normalize":true}}`)
+	op := &query.Operation{
+		ID: "histogram",
+		Spec: &functions.HistogramOpSpec{
+			Column:           "_value",
+			UpperBoundColumn: "le",
+			CountColumn:      "_value",
+			Bins:             []float64{0, 1, 2},
+			Normalize:        true,
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestHistogram_Process(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *functions.HistogramProcedureSpec
+		data    []query.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "linear",
+			spec: &functions.HistogramProcedureSpec{HistogramOpSpec: functions.HistogramOpSpec{
+				Column:           "_value",
+				UpperBoundColumn: "le",
+				CountColumn:      "_value",
+				Bins:             []float64{0, 10, 20, 30},
+			}},
+			data: []query.Table{&executetest.Table{
+				KeyCols: []string{"_start", "_stop"},
+				ColMeta: []query.ColMeta{
+					{Label: "_start", Type: query.TTime},
+					{Label: "_stop", Type: query.TTime},
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), execute.Time(3), execute.Time(1), 2.0},
+					{execute.Time(1), execute.Time(3), execute.Time(2), 10.0},
+					{execute.Time(1), execute.Time(3), execute.Time(2), 11.0},
+					{execute.Time(1), execute.Time(3), execute.Time(2), nil},
+					{execute.Time(1), execute.Time(3), execute.Time(2), 42.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols: []string{"_start", "_stop"},
+				ColMeta: []query.ColMeta{
+					{Label: "_start", Type: query.TTime},
+					{Label: "_stop", Type: query.TTime},
+					{Label: "le", Type: query.TFloat},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), execute.Time(3), 0.0, 0.0},
+					{execute.Time(1), execute.Time(3), 10.0, 2.0},
+					{execute.Time(1), execute.Time(3), 20.0, 3.0},
+					{execute.Time(1), execute.Time(3), 30.0, 3.0},
+				},
+			}},
+		},
+		{
+			name: "normalized",
+			spec: &functions.HistogramProcedureSpec{HistogramOpSpec: functions.HistogramOpSpec{
+				Column:           "x",
+				UpperBoundColumn: "le",
+				CountColumn:      "_value",
+				Bins:             []float64{1, 10, math.Inf(1)},
+				Normalize:        true,
+			}},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "x", Type: query.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(1)},
+					{execute.Time(2), int64(5)},
+					{execute.Time(3), int64(6)},
+					{execute.Time(4), int64(100)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "le", Type: query.TFloat},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{1.0, 0.25},
+					{10.0, 0.75},
+					{math.Inf(1), 1.0},
+				},
+			}},
+		},
+		{
+			name: "non numeric column",
+			spec: &functions.HistogramProcedureSpec{HistogramOpSpec: functions.HistogramOpSpec{
+				Column:           "_value",
+				UpperBoundColumn: "le",
+				CountColumn:      "_value",
+				Bins:             []float64{1},
+			}},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), "a"},
+				},
+			}},
+			want:    []*executetest.Table(nil),
+			wantErr: errors.New(`histogram error: column "_value" must be numeric, got string`),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return functions.NewHistogramTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
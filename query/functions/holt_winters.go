@@ -0,0 +1,551 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/functions/neldermead"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+)
+
+const HoltWintersKind = "holtWinters"
+
+// HoltWintersOpSpec forecasts the values of a column using the Holt-Winters damped method.
+type HoltWintersOpSpec struct {
+	N           int64          `json:"n"`
+	Seasonality int64          `json:"seasonality"`
+	Interval    query.Duration `json:"interval"`
+	WithFit     bool           `json:"withFit"`
+	TimeColumn  string         `json:"timeColumn"`
+	Column      string         `json:"column"`
+}
+
+var holtWintersSignature = query.DefaultFunctionSignature()
+
+func init() {
+	holtWintersSignature.Params["n"] = semantic.Int
+	holtWintersSignature.Params["seasonality"] = semantic.Int
+	holtWintersSignature.Params["interval"] = semantic.Duration
+	holtWintersSignature.Params["withFit"] = semantic.Bool
+	holtWintersSignature.Params["timeColumn"] = semantic.String
+	holtWintersSignature.Params["column"] = semantic.String
+
+	query.RegisterFunction(HoltWintersKind, createHoltWintersOpSpec, holtWintersSignature)
+	query.RegisterOpSpec(HoltWintersKind, newHoltWintersOp)
+	plan.RegisterProcedureSpec(HoltWintersKind, newHoltWintersProcedure, HoltWintersKind)
+	execute.RegisterTransformation(HoltWintersKind, createHoltWintersTransformation)
+}
+
+func createHoltWintersOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := &HoltWintersOpSpec{
+		TimeColumn: execute.DefaultTimeColLabel,
+		Column:     execute.DefaultValueColLabel,
+	}
+
+	n, err := args.GetRequiredInt("n")
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("holtWinters error: n must be positive, got %d", n)
+	}
+	spec.N = n
+
+	if s, ok, err := args.GetInt("seasonality"); err != nil {
+		return nil, err
+	} else if ok {
+		if s < 0 {
+			return nil, fmt.Errorf("holtWinters error: seasonality cannot be negative, got %d", s)
+		}
+		spec.Seasonality = s
+	}
+
+	interval, err := args.GetRequiredDuration("interval")
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		return nil, errors.New("holtWinters error: interval must be positive")
+	}
+	spec.Interval = interval
+
+	if withFit, ok, err := args.GetBool("withFit"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.WithFit = withFit
+	}
+	if col, ok, err := args.GetString("timeColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.TimeColumn = col
+	}
+	if col, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = col
+	}
+	return spec, nil
+}
+
+func newHoltWintersOp() query.OperationSpec {
+	return new(HoltWintersOpSpec)
+}
+
+func (s *HoltWintersOpSpec) Kind() query.OperationKind {
+	return HoltWintersKind
+}
+
+type HoltWintersProcedureSpec struct {
+	N           int64
+	Seasonality int64
+	Interval    query.Duration
+	WithFit     bool
+	TimeColumn  string
+	Column      string
+}
+
+func newHoltWintersProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*HoltWintersOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return &HoltWintersProcedureSpec{
+		N:           spec.N,
+		Seasonality: spec.Seasonality,
+		Interval:    spec.Interval,
+		WithFit:     spec.WithFit,
+		TimeColumn:  spec.TimeColumn,
+		Column:      spec.Column,
+	}, nil
+}
+
+func (s *HoltWintersProcedureSpec) Kind() plan.ProcedureKind {
+	return HoltWintersKind
+}
+func (s *HoltWintersProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(HoltWintersProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+func createHoltWintersTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*HoltWintersProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewHoltWintersTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type holtWintersTransformation struct {
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	spec HoltWintersProcedureSpec
+}
+
+func NewHoltWintersTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *HoltWintersProcedureSpec) *holtWintersTransformation {
+	return &holtWintersTransformation{
+		d:     d,
+		cache: cache,
+		spec:  *spec,
+	}
+}
+
+func (t *holtWintersTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+// Process forecasts the records of the table, which must be sorted by time.
+// Records with a null time or value are ignored.
+func (t *holtWintersTransformation) Process(id execute.DatasetID, tbl query.Table) error {
+	key := tbl.Key()
+	cols := tbl.Cols()
+	timeIdx := execute.ColIdx(t.spec.TimeColumn, cols)
+	if timeIdx < 0 {
+		return fmt.Errorf("holtWinters error: column %q doesn't exist", t.spec.TimeColumn)
+	}
+	if typ := cols[timeIdx].Type; typ != query.TTime {
+		return fmt.Errorf("holtWinters error: column %q must be of type time, got %v", t.spec.TimeColumn, typ)
+	}
+	valueIdx := execute.ColIdx(t.spec.Column, cols)
+	if valueIdx < 0 {
+		return fmt.Errorf("holtWinters error: column %q doesn't exist", t.spec.Column)
+	}
+	typ := cols[valueIdx].Type
+	switch typ {
+	case query.TInt, query.TUInt, query.TFloat:
+	default:
+		return fmt.Errorf("holtWinters error: unsupported type %v for column %q", typ, t.spec.Column)
+	}
+	for _, label := range []string{t.spec.TimeColumn, t.spec.Column} {
+		if key.HasCol(label) {
+			return fmt.Errorf("holtWinters error: column %q is part of the group key", label)
+		}
+	}
+
+	hw := newHoltWinters(int(t.spec.N), int(t.spec.Seasonality), t.spec.WithFit, execute.Duration(t.spec.Interval))
+	err := tbl.Do(func(cr query.ColReader) error {
+		for i, l := 0, cr.Len(); i < l; i++ {
+			if cr.IsNull(i, timeIdx) || cr.IsNull(i, valueIdx) {
+				continue
+			}
+			var v float64
+			switch typ {
+			case query.TInt:
+				v = float64(cr.Ints(valueIdx)[i])
+			case query.TUInt:
+				v = float64(cr.UInts(valueIdx)[i])
+			case query.TFloat:
+				v = cr.Floats(valueIdx)[i]
+			}
+			hw.add(cr.Times(timeIdx)[i], v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	builder, created := t.cache.TableBuilder(key)
+	if !created {
+		return fmt.Errorf("holtWinters found duplicate table with key: %v", key)
+	}
+	execute.AddTableKeyCols(key, builder)
+	bTimeIdx := builder.AddCol(query.ColMeta{Label: t.spec.TimeColumn, Type: query.TTime})
+	bValueIdx := builder.AddCol(query.ColMeta{Label: t.spec.Column, Type: query.TFloat})
+
+	times, values := hw.emit()
+	for i := range times {
+		execute.AppendKeyValues(key, builder)
+		builder.AppendTime(bTimeIdx, times[i])
+		builder.AppendFloat(bValueIdx, values[i])
+	}
+	return nil
+}
+
+func (t *holtWintersTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *holtWintersTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *holtWintersTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+const (
+	// Arbitrary weight for initializing some initial guesses.
+	// This should be in the range [0,1].
+	hwWeight = 0.5
+	// Epsilon value for the minimization process.
+	hwDefaultEpsilon = 1.0e-4
+	// Define a grid of initial guesses for the parameters: alpha, beta, gamma, and phi.
+	// Keep in mind that this grid is N^4 so we should keep N small.
+	// The starting lower guess.
+	hwGuessLower = 0.3
+	// The upper bound on the grid.
+	hwGuessUpper = 1.0
+	// The step between guesses.
+	hwGuessStep = 0.4
+)
+
+// holtWinters forecasts a series into the future using the Holt-Winters damped method.
+//  1. Using the series the initial values are calculated using a SSE.
+//  2. The series is forecasted into the future using the iterative relations.
+type holtWinters struct {
+	// Season period
+	m        int
+	seasonal bool
+
+	// Horizon
+	h int
+
+	// Interval between points
+	interval execute.Duration
+	// interval / 2 -- used to perform rounding
+	halfInterval execute.Duration
+
+	// Whether to include all data or only future values
+	includeFitData bool
+
+	// NelderMead optimizer
+	optim *neldermead.Optimizer
+	// Small difference bound for the optimizer
+	epsilon float64
+
+	y      []float64
+	times  []execute.Time
+	values []float64
+}
+
+func newHoltWinters(h, m int, includeFitData bool, interval execute.Duration) *holtWinters {
+	return &holtWinters{
+		h:              h,
+		m:              m,
+		seasonal:       m >= 2,
+		includeFitData: includeFitData,
+		interval:       interval,
+		halfInterval:   interval / 2,
+		optim:          neldermead.New(),
+		epsilon:        hwDefaultEpsilon,
+	}
+}
+
+func (r *holtWinters) add(t execute.Time, v float64) {
+	r.times = append(r.times, t)
+	r.values = append(r.values, v)
+}
+
+func (r *holtWinters) roundTime(t execute.Time) execute.Time {
+	// Overflow safe round function
+	interval := execute.Time(r.interval)
+	remainder := t % interval
+	if remainder > execute.Time(r.halfInterval) {
+		// Round up
+		return (t/interval + 1) * interval
+	}
+	// Round down
+	return (t / interval) * interval
+}
+
+// emit returns the points generated by the Holt-Winters algorithm.
+func (r *holtWinters) emit() ([]execute.Time, []float64) {
+	if l := len(r.values); l < 2 || r.seasonal && l < r.m || r.h <= 0 {
+		return nil, nil
+	}
+	// First fill in r.y with values and NaNs for missing values
+	start, stop := r.roundTime(r.times[0]), r.roundTime(r.times[len(r.times)-1])
+	count := (stop - start) / execute.Time(r.interval)
+	if count <= 0 {
+		return nil, nil
+	}
+	r.y = make([]float64, 1, count)
+	r.y[0] = r.values[0]
+	t := start
+	for i, v := range r.values[1:] {
+		rounded := r.roundTime(r.times[i+1])
+		if rounded <= t {
+			// Drop values that occur for the same time bucket
+			continue
+		}
+		t += execute.Time(r.interval)
+		// Add any missing values before the next point
+		for rounded != t {
+			// Add in a NaN so we can skip it later.
+			r.y = append(r.y, math.NaN())
+			t += execute.Time(r.interval)
+		}
+		r.y = append(r.y, v)
+	}
+
+	// Seasonality
+	m := r.m
+
+	// Starting guesses
+	// NOTE: Since these values are guesses
+	// in the cases where we were missing data,
+	// we can just skip the value and call it good.
+
+	l0 := 0.0
+	if r.seasonal {
+		for i := 0; i < m; i++ {
+			if !math.IsNaN(r.y[i]) {
+				l0 += (1 / float64(m)) * r.y[i]
+			}
+		}
+	} else {
+		l0 += hwWeight * r.y[0]
+	}
+
+	b0 := 0.0
+	if r.seasonal {
+		for i := 0; i < m && m+i < len(r.y); i++ {
+			if !math.IsNaN(r.y[i]) && !math.IsNaN(r.y[m+i]) {
+				b0 += 1 / float64(m*m) * (r.y[m+i] - r.y[i])
+			}
+		}
+	} else {
+		if !math.IsNaN(r.y[1]) {
+			b0 = hwWeight * (r.y[1] - r.y[0])
+		}
+	}
+
+	var s []float64
+	if r.seasonal {
+		s = make([]float64, m)
+		for i := 0; i < m; i++ {
+			if !math.IsNaN(r.y[i]) {
+				s[i] = r.y[i] / l0
+			} else {
+				s[i] = 0
+			}
+		}
+	}
+
+	parameters := make([]float64, 6+len(s))
+	parameters[4] = l0
+	parameters[5] = b0
+	o := len(parameters) - len(s)
+	for i := range s {
+		parameters[i+o] = s[i]
+	}
+
+	// Determine best fit for the various parameters
+	minSSE := math.Inf(1)
+	var bestParams []float64
+	for alpha := hwGuessLower; alpha < hwGuessUpper; alpha += hwGuessStep {
+		for beta := hwGuessLower; beta < hwGuessUpper; beta += hwGuessStep {
+			for gamma := hwGuessLower; gamma < hwGuessUpper; gamma += hwGuessStep {
+				for phi := hwGuessLower; phi < hwGuessUpper; phi += hwGuessStep {
+					parameters[0] = alpha
+					parameters[1] = beta
+					parameters[2] = gamma
+					parameters[3] = phi
+					sse, params := r.optim.Optimize(r.sse, parameters, r.epsilon, 1)
+					if sse < minSSE || bestParams == nil {
+						minSSE = sse
+						bestParams = params
+					}
+				}
+			}
+		}
+	}
+
+	// Forecast
+	forecasted := r.forecast(r.h, bestParams)
+	var (
+		times  []execute.Time
+		values []float64
+	)
+	if r.includeFitData {
+		start := r.times[0]
+		for i, v := range forecasted {
+			if !math.IsNaN(v) {
+				times = append(times, start+execute.Time(r.interval)*execute.Time(i))
+				values = append(values, v)
+			}
+		}
+	} else {
+		stop := r.times[len(r.times)-1]
+		for i, v := range forecasted[len(r.y):] {
+			if !math.IsNaN(v) {
+				times = append(times, stop+execute.Time(r.interval)*execute.Time(i+1))
+				values = append(values, v)
+			}
+		}
+	}
+	return times, values
+}
+
+// next computes the next values using the recursive relations.
+func (r *holtWinters) next(alpha, beta, gamma, phi, phiH, yT, lTp, bTp, sTm, sTmh float64) (yTh, lT, bT, sT float64) {
+	lT = alpha*(yT/sTm) + (1-alpha)*(lTp+phi*bTp)
+	bT = beta*(lT-lTp) + (1-beta)*phi*bTp
+	sT = gamma*(yT/(lTp+phi*bTp)) + (1-gamma)*sTm
+	yTh = (lT + phiH*bT) * sTmh
+	return
+}
+
+// forecast forecasts the data h points into the future.
+func (r *holtWinters) forecast(h int, params []float64) []float64 {
+	// Constrain parameters
+	r.constrain(params)
+
+	yT := r.y[0]
+
+	phi := params[3]
+	phiH := phi
+
+	lT := params[4]
+	bT := params[5]
+
+	// seasonals is a ring buffer of past sT values
+	var seasonals []float64
+	var m, so int
+	if r.seasonal {
+		seasonals = params[6:]
+		m = len(params[6:])
+		if m == 1 {
+			seasonals[0] = 1
+		}
+		// Season index offset
+		so = m - 1
+	}
+
+	forecasted := make([]float64, len(r.y)+h)
+	forecasted[0] = yT
+	l := len(r.y)
+	var hm int
+	stm, stmh := 1.0, 1.0
+	for t := 1; t < l+h; t++ {
+		if r.seasonal {
+			hm = t % m
+			stm = seasonals[(t-m+so)%m]
+			stmh = seasonals[(t-m+hm+so)%m]
+		}
+		var sT float64
+		yT, lT, bT, sT = r.next(
+			params[0], // alpha
+			params[1], // beta
+			params[2], // gamma
+			phi,
+			phiH,
+			yT,
+			lT,
+			bT,
+			stm,
+			stmh,
+		)
+		phiH += math.Pow(phi, float64(t))
+
+		if r.seasonal {
+			seasonals[(t+so)%m] = sT
+			so++
+		}
+
+		forecasted[t] = yT
+	}
+	return forecasted
+}
+
+// sse computes the sum squared error for the given parameters.
+func (r *holtWinters) sse(params []float64) float64 {
+	sse := 0.0
+	forecasted := r.forecast(0, params)
+	for i := range forecasted {
+		// Skip missing values since we cannot use them to compute an error.
+		if !math.IsNaN(r.y[i]) {
+			// Compute error
+			if math.IsNaN(forecasted[i]) {
+				// Penalize forecasted NaNs
+				return math.Inf(1)
+			}
+			diff := forecasted[i] - r.y[i]
+			sse += diff * diff
+		}
+	}
+	return sse
+}
+
+// constrain constrains alpha, beta, gamma and phi to the range [0, 1].
+func (r *holtWinters) constrain(x []float64) {
+	for i := 0; i < 4; i++ {
+		if x[i] > 1 {
+			x[i] = 1
+		}
+		if x[i] < 0 {
+			x[i] = 0
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package functions_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/querytest"
+)
+
+func TestHoltWinters_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "holt winters",
+			Raw:  `from(db:"mydb") |> holtWinters(n: 10, seasonality: 4, interval: 24h)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "holtWinters1",
+						Spec: &functions.HoltWintersOpSpec{
+							N:           10,
+							Seasonality: 4,
+							Interval:    query.Duration(24 * time.Hour),
+							TimeColumn:  "_time",
+							Column:      "_value",
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "holtWinters1"},
+				},
+			},
+		},
+		{
+			Name: "holt winters with fit",
+			Raw:  `from(db:"mydb") |> holtWinters(n: 3, interval: 1m, withFit: true, column: "x")`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "holtWinters1",
+						Spec: &functions.HoltWintersOpSpec{
+							N:          3,
+							Interval:   query.Duration(time.Minute),
+							WithFit:    true,
+							TimeColumn: "_time",
+							Column:     "x",
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "holtWinters1"},
+				},
+			},
+		},
+		{
+			Name:    "missing interval",
+			Raw:     `from(db:"mydb") |> holtWinters(n: 10)`,
+			WantErr: true,
+		},
+		{
+			Name:    "non positive n",
+			Raw:     `from(db:"mydb") |> holtWinters(n: 0, interval: 24h)`,
+			WantErr: true,
+		},
+		{
+			Name:    "negative seasonality",
+			Raw:     `from(db:"mydb") |> holtWinters(n: 10, seasonality: -1, interval: 24h)`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestHoltWintersOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"holtWinters","kind":"holtWinters","spec":{"n":10,"seasonality":4,"interval":"1m","withFit":true,"timeColumn":"_time","column":"_value"}}`)
+	op := &query.Operation{
+		ID: "holtWinters",
+		Spec: &functions.HoltWintersOpSpec{
+			N:           10,
+			Seasonality: 4,
+			Interval:    query.Duration(time.Minute),
+			WithFit:     true,
+			TimeColumn:  "_time",
+			Column:      "_value",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+// holtWintersTable creates a table with a record for each value, spaced by one nanosecond starting at the time 1.
+func holtWintersTable(values []float64) *executetest.Table {
+	tbl := &executetest.Table{
+		KeyCols: []string{"t1"},
+		ColMeta: []query.ColMeta{
+			{Label: "_time", Type: query.TTime},
+			{Label: "_value", Type: query.TFloat},
+			{Label: "t1", Type: query.TString},
+		},
+	}
+	for i, v := range values {
+		tbl.Data = append(tbl.Data, []interface{}{execute.Time(i + 1), v, "a"})
+	}
+	return tbl
+}
+
+func TestHoltWinters_Process(t *testing.T) {
+	// Dataset from http://www.inside-r.org/packages/cran/fpp/docs/austourists
+	austourists := []float64{
+		30.052513, 19.148496, 25.317692, 27.591437, 32.076456, 23.487961, 28.47594, 35.123753,
+		36.838485, 25.007017, 30.72223, 28.693759, 36.640986, 23.824609, 29.311683, 31.770309,
+		35.177877, 19.775244, 29.60175, 34.538842, 41.273599, 26.655862, 28.279859, 35.191153,
+		41.727458, 24.04185, 32.328103, 37.328708, 46.213153, 29.346326, 36.48291, 42.977719,
+		48.901525, 31.180221, 37.717881, 40.420211, 51.206863, 31.887228, 40.978263, 43.772491,
+		55.558567, 33.850915, 42.076383, 45.642292, 59.76678, 35.191877, 44.319737, 47.913736,
+	}
+	usPopulation := []float64{
+		3.93, 5.31, 7.24, 9.64, 12.90, 17.10, 23.20, 31.40, 39.80, 50.20,
+		62.90, 76.00, 92.00, 105.70, 122.80, 131.70, 151.30, 179.30, 203.20,
+	}
+
+	testCases := []struct {
+		name string
+		spec *functions.HoltWintersProcedureSpec
+		data *executetest.Table
+		want []float64
+	}{
+		{
+			name: "seasonal",
+			spec: &functions.HoltWintersProcedureSpec{
+				N:           10,
+				Seasonality: 4,
+				Interval:    1,
+				TimeColumn:  "_time",
+				Column:      "_value",
+			},
+			data: holtWintersTable(austourists),
+			want: []float64{
+				51.85064132137853,
+				43.26055282315273,
+				41.827258044814464,
+				54.3990354591749,
+				54.62334472770803,
+				45.57155693625209,
+				44.06051240252263,
+				57.30029870759433,
+				57.53591513519172,
+				47.999008139396096,
+			},
+		},
+		{
+			name: "with fit",
+			spec: &functions.HoltWintersProcedureSpec{
+				N:          10,
+				Interval:   1,
+				WithFit:    true,
+				TimeColumn: "_time",
+				Column:     "_value",
+			},
+			data: holtWintersTable(usPopulation),
+			want: []float64{
+				3.93,
+				4.957405463559748,
+				7.012210102535647,
+				10.099589257439924,
+				14.229926188104242,
+				19.418878968703797,
+				25.68749172281409,
+				33.062351305731305,
+				41.575791076125206,
+				51.26614395589263,
+				62.178047564264595,
+				74.36280483872488,
+				87.87880423073163,
+				102.79200429905801,
+				119.17648832929542,
+				137.11509549747296,
+				156.70013608313175,
+				178.03419933863566,
+				201.23106385518594,
+				226.4167216525905,
+				253.73052878285205,
+				283.32649700397553,
+				315.37474308085984,
+				350.06311454009256,
+				387.59901328556873,
+				428.21144141893404,
+				472.1532969569147,
+				519.7039509590035,
+				571.1721419458248,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			d := executetest.NewDataset(executetest.RandomDatasetID())
+			c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+			c.SetTriggerSpec(execute.DefaultTriggerSpec)
+
+			tx := functions.NewHoltWintersTransformation(d, c, tc.spec)
+			if err := tx.Process(executetest.RandomDatasetID(), tc.data); err != nil {
+				t.Fatal(err)
+			}
+
+			// The forecast starts after the last record unless the fitted values are included.
+			start := len(tc.data.Data) + 1
+			if tc.spec.WithFit {
+				start = 1
+			}
+			want := []*executetest.Table{{
+				KeyCols: []string{"t1"},
+				ColMeta: []query.ColMeta{
+					{Label: "t1", Type: query.TString},
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+			}}
+			for i, v := range tc.want {
+				want[0].Data = append(want[0].Data, []interface{}{"a", execute.Time(start + i), v})
+			}
+
+			got, err := executetest.TablesFromCache(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			executetest.NormalizeTables(got)
+			executetest.NormalizeTables(want)
+			if !cmp.Equal(want, got, cmpopts.EquateApprox(0, 1e-5)) {
+				t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+func TestHoltWinters_Process_Errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *functions.HoltWintersProcedureSpec
+		wantErr error
+	}{
+		{
+			name: "missing column",
+			spec: &functions.HoltWintersProcedureSpec{
+				N:          1,
+				Interval:   1,
+				TimeColumn: "_time",
+				Column:     "x",
+			},
+			wantErr: errors.New(`holtWinters error: column "x" doesn't exist`),
+		},
+		{
+			name: "string column",
+			spec: &functions.HoltWintersProcedureSpec{
+				N:          1,
+				Interval:   1,
+				TimeColumn: "_time",
+				Column:     "t1",
+			},
+			wantErr: errors.New(`holtWinters error: unsupported type string for column "t1"`),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				[]query.Table{holtWintersTable([]float64{1, 2, 3})},
+				nil,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return functions.NewHoltWintersTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
@@ -19,9 +19,30 @@ import (
 const JoinKind = "join"
 const MergeJoinKind = "merge-join"
 
+// Join methods determine which unmatched records are part of the result of a join.
+const (
+	JoinMethodInner = "inner"
+	JoinMethodLeft  = "left"
+	JoinMethodRight = "right"
+	JoinMethodFull  = "full"
+)
+
+// Join fills determine the values of the missing side of an unmatched record.
+const (
+	// JoinFillNull leaves the columns of the missing side null.
+	JoinFillNull = "null"
+	// JoinFillDefault sets the columns of the missing side to the default value of their type,
+	// that is 0, false, the empty string or the zero time.
+	JoinFillDefault = "default"
+)
+
 type JoinOpSpec struct {
 	// On is a list of tags on which to join.
 	On []string `json:"on"`
+	// Method is the join method, one of inner, left, right or full.
+	Method string `json:"method"`
+	// Fill is how the missing side of an unmatched record is filled, one of null or default.
+	Fill string `json:"fill"`
 	// Fn is a function accepting a single parameter.
 	// The parameter is map if records for each of the parent operations.
 	Fn *semantic.FunctionExpression `json:"fn"`
@@ -72,6 +93,8 @@ var joinSignature = semantic.FunctionSignature{
 		"tables": semantic.Object,
 		"fn":     semantic.Function,
 		"on":     semantic.NewArrayType(semantic.String),
+		"method": semantic.String,
+		"fill":   semantic.String,
 	},
 	ReturnType:   query.TableObjectType,
 	PipeArgument: "tables",
@@ -109,6 +132,30 @@ func createJoinOpSpec(args query.Arguments, a *query.Administration) (query.Oper
 		}
 	}
 
+	spec.Method = JoinMethodInner
+	if m, ok, err := args.GetString("method"); err != nil {
+		return nil, err
+	} else if ok {
+		switch m {
+		case JoinMethodInner, JoinMethodLeft, JoinMethodRight, JoinMethodFull:
+			spec.Method = m
+		default:
+			return nil, fmt.Errorf("unknown join method %q, must be one of inner, left, right or full", m)
+		}
+	}
+
+	spec.Fill = JoinFillNull
+	if f, ok, err := args.GetString("fill"); err != nil {
+		return nil, err
+	} else if ok {
+		switch f {
+		case JoinFillNull, JoinFillDefault:
+			spec.Fill = f
+		default:
+			return nil, fmt.Errorf("unknown join fill %q, must be one of null or default", f)
+		}
+	}
+
 	if m, ok, err := args.GetObject("tables"); err != nil {
 		return nil, err
 	} else if ok {
@@ -160,6 +207,8 @@ func (s *JoinOpSpec) Kind() query.OperationKind {
 
 type MergeJoinProcedureSpec struct {
 	On         []string                     `json:"keys"`
+	Method     string                       `json:"method"`
+	Fill       string                       `json:"fill"`
 	Fn         *semantic.FunctionExpression `json:"f"`
 	TableNames map[plan.ProcedureID]string  `json:"table_names"`
 }
@@ -178,9 +227,17 @@ func newMergeJoinProcedure(qs query.OperationSpec, pa plan.Administration) (plan
 
 	p := &MergeJoinProcedureSpec{
 		On:         spec.On,
+		Method:     spec.Method,
+		Fill:       spec.Fill,
 		Fn:         spec.Fn,
 		TableNames: tableNames,
 	}
+	if p.Method == "" {
+		p.Method = JoinMethodInner
+	}
+	if p.Fill == "" {
+		p.Fill = JoinFillNull
+	}
 	sort.Strings(p.On)
 	return p, nil
 }
@@ -193,6 +250,8 @@ func (s *MergeJoinProcedureSpec) Copy() plan.ProcedureSpec {
 
 	ns.On = make([]string, len(s.On))
 	copy(ns.On, s.On)
+	ns.Method = s.Method
+	ns.Fill = s.Fill
 
 	ns.Fn = s.Fn.Copy().(*semantic.FunctionExpression)
 
@@ -229,7 +288,7 @@ func createMergeJoinTransformation(id execute.DatasetID, mode execute.Accumulati
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "invalid expression")
 	}
-	cache := NewMergeJoinCache(joinFn, a.Allocator(), leftName, rightName, s.On)
+	cache := NewMergeJoinCache(joinFn, a.Allocator(), leftName, rightName, s.On, s.Method, s.Fill)
 	d := execute.NewDataset(id, mode, cache)
 	t := NewMergeJoinTransformation(d, cache, s, parents, tableNames)
 	return t, d, nil
@@ -284,16 +343,17 @@ func (t *mergeJoinTransformation) Process(id execute.DatasetID, tbl query.Table)
 
 	tables := t.cache.Tables(tbl.Key())
 
-	var references []string
+	var name string
 	var table execute.TableBuilder
 	switch id {
 	case t.leftID:
 		table = tables.left
-		references = tables.joinFn.references[t.leftName]
+		name = t.leftName
 	case t.rightID:
 		table = tables.right
-		references = tables.joinFn.references[t.rightName]
+		name = t.rightName
 	}
+	references := tables.joinFn.references[name]
 
 	// Add columns to table
 	labels := unionStrs(t.keys, references)
@@ -308,6 +368,7 @@ func (t *mergeJoinTransformation) Process(id execute.DatasetID, tbl query.Table)
 		if builderIdx < 0 {
 			c := tbl.Cols()[tableIdx]
 			builderIdx = table.AddCol(c)
+			tables.schemas[name][c.Label] = c
 		}
 		colMap[builderIdx] = tableIdx
 	}
@@ -390,8 +451,14 @@ type mergeJoinCache struct {
 	data  *execute.GroupLookup
 	alloc *execute.Allocator
 
-	keys []string
-	on   map[string]bool
+	keys   []string
+	on     map[string]bool
+	method string
+	fill   string
+
+	// schemas holds the columns received from each table by name, across all group keys.
+	// They are used to type the missing side of an outer join.
+	schemas map[string]map[string]query.ColMeta
 
 	leftName, rightName string
 
@@ -400,15 +467,21 @@ type mergeJoinCache struct {
 	joinFn *joinFunc
 }
 
-func NewMergeJoinCache(joinFn *joinFunc, a *execute.Allocator, leftName, rightName string, keys []string) *mergeJoinCache {
+func NewMergeJoinCache(joinFn *joinFunc, a *execute.Allocator, leftName, rightName string, keys []string, method, fill string) *mergeJoinCache {
 	on := make(map[string]bool, len(keys))
 	for _, k := range keys {
 		on[k] = true
 	}
 	return &mergeJoinCache{
-		data:      execute.NewGroupLookup(),
-		keys:      keys,
-		on:        on,
+		data:   execute.NewGroupLookup(),
+		keys:   keys,
+		on:     on,
+		method: method,
+		fill:   fill,
+		schemas: map[string]map[string]query.ColMeta{
+			leftName:  make(map[string]query.ColMeta),
+			rightName: make(map[string]query.ColMeta),
+		},
 		joinFn:    joinFn,
 		alloc:     a,
 		leftName:  leftName,
@@ -474,6 +547,9 @@ func (c *mergeJoinCache) Tables(key query.GroupKey) *joinTables {
 			keys:      c.keys,
 			key:       key,
 			on:        c.on,
+			method:    c.method,
+			fill:      c.fill,
+			schemas:   c.schemas,
 			alloc:     c.alloc,
 			left:      execute.NewColListTableBuilder(key, c.alloc),
 			right:     execute.NewColListTableBuilder(key, c.alloc),
@@ -488,9 +564,13 @@ func (c *mergeJoinCache) Tables(key query.GroupKey) *joinTables {
 }
 
 type joinTables struct {
-	keys []string
-	on   map[string]bool
-	key  query.GroupKey
+	keys   []string
+	on     map[string]bool
+	method string
+	fill   string
+	key    query.GroupKey
+
+	schemas map[string]map[string]query.ColMeta
 
 	alloc *execute.Allocator
 
@@ -506,20 +586,28 @@ func (t *joinTables) Size() int {
 	return t.left.NRows() + t.right.NRows()
 }
 
+// ClearData releases the buffered rows of both tables.
 func (t *joinTables) ClearData() {
+	t.left.ClearData()
+	t.right.ClearData()
 	t.left = execute.NewColListTableBuilder(t.key, t.alloc)
 	t.right = execute.NewColListTableBuilder(t.key, t.alloc)
 }
 
 // Join performs a sort-merge join
 func (t *joinTables) Join() (query.Table, error) {
+	// A table that has not received any records for this group key
+	// is typed by the columns received for other group keys.
+	t.addMissingCols(t.left, t.leftName)
+	t.addMissingCols(t.right, t.rightName)
+
 	// First prepare the join function
 	left := t.left.RawTable()
 	right := t.right.RawTable()
 	err := t.joinFn.Prepare(map[string]*execute.ColListTable{
 		t.leftName:  left,
 		t.rightName: right,
-	})
+	}, t.on, t.fill)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to prepare join function")
 	}
@@ -562,35 +650,93 @@ func (t *joinTables) Join() (query.Table, error) {
 		t.rightName: -1,
 	}
 
+	// appendRow evaluates the join function for the rows and adds the result to the table.
+	// A row index of -1 indicates the table has no matching record.
+	appendRow := func(l, r int) error {
+		rows[t.leftName] = l
+		rows[t.rightName] = r
+		m, err := t.joinFn.Eval(rows)
+		if err != nil {
+			return errors.Wrap(err, "failed to evaluate join function")
+		}
+		for j, c := range bCols {
+			if t.joinFn.IsNull(c.Label, rows) {
+				builder.AppendNil(j)
+				continue
+			}
+			v, _ := m.Get(c.Label)
+			execute.AppendValue(builder, j, v)
+		}
+		return nil
+	}
+	keepLeft := t.method == JoinMethodLeft || t.method == JoinMethodFull
+	keepRight := t.method == JoinMethodRight || t.method == JoinMethodFull
+
 	leftSet, leftKey = t.advance(leftSet.Stop, left)
 	rightSet, rightKey = t.advance(rightSet.Stop, right)
 	for !leftSet.Empty() && !rightSet.Empty() {
 		if leftKey.Equal(rightKey) {
-			// Inner join
 			for l := leftSet.Start; l < leftSet.Stop; l++ {
 				for r := rightSet.Start; r < rightSet.Stop; r++ {
-					// Evaluate expression and add to table
-					rows[t.leftName] = l
-					rows[t.rightName] = r
-					m, err := t.joinFn.Eval(rows)
-					if err != nil {
-						return nil, errors.Wrap(err, "failed to evaluate join function")
-					}
-					for j, c := range bCols {
-						v, _ := m.Get(c.Label)
-						execute.AppendValue(builder, j, v)
+					if err := appendRow(l, r); err != nil {
+						return nil, err
 					}
 				}
 			}
 			leftSet, leftKey = t.advance(leftSet.Stop, left)
 			rightSet, rightKey = t.advance(rightSet.Stop, right)
 		} else if leftKey.Less(rightKey) {
+			if keepLeft {
+				for l := leftSet.Start; l < leftSet.Stop; l++ {
+					if err := appendRow(l, -1); err != nil {
+						return nil, err
+					}
+				}
+			}
 			leftSet, leftKey = t.advance(leftSet.Stop, left)
 		} else {
+			if keepRight {
+				for r := rightSet.Start; r < rightSet.Stop; r++ {
+					if err := appendRow(-1, r); err != nil {
+						return nil, err
+					}
+				}
+			}
 			rightSet, rightKey = t.advance(rightSet.Stop, right)
 		}
 	}
-	return builder.Table()
+	// Only one of the tables can have records left.
+	if keepLeft {
+		for l := leftSet.Start; l < left.NRows(); l++ {
+			if err := appendRow(l, -1); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if keepRight {
+		for r := rightSet.Start; r < right.NRows(); r++ {
+			if err := appendRow(-1, r); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The result is a copy, release the rows held by the builder.
+	tbl, err := builder.Table()
+	builder.ClearData()
+	return tbl, err
+}
+
+// addMissingCols adds the columns needed by the join that the table has not received.
+func (t *joinTables) addMissingCols(b *execute.ColListTableBuilder, name string) {
+	for _, label := range unionStrs(t.keys, t.joinFn.references[name]) {
+		if execute.HasCol(label, b.Cols()) {
+			continue
+		}
+		if c, ok := t.schemas[name][label]; ok {
+			b.AddCol(c)
+		}
+	}
 }
 
 func (t *joinTables) advance(offset int, table *execute.ColListTable) (subset, query.GroupKey) {
@@ -667,6 +813,13 @@ type joinFunc struct {
 	recordCols map[tableCol]int
 	references map[string][]string
 
+	// propertyRefs are the table columns referenced by each property of the returned object.
+	// Properties that are not listed, for example when the body is not an object expression, may reference any column.
+	propertyRefs map[string][]tableCol
+	allRefs      []tableCol
+	on           map[string]bool
+	fill         string
+
 	isWrap  bool
 	wrapObj *execute.Record
 
@@ -682,17 +835,45 @@ func NewRowJoinFunction(fn *semantic.FunctionExpression, parentIDs []execute.Dat
 		return nil, errors.New("join function should only have one parameter for the map of tables")
 	}
 	scope, decls := query.BuiltIns()
+	references := findTableReferences(fn)
+	propertyRefs := make(map[string][]tableCol)
+	if obj, ok := fn.Body.(*semantic.ObjectExpression); ok {
+		for _, p := range obj.Properties {
+			v := &tableReferenceVisitor{
+				record: fn.Params[0].Key.Name,
+				refs:   make(map[string][]string),
+			}
+			semantic.Walk(v, p.Value)
+			propertyRefs[p.Key.Name] = tableCols(v.refs)
+		}
+	}
 	return &joinFunc{
 		compilationCache: compiler.NewCompilationCache(fn, scope, decls),
 		scope:            make(compiler.Scope, 1),
-		references:       findTableReferences(fn),
+		references:       references,
+		propertyRefs:     propertyRefs,
+		allRefs:          tableCols(references),
 		recordCols:       make(map[tableCol]int),
 		recordName:       fn.Params[0].Key.Name,
 	}, nil
 }
 
-func (f *joinFunc) Prepare(tables map[string]*execute.ColListTable) error {
+func tableCols(refs map[string][]string) []tableCol {
+	var cols []tableCol
+	for tbl, labels := range refs {
+		for _, label := range labels {
+			cols = append(cols, tableCol{table: tbl, col: label})
+		}
+	}
+	return cols
+}
+
+// Prepare compiles the function for the columns of the tables.
+// The columns in on are equal for matching records, and fill determines the columns of a table without a matching record.
+func (f *joinFunc) Prepare(tables map[string]*execute.ColListTable, on map[string]bool, fill string) error {
 	f.tableData = tables
+	f.on = on
+	f.fill = fill
 	propertyTypes := make(map[string]semantic.Type, len(f.references))
 	// Prepare types and recordcols
 	for tbl, b := range tables {
@@ -739,6 +920,10 @@ func (f *joinFunc) Type() semantic.Type {
 	return f.preparedFn.Type()
 }
 
+// Eval evaluates the function for the row of each table.
+// A row of -1 indicates that the table has no matching record, its columns are null,
+// or the default value of their type when filled with defaults,
+// except for the columns joined on which are read from the other tables.
 func (f *joinFunc) Eval(rows map[string]int) (values.Object, error) {
 	for tbl, references := range f.references {
 		row := rows[tbl]
@@ -746,7 +931,22 @@ func (f *joinFunc) Eval(rows map[string]int) (values.Object, error) {
 		obj, _ := f.record.Get(tbl)
 		o := obj.(*execute.Record)
 		for _, r := range references {
-			o.Set(r, execute.ValueForRow(row, f.recordCols[tableCol{table: tbl, col: r}], data))
+			j := f.recordCols[tableCol{table: tbl, col: r}]
+			if row >= 0 {
+				o.Set(r, execute.ValueForRow(row, j, data))
+				if data.IsNull(row, j) {
+					o.SetNull(r)
+				}
+				continue
+			}
+			if v, ok := f.onValue(r, rows); ok {
+				o.Set(r, v)
+				continue
+			}
+			o.Set(r, zeroValue(data.Cols()[j].Type))
+			if f.fill != JoinFillDefault {
+				o.SetNull(r)
+			}
 		}
 	}
 	f.scope[f.recordName] = f.record
@@ -762,6 +962,61 @@ func (f *joinFunc) Eval(rows map[string]int) (values.Object, error) {
 	return v.Object(), nil
 }
 
+// onValue returns the value of the joined on column from a table that has a matching record.
+func (f *joinFunc) onValue(label string, rows map[string]int) (values.Value, bool) {
+	if !f.on[label] {
+		return nil, false
+	}
+	for tbl, data := range f.tableData {
+		row := rows[tbl]
+		if row < 0 {
+			continue
+		}
+		if j := execute.ColIdx(label, data.Cols()); j >= 0 {
+			return execute.ValueForRow(row, j, data), true
+		}
+	}
+	return nil, false
+}
+
+// IsNull reports whether the property of the result of the last evaluation is null,
+// because it references a column of a table without a matching record.
+func (f *joinFunc) IsNull(property string, rows map[string]int) bool {
+	if f.fill == JoinFillDefault {
+		return false
+	}
+	refs, ok := f.propertyRefs[property]
+	if !ok {
+		refs = f.allRefs
+	}
+	for _, r := range refs {
+		if rows[r.table] < 0 && !f.on[r.col] {
+			return true
+		}
+	}
+	return false
+}
+
+func zeroValue(t query.DataType) values.Value {
+	switch t {
+	case query.TBool:
+		return values.NewBoolValue(false)
+	case query.TInt:
+		return values.NewIntValue(0)
+	case query.TUInt:
+		return values.NewUIntValue(0)
+	case query.TFloat:
+		return values.NewFloatValue(0)
+	case query.TString:
+		return values.NewStringValue("")
+	case query.TTime:
+		return values.NewTimeValue(0)
+	default:
+		execute.PanicUnknownType(t)
+		return nil
+	}
+}
+
 func findTableReferences(fn *semantic.FunctionExpression) map[string][]string {
 	v := &tableReferenceVisitor{
 		record: fn.Params[0].Key.Name,
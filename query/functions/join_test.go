@@ -15,6 +15,7 @@ import (
 	"github.com/influxdata/platform/query/plan/plantest"
 	"github.com/influxdata/platform/query/querytest"
 	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
 )
 
 func TestJoin_NewQuery(t *testing.T) {
@@ -73,6 +74,8 @@ join(tables:{a:a,b:b}, on:["host"], fn: (t) => t.a["_value"] + t.b["_value"])`,
 						ID: "join4",
 						Spec: &functions.JoinOpSpec{
 							On:         []string{"host"},
+							Method:     functions.JoinMethodInner,
+							Fill:       functions.JoinFillNull,
 							TableNames: map[query.OperationID]string{"range1": "a", "range3": "b"},
 							Fn: &semantic.FunctionExpression{
 								Params: []*semantic.FunctionParam{{Key: &semantic.Identifier{Name: "t"}}},
@@ -164,6 +167,8 @@ join(tables:{a:a,b:b}, on:["host"], fn: (t) => t.a["_value"] + t.b["_value"])`,
 						ID: "join4",
 						Spec: &functions.JoinOpSpec{
 							On:         []string{"t1"},
+							Method:     functions.JoinMethodInner,
+							Fill:       functions.JoinFillNull,
 							TableNames: map[query.OperationID]string{"range1": "a", "range3": "b"},
 							Fn: &semantic.FunctionExpression{
 								Params: []*semantic.FunctionParam{{Key: &semantic.Identifier{Name: "t"}}},
@@ -212,6 +217,118 @@ join(tables:{a:a,b:b}, on:["host"], fn: (t) => t.a["_value"] + t.b["_value"])`,
 				},
 			},
 		},
+		{
+			Name: "left join",
+			Raw: `
+a = from(db:"dbA")
+b = from(db:"dbB")
+join(tables:{a:a,b:b}, on:["_time"], method:"left", fn: (t) => t.b._value)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "dbA",
+						},
+					},
+					{
+						ID: "from1",
+						Spec: &functions.FromOpSpec{
+							Database: "dbB",
+						},
+					},
+					{
+						ID: "join2",
+						Spec: &functions.JoinOpSpec{
+							On:         []string{"_time"},
+							Method:     functions.JoinMethodLeft,
+							Fill:       functions.JoinFillNull,
+							TableNames: map[query.OperationID]string{"from0": "a", "from1": "b"},
+							Fn: &semantic.FunctionExpression{
+								Params: []*semantic.FunctionParam{{Key: &semantic.Identifier{Name: "t"}}},
+								Body: &semantic.MemberExpression{
+									Object: &semantic.MemberExpression{
+										Object: &semantic.IdentifierExpression{
+											Name: "t",
+										},
+										Property: "b",
+									},
+									Property: "_value",
+								},
+							},
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "join2"},
+					{Parent: "from1", Child: "join2"},
+				},
+			},
+		},
+		{
+			Name: "left join filled with defaults",
+			Raw: `
+a = from(db:"dbA")
+b = from(db:"dbB")
+join(tables:{a:a,b:b}, on:["_time"], method:"left", fill:"default", fn: (t) => t.b._value)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "dbA",
+						},
+					},
+					{
+						ID: "from1",
+						Spec: &functions.FromOpSpec{
+							Database: "dbB",
+						},
+					},
+					{
+						ID: "join2",
+						Spec: &functions.JoinOpSpec{
+							On:         []string{"_time"},
+							Method:     functions.JoinMethodLeft,
+							Fill:       functions.JoinFillDefault,
+							TableNames: map[query.OperationID]string{"from0": "a", "from1": "b"},
+							Fn: &semantic.FunctionExpression{
+								Params: []*semantic.FunctionParam{{Key: &semantic.Identifier{Name: "t"}}},
+								Body: &semantic.MemberExpression{
+									Object: &semantic.MemberExpression{
+										Object: &semantic.IdentifierExpression{
+											Name: "t",
+										},
+										Property: "b",
+									},
+									Property: "_value",
+								},
+							},
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "join2"},
+					{Parent: "from1", Child: "join2"},
+				},
+			},
+		},
+		{
+			Name: "unknown join method",
+			Raw: `
+a = from(db:"dbA")
+b = from(db:"dbB")
+join(tables:{a:a,b:b}, on:["_time"], method:"outer", fn: (t) => t.b._value)`,
+			WantErr: true,
+		},
+		{
+			Name: "unknown join fill",
+			Raw: `
+a = from(db:"dbA")
+b = from(db:"dbB")
+join(tables:{a:a,b:b}, on:["_time"], method:"left", fill:"previous", fn: (t) => t.b._value)`,
+			WantErr: true,
+		},
 	}
 	for _, tc := range tests {
 		tc := tc
@@ -1053,6 +1170,283 @@ func TestMergeJoin_Process(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "left",
+			spec: &functions.MergeJoinProcedureSpec{
+				On:         []string{"_time"},
+				Method:     functions.JoinMethodLeft,
+				Fill:       functions.JoinFillNull,
+				Fn:         addFunction,
+				TableNames: tableNames,
+			},
+			data0: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 2.0},
+						{execute.Time(4), 4.0},
+					},
+				},
+			},
+			data1: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(2), 20.0},
+						{execute.Time(3), 30.0},
+						{execute.Time(4), 40.0},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), nil},
+						{execute.Time(2), 22.0},
+						{execute.Time(4), 44.0},
+					},
+				},
+			},
+		},
+		{
+			name: "right",
+			spec: &functions.MergeJoinProcedureSpec{
+				On:         []string{"_time"},
+				Method:     functions.JoinMethodRight,
+				Fill:       functions.JoinFillNull,
+				Fn:         addFunction,
+				TableNames: tableNames,
+			},
+			data0: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 2.0},
+						{execute.Time(4), 4.0},
+					},
+				},
+			},
+			data1: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(2), 20.0},
+						{execute.Time(3), 30.0},
+						{execute.Time(4), 40.0},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(2), 22.0},
+						{execute.Time(3), nil},
+						{execute.Time(4), 44.0},
+					},
+				},
+			},
+		},
+		{
+			name: "full",
+			spec: &functions.MergeJoinProcedureSpec{
+				On:         []string{"_time"},
+				Method:     functions.JoinMethodFull,
+				Fill:       functions.JoinFillNull,
+				Fn:         addFunction,
+				TableNames: tableNames,
+			},
+			data0: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 2.0},
+						{execute.Time(4), 4.0},
+					},
+				},
+			},
+			data1: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(2), 20.0},
+						{execute.Time(3), 30.0},
+						{execute.Time(4), 40.0},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), nil},
+						{execute.Time(2), 22.0},
+						{execute.Time(3), nil},
+						{execute.Time(4), 44.0},
+					},
+				},
+			},
+		},
+		{
+			name: "full with default fill",
+			spec: &functions.MergeJoinProcedureSpec{
+				On:         []string{"_time"},
+				Method:     functions.JoinMethodFull,
+				Fill:       functions.JoinFillDefault,
+				Fn:         addFunction,
+				TableNames: tableNames,
+			},
+			data0: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 2.0},
+						{execute.Time(4), 4.0},
+					},
+				},
+			},
+			data1: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(2), 20.0},
+						{execute.Time(3), 30.0},
+						{execute.Time(4), 40.0},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+						{execute.Time(2), 22.0},
+						{execute.Time(3), 30.0},
+						{execute.Time(4), 44.0},
+					},
+				},
+			},
+		},
+		{
+			name: "left with missing group key",
+			spec: &functions.MergeJoinProcedureSpec{
+				On:         []string{"_time"},
+				Method:     functions.JoinMethodLeft,
+				Fill:       functions.JoinFillNull,
+				Fn:         addFunction,
+				TableNames: tableNames,
+			},
+			data0: []*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0, "a"},
+					},
+				},
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 2.0, "b"},
+					},
+				},
+			},
+			data1: []*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 10.0, "a"},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					GroupKey: execute.NewGroupKey(
+						[]query.ColMeta{{Label: "host", Type: query.TString}},
+						[]values.Value{values.NewStringValue("a")},
+					),
+					KeyCols:   []string{"host"},
+					KeyValues: []interface{}{"a"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 11.0},
+					},
+				},
+				{
+					GroupKey: execute.NewGroupKey(
+						[]query.ColMeta{{Label: "host", Type: query.TString}},
+						[]values.Value{values.NewStringValue("b")},
+					),
+					KeyCols:   []string{"host"},
+					KeyValues: []interface{}{"b"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), nil},
+					},
+				},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -1072,7 +1466,7 @@ func TestMergeJoin_Process(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			c := functions.NewMergeJoinCache(joinExpr, executetest.UnlimitedAllocator, tableNames[parents[0]], tableNames[parents[1]], tc.spec.On)
+			c := functions.NewMergeJoinCache(joinExpr, executetest.UnlimitedAllocator, tableNames[parents[0]], tableNames[parents[1]], tc.spec.On, tc.spec.Method, tc.spec.Fill)
 			c.SetTriggerSpec(execute.DefaultTriggerSpec)
 			jt := functions.NewMergeJoinTransformation(d, c, tc.spec, parents, tableNames)
 
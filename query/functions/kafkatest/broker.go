@@ -0,0 +1,177 @@
+// Package kafkatest provides an in-memory Kafka broker for testing the Kafka functions.
+package kafkatest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// Broker is an in-memory Kafka broker.
+// Every topic has a single partition.
+// Readers of the same group consume from the offset last committed by the group.
+//
+// The readers and writers it creates satisfy functions.KafkaReader and functions.KafkaWriter,
+// so a broker can be injected with the DefaultKafkaReaderFactory and DefaultKafkaWriterFactory variables.
+type Broker struct {
+	mu        sync.Mutex
+	topics    map[string][]kafka.Message
+	committed map[groupTopic]int64
+	// produced is closed and replaced whenever messages are produced.
+	produced chan struct{}
+}
+
+type groupTopic struct {
+	group, topic string
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		topics:    make(map[string][]kafka.Message),
+		committed: make(map[groupTopic]int64),
+		produced:  make(chan struct{}),
+	}
+}
+
+// Produce appends messages with the given values to topic.
+func (b *Broker) Produce(topic string, values ...[]byte) {
+	msgs := make([]kafka.Message, len(values))
+	for i, v := range values {
+		msgs[i] = kafka.Message{Value: v}
+	}
+	b.produce(topic, msgs)
+}
+
+func (b *Broker) produce(topic string, msgs []kafka.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	for _, m := range msgs {
+		m.Topic = topic
+		m.Partition = 0
+		m.Offset = int64(len(b.topics[topic]))
+		if m.Time.IsZero() {
+			m.Time = now
+		}
+		b.topics[topic] = append(b.topics[topic], m)
+	}
+	close(b.produced)
+	b.produced = make(chan struct{})
+}
+
+// Messages returns the messages of topic.
+func (b *Broker) Messages(topic string) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]kafka.Message(nil), b.topics[topic]...)
+}
+
+// CommittedOffset returns the offset of the next message group will consume from topic.
+func (b *Broker) CommittedOffset(group, topic string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.committed[groupTopic{group: group, topic: topic}]
+}
+
+// NewReader returns a reader that consumes conf.Topic as a member of conf.GroupID.
+func (b *Broker) NewReader(conf kafka.ReaderConfig) *Reader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	gt := groupTopic{group: conf.GroupID, topic: conf.Topic}
+	return &Reader{
+		b:      b,
+		gt:     gt,
+		offset: b.committed[gt],
+	}
+}
+
+// NewWriter returns a writer that produces to conf.Topic.
+func (b *Broker) NewWriter(conf kafka.WriterConfig) *Writer {
+	return &Writer{
+		b:     b,
+		topic: conf.Topic,
+	}
+}
+
+var errClosed = errors.New("kafkatest: closed")
+
+// Reader consumes the messages of a topic from a Broker.
+type Reader struct {
+	b      *Broker
+	gt     groupTopic
+	offset int64
+	closed bool
+}
+
+// FetchMessage returns the next message of the topic.
+// It blocks until a message is available or ctx is done.
+func (r *Reader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		r.b.mu.Lock()
+		if r.closed {
+			r.b.mu.Unlock()
+			return kafka.Message{}, errClosed
+		}
+		msgs := r.b.topics[r.gt.topic]
+		if r.offset < int64(len(msgs)) {
+			m := msgs[r.offset]
+			r.offset++
+			r.b.mu.Unlock()
+			return m, nil
+		}
+		produced := r.b.produced
+		r.b.mu.Unlock()
+
+		select {
+		case <-produced:
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		}
+	}
+}
+
+// CommitMessages commits the offsets of msgs for the group of the reader.
+func (r *Reader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.b.mu.Lock()
+	defer r.b.mu.Unlock()
+	if r.closed {
+		return errClosed
+	}
+	if r.gt.group == "" {
+		return errors.New("kafkatest: commits are unavailable when GroupID is not set")
+	}
+	for _, m := range msgs {
+		if m.Offset+1 > r.b.committed[r.gt] {
+			r.b.committed[r.gt] = m.Offset + 1
+		}
+	}
+	return nil
+}
+
+func (r *Reader) Close() error {
+	r.b.mu.Lock()
+	defer r.b.mu.Unlock()
+	if r.closed {
+		return errClosed
+	}
+	r.closed = true
+	return nil
+}
+
+// Writer produces messages to a topic of a Broker.
+type Writer struct {
+	b     *Broker
+	topic string
+}
+
+func (w *Writer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.b.produce(w.topic, msgs)
+	return nil
+}
+
+func (w *Writer) Close() error {
+	return nil
+}
@@ -0,0 +1,144 @@
+package functions
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+)
+
+const KaufmansAMAKind = "kaufmansAMA"
+
+// KaufmansAMAOpSpec computes Kaufman's adaptive moving average.
+// The efficiency ratio is computed over the last N changes of the column.
+type KaufmansAMAOpSpec struct {
+	N      int64  `json:"n"`
+	Column string `json:"column"`
+}
+
+var kaufmansAMASignature = query.DefaultFunctionSignature()
+
+func init() {
+	kaufmansAMASignature.Params["n"] = semantic.Int
+	kaufmansAMASignature.Params["column"] = semantic.String
+
+	query.RegisterFunction(KaufmansAMAKind, createKaufmansAMAOpSpec, kaufmansAMASignature)
+	query.RegisterOpSpec(KaufmansAMAKind, newKaufmansAMAOp)
+	plan.RegisterProcedureSpec(KaufmansAMAKind, newKaufmansAMAProcedure, KaufmansAMAKind)
+	execute.RegisterTransformation(KaufmansAMAKind, createKaufmansAMATransformation)
+}
+
+func createKaufmansAMAOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	n, column, err := getMovingAverageArgs(KaufmansAMAKind, args)
+	if err != nil {
+		return nil, err
+	}
+	return &KaufmansAMAOpSpec{
+		N:      n,
+		Column: column,
+	}, nil
+}
+
+func newKaufmansAMAOp() query.OperationSpec {
+	return new(KaufmansAMAOpSpec)
+}
+
+func (s *KaufmansAMAOpSpec) Kind() query.OperationKind {
+	return KaufmansAMAKind
+}
+
+type KaufmansAMAProcedureSpec struct {
+	N      int64
+	Column string
+}
+
+func newKaufmansAMAProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*KaufmansAMAOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return &KaufmansAMAProcedureSpec{
+		N:      spec.N,
+		Column: spec.Column,
+	}, nil
+}
+
+func (s *KaufmansAMAProcedureSpec) Kind() plan.ProcedureKind {
+	return KaufmansAMAKind
+}
+func (s *KaufmansAMAProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(KaufmansAMAProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+func createKaufmansAMATransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*KaufmansAMAProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewKaufmansAMATransformation(d, cache, s)
+	return t, d, nil
+}
+
+func NewKaufmansAMATransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *KaufmansAMAProcedureSpec) *averageTransformation {
+	n := spec.N
+	return newAverageTransformation(d, cache, KaufmansAMAKind, spec.Column, func() averager {
+		return &kaufmansAverager{
+			window: make([]float64, 0, n+1),
+		}
+	})
+}
+
+// The smoothing constants of the fastest and slowest exponential moving averages,
+// which correspond to periods of 2 and 30 respectively.
+var (
+	kaufmansFastSC = 2.0 / (2 + 1)
+	kaufmansSlowSC = 2.0 / (30 + 1)
+)
+
+// kaufmansAverager computes Kaufman's adaptive moving average.
+// The smoothing constant of each step is derived from the efficiency ratio,
+// the net change over the window divided by the sum of the absolute changes within the window.
+// The first average is produced once the window holds n changes and is seeded with the previous value.
+type kaufmansAverager struct {
+	window []float64
+	kama   float64
+	seeded bool
+}
+
+func (a *kaufmansAverager) add(v float64) (float64, bool) {
+	if len(a.window) == cap(a.window) {
+		copy(a.window, a.window[1:])
+		a.window = a.window[:len(a.window)-1]
+	}
+	a.window = append(a.window, v)
+	if len(a.window) < cap(a.window) {
+		return 0, false
+	}
+	if !a.seeded {
+		a.kama = a.window[len(a.window)-2]
+		a.seeded = true
+	}
+
+	change := math.Abs(v - a.window[0])
+	volatility := 0.0
+	for i := 1; i < len(a.window); i++ {
+		volatility += math.Abs(a.window[i] - a.window[i-1])
+	}
+	er := 0.0
+	if volatility != 0 {
+		er = change / volatility
+	}
+	sc := math.Pow(er*(kaufmansFastSC-kaufmansSlowSC)+kaufmansSlowSC, 2)
+	a.kama += sc * (v - a.kama)
+	return a.kama, true
+}
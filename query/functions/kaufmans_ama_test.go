@@ -0,0 +1,116 @@
+package functions_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/querytest"
+)
+
+func TestKaufmansAMA_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "kaufmans adaptive moving average",
+			Raw:  `from(db:"mydb") |> kaufmansAMA(n: 10)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "kaufmansAMA1",
+						Spec: &functions.KaufmansAMAOpSpec{
+							N:      10,
+							Column: "_value",
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "kaufmansAMA1"},
+				},
+			},
+		},
+		{
+			Name:    "zero n",
+			Raw:     `from(db:"mydb") |> kaufmansAMA(n: 0)`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestKaufmansAMAOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"kaufmansAMA","kind":"kaufmansAMA","spec":{"n":10,"column":"_value"}}`)
+	op := &query.Operation{
+		ID: "kaufmansAMA",
+		Spec: &functions.KaufmansAMAOpSpec{
+			N:      10,
+			Column: "_value",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestKaufmansAMA_Process(t *testing.T) {
+	d := executetest.NewDataset(executetest.RandomDatasetID())
+	c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+	c.SetTriggerSpec(execute.DefaultTriggerSpec)
+
+	tx := functions.NewKaufmansAMATransformation(d, c, &functions.KaufmansAMAProcedureSpec{
+		N:      2,
+		Column: "_value",
+	})
+	data := &executetest.Table{
+		ColMeta: []query.ColMeta{
+			{Label: "_time", Type: query.TTime},
+			{Label: "_value", Type: query.TFloat},
+		},
+		Data: [][]interface{}{
+			{execute.Time(1), 1.0},
+			{execute.Time(2), 2.0},
+			{execute.Time(3), 3.0},
+			{execute.Time(4), 6.0},
+			{execute.Time(5), 2.0},
+			{execute.Time(6), 4.0},
+		},
+	}
+	if err := tx.Process(executetest.RandomDatasetID(), data); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*executetest.Table{{
+		ColMeta: []query.ColMeta{
+			{Label: "_time", Type: query.TTime},
+			{Label: "_value", Type: query.TFloat},
+		},
+		Data: [][]interface{}{
+			{execute.Time(3), 2.444444444444444},
+			{execute.Time(4), 4.024691358024691},
+			{execute.Time(5), 3.9788086541083025},
+			{execute.Time(6), 3.9802994341612687},
+		},
+	}}
+	got, err := executetest.TablesFromCache(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	executetest.NormalizeTables(got)
+	executetest.NormalizeTables(want)
+	if !cmp.Equal(want, got, cmpopts.EquateApprox(0, 1e-12)) {
+		t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(want, got))
+	}
+}
@@ -200,7 +200,7 @@ type sliceColReader struct {
 }
 
 func (cr sliceColReader) Len() int {
-	return cr.stop
+	return cr.stop - cr.start
 }
 
 func (cr sliceColReader) Bools(j int) []bool {
@@ -227,6 +227,10 @@ func (cr sliceColReader) Times(j int) []execute.Time {
 	return cr.ColReader.Times(j)[cr.start:cr.stop]
 }
 
+func (cr sliceColReader) IsNull(i, j int) bool {
+	return cr.ColReader.IsNull(cr.start+i, j)
+}
+
 func (t *limitTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
 	return t.d.UpdateWatermark(mark)
 }
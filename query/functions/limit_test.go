@@ -83,6 +83,33 @@ func TestLimit_Process(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: "one table with offset and nulls",
+			spec: &functions.LimitProcedureSpec{
+				N:      1,
+				Offset: 1,
+			},
+			data: []query.Table{execute.CopyTable(&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 2.0},
+					{execute.Time(2), nil},
+					{execute.Time(3), 0.0},
+				},
+			}, executetest.UnlimitedAllocator)},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(2), nil},
+				},
+			}},
+		},
 		{
 			name: "one table with offset multiple batches",
 			spec: &functions.LimitProcedureSpec{
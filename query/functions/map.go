@@ -180,6 +180,10 @@ func (t *mapTransformation) Process(id execute.DatasetID, tbl query.Table) error
 				}
 			}
 			for j, c := range builder.Cols() {
+				if t.fn.IsNull(c.Label) {
+					builder.AppendNil(j)
+					continue
+				}
 				v, ok := m.Get(c.Label)
 				if !ok {
 					if idx := execute.ColIdx(c.Label, tbl.Key().Cols()); t.mergeKey && idx >= 0 {
@@ -207,6 +207,68 @@ func TestMap_Process(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: `_value+5 with null`,
+			spec: &functions.MapProcedureSpec{
+				MergeKey: false,
+				Fn: &semantic.FunctionExpression{
+					Params: []*semantic.FunctionParam{{Key: &semantic.Identifier{Name: "r"}}},
+					Body: &semantic.ObjectExpression{
+						Properties: []*semantic.Property{
+							{
+								Key: &semantic.Identifier{Name: "_time"},
+								Value: &semantic.MemberExpression{
+									Object:   &semantic.IdentifierExpression{Name: "r"},
+									Property: "_time",
+								},
+							},
+							{
+								Key: &semantic.Identifier{Name: "_value"},
+								Value: &semantic.BinaryExpression{
+									Operator: ast.AdditionOperator,
+									Left: &semantic.MemberExpression{
+										Object:   &semantic.IdentifierExpression{Name: "r"},
+										Property: "_value",
+									},
+									Right: &semantic.FloatLiteral{Value: 5},
+								},
+							},
+							{
+								Key: &semantic.Identifier{Name: "exists"},
+								Value: &semantic.UnaryExpression{
+									Operator: ast.ExistsOperator,
+									Argument: &semantic.MemberExpression{
+										Object:   &semantic.IdentifierExpression{Name: "r"},
+										Property: "_value",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), nil},
+					{execute.Time(2), 6.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+					{Label: "exists", Type: query.TBool},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), nil, false},
+					{execute.Time(2), 11.0, true},
+				},
+			}},
+		},
 		{
 			name: `_value+5 mergeKey=true`,
 			spec: &functions.MapProcedureSpec{
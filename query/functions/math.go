@@ -0,0 +1,148 @@
+package functions
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/interpreter"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+)
+
+const mathPackageName = "math"
+
+func init() {
+	pkg := newPackage(mathFunctions)
+	for name, v := range mathConstants {
+		pkg.Set(name, v)
+	}
+	query.RegisterBuiltInValue(mathPackageName, pkg)
+}
+
+var mathConstants = map[string]values.Value{
+	"pi":                   values.NewFloatValue(math.Pi),
+	"e":                    values.NewFloatValue(math.E),
+	"phi":                  values.NewFloatValue(math.Phi),
+	"sqrt2":                values.NewFloatValue(math.Sqrt2),
+	"sqrte":                values.NewFloatValue(math.SqrtE),
+	"sqrtpi":               values.NewFloatValue(math.SqrtPi),
+	"sqrtphi":              values.NewFloatValue(math.SqrtPhi),
+	"ln2":                  values.NewFloatValue(math.Ln2),
+	"log2e":                values.NewFloatValue(math.Log2E),
+	"ln10":                 values.NewFloatValue(math.Ln10),
+	"log10e":               values.NewFloatValue(math.Log10E),
+	"maxFloat":             values.NewFloatValue(math.MaxFloat64),
+	"smallestNonzeroFloat": values.NewFloatValue(math.SmallestNonzeroFloat64),
+	"maxInt":               values.NewIntValue(math.MaxInt64),
+	"minInt":               values.NewIntValue(math.MinInt64),
+	"maxUint":              values.NewUIntValue(math.MaxUint64),
+}
+
+var mathFunctions = map[string]packageFunction{
+	"abs":   unaryMathFunction(math.Abs),
+	"ceil":  unaryMathFunction(math.Ceil),
+	"floor": unaryMathFunction(math.Floor),
+	"round": unaryMathFunction(math.Round),
+	"trunc": unaryMathFunction(math.Trunc),
+	"sqrt":  unaryMathFunction(math.Sqrt),
+	"cbrt":  unaryMathFunction(math.Cbrt),
+	"exp":   unaryMathFunction(math.Exp),
+	"exp2":  unaryMathFunction(math.Exp2),
+	"log":   unaryMathFunction(math.Log),
+	"log10": unaryMathFunction(math.Log10),
+	"log2":  unaryMathFunction(math.Log2),
+	"log1p": unaryMathFunction(math.Log1p),
+	"sin":   unaryMathFunction(math.Sin),
+	"cos":   unaryMathFunction(math.Cos),
+	"tan":   unaryMathFunction(math.Tan),
+	"asin":  unaryMathFunction(math.Asin),
+	"acos":  unaryMathFunction(math.Acos),
+	"atan":  unaryMathFunction(math.Atan),
+	"sinh":  unaryMathFunction(math.Sinh),
+	"cosh":  unaryMathFunction(math.Cosh),
+	"tanh":  unaryMathFunction(math.Tanh),
+	"pow":   binaryMathFunction("x", "y", math.Pow),
+	"mod":   binaryMathFunction("x", "y", math.Mod),
+	"min":   binaryMathFunction("x", "y", math.Min),
+	"max":   binaryMathFunction("x", "y", math.Max),
+	"hypot": binaryMathFunction("x", "y", math.Hypot),
+	"atan2": binaryMathFunction("y", "x", math.Atan2),
+	"isNaN": {
+		params:     map[string]semantic.Type{"x": semantic.Float},
+		returnType: semantic.Bool,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			x, err := getRequiredNumber(args, "x")
+			if err != nil {
+				return nil, err
+			}
+			return values.NewBoolValue(math.IsNaN(x)), nil
+		},
+	},
+	"isInf": {
+		params:     map[string]semantic.Type{"x": semantic.Float, "sign": semantic.Int},
+		returnType: semantic.Bool,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			x, err := getRequiredNumber(args, "x")
+			if err != nil {
+				return nil, err
+			}
+			// A sign of zero reports either infinity, as does leaving it out.
+			sign, _, err := args.GetInt("sign")
+			if err != nil {
+				return nil, err
+			}
+			return values.NewBoolValue(math.IsInf(x, int(sign))), nil
+		},
+	},
+}
+
+func unaryMathFunction(f func(float64) float64) packageFunction {
+	return packageFunction{
+		params:     map[string]semantic.Type{"x": semantic.Float},
+		returnType: semantic.Float,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			x, err := getRequiredNumber(args, "x")
+			if err != nil {
+				return nil, err
+			}
+			return values.NewFloatValue(f(x)), nil
+		},
+	}
+}
+
+func binaryMathFunction(first, second string, f func(float64, float64) float64) packageFunction {
+	return packageFunction{
+		params:     map[string]semantic.Type{first: semantic.Float, second: semantic.Float},
+		returnType: semantic.Float,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			a, err := getRequiredNumber(args, first)
+			if err != nil {
+				return nil, err
+			}
+			b, err := getRequiredNumber(args, second)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewFloatValue(f(a, b)), nil
+		},
+	}
+}
+
+// getRequiredNumber reads a numeric argument as a float, so that integer columns may be used directly.
+func getRequiredNumber(args interpreter.Arguments, name string) (float64, error) {
+	v, err := args.GetRequired(name)
+	if err != nil {
+		return 0, err
+	}
+	switch k := v.Type().Kind(); k {
+	case semantic.Float:
+		return v.Float(), nil
+	case semantic.Int:
+		return float64(v.Int()), nil
+	case semantic.UInt:
+		return float64(v.UInt()), nil
+	default:
+		return 0, fmt.Errorf("keyword argument %q should be of kind %v, but got %v", name, semantic.Float, k)
+	}
+}
@@ -0,0 +1,99 @@
+package functions_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+)
+
+func TestMath(t *testing.T) {
+	testCases := []struct {
+		name    string
+		script  string
+		want    values.Value
+		wantErr string
+	}{
+		{name: "abs", script: `x = math.abs(x: -2.5)`, want: values.NewFloatValue(2.5)},
+		{name: "abs int", script: `x = math.abs(x: -2)`, want: values.NewFloatValue(2)},
+		{name: "ceil", script: `x = math.ceil(x: 1.2)`, want: values.NewFloatValue(2)},
+		{name: "floor", script: `x = math.floor(x: 1.8)`, want: values.NewFloatValue(1)},
+		{name: "round", script: `x = math.round(x: 2.5)`, want: values.NewFloatValue(3)},
+		{name: "trunc", script: `x = math.trunc(x: -2.7)`, want: values.NewFloatValue(-2)},
+		{name: "sqrt", script: `x = math.sqrt(x: 16.0)`, want: values.NewFloatValue(4)},
+		{name: "pow", script: `x = math.pow(x: 2.0, y: 10)`, want: values.NewFloatValue(1024)},
+		{name: "log", script: `x = math.log(x: math.e)`, want: values.NewFloatValue(1)},
+		{name: "log10", script: `x = math.log10(x: 1000.0)`, want: values.NewFloatValue(3)},
+		{name: "log2", script: `x = math.log2(x: 8.0)`, want: values.NewFloatValue(3)},
+		{name: "exp", script: `x = math.exp(x: 0.0)`, want: values.NewFloatValue(1)},
+		{name: "sin", script: `x = math.sin(x: 0.0)`, want: values.NewFloatValue(0)},
+		{name: "cos", script: `x = math.cos(x: 0.0)`, want: values.NewFloatValue(1)},
+		{name: "tan", script: `x = math.tan(x: 0.0)`, want: values.NewFloatValue(0)},
+		{name: "atan2", script: `x = math.atan2(y: 0.0, x: 1.0)`, want: values.NewFloatValue(0)},
+		{name: "min", script: `x = math.min(x: 1.5, y: -3.0)`, want: values.NewFloatValue(-3)},
+		{name: "max", script: `x = math.max(x: 1.5, y: -3.0)`, want: values.NewFloatValue(1.5)},
+		{name: "mod", script: `x = math.mod(x: 7.0, y: 4.0)`, want: values.NewFloatValue(3)},
+		{name: "isNaN", script: `x = math.isNaN(x: math.sqrt(x: -1.0))`, want: values.NewBoolValue(true)},
+		{name: "isInf", script: `x = math.isInf(x: math.log(x: 0.0), sign: -1)`, want: values.NewBoolValue(true)},
+		{name: "isInf any sign", script: `x = math.isInf(x: 1.0)`, want: values.NewBoolValue(false)},
+		{name: "pi", script: `x = math.pi`, want: values.NewFloatValue(math.Pi)},
+		{name: "maxInt", script: `x = math.maxInt`, want: values.NewIntValue(math.MaxInt64)},
+		{
+			name:    "wrong argument kind",
+			script:  `x = math.abs(x: "a")`,
+			wantErr: `error calling function "abs": keyword argument "x" should be of kind float, but got string`,
+		},
+		{
+			name:    "missing argument",
+			script:  `x = math.pow(x: 2.0)`,
+			wantErr: `error calling function "pow": missing required keyword argument "y"`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			itrp := query.NewInterpreter()
+			err := query.Eval(itrp, tc.script)
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error %q", tc.wantErr)
+				}
+				if got := err.Error(); got != tc.wantErr {
+					t.Fatalf("unexpected error -want/+got\n\t- %q\n\t+ %q", tc.wantErr, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := itrp.GlobalScope().Lookup("x")
+			if !ok {
+				t.Fatal("missing result x")
+			}
+			if got.Type() != tc.want.Type() || !got.Equal(tc.want) {
+				t.Errorf("unexpected value -want/+got\n\t- %v\n\t+ %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMath_Types(t *testing.T) {
+	_, decls := query.BuiltIns()
+	dec, ok := decls["math"]
+	if !ok {
+		t.Fatal("math package is not declared")
+	}
+	typ := dec.InitType()
+	want := semantic.NewFunctionType(semantic.FunctionSignature{
+		Params:     map[string]semantic.Type{"x": semantic.Float, "y": semantic.Float},
+		ReturnType: semantic.Float,
+	})
+	if got := typ.PropertyType("pow"); got != want {
+		t.Errorf("unexpected type for math.pow -want/+got\n\t- %v\n\t+ %v", want, got)
+	}
+	if got := typ.PropertyType("pi"); got != semantic.Float {
+		t.Errorf("unexpected type for math.pi: %v", got)
+	}
+}
@@ -0,0 +1,255 @@
+package functions
+
+import (
+	"fmt"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+)
+
+const MovingAverageKind = "movingAverage"
+
+// MovingAverageOpSpec computes the mean of the last N values of a column.
+type MovingAverageOpSpec struct {
+	N      int64  `json:"n"`
+	Column string `json:"column"`
+}
+
+var movingAverageSignature = query.DefaultFunctionSignature()
+
+func init() {
+	movingAverageSignature.Params["n"] = semantic.Int
+	movingAverageSignature.Params["column"] = semantic.String
+
+	query.RegisterBuiltIn("timedMovingAverage", timedMovingAverageBuiltIn)
+	query.RegisterFunction(MovingAverageKind, createMovingAverageOpSpec, movingAverageSignature)
+	query.RegisterOpSpec(MovingAverageKind, newMovingAverageOp)
+	plan.RegisterProcedureSpec(MovingAverageKind, newMovingAverageProcedure, MovingAverageKind)
+	execute.RegisterTransformation(MovingAverageKind, createMovingAverageTransformation)
+}
+
+// timedMovingAverageBuiltIn defines a moving average over time windows of length period, computed every duration.
+// Each average is timestamped with the stop time of its window.
+var timedMovingAverageBuiltIn = `
+timedMovingAverage = (every, period, column="_value", table=<-) =>
+	table
+		|> window(every: every, period: period)
+		|> mean(columns: [column])
+		|> window(every: inf)
+`
+
+// getMovingAverageArgs reads the arguments common to the moving average functions.
+func getMovingAverageArgs(kind string, args query.Arguments) (int64, string, error) {
+	n, err := args.GetRequiredInt("n")
+	if err != nil {
+		return 0, "", err
+	}
+	if n <= 0 {
+		return 0, "", fmt.Errorf("%s error: n must be positive, got %d", kind, n)
+	}
+	column := execute.DefaultValueColLabel
+	if col, ok, err := args.GetString("column"); err != nil {
+		return 0, "", err
+	} else if ok {
+		column = col
+	}
+	return n, column, nil
+}
+
+func createMovingAverageOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	n, column, err := getMovingAverageArgs(MovingAverageKind, args)
+	if err != nil {
+		return nil, err
+	}
+	return &MovingAverageOpSpec{
+		N:      n,
+		Column: column,
+	}, nil
+}
+
+func newMovingAverageOp() query.OperationSpec {
+	return new(MovingAverageOpSpec)
+}
+
+func (s *MovingAverageOpSpec) Kind() query.OperationKind {
+	return MovingAverageKind
+}
+
+type MovingAverageProcedureSpec struct {
+	N      int64
+	Column string
+}
+
+func newMovingAverageProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*MovingAverageOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return &MovingAverageProcedureSpec{
+		N:      spec.N,
+		Column: spec.Column,
+	}, nil
+}
+
+func (s *MovingAverageProcedureSpec) Kind() plan.ProcedureKind {
+	return MovingAverageKind
+}
+func (s *MovingAverageProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(MovingAverageProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+func createMovingAverageTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*MovingAverageProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewMovingAverageTransformation(d, cache, s)
+	return t, d, nil
+}
+
+func NewMovingAverageTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *MovingAverageProcedureSpec) *averageTransformation {
+	n := spec.N
+	return newAverageTransformation(d, cache, MovingAverageKind, spec.Column, func() averager {
+		return newSimpleAverager(n)
+	})
+}
+
+// averager computes a running average of a sequence of values.
+type averager interface {
+	// add adds the next value to the sequence and returns the current average.
+	// The second return value is false until enough values have been added to produce an average.
+	add(v float64) (float64, bool)
+}
+
+// averageTransformation replaces the values of a column with their running average.
+// Records are dropped until the averager produces its first average and records with a null value are skipped.
+type averageTransformation struct {
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	kind        string
+	column      string
+	newAverager func() averager
+}
+
+func newAverageTransformation(d execute.Dataset, cache execute.TableBuilderCache, kind, column string, newAverager func() averager) *averageTransformation {
+	return &averageTransformation{
+		d:           d,
+		cache:       cache,
+		kind:        kind,
+		column:      column,
+		newAverager: newAverager,
+	}
+}
+
+func (t *averageTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *averageTransformation) Process(id execute.DatasetID, tbl query.Table) error {
+	key := tbl.Key()
+	cols := tbl.Cols()
+	valueIdx := execute.ColIdx(t.column, cols)
+	if valueIdx < 0 {
+		return fmt.Errorf("%s error: column %q doesn't exist", t.kind, t.column)
+	}
+	if key.HasCol(t.column) {
+		return fmt.Errorf("%s error: column %q is part of the group key", t.kind, t.column)
+	}
+	typ := cols[valueIdx].Type
+	switch typ {
+	case query.TInt, query.TUInt, query.TFloat:
+	default:
+		return fmt.Errorf("%s error: unsupported type %v for column %q", t.kind, typ, t.column)
+	}
+
+	builder, created := t.cache.TableBuilder(key)
+	if !created {
+		return fmt.Errorf("%s found duplicate table with key: %v", t.kind, key)
+	}
+	for j, c := range cols {
+		if j == valueIdx {
+			c.Type = query.TFloat
+		}
+		builder.AddCol(c)
+	}
+
+	// The averager is shared by all blocks of the table.
+	avg := t.newAverager()
+	return tbl.Do(func(cr query.ColReader) error {
+		for i, l := 0, cr.Len(); i < l; i++ {
+			if cr.IsNull(i, valueIdx) {
+				continue
+			}
+			var v float64
+			switch typ {
+			case query.TInt:
+				v = float64(cr.Ints(valueIdx)[i])
+			case query.TUInt:
+				v = float64(cr.UInts(valueIdx)[i])
+			case query.TFloat:
+				v = cr.Floats(valueIdx)[i]
+			}
+			a, ok := avg.add(v)
+			if !ok {
+				continue
+			}
+			for j := range cols {
+				if j == valueIdx {
+					builder.AppendFloat(j, a)
+					continue
+				}
+				execute.AppendColValue(j, j, i, cr, builder)
+			}
+		}
+		return nil
+	})
+}
+
+func (t *averageTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *averageTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *averageTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+// simpleAverager computes the mean of the last n values.
+type simpleAverager struct {
+	window []float64
+	pos    int
+	count  int
+	sum    float64
+}
+
+func newSimpleAverager(n int64) *simpleAverager {
+	return &simpleAverager{
+		window: make([]float64, n),
+	}
+}
+
+func (a *simpleAverager) add(v float64) (float64, bool) {
+	if a.count == len(a.window) {
+		a.sum -= a.window[a.pos]
+	} else {
+		a.count++
+	}
+	a.window[a.pos] = v
+	a.sum += v
+	a.pos = (a.pos + 1) % len(a.window)
+	if a.count < len(a.window) {
+		return 0, false
+	}
+	return a.sum / float64(len(a.window)), true
+}
@@ -0,0 +1,312 @@
+package functions_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/querytest"
+)
+
+func TestMovingAverage_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "moving average",
+			Raw:  `from(db:"mydb") |> movingAverage(n: 5)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "movingAverage1",
+						Spec: &functions.MovingAverageOpSpec{
+							N:      5,
+							Column: "_value",
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "movingAverage1"},
+				},
+			},
+		},
+		{
+			Name: "moving average with column",
+			Raw:  `from(db:"mydb") |> movingAverage(n: 2, column: "x")`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "movingAverage1",
+						Spec: &functions.MovingAverageOpSpec{
+							N:      2,
+							Column: "x",
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "movingAverage1"},
+				},
+			},
+		},
+		{
+			Name:    "non positive n",
+			Raw:     `from(db:"mydb") |> movingAverage(n: 0)`,
+			WantErr: true,
+		},
+		{
+			Name:    "missing n",
+			Raw:     `from(db:"mydb") |> movingAverage()`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestMovingAverageOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"movingAverage","kind":"movingAverage","spec":{"n":3,"column":"_value"}}`)
+	op := &query.Operation{
+		ID: "movingAverage",
+		Spec: &functions.MovingAverageOpSpec{
+			N:      3,
+			Column: "_value",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestMovingAverage_PassThrough(t *testing.T) {
+	executetest.TransformationPassThroughTestHelper(t, func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+		s := functions.NewMovingAverageTransformation(
+			d,
+			c,
+			&functions.MovingAverageProcedureSpec{
+				N:      1,
+				Column: "_value",
+			},
+		)
+		return s
+	})
+}
+
+func TestMovingAverage_Process(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *functions.MovingAverageProcedureSpec
+		data    []query.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "float",
+			spec: &functions.MovingAverageProcedureSpec{
+				N:      3,
+				Column: "_value",
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+					{execute.Time(2), 2.0},
+					{execute.Time(3), 3.0},
+					{execute.Time(4), 6.0},
+					{execute.Time(5), 3.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(3), 2.0},
+					{execute.Time(4), 11.0 / 3.0},
+					{execute.Time(5), 4.0},
+				},
+			}},
+		},
+		{
+			name: "int",
+			spec: &functions.MovingAverageProcedureSpec{
+				N:      2,
+				Column: "_value",
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(1)},
+					{execute.Time(2), int64(2)},
+					{execute.Time(3), int64(4)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(2), 1.5},
+					{execute.Time(3), 3.0},
+				},
+			}},
+		},
+		{
+			name: "nulls",
+			spec: &functions.MovingAverageProcedureSpec{
+				N:      2,
+				Column: "_value",
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+					{execute.Time(2), nil},
+					{execute.Time(3), 3.0},
+					{execute.Time(4), 5.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(3), 2.0},
+					{execute.Time(4), 4.0},
+				},
+			}},
+		},
+		{
+			name: "other column",
+			spec: &functions.MovingAverageProcedureSpec{
+				N:      2,
+				Column: "x",
+			},
+			data: []query.Table{&executetest.Table{
+				KeyCols: []string{"t1"},
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "t1", Type: query.TString},
+					{Label: "x", Type: query.TUInt},
+					{Label: "y", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), "a", uint64(1), 10.0},
+					{execute.Time(2), "a", uint64(2), 20.0},
+					{execute.Time(3), "a", uint64(6), 30.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols: []string{"t1"},
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "t1", Type: query.TString},
+					{Label: "x", Type: query.TFloat},
+					{Label: "y", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(2), "a", 1.5, 20.0},
+					{execute.Time(3), "a", 4.0, 30.0},
+				},
+			}},
+		},
+		{
+			name: "fewer than n values",
+			spec: &functions.MovingAverageProcedureSpec{
+				N:      3,
+				Column: "_value",
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+					{execute.Time(2), 2.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+			}},
+		},
+		{
+			name: "missing column",
+			spec: &functions.MovingAverageProcedureSpec{
+				N:      3,
+				Column: "x",
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+				},
+			}},
+			wantErr: errors.New(`movingAverage error: column "x" doesn't exist`),
+		},
+		{
+			name: "string column",
+			spec: &functions.MovingAverageProcedureSpec{
+				N:      3,
+				Column: "_value",
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), "a"},
+				},
+			}},
+			wantErr: errors.New(`movingAverage error: unsupported type string for column "_value"`),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return functions.NewMovingAverageTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package functions
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/interpreter"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+)
+
+const PivotKind = "pivot"
+
+type PivotOpSpec struct {
+	RowKey      []string `json:"rowKey"`
+	ColKey      []string `json:"colKey"`
+	ValueColumn string   `json:"valueColumn"`
+}
+
+var pivotSignature = query.DefaultFunctionSignature()
+
+func init() {
+	pivotSignature.Params["rowKey"] = semantic.NewArrayType(semantic.String)
+	pivotSignature.Params["colKey"] = semantic.NewArrayType(semantic.String)
+	pivotSignature.Params["valueColumn"] = semantic.String
+
+	query.RegisterFunction(PivotKind, createPivotOpSpec, pivotSignature)
+	query.RegisterOpSpec(PivotKind, newPivotOp)
+	plan.RegisterProcedureSpec(PivotKind, newPivotProcedure, PivotKind)
+	execute.RegisterTransformation(PivotKind, createPivotTransformation)
+}
+
+func createPivotOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := &PivotOpSpec{
+		ValueColumn: execute.DefaultValueColLabel,
+	}
+
+	array, err := args.GetRequiredArray("rowKey", semantic.String)
+	if err != nil {
+		return nil, err
+	}
+	spec.RowKey, err = interpreter.ToStringArray(array)
+	if err != nil {
+		return nil, err
+	}
+
+	array, err = args.GetRequiredArray("colKey", semantic.String)
+	if err != nil {
+		return nil, err
+	}
+	spec.ColKey, err = interpreter.ToStringArray(array)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.ColKey) == 0 {
+		return nil, fmt.Errorf("pivot error: colKey must contain at least one column")
+	}
+
+	if col, ok, err := args.GetString("valueColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.ValueColumn = col
+	}
+
+	used := make(map[string]bool, len(spec.RowKey)+len(spec.ColKey)+1)
+	for _, c := range append(append(append([]string{}, spec.RowKey...), spec.ColKey...), spec.ValueColumn) {
+		if used[c] {
+			return nil, fmt.Errorf("pivot error: column %q is used more than once in rowKey, colKey and valueColumn", c)
+		}
+		used[c] = true
+	}
+
+	return spec, nil
+}
+
+func newPivotOp() query.OperationSpec {
+	return new(PivotOpSpec)
+}
+
+func (s *PivotOpSpec) Kind() query.OperationKind {
+	return PivotKind
+}
+
+type PivotProcedureSpec struct {
+	RowKey      []string
+	ColKey      []string
+	ValueColumn string
+}
+
+func newPivotProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*PivotOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+
+	return &PivotProcedureSpec{
+		RowKey:      spec.RowKey,
+		ColKey:      spec.ColKey,
+		ValueColumn: spec.ValueColumn,
+	}, nil
+}
+
+func (s *PivotProcedureSpec) Kind() plan.ProcedureKind {
+	return PivotKind
+}
+func (s *PivotProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(PivotProcedureSpec)
+	ns.RowKey = append([]string(nil), s.RowKey...)
+	ns.ColKey = append([]string(nil), s.ColKey...)
+	ns.ValueColumn = s.ValueColumn
+	return ns
+}
+
+func createPivotTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*PivotProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewPivotTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type pivotTransformation struct {
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+	spec  PivotProcedureSpec
+
+	// tables holds the *pivotTable of each output table, by group key.
+	tables *execute.GroupLookup
+}
+
+// pivotTable holds the rows of an output table until the transformation finishes,
+// when they are written to its builder in row key order.
+type pivotTable struct {
+	key query.GroupKey
+
+	// cols are the columns of the table: the group key columns, then the row key columns,
+	// then the pivoted value columns in the order they were first seen.
+	cols []query.ColMeta
+	// rowCols are the indexes in cols of the row key columns.
+	rowCols []int
+	// valueCols are the indexes in cols of the pivoted value columns, by label.
+	valueCols map[string]int
+
+	// rows holds the index in cells of each row, by row key.
+	rows    map[string]int
+	rowKeys []query.GroupKey
+	// cells holds the values of each row by column.
+	// Rows created before a column was added are shorter than the others.
+	cells [][]values.Value
+}
+
+func NewPivotTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *PivotProcedureSpec) *pivotTransformation {
+	return &pivotTransformation{
+		d:      d,
+		cache:  cache,
+		spec:   *spec,
+		tables: execute.NewGroupLookup(),
+	}
+}
+
+func (t *pivotTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
+	t.tables.Delete(key)
+	return t.d.RetractTable(key)
+}
+
+func (t *pivotTransformation) Process(id execute.DatasetID, tbl query.Table) error {
+	cols := tbl.Cols()
+	rowIdxs, err := pivotColIdxs(t.spec.RowKey, cols)
+	if err != nil {
+		return err
+	}
+	colIdxs, err := pivotColIdxs(t.spec.ColKey, cols)
+	if err != nil {
+		return err
+	}
+	valueIdxs, err := pivotColIdxs([]string{t.spec.ValueColumn}, cols)
+	if err != nil {
+		return err
+	}
+	valueIdx := valueIdxs[0]
+
+	// The columns of the column key and the value column are removed from the group key.
+	key := tbl.Key()
+	keyCols := make([]query.ColMeta, 0, len(key.Cols()))
+	keyValues := make([]values.Value, 0, len(key.Cols()))
+	for j, c := range key.Cols() {
+		if c.Label == t.spec.ValueColumn || execute.ContainsStr(t.spec.ColKey, c.Label) {
+			continue
+		}
+		keyCols = append(keyCols, c)
+		keyValues = append(keyValues, key.Value(j))
+	}
+	key = execute.NewGroupKey(keyCols, keyValues)
+
+	var pt *pivotTable
+	if v, ok := t.tables.Lookup(key); ok {
+		pt = v.(*pivotTable)
+	} else {
+		pt = &pivotTable{
+			key:       key,
+			cols:      append([]query.ColMeta(nil), key.Cols()...),
+			rowCols:   make([]int, len(rowIdxs)),
+			valueCols: make(map[string]int),
+			rows:      make(map[string]int),
+		}
+		for k, j := range rowIdxs {
+			pt.rowCols[k] = execute.ColIdx(cols[j].Label, pt.cols)
+			if pt.rowCols[k] < 0 {
+				pt.rowCols[k] = len(pt.cols)
+				pt.cols = append(pt.cols, cols[j])
+			}
+		}
+		t.tables.Set(key, pt)
+	}
+
+	rowKeyCols := make([]query.ColMeta, len(rowIdxs))
+	for k, j := range rowIdxs {
+		rowKeyCols[k] = cols[j]
+		if typ := pt.cols[pt.rowCols[k]].Type; typ != cols[j].Type {
+			return fmt.Errorf("pivot error: row key column %q has both %s and %s values", cols[j].Label, typ, cols[j].Type)
+		}
+	}
+
+	valueType := cols[valueIdx].Type
+	return tbl.Do(func(cr query.ColReader) error {
+		for i := 0; i < cr.Len(); i++ {
+			label := pivotColLabel(i, colIdxs, cr)
+			vj, ok := pt.valueCols[label]
+			if !ok {
+				if execute.HasCol(label, pt.cols) {
+					return fmt.Errorf("pivot error: pivoted column %q conflicts with an existing column", label)
+				}
+				vj = len(pt.cols)
+				pt.cols = append(pt.cols, query.ColMeta{Label: label, Type: valueType})
+				pt.valueCols[label] = vj
+			} else if typ := pt.cols[vj].Type; typ != valueType {
+				return fmt.Errorf("pivot error: pivoted column %q has both %s and %s values", label, typ, valueType)
+			}
+
+			rowKeyValues := make([]values.Value, len(rowIdxs))
+			for k, j := range rowIdxs {
+				rowKeyValues[k] = execute.ValueForRow(i, j, cr)
+			}
+			rowKey := execute.NewGroupKey(rowKeyCols, rowKeyValues)
+
+			r, ok := pt.rows[rowKey.String()]
+			if !ok {
+				row := make([]values.Value, len(pt.cols))
+				for k := range key.Cols() {
+					row[k] = key.Value(k)
+				}
+				for k, j := range pt.rowCols {
+					row[j] = rowKeyValues[k]
+				}
+				r = len(pt.cells)
+				pt.cells = append(pt.cells, row)
+				pt.rowKeys = append(pt.rowKeys, rowKey)
+				pt.rows[rowKey.String()] = r
+			}
+			if row := pt.cells[r]; vj >= len(row) {
+				pt.cells[r] = append(row, make([]values.Value, len(pt.cols)-len(row))...)
+			}
+			if !cr.IsNull(i, valueIdx) {
+				pt.cells[r][vj] = execute.ValueForRow(i, valueIdx, cr)
+			}
+		}
+		return nil
+	})
+}
+
+// write writes the rows of the table to builder, sorted by row key.
+// Missing values are written as nulls.
+func (pt *pivotTable) write(builder execute.TableBuilder) {
+	order := make([]int, len(pt.cells))
+	for r := range order {
+		order[r] = r
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return pt.rowKeys[order[i]].Less(pt.rowKeys[order[j]])
+	})
+
+	for _, c := range pt.cols {
+		builder.AddCol(c)
+	}
+	for j := range pt.cols {
+		for _, r := range order {
+			if row := pt.cells[r]; j < len(row) && row[j] != nil {
+				execute.AppendValue(builder, j, row[j])
+			} else {
+				builder.AppendNil(j)
+			}
+		}
+	}
+}
+
+func (t *pivotTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *pivotTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *pivotTransformation) Finish(id execute.DatasetID, err error) {
+	// Rows of an output table may come from any of the input tables,
+	// so the output tables are only built once every input table has been processed.
+	if err == nil {
+		t.tables.Range(func(key query.GroupKey, v interface{}) {
+			builder, created := t.cache.TableBuilder(key)
+			if !created {
+				if err == nil {
+					err = fmt.Errorf("pivot found duplicate table with key: %v", key)
+				}
+				return
+			}
+			v.(*pivotTable).write(builder)
+		})
+	}
+	t.tables = execute.NewGroupLookup()
+	t.d.Finish(err)
+}
+
+// pivotColIdxs returns the index of each of the labels in cols.
+func pivotColIdxs(labels []string, cols []query.ColMeta) ([]int, error) {
+	idxs := make([]int, len(labels))
+	for k, label := range labels {
+		idxs[k] = execute.ColIdx(label, cols)
+		if idxs[k] < 0 {
+			return nil, fmt.Errorf("pivot error: column %q doesn't exist", label)
+		}
+	}
+	return idxs, nil
+}
+
+// pivotColLabel returns the label of the output column for row i,
+// by joining the values of the column key with underscores.
+func pivotColLabel(i int, idxs []int, cr query.ColReader) string {
+	parts := make([]string, len(idxs))
+	for k, j := range idxs {
+		parts[k] = pivotValueString(execute.ValueForRow(i, j, cr))
+	}
+	return strings.Join(parts, "_")
+}
+
+func pivotValueString(v values.Value) string {
+	switch v.Type().Kind() {
+	case semantic.String:
+		return v.Str()
+	case semantic.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case semantic.UInt:
+		return strconv.FormatUint(v.UInt(), 10)
+	case semantic.Float:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case semantic.Bool:
+		return strconv.FormatBool(v.Bool())
+	case semantic.Time:
+		return v.Time().String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
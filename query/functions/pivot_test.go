@@ -0,0 +1,364 @@
+package functions_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/querytest"
+)
+
+func TestPivot_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "pivot with default value column",
+			Raw:  `from(db:"testdb") |> pivot(rowKey: ["_time"], colKey: ["_measurement", "_field"])`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "testdb",
+						},
+					},
+					{
+						ID: "pivot1",
+						Spec: &functions.PivotOpSpec{
+							RowKey:      []string{"_time"},
+							ColKey:      []string{"_measurement", "_field"},
+							ValueColumn: "_value",
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "pivot1"},
+				},
+			},
+		},
+		{
+			Name:    "missing colKey",
+			Raw:     `from(db:"testdb") |> pivot(rowKey: ["_time"])`,
+			WantErr: true,
+		},
+		{
+			Name:    "empty colKey",
+			Raw:     `from(db:"testdb") |> pivot(rowKey: ["_time"], colKey: [])`,
+			WantErr: true,
+		},
+		{
+			Name:    "column used twice",
+			Raw:     `from(db:"testdb") |> pivot(rowKey: ["_time"], colKey: ["_field"], valueColumn: "_time")`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestPivotOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"pivot","kind":"pivot","spec":{"rowKey":["_time"],"colKey":["_field"],"valueColumn":"_value"}}`)
+	op := &query.Operation{
+		ID: "pivot",
+		Spec: &functions.PivotOpSpec{
+			RowKey:      []string{"_time"},
+			ColKey:      []string{"_field"},
+			ValueColumn: "_value",
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestPivot_Process(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *functions.PivotProcedureSpec
+		data    []query.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "fields as columns",
+			spec: &functions.PivotProcedureSpec{
+				RowKey:      []string{"_time"},
+				ColKey:      []string{"_field"},
+				ValueColumn: "_value",
+			},
+			data: []query.Table{
+				&executetest.Table{
+					KeyCols: []string{"_measurement", "_field"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "_measurement", Type: query.TString},
+						{Label: "_field", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0, "m1", "f1"},
+						{execute.Time(2), 2.0, "m1", "f1"},
+					},
+				},
+				&executetest.Table{
+					KeyCols: []string{"_measurement", "_field"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "_measurement", Type: query.TString},
+						{Label: "_field", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 3.0, "m1", "f2"},
+						{execute.Time(2), 4.0, "m1", "f2"},
+					},
+				},
+			},
+			want: []*executetest.Table{{
+				KeyCols: []string{"_measurement"},
+				ColMeta: []query.ColMeta{
+					{Label: "_measurement", Type: query.TString},
+					{Label: "_time", Type: query.TTime},
+					{Label: "f1", Type: query.TFloat},
+					{Label: "f2", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{"m1", execute.Time(1), 1.0, 3.0},
+					{"m1", execute.Time(2), 2.0, 4.0},
+				},
+			}},
+		},
+		{
+			name: "group key update",
+			spec: &functions.PivotProcedureSpec{
+				RowKey:      []string{"_time"},
+				ColKey:      []string{"_measurement", "_field"},
+				ValueColumn: "_value",
+			},
+			data: []query.Table{
+				&executetest.Table{
+					KeyCols: []string{"_measurement", "_field", "host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "_measurement", Type: query.TString},
+						{Label: "_field", Type: query.TString},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0, "m1", "f1", "a"},
+					},
+				},
+				&executetest.Table{
+					KeyCols: []string{"_measurement", "_field", "host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "_measurement", Type: query.TString},
+						{Label: "_field", Type: query.TString},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 2.0, "m2", "f1", "a"},
+					},
+				},
+				&executetest.Table{
+					KeyCols: []string{"_measurement", "_field", "host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "_measurement", Type: query.TString},
+						{Label: "_field", Type: query.TString},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 3.0, "m1", "f1", "b"},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []query.ColMeta{
+						{Label: "host", Type: query.TString},
+						{Label: "_time", Type: query.TTime},
+						{Label: "m1_f1", Type: query.TFloat},
+						{Label: "m2_f1", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{"a", execute.Time(1), 1.0, 2.0},
+					},
+				},
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []query.ColMeta{
+						{Label: "host", Type: query.TString},
+						{Label: "_time", Type: query.TTime},
+						{Label: "m1_f1", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{"b", execute.Time(1), 3.0},
+					},
+				},
+			},
+		},
+		{
+			name: "missing values and mixed types",
+			spec: &functions.PivotProcedureSpec{
+				RowKey:      []string{"_time"},
+				ColKey:      []string{"_field"},
+				ValueColumn: "_value",
+			},
+			data: []query.Table{
+				&executetest.Table{
+					KeyCols: []string{"_field"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TString},
+						{Label: "_field", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(3), "c", "s"},
+						{execute.Time(2), "b", "s"},
+					},
+				},
+				&executetest.Table{
+					KeyCols: []string{"_field"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TInt},
+						{Label: "_field", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), int64(1), "i"},
+						{execute.Time(2), int64(2), "i"},
+					},
+				},
+			},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "s", Type: query.TString},
+					{Label: "i", Type: query.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), nil, int64(1)},
+					{execute.Time(2), "b", int64(2)},
+					{execute.Time(3), "c", nil},
+				},
+			}},
+		},
+		{
+			name: "missing column",
+			spec: &functions.PivotProcedureSpec{
+				RowKey:      []string{"_time"},
+				ColKey:      []string{"_field"},
+				ValueColumn: "_value",
+			},
+			data: []query.Table{
+				&executetest.Table{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+					},
+				},
+			},
+			want:    []*executetest.Table(nil),
+			wantErr: errors.New(`pivot error: column "_field" doesn't exist`),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			d := executetest.NewDataset(executetest.RandomDatasetID())
+			c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+			c.SetTriggerSpec(execute.DefaultTriggerSpec)
+			tx := functions.NewPivotTransformation(d, c, tc.spec)
+
+			parentID := executetest.RandomDatasetID()
+			for _, tbl := range tc.data {
+				if err := tx.Process(parentID, tbl); err != nil {
+					if tc.wantErr == nil || tc.wantErr.Error() != err.Error() {
+						t.Fatalf("unexpected error: want %v, got %v", tc.wantErr, err)
+					}
+					return
+				}
+			}
+			if tc.wantErr != nil {
+				t.Fatalf("expected error %v, got none", tc.wantErr)
+			}
+
+			// Output tables are only built once every input table has been processed.
+			got, err := executetest.TablesFromCache(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 0 {
+				t.Fatalf("expected no tables before finishing, got %d", len(got))
+			}
+
+			tx.Finish(parentID, nil)
+			got, err = executetest.TablesFromCache(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			executetest.NormalizeTables(got)
+			executetest.NormalizeTables(tc.want)
+			sort.Sort(executetest.SortedTables(got))
+			sort.Sort(executetest.SortedTables(tc.want))
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestPivot_Process_ConflictingTypes(t *testing.T) {
+	d := executetest.NewDataset(executetest.RandomDatasetID())
+	c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+	c.SetTriggerSpec(execute.DefaultTriggerSpec)
+	tx := functions.NewPivotTransformation(d, c, &functions.PivotProcedureSpec{
+		RowKey:      []string{"_time"},
+		ColKey:      []string{"_field"},
+		ValueColumn: "_value",
+	})
+
+	parentID := executetest.RandomDatasetID()
+	if err := tx.Process(parentID, &executetest.Table{
+		ColMeta: []query.ColMeta{
+			{Label: "_time", Type: query.TTime},
+			{Label: "_value", Type: query.TFloat},
+			{Label: "_field", Type: query.TString},
+		},
+		Data: [][]interface{}{
+			{execute.Time(1), 1.0, "f"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := tx.Process(parentID, &executetest.Table{
+		ColMeta: []query.ColMeta{
+			{Label: "_time", Type: query.TTime},
+			{Label: "_value", Type: query.TInt},
+			{Label: "_field", Type: query.TString},
+		},
+		Data: [][]interface{}{
+			{execute.Time(2), int64(2), "f"},
+		},
+	})
+	if want := `pivot error: pivoted column "f" has both float and int values`; err == nil || err.Error() != want {
+		t.Fatalf("unexpected error: want %q, got %v", want, err)
+	}
+}
@@ -327,22 +327,7 @@ func (t *rangeTransformation) Process(id execute.DatasetID, tbl query.Table) err
 					}
 					builder.AppendTime(j, stop)
 				default:
-					switch c.Type {
-					case query.TBool:
-						builder.AppendBool(j, cr.Bools(j)[i])
-					case query.TInt:
-						builder.AppendInt(j, cr.Ints(j)[i])
-					case query.TUInt:
-						builder.AppendUInt(j, cr.UInts(j)[i])
-					case query.TFloat:
-						builder.AppendFloat(j, cr.Floats(j)[i])
-					case query.TString:
-						builder.AppendString(j, cr.Strings(j)[i])
-					case query.TTime:
-						builder.AppendTime(j, cr.Times(j)[i])
-					default:
-						execute.PanicUnknownType(c.Type)
-					}
+					execute.AppendColValue(j, j, i, cr, builder)
 				}
 			}
 		}
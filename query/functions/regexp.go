@@ -0,0 +1,210 @@
+package functions
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/interpreter"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+)
+
+const regexpPackageName = "regexp"
+
+// maxCachedPatterns bounds the number of compiled patterns kept by the pattern cache.
+const maxCachedPatterns = 1000
+
+// patterns is shared by every query, as a compiled *regexp.Regexp is safe for concurrent use.
+var patterns = newPatternCache(maxCachedPatterns)
+
+func init() {
+	query.RegisterBuiltInValue(regexpPackageName, newPackage(regexpFunctions(patterns)))
+}
+
+// patternCache holds compiled patterns so that regexp.compile does not compile the same pattern for every row.
+// Once full, it evicts the least recently used pattern.
+type patternCache struct {
+	mu       sync.Mutex
+	size     int
+	order    *list.List
+	patterns map[string]*list.Element
+}
+
+type cachedPattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func newPatternCache(size int) *patternCache {
+	return &patternCache{
+		size:     size,
+		order:    list.New(),
+		patterns: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *patternCache) compile(pattern string) (*regexp.Regexp, error) {
+	c.mu.Lock()
+	if e, ok := c.patterns[pattern]; ok {
+		c.order.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*cachedPattern).re, nil
+	}
+	c.mu.Unlock()
+
+	// Compile outside the lock so that a slow pattern does not block other queries.
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.patterns[pattern]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*cachedPattern).re, nil
+	}
+	c.patterns[pattern] = c.order.PushFront(&cachedPattern{pattern: pattern, re: re})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.patterns, oldest.Value.(*cachedPattern).pattern)
+	}
+	return re, nil
+}
+
+func regexpFunctions(cache *patternCache) map[string]packageFunction {
+	return map[string]packageFunction{
+		"compile": {
+			params:     map[string]semantic.Type{"v": semantic.String},
+			returnType: semantic.Regexp,
+			call: func(args interpreter.Arguments) (values.Value, error) {
+				v, err := args.GetRequiredString("v")
+				if err != nil {
+					return nil, err
+				}
+				re, err := cache.compile(v)
+				if err != nil {
+					return nil, err
+				}
+				return values.NewRegexpValue(re), nil
+			},
+		},
+		"quoteMeta": {
+			params:     map[string]semantic.Type{"v": semantic.String},
+			returnType: semantic.String,
+			call: func(args interpreter.Arguments) (values.Value, error) {
+				v, err := args.GetRequiredString("v")
+				if err != nil {
+					return nil, err
+				}
+				return values.NewStringValue(regexp.QuoteMeta(v)), nil
+			},
+		},
+		"matchRegexpString": {
+			params:     map[string]semantic.Type{"r": semantic.Regexp, "v": semantic.String},
+			returnType: semantic.Bool,
+			call: func(args interpreter.Arguments) (values.Value, error) {
+				re, v, err := getRegexpArgs(args)
+				if err != nil {
+					return nil, err
+				}
+				return values.NewBoolValue(re.MatchString(v)), nil
+			},
+		},
+		"findString": {
+			params:     map[string]semantic.Type{"r": semantic.Regexp, "v": semantic.String},
+			returnType: semantic.String,
+			call: func(args interpreter.Arguments) (values.Value, error) {
+				re, v, err := getRegexpArgs(args)
+				if err != nil {
+					return nil, err
+				}
+				return values.NewStringValue(re.FindString(v)), nil
+			},
+		},
+		"findStringSubmatch": {
+			params:     map[string]semantic.Type{"r": semantic.Regexp, "v": semantic.String},
+			returnType: semantic.NewArrayType(semantic.String),
+			call: func(args interpreter.Arguments) (values.Value, error) {
+				re, v, err := getRegexpArgs(args)
+				if err != nil {
+					return nil, err
+				}
+				return newStringArray(re.FindStringSubmatch(v)), nil
+			},
+		},
+		"findStringIndex": {
+			params:     map[string]semantic.Type{"r": semantic.Regexp, "v": semantic.String},
+			returnType: semantic.NewArrayType(semantic.Int),
+			call: func(args interpreter.Arguments) (values.Value, error) {
+				re, v, err := getRegexpArgs(args)
+				if err != nil {
+					return nil, err
+				}
+				loc := re.FindStringIndex(v)
+				elements := make([]values.Value, len(loc))
+				for i, l := range loc {
+					elements[i] = values.NewIntValue(int64(l))
+				}
+				return values.NewArrayWithBacking(semantic.Int, elements), nil
+			},
+		},
+		"replaceAllString": {
+			params:     map[string]semantic.Type{"r": semantic.Regexp, "v": semantic.String, "t": semantic.String},
+			returnType: semantic.String,
+			call: func(args interpreter.Arguments) (values.Value, error) {
+				re, v, err := getRegexpArgs(args)
+				if err != nil {
+					return nil, err
+				}
+				t, err := args.GetRequiredString("t")
+				if err != nil {
+					return nil, err
+				}
+				return values.NewStringValue(re.ReplaceAllString(v, t)), nil
+			},
+		},
+		"splitRegexp": {
+			params:     map[string]semantic.Type{"r": semantic.Regexp, "v": semantic.String, "i": semantic.Int},
+			returnType: semantic.NewArrayType(semantic.String),
+			call: func(args interpreter.Arguments) (values.Value, error) {
+				re, v, err := getRegexpArgs(args)
+				if err != nil {
+					return nil, err
+				}
+				i, err := args.GetRequiredInt("i")
+				if err != nil {
+					return nil, err
+				}
+				return newStringArray(re.Split(v, int(i))), nil
+			},
+		},
+	}
+}
+
+func getRegexpArgs(args interpreter.Arguments) (*regexp.Regexp, string, error) {
+	r, err := args.GetRequired("r")
+	if err != nil {
+		return nil, "", err
+	}
+	if k := r.Type().Kind(); k != semantic.Regexp {
+		return nil, "", fmt.Errorf("keyword argument %q should be of kind %v, but got %v", "r", semantic.Regexp, k)
+	}
+	v, err := args.GetRequiredString("v")
+	if err != nil {
+		return nil, "", err
+	}
+	return r.Regexp(), v, nil
+}
+
+func newStringArray(strs []string) values.Array {
+	elements := make([]values.Value, len(strs))
+	for i, s := range strs {
+		elements[i] = values.NewStringValue(s)
+	}
+	return values.NewArrayWithBacking(semantic.String, elements)
+}
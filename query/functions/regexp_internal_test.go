@@ -0,0 +1,40 @@
+package functions
+
+import "testing"
+
+func TestPatternCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newPatternCache(2)
+	compile := func(pattern string) {
+		t.Helper()
+		if _, err := c.compile(pattern); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	compile("a")
+	a, _ := c.compile("a")
+	compile("b")
+	// Using a again makes b the least recently used pattern.
+	compile("a")
+	compile("c")
+
+	if _, ok := c.patterns["b"]; ok {
+		t.Error("expected b to be evicted")
+	}
+	if got, _ := c.compile("a"); got != a {
+		t.Error("expected a to stay cached")
+	}
+	if got := c.order.Len(); got != 2 {
+		t.Errorf("unexpected cache size: got %d want 2", got)
+	}
+}
+
+func TestPatternCache_InvalidPattern(t *testing.T) {
+	c := newPatternCache(2)
+	if _, err := c.compile("("); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := c.order.Len(); got != 0 {
+		t.Errorf("invalid pattern was cached: size %d", got)
+	}
+}
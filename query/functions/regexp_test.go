@@ -0,0 +1,149 @@
+package functions_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+)
+
+func TestRegexp(t *testing.T) {
+	testCases := []struct {
+		name    string
+		script  string
+		want    values.Value
+		wantErr string
+	}{
+		{
+			name:   "compile",
+			script: `x = regexp.compile(v: "cpu[0-9]+")`,
+			want:   values.NewRegexpValue(regexp.MustCompile("cpu[0-9]+")),
+		},
+		{
+			name:   "quoteMeta",
+			script: `x = regexp.quoteMeta(v: "1.5+2")`,
+			want:   values.NewStringValue(`1\.5\+2`),
+		},
+		{
+			name:   "matchRegexpString",
+			script: `x = regexp.matchRegexpString(r: /^server[0-9]+$/, v: "server01")`,
+			want:   values.NewBoolValue(true),
+		},
+		{
+			name:   "matchRegexpString compiled",
+			script: `x = regexp.matchRegexpString(r: regexp.compile(v: regexp.quoteMeta(v: "a.b")), v: "axb")`,
+			want:   values.NewBoolValue(false),
+		},
+		{
+			name:   "findString",
+			script: `x = regexp.findString(r: /[0-9]+/, v: "server01.local")`,
+			want:   values.NewStringValue("01"),
+		},
+		{
+			name:   "findStringSubmatch",
+			script: `x = regexp.findStringSubmatch(r: /(\w+)@(\w+)/, v: "user@example")`,
+			want: values.NewArrayWithBacking(semantic.String, []values.Value{
+				values.NewStringValue("user@example"),
+				values.NewStringValue("user"),
+				values.NewStringValue("example"),
+			}),
+		},
+		{
+			name:   "findStringIndex",
+			script: `x = regexp.findStringIndex(r: /ab?/, v: "tablett")`,
+			want: values.NewArrayWithBacking(semantic.Int, []values.Value{
+				values.NewIntValue(1),
+				values.NewIntValue(3),
+			}),
+		},
+		{
+			name:   "findStringIndex no match",
+			script: `x = regexp.findStringIndex(r: /z/, v: "tablett")`,
+			want:   values.NewArrayWithBacking(semantic.Int, []values.Value{}),
+		},
+		{
+			name:   "replaceAllString",
+			script: `x = regexp.replaceAllString(r: /a(x*)b/, v: "-ab-axxb-", t: "${1}W")`,
+			want:   values.NewStringValue("-W-xxW-"),
+		},
+		{
+			name:   "splitRegexp",
+			script: `x = regexp.splitRegexp(r: /a*/, v: "abaabaccadaaae", i: 5)`,
+			want: values.NewArrayWithBacking(semantic.String, []values.Value{
+				values.NewStringValue(""),
+				values.NewStringValue("b"),
+				values.NewStringValue("b"),
+				values.NewStringValue("c"),
+				values.NewStringValue("cadaaae"),
+			}),
+		},
+		{
+			name:    "invalid pattern",
+			script:  `x = regexp.compile(v: "a(b")`,
+			wantErr: "error calling function \"compile\": error parsing regexp: missing closing ): `a(b`",
+		},
+		{
+			name:    "string instead of regexp",
+			script:  `x = regexp.findString(r: "a", v: "abc")`,
+			wantErr: `error calling function "findString": keyword argument "r" should be of kind regexp, but got string`,
+		},
+		{
+			name:    "wrong value kind",
+			script:  `x = regexp.matchRegexpString(r: /a/, v: 1)`,
+			wantErr: `error calling function "matchRegexpString": keyword argument "v" should be of kind string, but got int`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			itrp := query.NewInterpreter()
+			err := query.Eval(itrp, tc.script)
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error %q", tc.wantErr)
+				}
+				if got := err.Error(); got != tc.wantErr {
+					t.Fatalf("unexpected error -want/+got\n\t- %q\n\t+ %q", tc.wantErr, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := itrp.GlobalScope().Lookup("x")
+			if !ok {
+				t.Fatal("missing result x")
+			}
+			if got.Type() != tc.want.Type() || !got.Equal(tc.want) {
+				t.Errorf("unexpected value -want/+got\n\t- %v\n\t+ %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRegexp_CompileCache(t *testing.T) {
+	script := `
+	a = regexp.compile(v: "cpu.*")
+	b = regexp.compile(v: "cpu.*")`
+
+	eval := func() (*regexp.Regexp, *regexp.Regexp) {
+		itrp := query.NewInterpreter()
+		if err := query.Eval(itrp, script); err != nil {
+			t.Fatal(err)
+		}
+		a, _ := itrp.GlobalScope().Lookup("a")
+		b, _ := itrp.GlobalScope().Lookup("b")
+		return a.Regexp(), b.Regexp()
+	}
+
+	a1, b1 := eval()
+	if a1 != b1 {
+		t.Error("expected the pattern to be compiled once within a query")
+	}
+	a2, _ := eval()
+	if a1 != a2 {
+		t.Error("expected queries to share the pattern cache")
+	}
+}
@@ -0,0 +1,53 @@
+package functions
+
+import (
+	"github.com/influxdata/platform/query"
+)
+
+// sinkKinds are the kinds of operations that write data outside of the query.
+var sinkKinds = map[query.OperationKind]bool{
+	ToKind:      true,
+	ToHTTPKind:  true,
+	ToKafkaKind: true,
+	SQLToKind:   true,
+}
+
+// IsSink reports whether operations of kind k write data outside of the query.
+func IsSink(k query.OperationKind) bool {
+	return sinkKinds[k]
+}
+
+// YieldSinks returns a copy of spec in which no sink operation writes data.
+// A sink without children is replaced with a yield named after the sink's operation ID.
+// A sink with children is replaced with an operation that passes its tables on unchanged,
+// so that the operations after it still run.
+// Running the returned spec produces the data the sinks would have written, without writing it.
+func YieldSinks(spec *query.Spec) *query.Spec {
+	out := &query.Spec{
+		Operations: make([]*query.Operation, len(spec.Operations)),
+		Edges:      append([]query.Edge(nil), spec.Edges...),
+		Resources:  spec.Resources,
+		Now:        spec.Now,
+	}
+	parents := make(map[query.OperationID]bool, len(spec.Edges))
+	for _, e := range spec.Edges {
+		parents[e.Parent] = true
+	}
+	for i, o := range spec.Operations {
+		if IsSink(o.Spec.Kind()) {
+			var s query.OperationSpec = &YieldOpSpec{Name: string(o.ID)}
+			if parents[o.ID] {
+				// A yield with children would become a result of its own,
+				// and the results of the operations after it would be lost.
+				// Renaming no columns leaves the tables as they are.
+				s = &RenameOpSpec{Cols: map[string]string{}}
+			}
+			o = &query.Operation{
+				ID:   o.ID,
+				Spec: s,
+			}
+		}
+		out.Operations[i] = o
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package functions_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/functions"
+)
+
+func TestYieldSinks(t *testing.T) {
+	spec := &query.Spec{
+		Operations: []*query.Operation{
+			{ID: "from0", Spec: &functions.FromOpSpec{Bucket: "b"}},
+			{ID: "toHTTP1", Spec: &functions.ToHTTPOpSpec{URL: "http://example.com"}},
+			{ID: "toKafka2", Spec: &functions.ToKafkaOpSpec{Topic: "t"}},
+			{ID: "to3", Spec: &functions.ToOpSpec{Bucket: "downsampled"}},
+			{ID: "toSQL4", Spec: &functions.SQLToOpSpec{DriverName: "sqlite3", Table: "t"}},
+		},
+		Edges: []query.Edge{
+			{Parent: "from0", Child: "toHTTP1"},
+			{Parent: "from0", Child: "toKafka2"},
+			{Parent: "from0", Child: "to3"},
+			{Parent: "from0", Child: "toSQL4"},
+		},
+	}
+
+	got := functions.YieldSinks(spec)
+	if len(got.Operations) != 5 || len(got.Edges) != 4 {
+		t.Fatalf("unexpected spec: %+v", got)
+	}
+	if got.Operations[0] != spec.Operations[0] {
+		t.Fatal("expected non-sink operation to be kept")
+	}
+	for _, o := range got.Operations[1:] {
+		y, ok := o.Spec.(*functions.YieldOpSpec)
+		if !ok {
+			t.Fatalf("expected sink %s to be replaced with a yield, got %T", o.ID, o.Spec)
+		}
+		if y.Name != string(o.ID) {
+			t.Fatalf("expected yield to be named %q, got %q", o.ID, y.Name)
+		}
+	}
+	if _, ok := spec.Operations[1].Spec.(*functions.ToHTTPOpSpec); !ok {
+		t.Fatal("original spec was modified")
+	}
+}
+
+func TestYieldSinks_WithChildren(t *testing.T) {
+	spec := &query.Spec{
+		Operations: []*query.Operation{
+			{ID: "from0", Spec: &functions.FromOpSpec{Bucket: "b"}},
+			{ID: "to1", Spec: &functions.ToOpSpec{Bucket: "downsampled"}},
+			{ID: "map2", Spec: &functions.MapOpSpec{}},
+			{ID: "to3", Spec: &functions.ToOpSpec{Bucket: "doubled"}},
+		},
+		Edges: []query.Edge{
+			{Parent: "from0", Child: "to1"},
+			{Parent: "to1", Child: "map2"},
+			{Parent: "map2", Child: "to3"},
+		},
+	}
+
+	got := functions.YieldSinks(spec)
+	if len(got.Operations) != 4 || len(got.Edges) != 3 {
+		t.Fatalf("expected operations and edges to be kept, got %+v", got)
+	}
+	// The sink with children passes its tables on to them.
+	r, ok := got.Operations[1].Spec.(*functions.RenameOpSpec)
+	if !ok || len(r.Cols) != 0 || r.Fn != nil {
+		t.Fatalf("expected sink with children to be replaced with a pass through, got %#v", got.Operations[1].Spec)
+	}
+	if got.Operations[2] != spec.Operations[2] {
+		t.Fatal("expected the operation after the sink to be kept")
+	}
+	if y, ok := got.Operations[3].Spec.(*functions.YieldOpSpec); !ok || y.Name != "to3" {
+		t.Fatalf("expected sink leaf to be replaced with a yield, got %#v", got.Operations[3].Spec)
+	}
+}
@@ -0,0 +1,216 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql" // Register the MySQL driver
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/values"
+	_ "github.com/lib/pq" // Register the Postgres driver
+)
+
+const sqlPackageName = "sql"
+
+func init() {
+	pkg := values.NewObject()
+	pkg.Set("from", query.NewOperationFunction(SQLFromKind, createSQLFromOpSpec, sqlFromSignature))
+	pkg.Set("to", query.NewOperationFunctionWithSideEffect(SQLToKind, createSQLToOpSpec, sqlToSignature))
+	query.RegisterBuiltInValue(sqlPackageName, pkg)
+}
+
+// SQLDependencies are the dependencies of sql.from and sql.to.
+type SQLDependencies struct {
+	// DataSources decides which databases queries may connect to.
+	DataSources SQLDataSourceValidator
+}
+
+// SQLDataSourceValidator decides which databases queries may connect to.
+type SQLDataSourceValidator interface {
+	// ValidateDataSource returns an error unless queries may connect to dataSourceName with driverName.
+	ValidateDataSource(driverName, dataSourceName string) error
+}
+
+func (d SQLDependencies) Validate() error {
+	if d.DataSources == nil {
+		return errors.New("missing data sources dependency")
+	}
+	return nil
+}
+
+// InjectSQLDependencies adds the dependencies of sql.from and sql.to to the execution dependencies.
+// Without them, queries cannot connect to any database.
+func InjectSQLDependencies(depsMap execute.Dependencies, deps SQLDependencies) error {
+	if err := deps.Validate(); err != nil {
+		return err
+	}
+	depsMap[sqlPackageName] = deps
+	return nil
+}
+
+// validateSQLDataSource returns an error unless the query may connect to dataSourceName with driverName.
+func validateSQLDataSource(a execute.Administration, driverName, dataSourceName string) error {
+	deps, ok := a.Dependencies()[sqlPackageName].(SQLDependencies)
+	if !ok {
+		return errors.New("sql: no data sources have been configured")
+	}
+	return deps.DataSources.ValidateDataSource(driverName, dataSourceName)
+}
+
+// SQLDataSources is a SQLDataSourceValidator that allows a fixed set of data source names for each driver name.
+type SQLDataSources map[string][]string
+
+func (s SQLDataSources) ValidateDataSource(driverName, dataSourceName string) error {
+	for _, dsn := range s[driverName] {
+		if dsn == dataSourceName {
+			return nil
+		}
+	}
+	// The data source name is not part of the error, since it may contain credentials.
+	return fmt.Errorf("sql: data source of driver %q is not allowed", driverName)
+}
+
+// sqlDialect describes how statements are written for a database driver.
+type sqlDialect struct {
+	// placeholder returns the bind parameter for the nth argument of a statement, counting from one.
+	placeholder func(n int) string
+	// quote quotes an identifier such as a table or column name.
+	quote func(ident string) string
+	// maxParams is the largest number of bind parameters the database accepts in one statement.
+	maxParams int
+}
+
+var sqlDialects = map[string]sqlDialect{
+	"postgres": {
+		placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		quote:       quoteSQLIdentifier(`"`),
+		maxParams:   65535,
+	},
+	"mysql": {
+		placeholder: func(int) string { return "?" },
+		quote:       quoteSQLIdentifier("`"),
+		maxParams:   65535,
+	},
+	"sqlite3": {
+		placeholder: func(int) string { return "?" },
+		quote:       quoteSQLIdentifier(`"`),
+		maxParams:   999,
+	},
+}
+
+func getSQLDialect(driverName string) (sqlDialect, error) {
+	d, ok := sqlDialects[driverName]
+	if !ok {
+		return sqlDialect{}, fmt.Errorf("sql driver %q is not supported", driverName)
+	}
+	return d, nil
+}
+
+func quoteSQLIdentifier(q string) func(string) string {
+	return func(ident string) string {
+		return q + strings.Replace(ident, q, q+q, -1) + q
+	}
+}
+
+// sqlColumnType maps the database type of a column onto the type of the column in a table.
+// Types that have no equivalent are read as strings.
+func sqlColumnType(databaseTypeName string) query.DataType {
+	name := strings.ToUpper(databaseTypeName)
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = strings.TrimSpace(name[:i])
+	}
+	if strings.HasPrefix(name, "UNSIGNED ") {
+		return query.TUInt
+	}
+	switch name {
+	case "INT", "INTEGER", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT", "INT2", "INT4", "INT8", "SMALLSERIAL", "SERIAL", "BIGSERIAL":
+		return query.TInt
+	case "FLOAT", "DOUBLE", "DOUBLE PRECISION", "REAL", "FLOAT4", "FLOAT8", "NUMERIC", "DECIMAL":
+		return query.TFloat
+	case "BOOL", "BOOLEAN":
+		return query.TBool
+	case "DATE", "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
+		return query.TTime
+	default:
+		return query.TString
+	}
+}
+
+// sqlTimeLayouts are the layouts of times that drivers return as text.
+var sqlTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// convertSQLValue converts a value scanned from a row into a value of type typ.
+// The value must not be nil.
+func convertSQLValue(v interface{}, typ query.DataType) (interface{}, error) {
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	switch typ {
+	case query.TInt:
+		switch v := v.(type) {
+		case int64:
+			return v, nil
+		case string:
+			return strconv.ParseInt(v, 10, 64)
+		}
+	case query.TUInt:
+		switch v := v.(type) {
+		case int64:
+			if v >= 0 {
+				return uint64(v), nil
+			}
+		case uint64:
+			return v, nil
+		case string:
+			return strconv.ParseUint(v, 10, 64)
+		}
+	case query.TFloat:
+		switch v := v.(type) {
+		case float64:
+			return v, nil
+		case int64:
+			return float64(v), nil
+		case string:
+			return strconv.ParseFloat(v, 64)
+		}
+	case query.TBool:
+		switch v := v.(type) {
+		case bool:
+			return v, nil
+		case int64:
+			return v != 0, nil
+		case string:
+			return strconv.ParseBool(v)
+		}
+	case query.TTime:
+		switch v := v.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			for _, layout := range sqlTimeLayouts {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t, nil
+				}
+			}
+		}
+	case query.TString:
+		switch v := v.(type) {
+		case string:
+			return v, nil
+		case time.Time:
+			return v.Format(time.RFC3339Nano), nil
+		default:
+			return fmt.Sprint(v), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %v of type %T to %v", v, v, typ)
+}
@@ -0,0 +1,209 @@
+package functions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/pkg/errors"
+)
+
+// SQLFromKind is the Kind for the sql.from Flux function
+const SQLFromKind = "fromSQL"
+
+type SQLFromOpSpec struct {
+	DriverName     string `json:"driverName"`
+	DataSourceName string `json:"dataSourceName"`
+	Query          string `json:"query"`
+}
+
+var sqlFromSignature = semantic.FunctionSignature{
+	Params: map[string]semantic.Type{
+		"driverName":     semantic.String,
+		"dataSourceName": semantic.String,
+		"query":          semantic.String,
+	},
+	ReturnType: query.TableObjectType,
+}
+
+func init() {
+	// The function itself is registered as a member of the sql package.
+	query.RegisterOpSpec(SQLFromKind, newSQLFromOp)
+	plan.RegisterProcedureSpec(SQLFromKind, newSQLFromProcedure, SQLFromKind)
+	execute.RegisterSource(SQLFromKind, createSQLFromSource)
+}
+
+func createSQLFromOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	spec := new(SQLFromOpSpec)
+
+	var err error
+	if spec.DriverName, err = args.GetRequiredString("driverName"); err != nil {
+		return nil, err
+	}
+	if _, err := getSQLDialect(spec.DriverName); err != nil {
+		return nil, err
+	}
+	if spec.DataSourceName, err = args.GetRequiredString("dataSourceName"); err != nil {
+		return nil, err
+	}
+	if spec.Query, err = args.GetRequiredString("query"); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func newSQLFromOp() query.OperationSpec {
+	return new(SQLFromOpSpec)
+}
+
+func (s *SQLFromOpSpec) Kind() query.OperationKind {
+	return SQLFromKind
+}
+
+type SQLFromProcedureSpec struct {
+	DriverName     string
+	DataSourceName string
+	Query          string
+}
+
+func newSQLFromProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*SQLFromOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+
+	return &SQLFromProcedureSpec{
+		DriverName:     spec.DriverName,
+		DataSourceName: spec.DataSourceName,
+		Query:          spec.Query,
+	}, nil
+}
+
+func (s *SQLFromProcedureSpec) Kind() plan.ProcedureKind {
+	return SQLFromKind
+}
+
+func (s *SQLFromProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(SQLFromProcedureSpec)
+	*ns = *s
+	return ns
+}
+
+func createSQLFromSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*SQLFromProcedureSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", prSpec)
+	}
+	if err := validateSQLDataSource(a, spec.DriverName, spec.DataSourceName); err != nil {
+		return nil, err
+	}
+	return NewSQLSource(dsid, spec, a.Allocator()), nil
+}
+
+// SQLSource reads the result of a SQL query into a single table.
+type SQLSource struct {
+	id    execute.DatasetID
+	spec  *SQLFromProcedureSpec
+	alloc *execute.Allocator
+	ts    []execute.Transformation
+}
+
+func NewSQLSource(id execute.DatasetID, spec *SQLFromProcedureSpec, alloc *execute.Allocator) *SQLSource {
+	return &SQLSource{
+		id:    id,
+		spec:  spec,
+		alloc: alloc,
+	}
+}
+
+func (s *SQLSource) AddTransformation(t execute.Transformation) {
+	s.ts = append(s.ts, t)
+}
+
+func (s *SQLSource) Run(ctx context.Context) {
+	err := s.run(ctx)
+	for _, t := range s.ts {
+		t.Finish(s.id, err)
+	}
+}
+
+func (s *SQLSource) run(ctx context.Context) error {
+	db, err := sql.Open(s.spec.DriverName, s.spec.DataSourceName)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, s.spec.Query)
+	if err != nil {
+		return errors.Wrap(err, "failed to run sql query")
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	builder := execute.NewColListTableBuilder(execute.NewGroupKey(nil, nil), s.alloc)
+	for _, ct := range columnTypes {
+		builder.AddCol(query.ColMeta{
+			Label: ct.Name(),
+			Type:  sqlColumnType(ct.DatabaseTypeName()),
+		})
+	}
+	cols := builder.Cols()
+
+	row := make([]interface{}, len(cols))
+	dest := make([]interface{}, len(cols))
+	for j := range row {
+		dest[j] = &row[j]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		for j, v := range row {
+			if v == nil {
+				builder.AppendNil(j)
+				continue
+			}
+			v, err := convertSQLValue(v, cols[j].Type)
+			if err != nil {
+				return errors.Wrapf(err, "column %s", cols[j].Label)
+			}
+			switch cols[j].Type {
+			case query.TInt:
+				builder.AppendInt(j, v.(int64))
+			case query.TUInt:
+				builder.AppendUInt(j, v.(uint64))
+			case query.TFloat:
+				builder.AppendFloat(j, v.(float64))
+			case query.TBool:
+				builder.AppendBool(j, v.(bool))
+			case query.TTime:
+				builder.AppendTime(j, execute.Time(v.(time.Time).UnixNano()))
+			case query.TString:
+				builder.AppendString(j, v.(string))
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tbl, err := builder.Table()
+	if err != nil {
+		return err
+	}
+	for _, t := range s.ts {
+		if err := t.Process(s.id, tbl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,522 @@
+package functions_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/control"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	_ "github.com/influxdata/platform/query/functions/sqlite"
+	"github.com/influxdata/platform/query/querytest"
+)
+
+func TestSQL_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "from",
+			Raw:  `sql.from(driverName:"sqlite3", dataSourceName:"file:inventory.db", query:"SELECT host, rack FROM hosts")`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "fromSQL0",
+						Spec: &functions.SQLFromOpSpec{
+							DriverName:     "sqlite3",
+							DataSourceName: "file:inventory.db",
+							Query:          "SELECT host, rack FROM hosts",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "from to",
+			Raw:  `sql.from(driverName:"postgres", dataSourceName:"postgres://localhost/inventory", query:"SELECT * FROM hosts") |> sql.to(driverName:"mysql", dataSourceName:"root@/inventory", table:"hosts")`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "fromSQL0",
+						Spec: &functions.SQLFromOpSpec{
+							DriverName:     "postgres",
+							DataSourceName: "postgres://localhost/inventory",
+							Query:          "SELECT * FROM hosts",
+						},
+					},
+					{
+						ID: "toSQL1",
+						Spec: &functions.SQLToOpSpec{
+							DriverName:     "mysql",
+							DataSourceName: "root@/inventory",
+							Table:          "hosts",
+							BatchSize:      functions.DefaultSQLBatchSize,
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "fromSQL0", Child: "toSQL1"},
+				},
+			},
+		},
+		{
+			Name: "to with batch size",
+			Raw:  `from(db:"mydb") |> sql.to(driverName:"sqlite3", dataSourceName:"file:metrics.db", table:"metrics", batchSize:10)`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "toSQL1",
+						Spec: &functions.SQLToOpSpec{
+							DriverName:     "sqlite3",
+							DataSourceName: "file:metrics.db",
+							Table:          "metrics",
+							BatchSize:      10,
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "toSQL1"},
+				},
+			},
+		},
+		{
+			Name:    "from unsupported driver",
+			Raw:     `sql.from(driverName:"oracle", dataSourceName:"db", query:"SELECT 1")`,
+			WantErr: true,
+		},
+		{
+			Name:    "from missing query",
+			Raw:     `sql.from(driverName:"sqlite3", dataSourceName:"file:inventory.db")`,
+			WantErr: true,
+		},
+		{
+			Name:    "to missing table",
+			Raw:     `from(db:"mydb") |> sql.to(driverName:"sqlite3", dataSourceName:"file:metrics.db")`,
+			WantErr: true,
+		},
+		{
+			Name:    "to invalid batch size",
+			Raw:     `from(db:"mydb") |> sql.to(driverName:"sqlite3", dataSourceName:"file:metrics.db", table:"metrics", batchSize:0)`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+// newSQLiteDB creates an embedded SQLite database and runs the given statements against it.
+// It returns the data source name of the database and a function that removes it.
+func newSQLiteDB(t *testing.T, stmts ...string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dsn := "file:" + filepath.Join(dir, "test.db")
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	return dsn, func() { os.RemoveAll(dir) }
+}
+
+// tableCollector is a transformation that converts the tables it processes.
+type tableCollector struct {
+	tables []*executetest.Table
+	err    error
+}
+
+func (c *tableCollector) RetractTable(id execute.DatasetID, key query.GroupKey) error {
+	return nil
+}
+
+func (c *tableCollector) Process(id execute.DatasetID, tbl query.Table) error {
+	t, err := executetest.ConvertTable(tbl)
+	if err != nil {
+		return err
+	}
+	c.tables = append(c.tables, t)
+	return nil
+}
+
+func (c *tableCollector) UpdateWatermark(id execute.DatasetID, t execute.Time) error {
+	return nil
+}
+
+func (c *tableCollector) UpdateProcessingTime(id execute.DatasetID, t execute.Time) error {
+	return nil
+}
+
+func (c *tableCollector) Finish(id execute.DatasetID, err error) {
+	c.err = err
+}
+
+func TestSQLSource_Run(t *testing.T) {
+	dsn, cleanup := newSQLiteDB(t,
+		`CREATE TABLE hosts (id INTEGER, host TEXT, load REAL, active BOOLEAN, installed DATETIME, rack VARCHAR(16))`,
+		`INSERT INTO hosts VALUES (1, 'a', 0.5, 1, '2018-05-22 19:53:26', 'r1')`,
+		`INSERT INTO hosts VALUES (2, 'b', 1.5, 0, '2018-05-22 19:53:36', NULL)`,
+	)
+	defer cleanup()
+
+	testCases := []struct {
+		name    string
+		query   string
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name:  "column types",
+			query: `SELECT id, host, load, active, installed, rack FROM hosts ORDER BY id`,
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "id", Type: query.TInt},
+					{Label: "host", Type: query.TString},
+					{Label: "load", Type: query.TFloat},
+					{Label: "active", Type: query.TBool},
+					{Label: "installed", Type: query.TTime},
+					{Label: "rack", Type: query.TString},
+				},
+				Data: [][]interface{}{
+					{int64(1), "a", 0.5, true, execute.Time(time.Date(2018, 5, 22, 19, 53, 26, 0, time.UTC).UnixNano()), "r1"},
+					{int64(2), "b", 1.5, false, execute.Time(time.Date(2018, 5, 22, 19, 53, 36, 0, time.UTC).UnixNano()), nil},
+				},
+			}},
+		},
+		{
+			name:  "no rows",
+			query: `SELECT id, host FROM hosts WHERE id > 2`,
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "id", Type: query.TInt},
+					{Label: "host", Type: query.TString},
+				},
+			}},
+		},
+		{
+			name:    "invalid query",
+			query:   `SELECT * FROM missing`,
+			wantErr: errors.New("failed to run sql query: no such table: missing"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := new(tableCollector)
+			s := functions.NewSQLSource(executetest.RandomDatasetID(), &functions.SQLFromProcedureSpec{
+				DriverName:     "sqlite3",
+				DataSourceName: dsn,
+				Query:          tc.query,
+			}, executetest.UnlimitedAllocator)
+			s.AddTransformation(c)
+			s.Run(context.Background())
+
+			if tc.wantErr != nil {
+				if c.err == nil || c.err.Error() != tc.wantErr.Error() {
+					t.Fatalf("unexpected error: want %v got %v", tc.wantErr, c.err)
+				}
+				return
+			}
+			if c.err != nil {
+				t.Fatal(c.err)
+			}
+			executetest.NormalizeTables(c.tables)
+			executetest.NormalizeTables(tc.want)
+			if !cmp.Equal(tc.want, c.tables) {
+				t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(tc.want, c.tables))
+			}
+		})
+	}
+}
+
+type metricRow struct {
+	Time  time.Time
+	Host  string
+	Value sql.NullFloat64
+}
+
+func TestSQLTo_Process(t *testing.T) {
+	cols := []query.ColMeta{
+		{Label: "_time", Type: query.TTime},
+		{Label: "host", Type: query.TString},
+		{Label: "_value", Type: query.TFloat},
+	}
+	ts := func(sec int) time.Time {
+		return time.Date(2018, 5, 22, 19, 53, sec, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name      string
+		batchSize int
+		canceled  bool
+		data      []query.Table
+		// passed are the tables passed through, if they are not the processed tables.
+		passed  []*executetest.Table
+		want    []metricRow
+		wantErr error
+	}{
+		{
+			name:      "batches",
+			batchSize: 2,
+			data: []query.Table{
+				&executetest.Table{
+					KeyCols: []string{"host"},
+					ColMeta: cols,
+					Data: [][]interface{}{
+						{execute.Time(ts(1).UnixNano()), "a", 1.0},
+						{execute.Time(ts(2).UnixNano()), "a", nil},
+						{execute.Time(ts(3).UnixNano()), "a", 3.0},
+					},
+				},
+				&executetest.Table{
+					KeyCols: []string{"host"},
+					ColMeta: cols,
+					Data: [][]interface{}{
+						{execute.Time(ts(1).UnixNano()), "b", 4.0},
+						{execute.Time(ts(2).UnixNano()), "b", 5.0},
+					},
+				},
+			},
+			want: []metricRow{
+				{Time: ts(1), Host: "a", Value: sql.NullFloat64{Float64: 1, Valid: true}},
+				{Time: ts(1), Host: "b", Value: sql.NullFloat64{Float64: 4, Valid: true}},
+				{Time: ts(2), Host: "a"},
+				{Time: ts(2), Host: "b", Value: sql.NullFloat64{Float64: 5, Valid: true}},
+				{Time: ts(3), Host: "a", Value: sql.NullFloat64{Float64: 3, Valid: true}},
+			},
+		},
+		{
+			name:      "rollback",
+			batchSize: 2,
+			data: []query.Table{
+				&executetest.Table{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "host", Type: query.TString},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(ts(1).UnixNano()), "a", 1.0},
+						{execute.Time(ts(2).UnixNano()), "a", 2.0},
+						{execute.Time(ts(3).UnixNano()), nil, 3.0},
+					},
+				},
+			},
+			wantErr: errors.New("failed to insert rows: NOT NULL constraint failed: metrics.host"),
+		},
+		{
+			name:      "canceled",
+			batchSize: 2,
+			canceled:  true,
+			data: []query.Table{
+				&executetest.Table{
+					ColMeta: cols,
+					Data: [][]interface{}{
+						{execute.Time(ts(1).UnixNano()), "a", 1.0},
+					},
+				},
+			},
+			// Nothing is inserted or passed through once the query is canceled.
+			passed:  []*executetest.Table{{ColMeta: cols}},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			dsn, cleanup := newSQLiteDB(t,
+				`CREATE TABLE metrics (_time DATETIME, host TEXT NOT NULL, _value REAL)`,
+			)
+			defer cleanup()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.canceled {
+				cancel()
+			}
+
+			// Processed tables are passed through unchanged.
+			want := tc.passed
+			if want == nil {
+				want = make([]*executetest.Table, len(tc.data))
+				for i, tbl := range tc.data {
+					want[i] = tbl.(*executetest.Table)
+				}
+			}
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					tx, err := functions.NewSQLToTransformation(ctx, d, c, &functions.SQLToProcedureSpec{
+						Spec: &functions.SQLToOpSpec{
+							DriverName:     "sqlite3",
+							DataSourceName: dsn,
+							Table:          "metrics",
+							BatchSize:      tc.batchSize,
+						},
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+					return tx
+				},
+			)
+
+			db, err := sql.Open("sqlite3", dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			rows, err := db.Query(`SELECT _time, host, _value FROM metrics ORDER BY _time, host`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			var got []metricRow
+			for rows.Next() {
+				var r metricRow
+				if err := rows.Scan(&r.Time, &r.Host, &r.Value); err != nil {
+					t.Fatal(err)
+				}
+				r.Time = r.Time.UTC()
+				got = append(got, r)
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("unexpected rows -want/+got\n%s", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+func TestSQL_DataSources(t *testing.T) {
+	dsn, cleanup := newSQLiteDB(t,
+		`CREATE TABLE hosts (_time DATETIME, host TEXT)`,
+		`INSERT INTO hosts VALUES ('2018-05-22 19:53:26', 'a')`,
+		`CREATE TABLE copies (_start DATETIME, _stop DATETIME, _time DATETIME, host TEXT)`,
+	)
+	defer cleanup()
+
+	allowed := make(execute.Dependencies)
+	if err := functions.InjectSQLDependencies(allowed, functions.SQLDependencies{
+		DataSources: functions.SQLDataSources{"sqlite3": {dsn}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	from := `sql.from(driverName: "sqlite3", dataSourceName: "` + dsn + `", query: "SELECT _time, host FROM hosts") |> range(start: 2018-05-22T00:00:00Z, stop: 2018-05-23T00:00:00Z)`
+	testCases := []struct {
+		name    string
+		deps    execute.Dependencies
+		query   string
+		wantErr string
+	}{
+		{
+			name:  "allowed",
+			deps:  allowed,
+			query: from + ` |> sql.to(driverName: "sqlite3", dataSourceName: "` + dsn + `", table: "copies")`,
+		},
+		{
+			name:    "not configured",
+			deps:    make(execute.Dependencies),
+			query:   from,
+			wantErr: "sql: no data sources have been configured",
+		},
+		{
+			name:    "other data source",
+			deps:    allowed,
+			query:   `sql.from(driverName: "sqlite3", dataSourceName: "/tmp/other.db", query: "SELECT 1") |> range(start: 2018-05-22T00:00:00Z)`,
+			wantErr: `sql: data source of driver "sqlite3" is not allowed`,
+		},
+		{
+			name:    "other data source to",
+			deps:    allowed,
+			query:   from + ` |> sql.to(driverName: "sqlite3", dataSourceName: "/tmp/other.db", table: "hosts")`,
+			wantErr: `sql: data source of driver "sqlite3" is not allowed`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := control.New(control.Config{
+				ConcurrencyQuota:     1,
+				MemoryBytesQuota:     math.MaxInt64,
+				ExecutorDependencies: tc.deps,
+			})
+			q, err := c.Query(context.Background(), &query.Request{
+				OrganizationID: platform.ID("org"),
+				Compiler:       query.FluxCompiler{Query: tc.query},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range <-q.Ready() {
+				if err := r.Tables().Do(func(tbl query.Table) error {
+					return tbl.Do(func(query.ColReader) error { return nil })
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			q.Done()
+			err = q.Err()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("unexpected error: got %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			db, err := sql.Open("sqlite3", dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			var n int
+			if err := db.QueryRow(`SELECT COUNT(*) FROM copies`).Scan(&n); err != nil {
+				t.Fatal(err)
+			}
+			if n != 1 {
+				t.Fatalf("expected 1 row to be inserted, got %d", n)
+			}
+			if got := q.Statistics().BytesWritten; got == 0 {
+				t.Fatal("expected bytes written by sql.to to be reported")
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package functions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/pkg/errors"
+)
+
+// SQLToKind is the Kind for the sql.to Flux function
+const SQLToKind = "toSQL"
+
+// DefaultSQLBatchSize is the number of rows sql.to inserts with one statement unless batchSize is given.
+const DefaultSQLBatchSize = 1000
+
+type SQLToOpSpec struct {
+	DriverName     string `json:"driverName"`
+	DataSourceName string `json:"dataSourceName"`
+	Table          string `json:"table"`
+	BatchSize      int    `json:"batchSize"`
+}
+
+// sqlToPipeParameter is the argument sql.to is piped into.
+// It cannot be query.TableParameter since table names the SQL table to write into.
+const sqlToPipeParameter = "tables"
+
+var sqlToSignature = semantic.FunctionSignature{
+	Params: map[string]semantic.Type{
+		sqlToPipeParameter: query.TableObjectType,
+		"driverName":       semantic.String,
+		"dataSourceName":   semantic.String,
+		"table":            semantic.String,
+		"batchSize":        semantic.Int,
+	},
+	ReturnType:   query.TableObjectType,
+	PipeArgument: sqlToPipeParameter,
+}
+
+func init() {
+
+	// The function itself is registered as a member of the sql package.
+	query.RegisterOpSpec(SQLToKind, func() query.OperationSpec { return &SQLToOpSpec{} })
+	plan.RegisterProcedureSpec(SQLToKind, newSQLToProcedure, SQLToKind)
+	execute.RegisterTransformation(SQLToKind, createSQLToTransformation)
+}
+
+// ReadArgs loads a query.Arguments into SQLToOpSpec.
+// If the batchSize isn't set, it defaults to DefaultSQLBatchSize.
+func (o *SQLToOpSpec) ReadArgs(args query.Arguments) error {
+	var err error
+	if o.DriverName, err = args.GetRequiredString("driverName"); err != nil {
+		return err
+	}
+	if _, err := getSQLDialect(o.DriverName); err != nil {
+		return err
+	}
+	if o.DataSourceName, err = args.GetRequiredString("dataSourceName"); err != nil {
+		return err
+	}
+	if o.Table, err = args.GetRequiredString("table"); err != nil {
+		return err
+	}
+	if o.Table == "" {
+		return errors.New("invalid table name")
+	}
+
+	batchSize, ok, err := args.GetInt("batchSize")
+	if err != nil {
+		return err
+	}
+	o.BatchSize = DefaultSQLBatchSize
+	if ok {
+		if batchSize <= 0 {
+			return errors.New("batchSize must be greater than zero")
+		}
+		o.BatchSize = int(batchSize)
+	}
+	return nil
+}
+
+func createSQLToOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	parent, err := args.GetRequiredObject(sqlToPipeParameter)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := parent.(*query.TableObject)
+	if !ok {
+		return nil, fmt.Errorf("argument is not a table object: got %T", parent)
+	}
+	a.AddParent(p)
+
+	s := new(SQLToOpSpec)
+	if err := s.ReadArgs(args); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (SQLToOpSpec) Kind() query.OperationKind {
+	return SQLToKind
+}
+
+type SQLToProcedureSpec struct {
+	Spec *SQLToOpSpec
+}
+
+func (o *SQLToProcedureSpec) Kind() plan.ProcedureKind {
+	return SQLToKind
+}
+
+func (o *SQLToProcedureSpec) Copy() plan.ProcedureSpec {
+	s := *o.Spec
+	return &SQLToProcedureSpec{Spec: &s}
+}
+
+func newSQLToProcedure(qs query.OperationSpec, a plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*SQLToOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return &SQLToProcedureSpec{Spec: spec}, nil
+}
+
+func createSQLToTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*SQLToProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	if err := validateSQLDataSource(a, s.Spec.DriverName, s.Spec.DataSourceName); err != nil {
+		return nil, nil, err
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t, err := NewSQLToTransformation(a.Context(), d, cache, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	t.writes = a.WriteCounter()
+	return t, d, nil
+}
+
+// SQLToTransformation inserts the rows of the tables it processes into a SQL table and passes the tables through unchanged.
+// Each table is inserted within its own transaction, which is rolled back if the query is canceled.
+type SQLToTransformation struct {
+	ctx     context.Context
+	d       execute.Dataset
+	cache   execute.TableBuilderCache
+	spec    *SQLToProcedureSpec
+	db      *sql.DB
+	dialect sqlDialect
+	writes  *execute.WriteCounter
+}
+
+func NewSQLToTransformation(ctx context.Context, d execute.Dataset, cache execute.TableBuilderCache, spec *SQLToProcedureSpec) (*SQLToTransformation, error) {
+	dialect, err := getSQLDialect(spec.Spec.DriverName)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(spec.Spec.DriverName, spec.Spec.DataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLToTransformation{
+		ctx:     ctx,
+		d:       d,
+		cache:   cache,
+		spec:    spec,
+		db:      db,
+		dialect: dialect,
+	}, nil
+}
+
+func (t *SQLToTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *SQLToTransformation) Process(id execute.DatasetID, tbl query.Table) error {
+	builder, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return fmt.Errorf("sql.to found duplicate table with key: %v", tbl.Key())
+	}
+	colMap := execute.AddNewCols(tbl, builder)
+
+	cols := tbl.Cols()
+	if len(cols) == 0 {
+		return nil
+	}
+	// Keep the number of bind parameters of each statement within the limit of the database.
+	batchSize := t.spec.Spec.BatchSize
+	if max := t.dialect.maxParams / len(cols); batchSize > max {
+		batchSize = max
+	}
+
+	tx, err := t.db.BeginTx(t.ctx, nil)
+	if err != nil {
+		return err
+	}
+	args := make([]interface{}, 0, batchSize*len(cols))
+	n := 0
+	// written is the size of the statements and their arguments sent to the database.
+	var written int64
+	insert := func() error {
+		if n == 0 {
+			return nil
+		}
+		stmt := t.insertStatement(cols, n)
+		if _, err := tx.ExecContext(t.ctx, stmt, args...); err != nil {
+			return errors.Wrap(err, "failed to insert rows")
+		}
+		written += int64(len(stmt))
+		for _, arg := range args {
+			written += sqlArgSize(arg)
+		}
+		args = args[:0]
+		n = 0
+		return nil
+	}
+	err = tbl.Do(func(cr query.ColReader) error {
+		execute.AppendCols(cr, builder, colMap)
+		for i, l := 0, cr.Len(); i < l; i++ {
+			for j := range cols {
+				args = append(args, sqlArg(i, j, cr))
+			}
+			n++
+			if n == batchSize {
+				if err := insert(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = insert()
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	t.writes.AddBytes(written)
+	return nil
+}
+
+// insertStatement returns a statement that inserts n rows with the given columns.
+func (t *SQLToTransformation) insertStatement(cols []query.ColMeta, n int) string {
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	for i, part := range strings.Split(t.spec.Spec.Table, ".") {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(t.dialect.quote(part))
+	}
+	b.WriteString(" (")
+	for j, c := range cols {
+		if j > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(t.dialect.quote(c.Label))
+	}
+	b.WriteString(") VALUES ")
+	p := 0
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j := range cols {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			p++
+			b.WriteString(t.dialect.placeholder(p))
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// sqlArg returns the value in row i of column j as an argument to a statement.
+func sqlArg(i, j int, cr query.ColReader) interface{} {
+	if cr.IsNull(i, j) {
+		return nil
+	}
+	switch typ := cr.Cols()[j].Type; typ {
+	case query.TBool:
+		return cr.Bools(j)[i]
+	case query.TInt:
+		return cr.Ints(j)[i]
+	case query.TUInt:
+		return cr.UInts(j)[i]
+	case query.TFloat:
+		return cr.Floats(j)[i]
+	case query.TString:
+		return cr.Strings(j)[i]
+	case query.TTime:
+		return cr.Times(j)[i].Time()
+	default:
+		execute.PanicUnknownType(typ)
+		return nil
+	}
+}
+
+// sqlArgSize returns the number of bytes an argument returned by sqlArg occupies.
+// Strings count their length, and other values the size of their fixed width encoding.
+func sqlArgSize(arg interface{}) int64 {
+	switch v := arg.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case string:
+		return int64(len(v))
+	default:
+		return 8
+	}
+}
+
+func (t *SQLToTransformation) UpdateWatermark(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateWatermark(pt)
+}
+
+func (t *SQLToTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *SQLToTransformation) Finish(id execute.DatasetID, err error) {
+	t.db.Close()
+	t.d.Finish(err)
+}
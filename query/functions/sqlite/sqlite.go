@@ -0,0 +1,7 @@
+// Package sqlite registers the SQLite driver, so that sql.from and sql.to can use the sqlite3 driver name.
+// The driver requires cgo, so it is only linked into the binaries that import this package.
+package sqlite
+
+import (
+	_ "github.com/mattn/go-sqlite3" // Register the SQLite driver
+)
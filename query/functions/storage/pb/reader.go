@@ -511,6 +511,11 @@ func (t *table) Times(j int) []execute.Time {
 	return t.colBufs[j].([]execute.Time)
 }
 
+// IsNull always returns false, the storage engine does not produce null values.
+func (t *table) IsNull(i, j int) bool {
+	return false
+}
+
 // readTags populates b.tags with the provided tags
 func (t *table) readTags(tags []Tag) {
 	for j := range t.tags {
@@ -0,0 +1,297 @@
+package functions
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/interpreter"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+)
+
+const stringsPackageName = "strings"
+
+func init() {
+	query.RegisterBuiltInValue(stringsPackageName, newPackage(stringFunctions))
+}
+
+// packageFunction describes the arguments, result and implementation of a function that belongs to
+// a builtin package object such as strings.
+type packageFunction struct {
+	params     map[string]semantic.Type
+	returnType semantic.Type
+	call       func(args interpreter.Arguments) (values.Value, error)
+}
+
+var stringFunctions = map[string]packageFunction{
+	"trimSpace":   unaryStringFunction(strings.TrimSpace),
+	"toUpper":     unaryStringFunction(strings.ToUpper),
+	"toLower":     unaryStringFunction(strings.ToLower),
+	"title":       unaryStringFunction(strings.Title),
+	"trim":        binaryStringFunction("cutset", strings.Trim),
+	"trimLeft":    binaryStringFunction("cutset", strings.TrimLeft),
+	"trimRight":   binaryStringFunction("cutset", strings.TrimRight),
+	"trimPrefix":  binaryStringFunction("prefix", strings.TrimPrefix),
+	"trimSuffix":  binaryStringFunction("suffix", strings.TrimSuffix),
+	"containsStr": stringPredicate("substr", strings.Contains),
+	"containsAny": stringPredicate("chars", strings.ContainsAny),
+	"hasPrefix":   stringPredicate("prefix", strings.HasPrefix),
+	"hasSuffix":   stringPredicate("suffix", strings.HasSuffix),
+	"equalFold":   stringPredicate("t", strings.EqualFold),
+	"index": {
+		params:     map[string]semantic.Type{"v": semantic.String, "substr": semantic.String},
+		returnType: semantic.Int,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			v, substr, err := getStringPair(args, "substr")
+			if err != nil {
+				return nil, err
+			}
+			return values.NewIntValue(int64(strings.Index(v, substr))), nil
+		},
+	},
+	"strlen": {
+		params:     map[string]semantic.Type{"v": semantic.String},
+		returnType: semantic.Int,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			v, err := args.GetRequiredString("v")
+			if err != nil {
+				return nil, err
+			}
+			return values.NewIntValue(int64(utf8.RuneCountInString(v))), nil
+		},
+	},
+	"substring": {
+		params:     map[string]semantic.Type{"v": semantic.String, "start": semantic.Int, "end": semantic.Int},
+		returnType: semantic.String,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			v, err := args.GetRequiredString("v")
+			if err != nil {
+				return nil, err
+			}
+			start, err := args.GetRequiredInt("start")
+			if err != nil {
+				return nil, err
+			}
+			end, err := args.GetRequiredInt("end")
+			if err != nil {
+				return nil, err
+			}
+			// Indexes count characters rather than bytes so that multi-byte characters are never split.
+			runes := []rune(v)
+			if start < 0 || end < start || end > int64(len(runes)) {
+				return nil, fmt.Errorf("substring range [%d:%d] out of bounds for string of length %d", start, end, len(runes))
+			}
+			return values.NewStringValue(string(runes[start:end])), nil
+		},
+	},
+	"split": {
+		params:     map[string]semantic.Type{"v": semantic.String, "t": semantic.String},
+		returnType: semantic.NewArrayType(semantic.String),
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			v, t, err := getStringPair(args, "t")
+			if err != nil {
+				return nil, err
+			}
+			return newStringArray(strings.Split(v, t)), nil
+		},
+	},
+	"join": {
+		params:     map[string]semantic.Type{"arr": semantic.NewArrayType(semantic.String), "v": semantic.String},
+		returnType: semantic.String,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			arr, err := args.GetRequiredArray("arr", semantic.String)
+			if err != nil {
+				return nil, err
+			}
+			sep, err := args.GetRequiredString("v")
+			if err != nil {
+				return nil, err
+			}
+			parts := make([]string, arr.Len())
+			arr.Range(func(i int, v values.Value) {
+				parts[i] = v.Str()
+			})
+			return values.NewStringValue(strings.Join(parts, sep)), nil
+		},
+	},
+	"replace": {
+		params:     map[string]semantic.Type{"v": semantic.String, "t": semantic.String, "u": semantic.String, "i": semantic.Int},
+		returnType: semantic.String,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			v, t, u, err := getReplaceArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			i, err := args.GetRequiredInt("i")
+			if err != nil {
+				return nil, err
+			}
+			return values.NewStringValue(strings.Replace(v, t, u, int(i))), nil
+		},
+	},
+	"replaceAll": {
+		params:     map[string]semantic.Type{"v": semantic.String, "t": semantic.String, "u": semantic.String},
+		returnType: semantic.String,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			v, t, u, err := getReplaceArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewStringValue(strings.Replace(v, t, u, -1)), nil
+		},
+	},
+	"repeat": {
+		params:     map[string]semantic.Type{"v": semantic.String, "i": semantic.Int},
+		returnType: semantic.String,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			v, err := args.GetRequiredString("v")
+			if err != nil {
+				return nil, err
+			}
+			i, err := args.GetRequiredInt("i")
+			if err != nil {
+				return nil, err
+			}
+			if i < 0 {
+				return nil, fmt.Errorf("repeat count must be non-negative, got %d", i)
+			}
+			return values.NewStringValue(strings.Repeat(v, int(i))), nil
+		},
+	},
+	"sprintf": {
+		params: map[string]semantic.Type{
+			"format": semantic.String,
+			// TODO: The values may be of any kind once polymorphic array types are supported.
+			"values": semantic.NewArrayType(semantic.String),
+		},
+		returnType: semantic.String,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			format, err := args.GetRequiredString("format")
+			if err != nil {
+				return nil, err
+			}
+			var a []interface{}
+			if v, ok := args.Get("values"); ok {
+				if v.Type().Kind() != semantic.Array {
+					return nil, fmt.Errorf("keyword argument %q should be of kind %v, but got %v", "values", semantic.Array, v.Type().Kind())
+				}
+				arr := v.Array()
+				a = make([]interface{}, arr.Len())
+				arr.Range(func(i int, v values.Value) {
+					if err == nil {
+						a[i], err = formatOperand(v)
+					}
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+			return values.NewStringValue(fmt.Sprintf(format, a...)), nil
+		},
+	},
+}
+
+// newPackage creates an object that exposes the functions as its properties, i.e. strings.toUpper(v: r._value).
+func newPackage(functions map[string]packageFunction) values.Object {
+	pkg := values.NewObject()
+	for name, f := range functions {
+		pkg.Set(name, newPackageFunction(name, f))
+	}
+	return pkg
+}
+
+func newPackageFunction(name string, f packageFunction) values.Function {
+	ftype := semantic.NewFunctionType(semantic.FunctionSignature{
+		Params:     f.params,
+		ReturnType: f.returnType,
+	})
+	call := func(args values.Object) (values.Value, error) {
+		return f.call(interpreter.NewArguments(args))
+	}
+	return values.NewFunction(name, ftype, call, false)
+}
+
+func unaryStringFunction(f func(string) string) packageFunction {
+	return packageFunction{
+		params:     map[string]semantic.Type{"v": semantic.String},
+		returnType: semantic.String,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			v, err := args.GetRequiredString("v")
+			if err != nil {
+				return nil, err
+			}
+			return values.NewStringValue(f(v)), nil
+		},
+	}
+}
+
+func binaryStringFunction(arg string, f func(string, string) string) packageFunction {
+	return packageFunction{
+		params:     map[string]semantic.Type{"v": semantic.String, arg: semantic.String},
+		returnType: semantic.String,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			v, t, err := getStringPair(args, arg)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewStringValue(f(v, t)), nil
+		},
+	}
+}
+
+func stringPredicate(arg string, f func(string, string) bool) packageFunction {
+	return packageFunction{
+		params:     map[string]semantic.Type{"v": semantic.String, arg: semantic.String},
+		returnType: semantic.Bool,
+		call: func(args interpreter.Arguments) (values.Value, error) {
+			v, t, err := getStringPair(args, arg)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewBoolValue(f(v, t)), nil
+		},
+	}
+}
+
+func getStringPair(args interpreter.Arguments, arg string) (string, string, error) {
+	v, err := args.GetRequiredString("v")
+	if err != nil {
+		return "", "", err
+	}
+	t, err := args.GetRequiredString(arg)
+	if err != nil {
+		return "", "", err
+	}
+	return v, t, nil
+}
+
+func getReplaceArgs(args interpreter.Arguments) (v, t, u string, err error) {
+	if v, t, err = getStringPair(args, "t"); err != nil {
+		return
+	}
+	u, err = args.GetRequiredString("u")
+	return
+}
+
+func formatOperand(v values.Value) (interface{}, error) {
+	switch k := v.Type().Kind(); k {
+	case semantic.String:
+		return v.Str(), nil
+	case semantic.Int:
+		return v.Int(), nil
+	case semantic.UInt:
+		return v.UInt(), nil
+	case semantic.Float:
+		return v.Float(), nil
+	case semantic.Bool:
+		return v.Bool(), nil
+	case semantic.Time:
+		return v.Time().Time(), nil
+	case semantic.Duration:
+		return v.Duration().Duration(), nil
+	default:
+		return nil, fmt.Errorf("cannot format value of kind %v", k)
+	}
+}
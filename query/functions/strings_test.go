@@ -0,0 +1,103 @@
+package functions_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+)
+
+func TestStrings(t *testing.T) {
+	testCases := []struct {
+		name    string
+		script  string
+		want    values.Value
+		wantErr string
+	}{
+		{name: "trimSpace", script: `x = strings.trimSpace(v: "  abc  ")`, want: values.NewStringValue("abc")},
+		{name: "trim", script: `x = strings.trim(v: ".abc..", cutset: ".")`, want: values.NewStringValue("abc")},
+		{name: "trimLeft", script: `x = strings.trimLeft(v: ".abc.", cutset: ".")`, want: values.NewStringValue("abc.")},
+		{name: "trimRight", script: `x = strings.trimRight(v: ".abc.", cutset: ".")`, want: values.NewStringValue(".abc")},
+		{name: "trimPrefix", script: `x = strings.trimPrefix(v: "cpu_total", prefix: "cpu_")`, want: values.NewStringValue("total")},
+		{name: "trimSuffix", script: `x = strings.trimSuffix(v: "cpu_total", suffix: "_total")`, want: values.NewStringValue("cpu")},
+		{name: "toUpper", script: `x = strings.toUpper(v: "abc")`, want: values.NewStringValue("ABC")},
+		{name: "toLower", script: `x = strings.toLower(v: "ABC")`, want: values.NewStringValue("abc")},
+		{name: "title", script: `x = strings.title(v: "a quick fox")`, want: values.NewStringValue("A Quick Fox")},
+		{name: "containsStr", script: `x = strings.containsStr(v: "server01", substr: "ver")`, want: values.NewBoolValue(true)},
+		{name: "containsAny", script: `x = strings.containsAny(v: "server01", chars: "xyz")`, want: values.NewBoolValue(false)},
+		{name: "hasPrefix", script: `x = strings.hasPrefix(v: "server01", prefix: "server")`, want: values.NewBoolValue(true)},
+		{name: "hasSuffix", script: `x = strings.hasSuffix(v: "server01", suffix: "02")`, want: values.NewBoolValue(false)},
+		{name: "equalFold", script: `x = strings.equalFold(v: "Go", t: "GO")`, want: values.NewBoolValue(true)},
+		{name: "index", script: `x = strings.index(v: "chicken", substr: "ken")`, want: values.NewIntValue(4)},
+		{name: "strlen", script: `x = strings.strlen(v: "héllo")`, want: values.NewIntValue(5)},
+		{name: "substring", script: `x = strings.substring(v: "héllo", start: 1, end: 3)`, want: values.NewStringValue("él")},
+		{name: "replace", script: `x = strings.replace(v: "oink oink oink", t: "k", u: "ky", i: 2)`, want: values.NewStringValue("oinky oinky oink")},
+		{name: "replaceAll", script: `x = strings.replaceAll(v: "oink oink oink", t: "oink", u: "moo")`, want: values.NewStringValue("moo moo moo")},
+		{name: "repeat", script: `x = strings.repeat(v: "ab", i: 3)`, want: values.NewStringValue("ababab")},
+		{name: "split join", script: `x = strings.join(arr: strings.split(v: "a,b,c", t: ","), v: "-")`, want: values.NewStringValue("a-b-c")},
+		{name: "sprintf", script: `x = strings.sprintf(format: "%s=%d", values: ["a", "b"])`, want: values.NewStringValue("a=%!d(string=b)")},
+		{name: "sprintf ints", script: `x = strings.sprintf(format: "%03d", values: [7])`, want: values.NewStringValue("007")},
+		{
+			name:    "wrong argument kind",
+			script:  `x = strings.toUpper(v: 1)`,
+			wantErr: `error calling function "toUpper": keyword argument "v" should be of kind string, but got int`,
+		},
+		{
+			name:    "missing argument",
+			script:  `x = strings.trimPrefix(v: "abc")`,
+			wantErr: `error calling function "trimPrefix": missing required keyword argument "prefix"`,
+		},
+		{
+			name:    "wrong array kind",
+			script:  `x = strings.join(arr: [1, 2], v: ",")`,
+			wantErr: `error calling function "join": keyword argument "arr" should be of an array of type string, but got an array of type [int]`,
+		},
+		{
+			name:    "substring out of bounds",
+			script:  `x = strings.substring(v: "abc", start: 2, end: 4)`,
+			wantErr: `error calling function "substring": substring range [2:4] out of bounds for string of length 3`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			itrp := query.NewInterpreter()
+			err := query.Eval(itrp, tc.script)
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error %q", tc.wantErr)
+				}
+				if got := err.Error(); got != tc.wantErr {
+					t.Fatalf("unexpected error -want/+got\n\t- %q\n\t+ %q", tc.wantErr, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := itrp.GlobalScope().Lookup("x")
+			if !ok {
+				t.Fatal("missing result x")
+			}
+			if got.Type().Kind() != tc.want.Type().Kind() || !got.Equal(tc.want) {
+				t.Errorf("unexpected value -want/+got\n\t- %v\n\t+ %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStrings_Types(t *testing.T) {
+	_, decls := query.BuiltIns()
+	dec, ok := decls["strings"]
+	if !ok {
+		t.Fatal("strings package is not declared")
+	}
+	want := semantic.NewFunctionType(semantic.FunctionSignature{
+		Params:     map[string]semantic.Type{"v": semantic.String},
+		ReturnType: semantic.String,
+	})
+	if got := dec.InitType().PropertyType("toUpper"); got != want {
+		t.Errorf("unexpected type for strings.toUpper -want/+got\n\t- %v\n\t+ %v", want, got)
+	}
+}
@@ -0,0 +1,438 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/platform"
+	idpctx "github.com/influxdata/platform/context"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/functions/storage"
+	"github.com/influxdata/platform/query/interpreter"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+	"github.com/pkg/errors"
+)
+
+// ToKind is the Kind for the To Flux function
+const ToKind = "to"
+
+const (
+	measurementColLabel = "_measurement"
+	fieldColLabel       = "_field"
+)
+
+// ToOpSpec is the operation spec for writing tables into a bucket.
+type ToOpSpec struct {
+	Bucket     string                       `json:"bucket"`
+	Org        string                       `json:"org,omitempty"`
+	TimeColumn string                       `json:"timeColumn"`
+	TagColumns []string                     `json:"tagColumns,omitempty"`
+	FieldFn    *semantic.FunctionExpression `json:"fieldFn,omitempty"`
+}
+
+var toSignature = query.DefaultFunctionSignature()
+
+func init() {
+	toSignature.Params["bucket"] = semantic.String
+	toSignature.Params["org"] = semantic.String
+	toSignature.Params["timeColumn"] = semantic.String
+	toSignature.Params["tagColumns"] = semantic.NewArrayType(semantic.String)
+	toSignature.Params["fieldFn"] = semantic.Function
+
+	query.RegisterFunctionWithSideEffect(ToKind, createToOpSpec, toSignature)
+	query.RegisterOpSpec(ToKind, func() query.OperationSpec { return &ToOpSpec{} })
+	plan.RegisterProcedureSpec(ToKind, newToProcedure, ToKind)
+	execute.RegisterTransformation(ToKind, createToTransformation)
+}
+
+// Point is a single point of a series written into a bucket.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	// Fields maps field keys to values of type float64, int64, uint64, string or bool.
+	Fields map[string]interface{}
+	Time   time.Time
+}
+
+// PointsWriter writes points into a bucket.
+type PointsWriter interface {
+	// WritePoints writes the points and returns the number of bytes it sent to the storage engine.
+	WritePoints(ctx context.Context, orgID, bucketID platform.ID, points []Point) (int64, error)
+}
+
+// ToDependencies are the dependencies to() needs to resolve buckets and write points into them.
+type ToDependencies struct {
+	PointsWriter       PointsWriter
+	BucketLookup       storage.BucketLookup
+	OrganizationLookup storage.OrganizationLookup
+}
+
+func (d ToDependencies) Validate() error {
+	if d.PointsWriter == nil {
+		return errors.New("missing points writer dependency")
+	}
+	if d.BucketLookup == nil {
+		return errors.New("missing bucket lookup dependency")
+	}
+	if d.OrganizationLookup == nil {
+		return errors.New("missing organization lookup dependency")
+	}
+	return nil
+}
+
+// InjectToDependencies adds the dependencies of to() to the execution dependencies.
+func InjectToDependencies(depsMap execute.Dependencies, deps ToDependencies) error {
+	if err := deps.Validate(); err != nil {
+		return err
+	}
+	depsMap[ToKind] = deps
+	return nil
+}
+
+// ReadArgs loads a query.Arguments into ToOpSpec.
+// If the timeColumn isn't set, it defaults to execute.DefaultTimeColLabel.
+func (o *ToOpSpec) ReadArgs(args query.Arguments) error {
+	var err error
+	var ok bool
+
+	o.Bucket, err = args.GetRequiredString("bucket")
+	if err != nil {
+		return err
+	}
+	if o.Bucket == "" {
+		return errors.New("invalid bucket name")
+	}
+
+	o.Org, _, err = args.GetString("org")
+	if err != nil {
+		return err
+	}
+
+	o.TimeColumn, ok, err = args.GetString("timeColumn")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		o.TimeColumn = execute.DefaultTimeColLabel
+	}
+
+	tagColumns, ok, err := args.GetArray("tagColumns", semantic.String)
+	if err != nil {
+		return err
+	}
+	o.TagColumns = o.TagColumns[:0]
+	if ok {
+		o.TagColumns, err = interpreter.ToStringArray(tagColumns)
+		if err != nil {
+			return err
+		}
+		sort.Strings(o.TagColumns)
+	}
+
+	if f, ok, err := args.GetFunction("fieldFn"); err != nil {
+		return err
+	} else if ok {
+		fn, err := interpreter.ResolveFunction(f)
+		if err != nil {
+			return err
+		}
+		o.FieldFn = fn
+	}
+	return nil
+}
+
+func createToOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	s := new(ToOpSpec)
+	if err := s.ReadArgs(args); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (ToOpSpec) Kind() query.OperationKind {
+	return ToKind
+}
+
+type ToProcedureSpec struct {
+	Spec *ToOpSpec
+}
+
+func (o *ToProcedureSpec) Kind() plan.ProcedureKind {
+	return ToKind
+}
+
+func (o *ToProcedureSpec) Copy() plan.ProcedureSpec {
+	s := o.Spec
+	res := &ToProcedureSpec{
+		Spec: &ToOpSpec{
+			Bucket:     s.Bucket,
+			Org:        s.Org,
+			TimeColumn: s.TimeColumn,
+			TagColumns: append([]string(nil), s.TagColumns...),
+		},
+	}
+	if s.FieldFn != nil {
+		res.Spec.FieldFn = s.FieldFn.Copy().(*semantic.FunctionExpression)
+	}
+	return res
+}
+
+func newToProcedure(qs query.OperationSpec, a plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*ToOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return &ToProcedureSpec{Spec: spec}, nil
+}
+
+func createToTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*ToProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	deps, ok := a.Dependencies()[ToKind].(ToDependencies)
+	if !ok {
+		return nil, nil, errors.New("to: no points writer has been configured")
+	}
+
+	orgID := a.OrganizationID()
+	if s.Spec.Org != "" {
+		id, ok := deps.OrganizationLookup.Lookup(context.TODO(), s.Spec.Org)
+		if !ok {
+			return nil, nil, fmt.Errorf("could not find organization %q", s.Spec.Org)
+		}
+		orgID = id
+	}
+	bucketID, ok := deps.BucketLookup.Lookup(orgID, s.Spec.Bucket)
+	if !ok {
+		return nil, nil, fmt.Errorf("could not find bucket %q", s.Spec.Bucket)
+	}
+
+	// The query's own token must allow writing to the bucket.
+	auth := a.Authorization()
+	if auth == nil || !platform.Allowed(platform.WriteBucketPermission(bucketID), auth.Permissions) {
+		return nil, nil, fmt.Errorf("not authorized to write to bucket %q", s.Spec.Bucket)
+	}
+
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t, err := NewToTransformation(d, cache, s, deps.PointsWriter, orgID, bucketID)
+	if err != nil {
+		return nil, nil, err
+	}
+	t.ctx = idpctx.SetAuthorization(context.Background(), auth)
+	t.writes = a.WriteCounter()
+	return t, d, nil
+}
+
+// ToTransformation writes the tables it processes into a bucket and passes them through unchanged.
+type ToTransformation struct {
+	d        execute.Dataset
+	cache    execute.TableBuilderCache
+	spec     *ToProcedureSpec
+	fn       *execute.RowMapFn
+	w        PointsWriter
+	orgID    platform.ID
+	bucketID platform.ID
+	ctx      context.Context
+	writes   *execute.WriteCounter
+}
+
+func NewToTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *ToProcedureSpec, w PointsWriter, orgID, bucketID platform.ID) (*ToTransformation, error) {
+	t := &ToTransformation{
+		d:        d,
+		cache:    cache,
+		spec:     spec,
+		w:        w,
+		orgID:    orgID,
+		bucketID: bucketID,
+		ctx:      context.Background(),
+	}
+	if spec.Spec.FieldFn != nil {
+		fn, err := execute.NewRowMapFn(spec.Spec.FieldFn)
+		if err != nil {
+			return nil, err
+		}
+		t.fn = fn
+	}
+	return t, nil
+}
+
+func (t *ToTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *ToTransformation) Process(id execute.DatasetID, tbl query.Table) error {
+	cols := tbl.Cols()
+	timeIdx := execute.ColIdx(t.spec.Spec.TimeColumn, cols)
+	if timeIdx < 0 {
+		return fmt.Errorf("no column with label %s exists", t.spec.Spec.TimeColumn)
+	}
+	if typ := cols[timeIdx].Type; typ != query.TTime {
+		return fmt.Errorf("column %s is not of type %s", t.spec.Spec.TimeColumn, query.TTime)
+	}
+	measurementIdx := execute.ColIdx(measurementColLabel, cols)
+	if measurementIdx < 0 {
+		return fmt.Errorf("no column with label %s exists", measurementColLabel)
+	}
+	if typ := cols[measurementIdx].Type; typ != query.TString {
+		return fmt.Errorf("column %s is not of type %s", measurementColLabel, query.TString)
+	}
+	tagIdxs, err := t.tagColumns(cols)
+	if err != nil {
+		return err
+	}
+
+	fieldIdx, valueIdx := -1, -1
+	if t.fn == nil {
+		fieldIdx = execute.ColIdx(fieldColLabel, cols)
+		if fieldIdx < 0 {
+			return fmt.Errorf("no column with label %s exists", fieldColLabel)
+		}
+		if typ := cols[fieldIdx].Type; typ != query.TString {
+			return fmt.Errorf("column %s is not of type %s", fieldColLabel, query.TString)
+		}
+		valueIdx = execute.ColIdx(execute.DefaultValueColLabel, cols)
+		if valueIdx < 0 {
+			return fmt.Errorf("no column with label %s exists", execute.DefaultValueColLabel)
+		}
+	} else if err := t.fn.Prepare(cols); err != nil {
+		return err
+	}
+
+	builder, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return fmt.Errorf("to found duplicate table with key: %v", tbl.Key())
+	}
+	colMap := execute.AddNewCols(tbl, builder)
+
+	var points []Point
+	err = tbl.Do(func(cr query.ColReader) error {
+		execute.AppendCols(cr, builder, colMap)
+		l := cr.Len()
+		for i := 0; i < l; i++ {
+			p := Point{
+				Measurement: cr.Strings(measurementIdx)[i],
+				Tags:        make(map[string]string, len(tagIdxs)),
+				Time:        cr.Times(timeIdx)[i].Time(),
+			}
+			for _, j := range tagIdxs {
+				p.Tags[cols[j].Label] = cr.Strings(j)[i]
+			}
+			if t.fn == nil {
+				v, err := fieldValue(execute.ValueForRow(i, valueIdx, cr))
+				if err != nil {
+					return errors.Wrapf(err, "column %s", execute.DefaultValueColLabel)
+				}
+				p.Fields = map[string]interface{}{cr.Strings(fieldIdx)[i]: v}
+			} else {
+				obj, err := t.fn.Eval(i, cr)
+				if err != nil {
+					return errors.Wrap(err, "failed to evaluate fieldFn")
+				}
+				p.Fields = make(map[string]interface{}, obj.Len())
+				var fieldErr error
+				obj.Range(func(k string, v values.Value) {
+					if fieldErr != nil {
+						return
+					}
+					fv, err := fieldValue(v)
+					if err != nil {
+						fieldErr = errors.Wrapf(err, "field %s", k)
+						return
+					}
+					p.Fields[k] = fv
+				})
+				if fieldErr != nil {
+					return fieldErr
+				}
+			}
+			if len(p.Fields) == 0 {
+				return errors.New("cannot write a point without fields")
+			}
+			points = append(points, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(points) == 0 {
+		return nil
+	}
+	n, err := t.w.WritePoints(t.ctx, t.orgID, t.bucketID, points)
+	if err != nil {
+		return errors.Wrap(err, "failed to write points")
+	}
+	t.writes.AddPoints(int64(len(points)))
+	t.writes.AddBytes(n)
+	return nil
+}
+
+// tagColumns returns the indexes of the columns written as tags.
+// Unless tag columns are given, every string column other than _measurement, _field and _value is a tag.
+func (t *ToTransformation) tagColumns(cols []query.ColMeta) ([]int, error) {
+	var idxs []int
+	if len(t.spec.Spec.TagColumns) > 0 {
+		for _, label := range t.spec.Spec.TagColumns {
+			j := execute.ColIdx(label, cols)
+			if j < 0 {
+				return nil, fmt.Errorf("no column with label %s exists", label)
+			}
+			if cols[j].Type != query.TString {
+				return nil, fmt.Errorf("tag column %s is not of type %s", label, query.TString)
+			}
+			idxs = append(idxs, j)
+		}
+		return idxs, nil
+	}
+	for j, c := range cols {
+		if c.Type != query.TString {
+			continue
+		}
+		switch c.Label {
+		case measurementColLabel, fieldColLabel, execute.DefaultValueColLabel:
+			continue
+		}
+		idxs = append(idxs, j)
+	}
+	return idxs, nil
+}
+
+// fieldValue converts v into a value that may be written as a field.
+func fieldValue(v values.Value) (interface{}, error) {
+	switch k := v.Type().Kind(); k {
+	case semantic.Float:
+		return v.Float(), nil
+	case semantic.Int:
+		return v.Int(), nil
+	case semantic.UInt:
+		return v.UInt(), nil
+	case semantic.String:
+		return v.Str(), nil
+	case semantic.Bool:
+		return v.Bool(), nil
+	default:
+		return nil, fmt.Errorf("unsupported field type %v", k)
+	}
+}
+
+func (t *ToTransformation) UpdateWatermark(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateWatermark(pt)
+}
+
+func (t *ToTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *ToTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
@@ -249,13 +249,15 @@ func createToHTTPTransformation(id execute.DatasetID, mode execute.AccumulationM
 	cache := execute.NewTableBuilderCache(a.Allocator())
 	d := execute.NewDataset(id, mode, cache)
 	t := NewToHTTPTransformation(d, cache, s)
+	t.writes = a.WriteCounter()
 	return t, d, nil
 }
 
 type ToHTTPTransformation struct {
-	d     execute.Dataset
-	cache execute.TableBuilderCache
-	spec  *ToHTTPProcedureSpec
+	d      execute.Dataset
+	cache  execute.TableBuilderCache
+	spec   *ToHTTPProcedureSpec
+	writes *execute.WriteCounter
 }
 
 func (t *ToHTTPTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
@@ -342,6 +344,7 @@ func (t *ToHTTPTransformation) Process(id execute.DatasetID, tbl query.Table) er
 		isValue[i] = sort.SearchStrings(t.spec.Spec.ValueColumns, col.Label) < len(t.spec.Spec.ValueColumns) && t.spec.Spec.ValueColumns[sort.SearchStrings(t.spec.Spec.ValueColumns, col.Label)] == col.Label
 		isTag[i] = sort.SearchStrings(t.spec.Spec.TagColumns, col.Label) < len(t.spec.Spec.TagColumns) && t.spec.Spec.TagColumns[sort.SearchStrings(t.spec.Spec.TagColumns, col.Label)] == col.Label
 	}
+	var written int64
 	wg := sync.WaitGroup{}
 	var err error
 	wg.Add(1)
@@ -385,10 +388,11 @@ func (t *ToHTTPTransformation) Process(id execute.DatasetID, tbl query.Table) er
 						}
 					}
 				}
-				_, err := e.Encode(m)
+				n, err := e.Encode(m)
 				if err != nil {
 					return err
 				}
+				written += int64(n)
 			}
 			return nil
 		})
@@ -418,6 +422,7 @@ func (t *ToHTTPTransformation) Process(id execute.DatasetID, tbl query.Table) er
 	}
 	wg.Wait()
 	resp.Body.Close()
+	t.writes.AddBytes(written)
 
 	return req.Body.Close()
 }
@@ -212,13 +212,15 @@ func createToKafkaTransformation(id execute.DatasetID, mode execute.Accumulation
 	cache := execute.NewTableBuilderCache(a.Allocator())
 	d := execute.NewDataset(id, mode, cache)
 	t := NewToKafkaTransformation(d, cache, s)
+	t.writes = a.WriteCounter()
 	return t, d, nil
 }
 
 type ToKafkaTransformation struct {
-	d     execute.Dataset
-	cache execute.TableBuilderCache
-	spec  *ToKafkaProcedureSpec
+	d      execute.Dataset
+	cache  execute.TableBuilderCache
+	spec   *ToKafkaProcedureSpec
+	writes *execute.WriteCounter
 }
 
 func (t *ToKafkaTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
@@ -310,6 +312,7 @@ func (t *ToKafkaTransformation) Process(id execute.DatasetID, tbl query.Table) (
 		isTag[i] = sort.SearchStrings(t.spec.Spec.TagColumns, col.Label) < len(t.spec.Spec.TagColumns) && t.spec.Spec.TagColumns[sort.SearchStrings(t.spec.Spec.TagColumns, col.Label)] == col.Label
 	}
 	m.name = t.spec.Spec.Name
+	var written int64
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
@@ -350,10 +353,11 @@ func (t *ToKafkaTransformation) Process(id execute.DatasetID, tbl query.Table) (
 						}
 					}
 				}
-				_, err := e.Encode(m)
+				n, err := e.Encode(m)
 				if err != nil {
 					return err
 				}
+				written += int64(n)
 			}
 			return nil
 		})
@@ -388,6 +392,9 @@ func (t *ToKafkaTransformation) Process(id execute.DatasetID, tbl query.Table) (
 		}
 	}
 	wg.Wait()
+	if err == nil {
+		t.writes.AddBytes(written)
+	}
 	return err
 }
 
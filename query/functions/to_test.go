@@ -0,0 +1,480 @@
+package functions_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/control"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/querytest"
+	"github.com/influxdata/platform/query/semantic"
+)
+
+func TestTo_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "from with defaults",
+			Raw:  `from(db:"mydb") |> to(bucket:"downsampled")`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "to1",
+						Spec: &functions.ToOpSpec{
+							Bucket:     "downsampled",
+							TimeColumn: execute.DefaultTimeColLabel,
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "to1"},
+				},
+			},
+		},
+		{
+			Name: "from with all arguments",
+			Raw:  `from(db:"mydb") |> to(bucket:"downsampled", org:"my-org", timeColumn:"_stop", tagColumns:["region", "host"], fieldFn:(r) => ({max: r._value}))`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "to1",
+						Spec: &functions.ToOpSpec{
+							Bucket:     "downsampled",
+							Org:        "my-org",
+							TimeColumn: execute.DefaultStopColLabel,
+							TagColumns: []string{"host", "region"},
+							FieldFn:    maxFieldFn,
+						},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "to1"},
+				},
+			},
+		},
+		{
+			Name:    "missing bucket",
+			Raw:     `from(db:"mydb") |> to(org:"my-org")`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+// maxFieldFn is the function (r) => ({max: r._value}).
+var maxFieldFn = &semantic.FunctionExpression{
+	Params: []*semantic.FunctionParam{{Key: &semantic.Identifier{Name: "r"}}},
+	Body: &semantic.ObjectExpression{
+		Properties: []*semantic.Property{
+			{
+				Key: &semantic.Identifier{Name: "max"},
+				Value: &semantic.MemberExpression{
+					Object:   &semantic.IdentifierExpression{Name: "r"},
+					Property: "_value",
+				},
+			},
+		},
+	},
+}
+
+// pointsWriterMock is an in-memory PointsWriter that records the points written into each bucket.
+type pointsWriterMock struct {
+	mu     sync.Mutex
+	points map[string][]functions.Point
+}
+
+func newPointsWriterMock() *pointsWriterMock {
+	return &pointsWriterMock{
+		points: make(map[string][]functions.Point),
+	}
+}
+
+// WritePoints records the points, and reports writing pointSize bytes for each of them.
+func (w *pointsWriterMock) WritePoints(_ context.Context, orgID, bucketID platform.ID, points []functions.Point) (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	key := bucketKey(orgID, bucketID)
+	w.points[key] = append(w.points[key], points...)
+	return int64(len(points)) * pointSize, nil
+}
+
+// pointSize is the number of bytes pointsWriterMock reports writing for each point.
+const pointSize = 16
+
+func (w *pointsWriterMock) Points(orgID, bucketID platform.ID) []functions.Point {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.points[bucketKey(orgID, bucketID)]
+}
+
+func bucketKey(orgID, bucketID platform.ID) string {
+	return orgID.String() + "/" + bucketID.String()
+}
+
+func TestTo_Process(t *testing.T) {
+	orgID, bucketID := platform.ID("org"), platform.ID("bucket")
+	testCases := []struct {
+		name    string
+		spec    *functions.ToProcedureSpec
+		data    []query.Table
+		want    []functions.Point
+		wantErr error
+	}{
+		{
+			name: "default fields and tags",
+			spec: &functions.ToProcedureSpec{
+				Spec: &functions.ToOpSpec{
+					Bucket:     "downsampled",
+					TimeColumn: execute.DefaultTimeColLabel,
+				},
+			},
+			data: []query.Table{&executetest.Table{
+				KeyCols: []string{"_measurement", "_field", "host"},
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_measurement", Type: query.TString},
+					{Label: "_field", Type: query.TString},
+					{Label: "_value", Type: query.TFloat},
+					{Label: "host", Type: query.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(11), "cpu", "usage_user", 2.0, "a"},
+					{execute.Time(21), "cpu", "usage_user", 1.5, "a"},
+				},
+			}},
+			want: []functions.Point{
+				{
+					Measurement: "cpu",
+					Tags:        map[string]string{"host": "a"},
+					Fields:      map[string]interface{}{"usage_user": 2.0},
+					Time:        time.Unix(0, 11).UTC(),
+				},
+				{
+					Measurement: "cpu",
+					Tags:        map[string]string{"host": "a"},
+					Fields:      map[string]interface{}{"usage_user": 1.5},
+					Time:        time.Unix(0, 21).UTC(),
+				},
+			},
+		},
+		{
+			name: "tag columns and time column",
+			spec: &functions.ToProcedureSpec{
+				Spec: &functions.ToOpSpec{
+					Bucket:     "downsampled",
+					TimeColumn: execute.DefaultStopColLabel,
+					TagColumns: []string{"region"},
+				},
+			},
+			data: []query.Table{&executetest.Table{
+				KeyCols: []string{"_stop", "_measurement", "_field", "region"},
+				ColMeta: []query.ColMeta{
+					{Label: "_stop", Type: query.TTime},
+					{Label: "_measurement", Type: query.TString},
+					{Label: "_field", Type: query.TString},
+					{Label: "_value", Type: query.TInt},
+					{Label: "host", Type: query.TString},
+					{Label: "region", Type: query.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(100), "mem", "used", int64(64), "a", "east"},
+					{execute.Time(100), "mem", "used", int64(32), "b", "east"},
+				},
+			}},
+			want: []functions.Point{
+				{
+					Measurement: "mem",
+					Tags:        map[string]string{"region": "east"},
+					Fields:      map[string]interface{}{"used": int64(64)},
+					Time:        time.Unix(0, 100).UTC(),
+				},
+				{
+					Measurement: "mem",
+					Tags:        map[string]string{"region": "east"},
+					Fields:      map[string]interface{}{"used": int64(32)},
+					Time:        time.Unix(0, 100).UTC(),
+				},
+			},
+		},
+		{
+			name: "field function",
+			spec: &functions.ToProcedureSpec{
+				Spec: &functions.ToOpSpec{
+					Bucket:     "downsampled",
+					TimeColumn: execute.DefaultTimeColLabel,
+					FieldFn: &semantic.FunctionExpression{
+						Params: []*semantic.FunctionParam{{Key: &semantic.Identifier{Name: "r"}}},
+						Body: &semantic.ObjectExpression{
+							Properties: []*semantic.Property{
+								{
+									Key: &semantic.Identifier{Name: "max"},
+									Value: &semantic.MemberExpression{
+										Object:   &semantic.IdentifierExpression{Name: "r"},
+										Property: "_value",
+									},
+								},
+								{
+									Key: &semantic.Identifier{Name: "count"},
+									Value: &semantic.MemberExpression{
+										Object:   &semantic.IdentifierExpression{Name: "r"},
+										Property: "n",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			data: []query.Table{&executetest.Table{
+				KeyCols: []string{"_measurement", "host"},
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_measurement", Type: query.TString},
+					{Label: "_value", Type: query.TFloat},
+					{Label: "n", Type: query.TInt},
+					{Label: "host", Type: query.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(11), "cpu", 80.0, int64(6), "a"},
+				},
+			}},
+			want: []functions.Point{
+				{
+					Measurement: "cpu",
+					Tags:        map[string]string{"host": "a"},
+					Fields:      map[string]interface{}{"max": 80.0, "count": int64(6)},
+					Time:        time.Unix(0, 11).UTC(),
+				},
+			},
+		},
+		{
+			name: "missing measurement",
+			spec: &functions.ToProcedureSpec{
+				Spec: &functions.ToOpSpec{
+					Bucket:     "downsampled",
+					TimeColumn: execute.DefaultTimeColLabel,
+				},
+			},
+			data: []query.Table{&executetest.Table{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_field", Type: query.TString},
+					{Label: "_value", Type: query.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(11), "usage_user", 2.0},
+				},
+			}},
+			wantErr: errors.New("no column with label _measurement exists"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			w := newPointsWriterMock()
+			// Processed tables are passed through unchanged.
+			var want []*executetest.Table
+			if tc.wantErr == nil {
+				for _, tbl := range tc.data {
+					want = append(want, tbl.(*executetest.Table))
+				}
+			}
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					tx, err := functions.NewToTransformation(d, c, tc.spec, w, orgID, bucketID)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return tx
+				},
+			)
+			if got := w.Points(orgID, bucketID); !cmp.Equal(tc.want, got) {
+				t.Errorf("unexpected points -want/+got\n%s", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
+
+type staticBucketLookup map[string]platform.ID
+
+func (l staticBucketLookup) Lookup(orgID platform.ID, name string) (platform.ID, bool) {
+	id, ok := l[orgID.String()+"/"+name]
+	return id, ok
+}
+
+type staticOrganizationLookup map[string]platform.ID
+
+func (l staticOrganizationLookup) Lookup(ctx context.Context, name string) (platform.ID, bool) {
+	id, ok := l[name]
+	return id, ok
+}
+
+func TestTo_Query(t *testing.T) {
+	orgID, otherOrgID := platform.ID("org"), platform.ID("other")
+	bucketID, otherBucketID := platform.ID("downsampled"), platform.ID("archive")
+
+	w := newPointsWriterMock()
+	deps := make(execute.Dependencies)
+	if err := functions.InjectToDependencies(deps, functions.ToDependencies{
+		PointsWriter: w,
+		BucketLookup: staticBucketLookup{
+			orgID.String() + "/downsampled":  bucketID,
+			otherOrgID.String() + "/archive": otherBucketID,
+		},
+		OrganizationLookup: staticOrganizationLookup{"other": otherOrgID},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	c := control.New(control.Config{
+		ConcurrencyQuota:     1,
+		MemoryBytesQuota:     math.MaxInt64,
+		ExecutorDependencies: deps,
+	})
+
+	f, err := ioutil.TempFile("", "to_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(`#datatype,string,long,dateTime:RFC3339,string,string,string,double
+#group,false,false,false,true,true,true,false
+#default,_result,,,,,,
+,result,table,_time,_measurement,_field,host,_value
+,,0,2018-05-22T19:53:26Z,cpu,usage_user,a,1.5
+,,0,2018-05-22T19:53:36Z,cpu,usage_user,a,2.5
+`); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name        string
+		to          string
+		permissions []platform.Permission
+		noAuth      bool
+		wantBucket  platform.ID
+		wantOrg     platform.ID
+		wantErr     string
+	}{
+		{
+			name:        "write",
+			to:          `to(bucket: "downsampled")`,
+			permissions: []platform.Permission{platform.WriteBucketPermission(bucketID)},
+			wantOrg:     orgID,
+			wantBucket:  bucketID,
+		},
+		{
+			name:        "write to other organization",
+			to:          `to(bucket: "archive", org: "other")`,
+			permissions: []platform.Permission{platform.WriteBucketPermission(otherBucketID)},
+			wantOrg:     otherOrgID,
+			wantBucket:  otherBucketID,
+		},
+		{
+			name:        "read permission only",
+			to:          `to(bucket: "downsampled")`,
+			permissions: []platform.Permission{platform.ReadBucketPermission(bucketID)},
+			wantErr:     `not authorized to write to bucket "downsampled"`,
+		},
+		{
+			name:    "no authorization",
+			to:      `to(bucket: "downsampled")`,
+			noAuth:  true,
+			wantErr: `not authorized to write to bucket "downsampled"`,
+		},
+		{
+			name:        "unknown bucket",
+			to:          `to(bucket: "missing")`,
+			permissions: []platform.Permission{platform.WriteBucketPermission(bucketID)},
+			wantErr:     `could not find bucket "missing"`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := &query.Request{
+				OrganizationID: orgID,
+				Compiler: querytest.FromCSVCompiler{
+					Compiler: query.FluxCompiler{
+						Query: `from(bucket: "telegraf") |> range(start: 2018-05-22T19:53:00Z) |> ` + tc.to,
+					},
+					InputFile: f.Name(),
+				},
+			}
+			if !tc.noAuth {
+				req.Authorization = &platform.Authorization{Permissions: tc.permissions}
+			}
+			q, err := c.Query(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := <-q.Ready()
+			for _, r := range results {
+				if err := r.Tables().Do(func(tbl query.Table) error {
+					return tbl.Do(func(query.ColReader) error { return nil })
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			q.Done()
+			err = q.Err()
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error %q", tc.wantErr)
+				}
+				if got := err.Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("unexpected error: got %q, want %q", got, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(w.Points(tc.wantOrg, tc.wantBucket)); got != 2 {
+				t.Errorf("unexpected number of points written: got %d, want 2", got)
+			}
+			if got := q.Statistics().PointsWritten; got != 2 {
+				t.Errorf("unexpected points written statistic: got %d, want 2", got)
+			}
+			if got := q.Statistics().BytesWritten; got != 2*pointSize {
+				t.Errorf("unexpected bytes written statistic: got %d, want %d", got, 2*pointSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
+)
+
+const UnionKind = "union"
+
+// UnionOpSpec concatenates the tables of its parents.
+// The parents are the table objects of the tables argument.
+type UnionOpSpec struct{}
+
+var unionSignature = semantic.FunctionSignature{
+	Params: map[string]semantic.Type{
+		"tables": semantic.NewArrayType(query.TableObjectType),
+	},
+	ReturnType: query.TableObjectType,
+}
+
+func init() {
+	query.RegisterFunction(UnionKind, createUnionOpSpec, unionSignature)
+	query.RegisterOpSpec(UnionKind, newUnionOp)
+	plan.RegisterProcedureSpec(UnionKind, newUnionProcedure, UnionKind)
+	execute.RegisterTransformation(UnionKind, createUnionTransformation)
+}
+
+func createUnionOpSpec(args query.Arguments, a *query.Administration) (query.OperationSpec, error) {
+	v, err := args.GetRequired("tables")
+	if err != nil {
+		return nil, err
+	}
+	if k := v.Type().Kind(); k != semantic.Array {
+		return nil, fmt.Errorf("tables must be an array of table objects, got %v", k)
+	}
+	tables := v.Array()
+	if tables.Len() < 2 {
+		return nil, errors.New("union requires at least two tables")
+	}
+	tables.Range(func(i int, t values.Value) {
+		if err != nil {
+			return
+		}
+		p, ok := t.(*query.TableObject)
+		if !ok {
+			err = fmt.Errorf("value at index %d in tables must be a table object: got %v", i, t.Type())
+			return
+		}
+		a.AddParent(p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return new(UnionOpSpec), nil
+}
+
+func newUnionOp() query.OperationSpec {
+	return new(UnionOpSpec)
+}
+
+func (s *UnionOpSpec) Kind() query.OperationKind {
+	return UnionKind
+}
+
+type UnionProcedureSpec struct{}
+
+func newUnionProcedure(qs query.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	if _, ok := qs.(*UnionOpSpec); !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return new(UnionProcedureSpec), nil
+}
+
+func (s *UnionProcedureSpec) Kind() plan.ProcedureKind {
+	return UnionKind
+}
+func (s *UnionProcedureSpec) Copy() plan.ProcedureSpec {
+	return new(UnionProcedureSpec)
+}
+
+func createUnionTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*UnionProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewUnionTransformation(d, cache, s, a.Parents())
+	return t, d, nil
+}
+
+type unionTransformation struct {
+	mu sync.Mutex
+
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	parentState map[execute.DatasetID]*unionParentState
+}
+
+type unionParentState struct {
+	mark       execute.Time
+	processing execute.Time
+	finished   bool
+}
+
+func NewUnionTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *UnionProcedureSpec, parents []execute.DatasetID) *unionTransformation {
+	t := &unionTransformation{
+		d:           d,
+		cache:       cache,
+		parentState: make(map[execute.DatasetID]*unionParentState, len(parents)),
+	}
+	for _, id := range parents {
+		t.parentState[id] = new(unionParentState)
+	}
+	return t
+}
+
+func (t *unionTransformation) RetractTable(id execute.DatasetID, key query.GroupKey) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.d.RetractTable(key)
+}
+
+// Process appends the records of the table to the output table with the same group key.
+// Columns missing from either the table or the previous records are filled with nulls.
+func (t *unionTransformation) Process(id execute.DatasetID, tbl query.Table) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	builder, _ := t.cache.TableBuilder(tbl.Key())
+
+	cols := tbl.Cols()
+	colMap := make([]int, len(cols))
+	for j, c := range cols {
+		bj := execute.ColIdx(c.Label, builder.Cols())
+		if bj < 0 {
+			bj = builder.AddCol(c)
+			for i, n := 0, builder.NRows(); i < n; i++ {
+				builder.AppendNil(bj)
+			}
+		} else if typ := builder.Cols()[bj].Type; typ != c.Type {
+			return fmt.Errorf("union error: column %q has both %v and %v values", c.Label, typ, c.Type)
+		}
+		colMap[j] = bj
+	}
+	// missing are the builder columns that the table does not have.
+	var missing []int
+	for bj, c := range builder.Cols() {
+		if !execute.HasCol(c.Label, cols) {
+			missing = append(missing, bj)
+		}
+	}
+
+	return tbl.Do(func(cr query.ColReader) error {
+		for j := range cols {
+			execute.AppendCol(colMap[j], j, cr, builder)
+		}
+		for _, bj := range missing {
+			for i, l := 0, cr.Len(); i < l; i++ {
+				builder.AppendNil(bj)
+			}
+		}
+		return nil
+	})
+}
+
+func (t *unionTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.parentState[id].mark = mark
+
+	min := execute.Time(math.MaxInt64)
+	for _, state := range t.parentState {
+		if state.mark < min {
+			min = state.mark
+		}
+	}
+	return t.d.UpdateWatermark(min)
+}
+
+func (t *unionTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.parentState[id].processing = pt
+
+	min := execute.Time(math.MaxInt64)
+	for _, state := range t.parentState {
+		if state.processing < min {
+			min = state.processing
+		}
+	}
+	return t.d.UpdateProcessingTime(min)
+}
+
+func (t *unionTransformation) Finish(id execute.DatasetID, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		t.d.Finish(err)
+		return
+	}
+
+	t.parentState[id].finished = true
+	for _, state := range t.parentState {
+		if !state.finished {
+			return
+		}
+	}
+	t.d.Finish(nil)
+}
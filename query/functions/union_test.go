@@ -0,0 +1,251 @@
+package functions_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/execute/executetest"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/querytest"
+)
+
+func TestUnion_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name: "union of three streams",
+			Raw: `
+a = from(db:"dbA")
+b = from(db:"dbB")
+c = from(db:"dbC")
+union(tables:[a, b, c])`,
+			Want: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "from0",
+						Spec: &functions.FromOpSpec{
+							Database: "dbA",
+						},
+					},
+					{
+						ID: "from1",
+						Spec: &functions.FromOpSpec{
+							Database: "dbB",
+						},
+					},
+					{
+						ID: "from2",
+						Spec: &functions.FromOpSpec{
+							Database: "dbC",
+						},
+					},
+					{
+						ID:   "union3",
+						Spec: &functions.UnionOpSpec{},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "from0", Child: "union3"},
+					{Parent: "from1", Child: "union3"},
+					{Parent: "from2", Child: "union3"},
+				},
+			},
+		},
+		{
+			Name:    "single table",
+			Raw:     `union(tables:[from(db:"dbA")])`,
+			WantErr: true,
+		},
+		{
+			Name:    "not tables",
+			Raw:     `union(tables:[1, 2])`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestUnionOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"union","kind":"union","spec":{}}`)
+	op := &query.Operation{
+		ID:   "union",
+		Spec: &functions.UnionOpSpec{},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestUnion_Process(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    []query.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "same schema",
+			data: []query.Table{
+				&executetest.Table{
+					KeyCols: []string{"host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0, "a"},
+					},
+				},
+				&executetest.Table{
+					KeyCols: []string{"host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(2), 2.0, "a"},
+					},
+				},
+				&executetest.Table{
+					KeyCols: []string{"host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(3), 3.0, "b"},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0, "a"},
+						{execute.Time(2), 2.0, "a"},
+					},
+				},
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+						{Label: "host", Type: query.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(3), 3.0, "b"},
+					},
+				},
+			},
+		},
+		{
+			name: "different schemas",
+			data: []query.Table{
+				&executetest.Table{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "_value", Type: query.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 1.0},
+					},
+				},
+				&executetest.Table{
+					ColMeta: []query.ColMeta{
+						{Label: "_time", Type: query.TTime},
+						{Label: "count", Type: query.TInt},
+					},
+					Data: [][]interface{}{
+						{execute.Time(2), int64(2)},
+					},
+				},
+			},
+			want: []*executetest.Table{{
+				ColMeta: []query.ColMeta{
+					{Label: "_time", Type: query.TTime},
+					{Label: "_value", Type: query.TFloat},
+					{Label: "count", Type: query.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0, nil},
+					{execute.Time(2), nil, int64(2)},
+				},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return functions.NewUnionTransformation(d, c, &functions.UnionProcedureSpec{}, nil)
+				},
+			)
+		})
+	}
+}
+
+func TestUnion_Process_ConflictingTypes(t *testing.T) {
+	d := executetest.NewDataset(executetest.RandomDatasetID())
+	c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+	c.SetTriggerSpec(execute.DefaultTriggerSpec)
+	parents := []execute.DatasetID{executetest.RandomDatasetID(), executetest.RandomDatasetID()}
+	tx := functions.NewUnionTransformation(d, c, &functions.UnionProcedureSpec{}, parents)
+
+	if err := tx.Process(parents[0], &executetest.Table{
+		ColMeta: []query.ColMeta{
+			{Label: "_time", Type: query.TTime},
+			{Label: "_value", Type: query.TFloat},
+		},
+		Data: [][]interface{}{
+			{execute.Time(1), 1.0},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := tx.Process(parents[1], &executetest.Table{
+		ColMeta: []query.ColMeta{
+			{Label: "_time", Type: query.TTime},
+			{Label: "_value", Type: query.TString},
+		},
+		Data: [][]interface{}{
+			{execute.Time(2), "a"},
+		},
+	})
+	if want := `union error: column "_value" has both float and string values`; err == nil || err.Error() != want {
+		t.Fatalf("unexpected error: want %q, got %v", want, err)
+	}
+}
+
+func TestUnion_Finish(t *testing.T) {
+	d := executetest.NewDataset(executetest.RandomDatasetID())
+	c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+	parents := []execute.DatasetID{executetest.RandomDatasetID(), executetest.RandomDatasetID()}
+	tx := functions.NewUnionTransformation(d, c, &functions.UnionProcedureSpec{}, parents)
+
+	tx.Finish(parents[0], nil)
+	if d.Finished {
+		t.Fatal("finished before all parents finished")
+	}
+	tx.Finish(parents[1], nil)
+	if !d.Finished {
+		t.Fatal("not finished after all parents finished")
+	}
+}
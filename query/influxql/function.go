@@ -3,6 +3,7 @@ package influxql
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/influxdata/influxql"
 	"github.com/influxdata/platform/query"
@@ -94,12 +95,49 @@ func parseFunction(expr *influxql.Call) (*function, error) {
 			Ref:  functionRef,
 			call: expr,
 		}, nil
+	case "holt_winters", "holt_winters_with_fit":
+		if exp, got := 3, len(expr.Args); exp != got {
+			return nil, fmt.Errorf("invalid number of arguments for %s, expected %d, got %d", expr.Name, exp, got)
+		}
+
+		if n, ok := expr.Args[1].(*influxql.IntegerLiteral); !ok {
+			return nil, fmt.Errorf("expected integer argument as second arg in %s", expr.Name)
+		} else if n.Val <= 0 {
+			return nil, fmt.Errorf("second arg to %s must be greater than 0, got %d", expr.Name, n.Val)
+		}
+		if s, ok := expr.Args[2].(*influxql.IntegerLiteral); !ok {
+			return nil, fmt.Errorf("expected integer argument as third arg in %s", expr.Name)
+		} else if s.Val < 0 {
+			return nil, fmt.Errorf("third arg to %s cannot be negative, got %d", expr.Name, s.Val)
+		}
+
+		call, ok := expr.Args[0].(*influxql.Call)
+		if !ok {
+			return nil, fmt.Errorf("must use aggregate function with %s", expr.Name)
+		}
+		fn, err := parseFunction(call)
+		if err != nil {
+			return nil, err
+		}
+		return &function{
+			Ref:  fn.Ref,
+			call: expr,
+		}, nil
 	default:
 		return nil, fmt.Errorf("unimplemented function: %q", expr.Name)
 	}
 
 }
 
+// isTransformation reports whether the call transforms the result of the aggregate call in its first argument.
+func isTransformation(call *influxql.Call) bool {
+	switch call.Name {
+	case "holt_winters", "holt_winters_with_fit":
+		return true
+	}
+	return false
+}
+
 // createFunctionCursor creates a new cursor that calls a function on one of the columns
 // and returns the result.
 func createFunctionCursor(t *transpilerState, call *influxql.Call, in cursor) (cursor, error) {
@@ -240,6 +278,39 @@ func createFunctionCursor(t *transpilerState, call *influxql.Call, in cursor) (c
 	return cur, nil
 }
 
+// createTransformationCursor creates a new cursor that applies a transformation to the result
+// of the aggregate cursor given as input.
+func createTransformationCursor(t *transpilerState, call *influxql.Call, in cursor, interval time.Duration) (cursor, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("%s aggregate requires a GROUP BY interval", call.Name)
+	}
+	aggregate := call.Args[0].(*influxql.Call)
+	value, ok := in.Value(aggregate)
+	if !ok {
+		return nil, fmt.Errorf("undefined variable: %s", aggregate)
+	}
+	cur := &functionCursor{
+		call:    call,
+		value:   value,
+		exclude: map[influxql.Expr]struct{}{aggregate: {}},
+		parent:  in,
+	}
+	switch call.Name {
+	case "holt_winters", "holt_winters_with_fit":
+		cur.id = t.op("holtWinters", &functions.HoltWintersOpSpec{
+			N:           call.Args[1].(*influxql.IntegerLiteral).Val,
+			Seasonality: call.Args[2].(*influxql.IntegerLiteral).Val,
+			Interval:    query.Duration(interval),
+			WithFit:     call.Name == "holt_winters_with_fit",
+			TimeColumn:  execute.DefaultTimeColLabel,
+			Column:      value,
+		}, in.ID())
+	default:
+		return nil, fmt.Errorf("unimplemented function: %q", call.Name)
+	}
+	return cur, nil
+}
+
 type functionCursor struct {
 	id      query.OperationID
 	call    *influxql.Call
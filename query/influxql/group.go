@@ -33,7 +33,14 @@ func (v *groupVisitor) Visit(n influxql.Node) influxql.Visitor {
 	// TODO(jsternberg): Identify duplicates so they are a single common instance.
 	switch expr := n.(type) {
 	case *influxql.Call:
-		// TODO(jsternberg): Identify math functions so we visit their arguments instead of recording them.
+		if isMathFunction(expr) {
+			// Math functions are evaluated by the final map so visit their arguments instead of recording them.
+			if err := validateMathFunction(expr); err != nil {
+				v.err = err
+				return nil
+			}
+			return v
+		}
 		fn, err := parseFunction(expr)
 		if err != nil {
 			v.err = err
@@ -63,9 +70,14 @@ func (v *groupVisitor) Visit(n influxql.Node) influxql.Visitor {
 // identifyGroups will identify the groups for creating data access cursors.
 func identifyGroups(stmt *influxql.SelectStatement) ([]*groupInfo, error) {
 	v := &groupVisitor{}
-	influxql.Walk(v, stmt.Fields)
-	if v.err != nil {
-		return nil, v.err
+	for _, f := range stmt.Fields {
+		n := len(v.refs) + len(v.calls)
+		influxql.Walk(v, f.Expr)
+		if v.err != nil {
+			return nil, v.err
+		} else if len(v.refs)+len(v.calls) == n && containsMathFunction(f.Expr) {
+			return nil, errors.New("field must contain at least one variable")
+		}
 	}
 
 	// Attempt to take the calls and variables and put them into groups.
@@ -106,11 +118,17 @@ func (gr *groupInfo) createCursor(t *transpilerState) (cursor, error) {
 	// Create all of the cursors for every variable reference.
 	// TODO(jsternberg): Determine which of these cursors are from fields and which are tags.
 	var cursors []cursor
-	if gr.call != nil {
-		ref, ok := gr.call.Args[0].(*influxql.VarRef)
+
+	// A transformation is applied to the result of the aggregate call in its first argument.
+	call := gr.call
+	if call != nil && isTransformation(call) {
+		call = call.Args[0].(*influxql.Call)
+	}
+	if call != nil {
+		ref, ok := call.Args[0].(*influxql.VarRef)
 		if !ok {
 			// TODO(jsternberg): This should be validated and figured out somewhere else.
-			return nil, fmt.Errorf("first argument to %q must be a variable", gr.call.Name)
+			return nil, fmt.Errorf("first argument to %q must be a variable", call.Name)
 		}
 		cur, err := createVarRefCursor(t, ref)
 		if err != nil {
@@ -219,8 +237,8 @@ func (gr *groupInfo) createCursor(t *transpilerState) (cursor, error) {
 	}
 
 	// If a function call is present, evaluate the function call.
-	if gr.call != nil {
-		c, err := createFunctionCursor(t, gr.call, cur)
+	if call != nil {
+		c, err := createFunctionCursor(t, call, cur)
 		if err != nil {
 			return nil, err
 		}
@@ -241,6 +259,15 @@ func (gr *groupInfo) createCursor(t *transpilerState) (cursor, error) {
 				cursor: cur,
 			}
 		}
+
+		// The transformation is applied once the windows have been merged.
+		if call != gr.call {
+			c, err := createTransformationCursor(t, gr.call, cur, interval)
+			if err != nil {
+				return nil, err
+			}
+			cur = c
+		}
 	} else {
 		// If we do not have a function, but we have a field option,
 		// return the appropriate error message if there is something wrong with the query.
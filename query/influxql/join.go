@@ -64,6 +64,8 @@ func Join(t *transpilerState, cursors []cursor, on, except []string) cursor {
 		parents = append(parents, cur.ID())
 	}
 	id := t.op("join", &functions.JoinOpSpec{
+		Method:     functions.JoinMethodInner,
+		Fill:       functions.JoinFillNull,
 		TableNames: tables,
 		Fn: &semantic.FunctionExpression{
 			Params: []*semantic.FunctionParam{{
@@ -94,7 +94,7 @@ func (t *transpilerState) mapField(expr influxql.Expr, in cursor) (semantic.Expr
 	switch expr := expr.(type) {
 	case *influxql.Call:
 		if isMathFunction(expr) {
-			return nil, fmt.Errorf("unimplemented math function: %q", expr.Name)
+			return t.mapMathFunction(expr, in)
 		}
 		return nil, fmt.Errorf("missing symbol for %s", expr)
 	case *influxql.VarRef:
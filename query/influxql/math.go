@@ -1,6 +1,12 @@
 package influxql
 
-import "github.com/influxdata/influxql"
+import (
+	"fmt"
+
+	"github.com/influxdata/influxql"
+	"github.com/influxdata/platform/query/ast"
+	"github.com/influxdata/platform/query/semantic"
+)
 
 // isMathFunction returns true if the call is a math function.
 func isMathFunction(expr *influxql.Call) bool {
@@ -10,3 +16,78 @@ func isMathFunction(expr *influxql.Call) bool {
 	}
 	return false
 }
+
+// containsMathFunction returns true if a math function is used anywhere within the expression.
+func containsMathFunction(expr influxql.Expr) bool {
+	found := false
+	influxql.WalkFunc(expr, func(n influxql.Node) {
+		if call, ok := n.(*influxql.Call); ok && isMathFunction(call) {
+			found = true
+		}
+	})
+	return found
+}
+
+// validateMathFunction verifies the number of arguments passed to a math function.
+func validateMathFunction(expr *influxql.Call) error {
+	exp := 1
+	switch expr.Name {
+	case "atan2", "pow", "log":
+		exp = 2
+	}
+	if got := len(expr.Args); exp != got {
+		return fmt.Errorf("invalid number of arguments for %s, expected %d, got %d", expr.Name, exp, got)
+	}
+	return nil
+}
+
+// mapMathFunction converts a math function into a call to the equivalent function in the flux math package.
+func (t *transpilerState) mapMathFunction(expr *influxql.Call, in cursor) (semantic.Expression, error) {
+	args := make([]semantic.Expression, len(expr.Args))
+	for i, arg := range expr.Args {
+		v, err := t.mapField(arg, in)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	switch expr.Name {
+	case "ln":
+		return mathCall("log", mathArg("x", args[0])), nil
+	case "log":
+		// The logarithm with an arbitrary base is computed by changing the base to e.
+		return &semantic.BinaryExpression{
+			Operator: ast.DivisionOperator,
+			Left:     mathCall("log", mathArg("x", args[0])),
+			Right:    mathCall("log", mathArg("x", args[1])),
+		}, nil
+	case "pow":
+		return mathCall("pow", mathArg("x", args[0]), mathArg("y", args[1])), nil
+	case "atan2":
+		return mathCall("atan2", mathArg("y", args[0]), mathArg("x", args[1])), nil
+	default:
+		return mathCall(expr.Name, mathArg("x", args[0])), nil
+	}
+}
+
+// mathCall creates a call to the named function in the flux math package.
+func mathCall(name string, args ...*semantic.Property) *semantic.CallExpression {
+	return &semantic.CallExpression{
+		Callee: &semantic.MemberExpression{
+			Object:   &semantic.IdentifierExpression{Name: "math"},
+			Property: name,
+		},
+		Arguments: &semantic.ObjectExpression{
+			Properties: args,
+		},
+	}
+}
+
+// mathArg creates a keyword argument for a call to a math function.
+func mathArg(key string, value semantic.Expression) *semantic.Property {
+	return &semantic.Property{
+		Key:   &semantic.Identifier{Name: key},
+		Value: value,
+	}
+}
@@ -126,7 +126,9 @@ func init() {
 					{
 						ID: "join0",
 						Spec: &functions.JoinOpSpec{
-							On: []string{"_measurement"},
+							On:     []string{"_measurement"},
+							Method: functions.JoinMethodInner,
+							Fill:   functions.JoinFillNull,
 							Fn: &semantic.FunctionExpression{
 								Params: []*semantic.FunctionParam{{
 									Key: &semantic.Identifier{Name: "tables"},
@@ -171,6 +171,9 @@ func (itrp *Interpreter) doExpression(expr semantic.Expression, scope *Scope) (v
 	case *semantic.ObjectExpression:
 		return itrp.doObject(e, scope)
 	case *semantic.UnaryExpression:
+		if e.Operator == ast.ExistsOperator {
+			return itrp.doExists(e.Argument, scope)
+		}
 		v, err := itrp.doExpression(e.Argument, scope)
 		if err != nil {
 			return nil, err
@@ -261,6 +264,26 @@ func (itrp *Interpreter) doExpression(expr semantic.Expression, scope *Scope) (v
 	}
 }
 
+// doExists reports whether the value of the expression exists.
+// Only object properties can be missing, any other expression exists if it can be evaluated.
+func (itrp *Interpreter) doExists(expr semantic.Expression, scope *Scope) (values.Value, error) {
+	if m, ok := expr.(*semantic.MemberExpression); ok {
+		obj, err := itrp.doExpression(m.Object, scope)
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := obj.Object().(values.NullableObject); ok {
+			return values.NewBoolValue(n.Exists(m.Property)), nil
+		}
+		_, ok := obj.Object().Get(m.Property)
+		return values.NewBoolValue(ok), nil
+	}
+	if _, err := itrp.doExpression(expr, scope); err != nil {
+		return nil, err
+	}
+	return values.NewBoolValue(true), nil
+}
+
 func (itrp *Interpreter) doArray(a *semantic.ArrayExpression, scope *Scope) (values.Value, error) {
 	elements := make([]values.Value, len(a.Elements))
 	elementType := semantic.EmptyArrayType.ElementType()
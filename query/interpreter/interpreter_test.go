@@ -240,6 +240,15 @@ func TestEval(t *testing.T) {
             not m.b or fail()
 			`,
 		},
+		{
+			name: "exists",
+			query: `
+            m = {a: 1}
+            exists m.a or fail()
+            not exists m.b or fail()
+            exists 1 or fail()
+			`,
+		},
 		{
 			name: "pipe expression",
 			query: `
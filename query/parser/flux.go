@@ -4725,6 +4725,11 @@ var g = &grammar{
 													val:        "not",
 													ignoreCase: false,
 												},
+												&litMatcher{
+													pos:        position{line: 248, col: 20, offset: 5265},
+													val:        "exists",
+													ignoreCase: false,
+												},
 											},
 										},
 									},
@@ -4774,7 +4779,7 @@ var g = &grammar{
 									label: "argument",
 									expr: &ruleRefExpr{
 										pos:  position{line: 253, col: 37, offset: 5355},
-										name: "Primary",
+										name: "UnaryExpression",
 									},
 								},
 								&zeroOrMoreExpr{
@@ -793,6 +793,37 @@ a = 5.0
 				},
 			},
 		},
+		{
+			name: "exists and not exists",
+			raw: `
+            exists r._value and not exists r.host`,
+			want: &ast.Program{
+				Body: []ast.Statement{
+					&ast.ExpressionStatement{
+						Expression: &ast.LogicalExpression{
+							Operator: ast.AndOperator,
+							Left: &ast.UnaryExpression{
+								Operator: ast.ExistsOperator,
+								Argument: &ast.MemberExpression{
+									Object:   &ast.Identifier{Name: "r"},
+									Property: &ast.Identifier{Name: "_value"},
+								},
+							},
+							Right: &ast.UnaryExpression{
+								Operator: ast.NotOperator,
+								Argument: &ast.UnaryExpression{
+									Operator: ast.ExistsOperator,
+									Argument: &ast.MemberExpression{
+										Object:   &ast.Identifier{Name: "r"},
+										Property: &ast.Identifier{Name: "host"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "arrow function called",
 			raw: `plusOne = (r) => r + 1
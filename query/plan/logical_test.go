@@ -147,6 +147,8 @@ func TestLogicalPlanner_Plan(t *testing.T) {
 					plan.ProcedureIDFromOperationID("join"): {
 						ID: plan.ProcedureIDFromOperationID("join"),
 						Spec: &functions.MergeJoinProcedureSpec{
+							Method: functions.JoinMethodInner,
+							Fill:   functions.JoinFillNull,
 							TableNames: map[plan.ProcedureID]string{
 								plan.ProcedureIDFromOperationID("sum1"):   "sum",
 								plan.ProcedureIDFromOperationID("count0"): "count",
@@ -170,6 +172,63 @@ func TestLogicalPlanner_Plan(t *testing.T) {
 				},
 			},
 		},
+		{
+			q: &query.Spec{
+				Operations: []*query.Operation{
+					{
+						ID: "select0",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID: "select1",
+						Spec: &functions.FromOpSpec{
+							Database: "mydb",
+						},
+					},
+					{
+						ID:   "union",
+						Spec: &functions.UnionOpSpec{},
+					},
+				},
+				Edges: []query.Edge{
+					{Parent: "select0", Child: "union"},
+					{Parent: "select1", Child: "union"},
+				},
+			},
+			ap: &plan.LogicalPlanSpec{
+				Procedures: map[plan.ProcedureID]*plan.Procedure{
+					plan.ProcedureIDFromOperationID("select0"): {
+						ID: plan.ProcedureIDFromOperationID("select0"),
+						Spec: &functions.FromProcedureSpec{
+							Database: "mydb",
+						},
+						Children: []plan.ProcedureID{plan.ProcedureIDFromOperationID("union")},
+					},
+					plan.ProcedureIDFromOperationID("select1"): {
+						ID: plan.ProcedureIDFromOperationID("select1"),
+						Spec: &functions.FromProcedureSpec{
+							Database: "mydb",
+						},
+						Children: []plan.ProcedureID{plan.ProcedureIDFromOperationID("union")},
+					},
+					plan.ProcedureIDFromOperationID("union"): {
+						ID:   plan.ProcedureIDFromOperationID("union"),
+						Spec: &functions.UnionProcedureSpec{},
+						Parents: []plan.ProcedureID{
+							plan.ProcedureIDFromOperationID("select0"),
+							plan.ProcedureIDFromOperationID("select1"),
+						},
+					},
+				},
+				Order: []plan.ProcedureID{
+					plan.ProcedureIDFromOperationID("select1"),
+					plan.ProcedureIDFromOperationID("select0"),
+					plan.ProcedureIDFromOperationID("union"),
+				},
+			},
+		},
 	}
 	for i, tc := range testCases {
 		tc := tc
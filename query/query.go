@@ -271,4 +271,8 @@ type Statistics struct {
 	Concurrency int `json:"concurrency"`
 	// MaxAllocated is the maximum number of bytes the query allocated.
 	MaxAllocated int64 `json:"max_allocated"`
+	// BytesWritten is the number of bytes the query's sinks wrote to external systems.
+	BytesWritten int64 `json:"bytes_written"`
+	// PointsWritten is the number of points the query wrote into buckets.
+	PointsWritten int64 `json:"points_written"`
 }
@@ -88,6 +88,9 @@ type ColReader interface {
 	Floats(j int) []float64
 	Strings(j int) []string
 	Times(j int) []values.Time
+	// IsNull reports whether the value in row i of column j is null.
+	// The slice accessors contain the zero value of the column type for null values.
+	IsNull(i, j int) bool
 }
 
 type GroupKey interface {
@@ -545,6 +545,9 @@ type UnaryExpression struct {
 
 func (*UnaryExpression) NodeType() string { return "UnaryExpression" }
 func (e *UnaryExpression) Type() Type {
+	if e.Operator == ast.ExistsOperator {
+		return Bool
+	}
 	return e.Argument.Type()
 }
 
@@ -1152,17 +1155,16 @@ func analyzePipeExpression(pipe *ast.PipeExpression, declarations DeclarationSco
 		return nil, err
 	}
 
-	decl, err := resolveDeclaration(call.Callee)
+	fnTyp, name, err := resolveCalleeType(call.Callee)
 	if err != nil {
 		return nil, err
 	}
-	fnTyp := decl.InitType()
 	if fnTyp.Kind() != Function {
 		return nil, fmt.Errorf("cannot pipe into non function %q", fnTyp.Kind())
 	}
 	key := fnTyp.PipeArgument()
 	if key == "" {
-		return nil, fmt.Errorf("function %q does not have a pipe argument", decl.ID().Name)
+		return nil, fmt.Errorf("function %q does not have a pipe argument", name)
 	}
 
 	value, err := analyzeExpression(pipe.Argument, declarations)
@@ -1188,6 +1190,30 @@ func analyzePipeExpression(pipe *ast.PipeExpression, declarations DeclarationSco
 	return call, nil
 }
 
+// resolveCalleeType returns the type and name of the callee of a call expression.
+// The callee is either an identifier or a member of an object, i.e. a function in a package.
+func resolveCalleeType(n Expression) (Type, string, error) {
+	if m, ok := n.(*MemberExpression); ok {
+		typ, name, err := resolveCalleeType(m.Object)
+		if err != nil {
+			return nil, "", err
+		}
+		if typ.Kind() != Object {
+			return nil, "", fmt.Errorf("cannot access property %q of non object %q", m.Property, typ.Kind())
+		}
+		propTyp := typ.PropertyType(m.Property)
+		if propTyp == nil {
+			return nil, "", fmt.Errorf("object %q has no property %q", name, m.Property)
+		}
+		return propTyp, name + "." + m.Property, nil
+	}
+	decl, err := resolveDeclaration(n)
+	if err != nil {
+		return nil, "", err
+	}
+	return decl.InitType(), decl.ID().Name, nil
+}
+
 // resolveDeclaration traverse the expression until a variable declaration is found for the expression.
 func resolveDeclaration(n Node) (VariableDeclaration, error) {
 	switch n := n.(type) {
@@ -14,6 +14,14 @@ type Object interface {
 	Range(func(name string, v Value))
 }
 
+// NullableObject is implemented by objects whose properties may be null, such as the records of a table.
+// Get reports a null property as missing.
+type NullableObject interface {
+	Object
+	// Exists reports whether the property has a value that is not null, without reading the value.
+	Exists(name string) bool
+}
+
 type object struct {
 	values        map[string]Value
 	propertyTypes map[string]semantic.Type
@@ -1,6 +1,9 @@
 package platform
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Task is a task. 🎊
 type Task struct {
@@ -9,10 +12,43 @@ type Task struct {
 	Name         string `json:"name"`
 	Status       string `json:"status"`
 	Owner        User   `json:"owner"`
-	Flux         string `json:"flux"`
-	Every        string `json:"every,omitempty"`
-	Cron         string `json:"cron,omitempty"`
-	Last         Run    `json:"last,omitempty"`
+	// AuthorizationID identifies the authorization that runs of the task execute with.
+	// It is set from the authorization of the request that created the task.
+	AuthorizationID ID     `json:"authorizationId,omitempty"`
+	Flux            string `json:"flux"`
+	Every           string `json:"every,omitempty"`
+	Cron            string `json:"cron,omitempty"`
+	Timezone        string `json:"timezone,omitempty"`
+	Offset          string `json:"offset,omitempty"`
+	DependsOn       ID     `json:"dependsOn,omitempty"`
+	Revision        int64  `json:"revision,omitempty"`
+	Last            Run    `json:"last,omitempty"`
+}
+
+// TaskDependencies is the graph of tasks connected to a task through their dependsOn options.
+type TaskDependencies struct {
+	TaskID ID `json:"taskId"`
+
+	// Upstream lists the tasks whose runs trigger the task, directly or indirectly, nearest first.
+	Upstream []TaskDependency `json:"upstream"`
+
+	// Downstream lists the tasks triggered by the runs of the task, directly or indirectly, nearest first.
+	Downstream []TaskDependency `json:"downstream"`
+}
+
+// TaskDependency is a task in a dependency graph, with the task it depends on.
+type TaskDependency struct {
+	ID        ID     `json:"id"`
+	Name      string `json:"name"`
+	DependsOn ID     `json:"dependsOn,omitempty"`
+}
+
+// TaskRevision is a version of a task's Flux script.
+type TaskRevision struct {
+	Revision int64  `json:"revision"`
+	Flux     string `json:"flux"`
+	Author   ID     `json:"author,omitempty"`
+	Created  string `json:"created,omitempty"`
 }
 
 // Run is a record created when a run of a task is queued.
@@ -23,6 +59,39 @@ type Run struct {
 	StartTime string `json:"startTime"`
 	EndTime   string `json:"endTime"`
 	Log       Log    `json:"log"`
+
+	// ScriptRevision is the revision of the task's script that the run executed.
+	ScriptRevision int64 `json:"scriptRevision,omitempty"`
+
+	// Statistics is set once the run has executed.
+	Statistics *RunStatistics `json:"statistics,omitempty"`
+}
+
+// RunStatistics describes the work done by a single run of a task.
+type RunStatistics struct {
+	// Durations are reported in nanoseconds.
+	TotalDuration   time.Duration `json:"totalDuration"`
+	CompileDuration time.Duration `json:"compileDuration"`
+	QueueDuration   time.Duration `json:"queueDuration"`
+	PlanDuration    time.Duration `json:"planDuration"`
+	ExecuteDuration time.Duration `json:"executeDuration"`
+
+	// MaxAllocated is the maximum number of bytes the run's query allocated.
+	MaxAllocated int64 `json:"maxAllocated"`
+	// BytesWritten is the number of bytes written by sinks: toHTTP, toKafka, to and sql.to.
+	BytesWritten int64 `json:"bytesWritten"`
+	// PointsWritten is the number of points written into buckets by to.
+	PointsWritten int64 `json:"pointsWritten"`
+
+	// Yields holds the data produced by each result of the run's query, sorted by name.
+	Yields []YieldStatistics `json:"yields"`
+}
+
+// YieldStatistics counts the data produced by one named result of a run.
+type YieldStatistics struct {
+	Name   string `json:"name"`
+	Tables int64  `json:"tables"`
+	Rows   int64  `json:"rows"`
 }
 
 // Log represents a link to a log resource
@@ -52,17 +121,114 @@ type TaskService interface {
 	// Returns a list of runs that match a filter and the total count of returned runs.
 	FindRuns(ctx context.Context, filter RunFilter) ([]*Run, int, error)
 
-	// Returns a single run
-	FindRunByID(ctx context.Context, id ID) (*Run, error)
+	// Returns a single run of a task
+	FindRunByID(ctx context.Context, taskID, runID ID) (*Run, error)
 
 	// Creates and returns a new run (which is a retry of another run)
-	RetryRun(ctx context.Context, id ID) (*Run, error)
+	RetryRun(ctx context.Context, taskID, runID ID) (*Run, error)
+
+	// Returns every revision of a task's script, oldest first.
+	FindTaskRevisions(ctx context.Context, id ID) ([]*TaskRevision, error)
+
+	// Makes the script of an earlier revision the task's current script, by storing it as a new revision.
+	RollbackTask(ctx context.Context, id ID, revision int64, author ID) (*Task, error)
+
+	// Returns the tasks that trigger, or are triggered by, a task.
+	FindTaskDependencies(ctx context.Context, id ID) (*TaskDependencies, error)
+}
+
+// TaskDryRunService checks task scripts and runs them without creating tasks or writing to sinks.
+type TaskDryRunService interface {
+	// DryRunTask parses the options of the script, then compiles and plans its query.
+	// If dr.Now is set, one run is also executed as of that time, returning the tables it produced.
+	// Nothing is persisted, and sinks such as toHTTP receive no data.
+	DryRunTask(ctx context.Context, dr TaskDryRun) (*TaskDryRunResult, error)
+}
+
+// TaskDryRun describes a task script to check.
+type TaskDryRun struct {
+	Organization ID     `json:"organizationId"`
+	Flux         string `json:"flux"`
+
+	// Now is an RFC3339 time. If set, a run is executed as if it were scheduled at Now.
+	Now string `json:"now,omitempty"`
+}
+
+// TaskDryRunResult is the outcome of a successful dry run.
+type TaskDryRunResult struct {
+	Name     string `json:"name"`
+	Every    string `json:"every,omitempty"`
+	Cron     string `json:"cron,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	Offset   string `json:"offset,omitempty"`
+
+	// Tables holds the tables produced by the run, if one was executed.
+	// The data that sinks would have written is reported under a result named after the sink's operation.
+	Tables []*DryRunTable `json:"tables,omitempty"`
+	// Truncated is true if the run produced more rows than were returned.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// DryRunTable is a table produced by a dry run.
+type DryRunTable struct {
+	Result  string         `json:"result"`
+	Columns []DryRunColumn `json:"columns"`
+	// Rows holds the values of each row in column order; null values are nil.
+	Rows [][]interface{} `json:"rows"`
+}
+
+// DryRunColumn describes a column of a DryRunTable.
+type DryRunColumn struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	// Group is true if the column is part of the table's group key.
+	Group bool `json:"group,omitempty"`
+}
+
+// TaskNotificationService reports on the notifications sent about the runs of tasks.
+type TaskNotificationService interface {
+	// FindTaskNotifications returns the consecutive failure count of a task and the recent deliveries to its notification endpoints.
+	FindTaskNotifications(ctx context.Context, taskID ID) (*TaskNotifications, error)
+}
+
+// TaskNotifications describes the notification state of a task.
+type TaskNotifications struct {
+	// ConsecutiveFailures is the number of the task's most recent runs that failed in a row.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+
+	// Deliveries holds the most recent delivery attempts to each of the task's notification endpoints,
+	// ordered by endpoint URL, then oldest first.
+	Deliveries []NotificationDelivery `json:"deliveries"`
+}
+
+// NotificationDelivery records a single attempt to deliver a notification to an endpoint.
+type NotificationDelivery struct {
+	URL     string    `json:"url"`
+	Event   string    `json:"event"`
+	RunID   ID        `json:"runId"`
+	Attempt int       `json:"attempt"`
+	Time    time.Time `json:"time"`
+
+	// StatusCode is the HTTP status returned by the endpoint, or 0 if no response was received.
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// Error describes why the attempt failed. It is empty if the attempt succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// Succeeded reports whether the notification was accepted by the endpoint.
+func (d NotificationDelivery) Succeeded() bool {
+	return d.Error == ""
 }
 
 // TaskUpdate represents updates to a task
 type TaskUpdate struct {
 	Flux   *string `json:"flux,omitempty"`
 	Status *string `json:"status,omitempty"`
+
+	// Author is the user making the update, recorded with the new script revision when Flux is set.
+	// It is never read from JSON; the HTTP handler sets it from the request's authorization.
+	Author ID `json:"-"`
 }
 
 // TaskFilter represents a set of filters that restrict the returned results
@@ -70,6 +236,20 @@ type TaskFilter struct {
 	After        *ID
 	Organization *ID
 	User         *ID
+
+	// NamePrefix and NameRegex restrict the task name.
+	NamePrefix string
+	NameRegex  string
+
+	// Status is either "enabled" or "disabled".
+	Status string
+
+	// LastRunStatus is either "success" or "failed".
+	LastRunStatus string
+
+	// LastRunAfterTime and LastRunBeforeTime are RFC3339 times bounding when the task's latest run finished.
+	LastRunAfterTime  string
+	LastRunBeforeTime string
 }
 
 // RunFilter represents a set of filters that restrict the returned results
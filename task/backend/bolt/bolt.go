@@ -7,12 +7,29 @@
 //                                    so we have a consistent view of runs in progress and max concurrency.
 //    bucket(/tasks/v1/org_by_task_id) key(task_id) -> The organization ID (stored as encoded string) associated with given task.
 //    bucket(/tasks/v1/user_by_task_id) key(:task_id) -> The user ID (stored as encoded string) associated with given task.
+//    bucket(/tasks/v1/auth_by_task_id) key(:task_id) -> The ID of the authorization that runs of the task execute with.
 //    buket(/tasks/v1/name_by_task_id) key(:task_id) -> The user-supplied name of the script.
 //                                         Maybe we don't need this after name becomes a script option?
 //                                         Or maybe we do need it as part of ensuring uniqueness.
 //    bucket(/tasks/v1/run_ids) -> Counter for run IDs
+//    bucket(/tasks/v1/script_revisions).bucket(:task_id) key(:revision) -> JSON encoded script revision,
+//                                         with the revision number stored as a big-endian uint64.
 //    bucket(/tasks/v1/orgs).bucket(:org_id) key(:task_id) -> Empty content; presence of :task_id allows for lookup from org to tasks.
 //    bucket(/tasks/v1/users).bucket(:user_id) key(:task_id) -> Empty content; presence of :task_id allows for lookup from user to tasks.
+//    bucket(/tasks/v1/name_index) key(:name:task_id) -> Empty content; allows for lookup of tasks by name prefix.
+//    bucket(/tasks/v1/status_index).bucket(:status) key(:task_id) -> Empty content; allows for lookup of tasks by status.
+//    bucket(/tasks/v1/last_run_by_task_id) key(:task_id) -> The status (one byte) and big-endian finish time in Unix nanoseconds
+//                                         of the task's most recently finished run.
+//    bucket(/tasks/v1/last_run_status_index).bucket(:status) key(:task_id) -> Empty content; allows for lookup of tasks by last run status.
+//    bucket(/tasks/v1/last_run_time_index) key(:finish_time:task_id) -> Empty content; allows for lookup of tasks by last run time.
+//    bucket(/tasks/v1/depends_on_by_task_id) key(:task_id) -> The ID of the task named by the task's dependsOn option.
+//    bucket(/tasks/v1/depends_on_index) key(:upstream_id:task_id) -> Empty content; allows for lookup of the tasks that depend on a task.
+//    bucket(/tasks/v1/lease_nodes) key(:node_id) -> The big-endian unix timestamp when the node's last heartbeat expires.
+//    bucket(/tasks/v1/leases) key(:task_id) -> JSON encoded lease: the ID of the node that owns the task, and when the lease expires.
+//    bucket(/tasks/v1/runs).bucket(:task_id) key(:run_id) -> JSON encoded platform.Run, including its log and statistics.
+//    bucket(/tasks/v1/task_by_run_id) key(:run_id) -> The ID of the task the run belongs to.
+//    bucket(/tasks/v1/notify_failures) key(:task_id) -> The big-endian number of the task's most recent runs that failed in a row.
+//    bucket(/tasks/v1/notify_deliveries).bucket(:task_id).bucket(:url) key(:sequence) -> JSON encoded notification delivery attempt.
 //
 // Note that task IDs are stored big-endian uint64s for sorting purposes,
 // but presented to the users with leading 0-bytes stripped.
@@ -23,12 +40,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	bolt "github.com/coreos/bbolt"
 	"github.com/influxdata/platform"
 	"github.com/influxdata/platform/task/backend"
+	"github.com/influxdata/platform/task/options"
 )
 
 // ErrDBReadOnly is an error for when the database is set to read only.
@@ -61,7 +82,19 @@ var (
 	orgByTaskID  = []byte(basePath + "org_by_task_id")
 	userByTaskID = []byte(basePath + "user_by_task_id")
 	nameByTaskID = []byte(basePath + "name_by_task_id")
+	authByTaskID = []byte(basePath + "auth_by_task_id")
 	runIDs       = []byte(basePath + "run_ids")
+
+	scriptRevisionsPath = []byte(basePath + "script_revisions")
+
+	nameIndexPath          = []byte(basePath + "name_index")
+	statusIndexPath        = []byte(basePath + "status_index")
+	lastRunByTaskID        = []byte(basePath + "last_run_by_task_id")
+	lastRunStatusIndexPath = []byte(basePath + "last_run_status_index")
+	lastRunTimeIndexPath   = []byte(basePath + "last_run_time_index")
+
+	dependsOnByTaskID  = []byte(basePath + "depends_on_by_task_id")
+	dependsOnIndexPath = []byte(basePath + "depends_on_index")
 )
 
 // New gives us a new Store based on "github.com/coreos/bbolt"
@@ -77,16 +110,32 @@ func New(db *bolt.DB, rootBucket string) (*Store, error) {
 		if err != nil {
 			return err
 		}
+		// Stores created before the task indexes existed need their indexes built.
+		buildIndexes := root.Bucket(nameIndexPath) == nil || root.Bucket(lastRunTimeIndexPath) == nil
+		buildDependsOn := root.Bucket(dependsOnIndexPath) == nil
+
 		// create the buckets inside the root
 		for _, b := range [][]byte{
 			tasksPath, orgsPath, usersPath, taskMetaPath,
-			orgByTaskID, userByTaskID, nameByTaskID, runIDs,
+			orgByTaskID, userByTaskID, nameByTaskID, authByTaskID, runIDs,
+			scriptRevisionsPath,
+			nameIndexPath, statusIndexPath, lastRunByTaskID, lastRunStatusIndexPath, lastRunTimeIndexPath,
+			dependsOnByTaskID, dependsOnIndexPath,
 		} {
 			_, err := root.CreateBucketIfNotExists(b)
 			if err != nil {
 				return err
 			}
 		}
+
+		if buildIndexes {
+			if err := buildTaskIndexes(root); err != nil {
+				return err
+			}
+		}
+		if buildDependsOn {
+			return buildDependsOnIndex(root)
+		}
 		return nil
 	})
 	if err != nil {
@@ -96,7 +145,8 @@ func New(db *bolt.DB, rootBucket string) (*Store, error) {
 }
 
 // CreateTask creates a task in the boltdb task store.
-func (s *Store) CreateTask(ctx context.Context, org, user platform.ID, script string) (platform.ID, error) {
+func (s *Store) CreateTask(ctx context.Context, req backend.CreateTaskRequest) (platform.ID, error) {
+	org, user, script := req.Org, req.User, req.Script
 	o, err := backend.StoreValidator.CreateArgs(org, user, script)
 	if err != nil {
 		return nil, err
@@ -123,6 +173,21 @@ func (s *Store) CreateTask(ctx context.Context, org, user platform.ID, script st
 			return err
 		}
 
+		err = b.Bucket(nameIndexPath).Put(nameIndexKey(o.Name, id), nil)
+		if err != nil {
+			return err
+		}
+
+		err = setStatusIndex(b, id, backend.TaskEnabled)
+		if err != nil {
+			return err
+		}
+
+		err = setDependsOnIndex(b, id, o.DependsOn)
+		if err != nil {
+			return err
+		}
+
 		// org
 		orgB, err := b.Bucket(orgsPath).CreateBucketIfNotExists([]byte(org))
 		if err != nil {
@@ -155,6 +220,25 @@ func (s *Store) CreateTask(ctx context.Context, org, user platform.ID, script st
 			return err
 		}
 
+		// authorization
+		if len(req.AuthorizationID) > 0 {
+			err = b.Bucket(authByTaskID).Put(id, []byte(req.AuthorizationID))
+			if err != nil {
+				return err
+			}
+		}
+
+		// first script revision
+		err = putScriptRevision(b, id, backend.ScriptRevision{
+			Revision: 1,
+			Script:   script,
+			Author:   user,
+			Created:  time.Now().Unix(),
+		})
+		if err != nil {
+			return err
+		}
+
 		// metadata
 		stm := backend.StoreTaskMeta{
 			MaxConcurrency: int32(o.Concurrency),
@@ -175,25 +259,125 @@ func (s *Store) CreateTask(ctx context.Context, org, user platform.ID, script st
 }
 
 // ModifyTask changes a task with a new script, it should error if the task does not exist.
-func (s *Store) ModifyTask(ctx context.Context, id platform.ID, newScript string) error {
-	if _, err := backend.StoreValidator.ModifyArgs(id, newScript); err != nil {
+// The new script is stored as the next revision of the task.
+func (s *Store) ModifyTask(ctx context.Context, id, author platform.ID, newScript string) error {
+	o, err := backend.StoreValidator.ModifyArgs(id, newScript)
+	if err != nil {
 		return err
 	}
 
 	paddedID := padID(id)
 	return s.db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket(s.bucket).Bucket(tasksPath)
-		if v := b.Get(paddedID); v == nil { // this is so we can error if the task doesn't exist
+		b := tx.Bucket(s.bucket)
+		oldScript := b.Bucket(tasksPath).Get(paddedID)
+		if oldScript == nil { // this is so we can error if the task doesn't exist
 			return ErrNotFound
 		}
-		return b.Put(paddedID, []byte(newScript))
+
+		rev := currentScriptRevision(b, paddedID)
+		if rev == 0 {
+			// The task was created before revisions were recorded.
+			// Keep its existing script as the first revision, with an unknown creation time.
+			if err := putScriptRevision(b, paddedID, backend.ScriptRevision{
+				Revision: 1,
+				Script:   string(oldScript),
+				Author:   b.Bucket(userByTaskID).Get(paddedID),
+			}); err != nil {
+				return err
+			}
+			rev = 1
+		}
+
+		if err := putScriptRevision(b, paddedID, backend.ScriptRevision{
+			Revision: rev + 1,
+			Script:   newScript,
+			Author:   author,
+			Created:  time.Now().Unix(),
+		}); err != nil {
+			return err
+		}
+
+		// The name may have changed with the script.
+		oldName := b.Bucket(nameByTaskID).Get(paddedID)
+		if string(oldName) != o.Name {
+			if err := b.Bucket(nameIndexPath).Delete(nameIndexKey(string(oldName), paddedID)); err != nil {
+				return err
+			}
+			if err := b.Bucket(nameIndexPath).Put(nameIndexKey(o.Name, paddedID), nil); err != nil {
+				return err
+			}
+			if err := b.Bucket(nameByTaskID).Put(paddedID, []byte(o.Name)); err != nil {
+				return err
+			}
+		}
+
+		if err := setDependsOnIndex(b, paddedID, o.DependsOn); err != nil {
+			return err
+		}
+
+		return b.Bucket(tasksPath).Put(paddedID, []byte(newScript))
 	})
 }
 
+// ListScriptRevisions returns all the revisions of a task's script, oldest first.
+func (s *Store) ListScriptRevisions(ctx context.Context, id platform.ID) ([]backend.ScriptRevision, error) {
+	var revs []backend.ScriptRevision
+	paddedID := padID(id)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		rb := tx.Bucket(s.bucket).Bucket(scriptRevisionsPath).Bucket(paddedID)
+		if rb == nil {
+			return nil
+		}
+		return rb.ForEach(func(k, v []byte) error {
+			rev, err := decodeScriptRevision(k, v)
+			if err != nil {
+				return err
+			}
+			revs = append(revs, rev)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// FindScriptRevision returns a single revision of a task's script. It will return nil if the revision does not exist.
+func (s *Store) FindScriptRevision(ctx context.Context, id platform.ID, revision int64) (*backend.ScriptRevision, error) {
+	if revision < 1 {
+		return nil, nil
+	}
+
+	var rev *backend.ScriptRevision
+	paddedID := padID(id)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		rb := tx.Bucket(s.bucket).Bucket(scriptRevisionsPath).Bucket(paddedID)
+		if rb == nil {
+			return nil
+		}
+		k := revisionKey(revision)
+		v := rb.Get(k)
+		if v == nil {
+			return nil
+		}
+		r, err := decodeScriptRevision(k, v)
+		if err != nil {
+			return err
+		}
+		rev = &r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
 // ListTasks lists the tasks based on a filter.
 func (s *Store) ListTasks(ctx context.Context, params backend.TaskSearchParams) ([]backend.StoreTask, error) {
-	if len(params.Org) > 0 && len(params.User) > 0 {
-		return nil, errors.New("ListTasks: org and user filters are mutually exclusive")
+	if err := backend.StoreValidator.ListArgs(params); err != nil {
+		return nil, err
 	}
 
 	const (
@@ -213,32 +397,24 @@ func (s *Store) ListTasks(ctx context.Context, params backend.TaskSearchParams)
 	taskIDs := make([]platform.ID, 0, params.PageSize)
 
 	err := s.db.View(func(tx *bolt.Tx) error {
-		var c *bolt.Cursor
+		var base *bolt.Bucket
 		b := tx.Bucket(s.bucket)
 		if len(params.Org) > 0 {
-			orgB := b.Bucket(orgsPath).Bucket(params.Org)
-			if orgB == nil {
-				return ErrNotFound
-			}
-			c = orgB.Cursor()
+			base = b.Bucket(orgsPath).Bucket(params.Org)
 		} else if len(params.User) > 0 {
-			userB := b.Bucket(usersPath).Bucket(params.User)
-			if userB == nil {
-				return ErrNotFound
-			}
-			c = userB.Cursor()
+			base = b.Bucket(usersPath).Bucket(params.User)
 		} else {
-			c = b.Bucket(tasksPath).Cursor()
+			base = b.Bucket(tasksPath)
 		}
-		if len(params.After) > 0 {
-			c.Seek(padID(params.After))
-			for k, _ := c.Next(); k != nil && len(taskIDs) < lim; k, _ = c.Next() {
-				taskIDs = append(taskIDs, k)
-			}
-			return nil
+		if base == nil {
+			return ErrNotFound
 		}
-		for k, _ := c.First(); k != nil && len(taskIDs) < lim; k, _ = c.Next() {
-			taskIDs = append(taskIDs, k)
+
+		next := candidateTaskIDs(b, base, params)
+		for k := next(); k != nil && len(taskIDs) < lim; k = next() {
+			if matchTask(b, base, params, k) {
+				taskIDs = append(taskIDs, append([]byte(nil), k...))
+			}
 		}
 		return nil
 	})
@@ -263,6 +439,10 @@ func (s *Store) ListTasks(ctx context.Context, params backend.TaskSearchParams)
 				tasks[i].ID = unpadID(paddedID)
 				tasks[i].Script = string(b.Bucket(tasksPath).Get(paddedID))
 				tasks[i].Name = string(b.Bucket(nameByTaskID).Get(paddedID))
+				tasks[i].Revision = currentScriptRevision(b, paddedID)
+				if a := b.Bucket(authByTaskID).Get(paddedID); a != nil {
+					tasks[i].AuthorizationID = append(platform.ID(nil), a...)
+				}
 			}
 		}
 		if len(params.Org) > 0 {
@@ -315,6 +495,8 @@ func (s *Store) FindTaskByID(ctx context.Context, id platform.ID) (*backend.Stor
 	var userID []byte
 	var name []byte
 	var org []byte
+	var authID platform.ID
+	var revision int64
 	paddedID := padID(id)
 	err := s.db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket(s.bucket)
@@ -322,10 +504,14 @@ func (s *Store) FindTaskByID(ctx context.Context, id platform.ID) (*backend.Stor
 		if script == nil {
 			return ErrNotFound
 		}
+		revision = currentScriptRevision(b, paddedID)
 		stmBytes = b.Bucket(taskMetaPath).Get(paddedID)
 		userID = b.Bucket(userByTaskID).Get(paddedID)
 		name = b.Bucket(nameByTaskID).Get(paddedID)
 		org = b.Bucket(orgByTaskID).Get(paddedID)
+		if a := b.Bucket(authByTaskID).Get(paddedID); a != nil {
+			authID = append(platform.ID(nil), a...)
+		}
 		return nil
 	})
 	if err == ErrNotFound {
@@ -342,11 +528,13 @@ func (s *Store) FindTaskByID(ctx context.Context, id platform.ID) (*backend.Stor
 	}
 
 	return &backend.StoreTask{
-		ID:     append([]byte(nil), id...), // copy of input id
-		Org:    org,
-		User:   userID,
-		Name:   string(name),
-		Script: string(script),
+		ID:              append([]byte(nil), id...), // copy of input id
+		Org:             org,
+		User:            userID,
+		AuthorizationID: authID,
+		Name:            string(name),
+		Script:          string(script),
+		Revision:        revision,
 	}, err
 }
 
@@ -369,6 +557,9 @@ func (s *Store) EnableTask(ctx context.Context, id platform.ID) error {
 			return err
 		}
 
+		if err := setStatusIndex(tx.Bucket(s.bucket), paddedID, backend.TaskEnabled); err != nil {
+			return err
+		}
 		return b.Put(paddedID, stmBytes)
 	})
 }
@@ -392,6 +583,9 @@ func (s *Store) DisableTask(ctx context.Context, id platform.ID) error {
 			return err
 		}
 
+		if err := setStatusIndex(tx.Bucket(s.bucket), paddedID, backend.TaskDisabled); err != nil {
+			return err
+		}
 		return b.Put(paddedID, stmBytes)
 	})
 }
@@ -428,6 +622,9 @@ func (s *Store) DeleteTask(ctx context.Context, id platform.ID) (deleted bool, e
 		if check := b.Bucket(tasksPath).Get(paddedID); check == nil {
 			return ErrNotFound
 		}
+		if err := deleteTaskIndexes(b, paddedID); err != nil {
+			return err
+		}
 		if err := b.Bucket(taskMetaPath).Delete(paddedID); err != nil {
 			return err
 		}
@@ -446,6 +643,12 @@ func (s *Store) DeleteTask(ctx context.Context, id platform.ID) (deleted bool, e
 		if err := b.Bucket(nameByTaskID).Delete(paddedID); err != nil {
 			return err
 		}
+		if err := b.Bucket(authByTaskID).Delete(paddedID); err != nil {
+			return err
+		}
+		if err := deleteScriptRevisions(b, paddedID); err != nil {
+			return err
+		}
 
 		org := b.Bucket(orgByTaskID).Get(paddedID)
 		if len(org) > 0 {
@@ -499,6 +702,7 @@ func (s *Store) CreateRun(ctx context.Context, taskID platform.ID, now int64) (b
 		}
 
 		queuedRun.RunID = id
+		queuedRun.Revision = currentScriptRevision(b, paddedID)
 
 		return tx.Bucket(s.bucket).Bucket(taskMetaPath).Put(paddedID, stmBytes)
 	}); err != nil {
@@ -508,6 +712,103 @@ func (s *Store) CreateRun(ctx context.Context, taskID platform.ID, now int64) (b
 	return queuedRun, nil
 }
 
+// TriggerDependents queues a run with the given now timestamp in the metadata of every task that depends on taskID.
+func (s *Store) TriggerDependents(ctx context.Context, taskID platform.ID, now int64) error {
+	upstream := padID(taskID)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		metaB := b.Bucket(taskMetaPath)
+		c := b.Bucket(dependsOnIndexPath).Cursor()
+		for k, _ := c.Seek(upstream); k != nil && bytes.HasPrefix(k, upstream); k, _ = c.Next() {
+			id := k[len(upstream):]
+			stmBytes := metaB.Get(id)
+			if stmBytes == nil {
+				continue
+			}
+			var stm backend.StoreTaskMeta
+			if err := stm.Unmarshal(stmBytes); err != nil {
+				return err
+			}
+			if !stm.Trigger(now) {
+				continue
+			}
+			stmBytes, err := stm.Marshal()
+			if err != nil {
+				return err
+			}
+			if err := metaB.Put(id, stmBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateTriggeredRun creates a run for the task's oldest queued trigger, if we have not exceeded 'max_concurrency'.
+func (s *Store) CreateTriggeredRun(ctx context.Context, taskID platform.ID) (backend.QueuedRun, error) {
+	paddedID := padID(taskID)
+
+	// Schedulers check for triggered runs on every tick, so avoid a write transaction when there are none.
+	var triggered bool
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		var stm backend.StoreTaskMeta
+		if err := stm.Unmarshal(tx.Bucket(s.bucket).Bucket(taskMetaPath).Get(paddedID)); err != nil {
+			return err
+		}
+		triggered = len(stm.Triggered) > 0
+		return nil
+	}); err != nil {
+		return backend.QueuedRun{}, err
+	}
+	if !triggered {
+		return backend.QueuedRun{}, backend.ErrNoRunTriggered
+	}
+
+	queuedRun := backend.QueuedRun{TaskID: append([]byte(nil), taskID...)}
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		stm := backend.StoreTaskMeta{}
+		stmBytes := b.Bucket(taskMetaPath).Get(paddedID)
+		if err := stm.Unmarshal(stmBytes); err != nil {
+			return err
+		}
+		if len(stm.Triggered) == 0 {
+			return backend.ErrNoRunTriggered
+		}
+		if len(stm.CurrentlyRunning) >= int(stm.MaxConcurrency) {
+			return ErrMaxConcurrency
+		}
+
+		id := make(platform.ID, 8)
+		idi, err := b.Bucket(runIDs).NextSequence()
+		if err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint64(id, idi)
+
+		queuedRun.Now = stm.Triggered[0]
+		stm.Triggered = stm.Triggered[1:]
+		stm.CurrentlyRunning = append(stm.CurrentlyRunning, &backend.StoreTaskMetaRun{
+			Now:   queuedRun.Now,
+			Try:   1,
+			RunID: id,
+		})
+		stmBytes, err = stm.Marshal()
+		if err != nil {
+			return err
+		}
+
+		queuedRun.RunID = id
+		queuedRun.Revision = currentScriptRevision(b, paddedID)
+
+		return b.Bucket(taskMetaPath).Put(paddedID, stmBytes)
+	}); err != nil {
+		return backend.QueuedRun{}, err
+	}
+
+	return queuedRun, nil
+}
+
 // FinishRun removes runID from the list of running tasks and if its `now` is later then last completed update it.
 func (s *Store) FinishRun(ctx context.Context, taskID, runID platform.ID) error {
 	stm := backend.StoreTaskMeta{}
@@ -543,6 +844,376 @@ func (s *Store) FinishRun(ctx context.Context, taskID, runID platform.ID) error
 	})
 }
 
+// RecordRunResult records the status of the task's most recently finished run, and updates the last run indexes.
+func (s *Store) RecordRunResult(ctx context.Context, taskID platform.ID, status backend.RunStatus, finished time.Time) error {
+	if status != backend.RunSuccess && status != backend.RunFail {
+		return fmt.Errorf("RecordRunResult: invalid status %d", status)
+	}
+
+	paddedID := padID(taskID)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b.Bucket(tasksPath).Get(paddedID) == nil {
+			return ErrNotFound
+		}
+		if err := deleteLastRunIndexes(b, paddedID); err != nil {
+			return err
+		}
+
+		v := make([]byte, 9)
+		v[0] = byte(status)
+		binary.BigEndian.PutUint64(v[1:], uint64(finished.UnixNano()))
+		if err := b.Bucket(lastRunByTaskID).Put(paddedID, v); err != nil {
+			return err
+		}
+
+		sb, err := b.Bucket(lastRunStatusIndexPath).CreateBucketIfNotExists([]byte(status.String()))
+		if err != nil {
+			return err
+		}
+		if err := sb.Put(paddedID, nil); err != nil {
+			return err
+		}
+		return b.Bucket(lastRunTimeIndexPath).Put(lastRunTimeKey(v[1:], paddedID), nil)
+	})
+}
+
+// storedScriptRevision is the JSON encoding of a backend.ScriptRevision.
+// The revision number is the key under which it is stored.
+type storedScriptRevision struct {
+	Script  string      `json:"script"`
+	Author  platform.ID `json:"author"`
+	Created int64       `json:"created"`
+}
+
+func revisionKey(revision int64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(revision))
+	return k
+}
+
+func decodeScriptRevision(k, v []byte) (backend.ScriptRevision, error) {
+	var sr storedScriptRevision
+	if err := json.Unmarshal(v, &sr); err != nil {
+		return backend.ScriptRevision{}, err
+	}
+	return backend.ScriptRevision{
+		Revision: int64(binary.BigEndian.Uint64(k)),
+		Script:   sr.Script,
+		Author:   sr.Author,
+		Created:  sr.Created,
+	}, nil
+}
+
+// putScriptRevision stores rev for the task with the given padded ID. b must be the root bucket.
+func putScriptRevision(b *bolt.Bucket, paddedID platform.ID, rev backend.ScriptRevision) error {
+	rb, err := b.Bucket(scriptRevisionsPath).CreateBucketIfNotExists(paddedID)
+	if err != nil {
+		return err
+	}
+	v, err := json.Marshal(storedScriptRevision{
+		Script:  rev.Script,
+		Author:  rev.Author,
+		Created: rev.Created,
+	})
+	if err != nil {
+		return err
+	}
+	return rb.Put(revisionKey(rev.Revision), v)
+}
+
+// currentScriptRevision returns the latest revision number of the task with the given padded ID,
+// or 0 if it has no recorded revisions. b must be the root bucket.
+func currentScriptRevision(b *bolt.Bucket, paddedID platform.ID) int64 {
+	rb := b.Bucket(scriptRevisionsPath).Bucket(paddedID)
+	if rb == nil {
+		return 0
+	}
+	k, _ := rb.Cursor().Last()
+	if k == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(k))
+}
+
+// deleteScriptRevisions removes all revisions of the task with the given padded ID. b must be the root bucket.
+func deleteScriptRevisions(b *bolt.Bucket, paddedID platform.ID) error {
+	err := b.Bucket(scriptRevisionsPath).DeleteBucket(paddedID)
+	if err == bolt.ErrBucketNotFound {
+		return nil
+	}
+	return err
+}
+
+// nameIndexKey returns the key of the task in the name index: the name followed by the padded task ID.
+func nameIndexKey(name string, paddedID platform.ID) []byte {
+	k := make([]byte, 0, len(name)+len(paddedID))
+	k = append(k, name...)
+	return append(k, paddedID...)
+}
+
+// lastRunTimeKey returns the key of the task in the last run time index:
+// the big-endian finish time followed by the padded task ID.
+func lastRunTimeKey(finished []byte, paddedID platform.ID) []byte {
+	k := make([]byte, 0, len(finished)+len(paddedID))
+	k = append(k, finished...)
+	return append(k, paddedID...)
+}
+
+// setStatusIndex moves the task with the given padded ID into the index for status. b must be the root bucket.
+func setStatusIndex(b *bolt.Bucket, paddedID platform.ID, status backend.TaskStatus) error {
+	idx := b.Bucket(statusIndexPath)
+	for _, st := range []backend.TaskStatus{backend.TaskEnabled, backend.TaskDisabled} {
+		sb, err := idx.CreateBucketIfNotExists([]byte(st))
+		if err != nil {
+			return err
+		}
+		if st == status {
+			err = sb.Put(paddedID, nil)
+		} else {
+			err = sb.Delete(paddedID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteLastRunIndexes removes the last run record of the task with the given padded ID from the last run indexes.
+// b must be the root bucket.
+func deleteLastRunIndexes(b *bolt.Bucket, paddedID platform.ID) error {
+	v := b.Bucket(lastRunByTaskID).Get(paddedID)
+	if len(v) != 9 {
+		return nil
+	}
+	if sb := b.Bucket(lastRunStatusIndexPath).Bucket([]byte(backend.RunStatus(v[0]).String())); sb != nil {
+		if err := sb.Delete(paddedID); err != nil {
+			return err
+		}
+	}
+	return b.Bucket(lastRunTimeIndexPath).Delete(lastRunTimeKey(v[1:], paddedID))
+}
+
+// deleteTaskIndexes removes the task with the given padded ID from all indexes and deletes its last run record.
+// It must be called before the task's name is deleted. b must be the root bucket.
+func deleteTaskIndexes(b *bolt.Bucket, paddedID platform.ID) error {
+	name := b.Bucket(nameByTaskID).Get(paddedID)
+	if err := b.Bucket(nameIndexPath).Delete(nameIndexKey(string(name), paddedID)); err != nil {
+		return err
+	}
+	if err := b.Bucket(statusIndexPath).ForEach(func(k, _ []byte) error {
+		return b.Bucket(statusIndexPath).Bucket(k).Delete(paddedID)
+	}); err != nil {
+		return err
+	}
+	if err := deleteLastRunIndexes(b, paddedID); err != nil {
+		return err
+	}
+	if err := setDependsOnIndex(b, paddedID, ""); err != nil {
+		return err
+	}
+	return b.Bucket(lastRunByTaskID).Delete(paddedID)
+}
+
+// setDependsOnIndex records that the task with the given padded ID depends on the task with the hex encoded ID dependsOn,
+// replacing any dependency previously recorded for the task. An empty dependsOn removes the task's dependency.
+// b must be the root bucket.
+func setDependsOnIndex(b *bolt.Bucket, paddedID platform.ID, dependsOn string) error {
+	if old := b.Bucket(dependsOnByTaskID).Get(paddedID); old != nil {
+		if err := b.Bucket(dependsOnIndexPath).Delete(dependsOnIndexKey(old, paddedID)); err != nil {
+			return err
+		}
+		if err := b.Bucket(dependsOnByTaskID).Delete(paddedID); err != nil {
+			return err
+		}
+	}
+	if dependsOn == "" {
+		return nil
+	}
+
+	var upstream platform.ID
+	if err := upstream.DecodeFromString(dependsOn); err != nil {
+		return err
+	}
+	upstream = padID(upstream)
+	if err := b.Bucket(dependsOnByTaskID).Put(paddedID, upstream); err != nil {
+		return err
+	}
+	return b.Bucket(dependsOnIndexPath).Put(dependsOnIndexKey(upstream, paddedID), nil)
+}
+
+// dependsOnIndexKey returns the key in the depends on index for the task with the given padded ID,
+// which depends on the task with the padded ID upstream.
+func dependsOnIndexKey(upstream, paddedID []byte) []byte {
+	k := make([]byte, 0, len(upstream)+len(paddedID))
+	k = append(k, upstream...)
+	return append(k, paddedID...)
+}
+
+// buildDependsOnIndex indexes every existing task by the task its dependsOn option names. b must be the root bucket.
+func buildDependsOnIndex(b *bolt.Bucket) error {
+	return b.Bucket(tasksPath).ForEach(func(k, v []byte) error {
+		o, err := options.FromScript(string(v))
+		if err != nil {
+			// A stored script that no longer parses cannot depend on another task.
+			return nil
+		}
+		return setDependsOnIndex(b, k, o.DependsOn)
+	})
+}
+
+// buildTaskIndexes indexes every existing task by name, status and last run time. b must be the root bucket.
+func buildTaskIndexes(b *bolt.Bucket) error {
+	names := b.Bucket(nameByTaskID)
+	lastRuns := b.Bucket(lastRunByTaskID)
+	metas := b.Bucket(taskMetaPath)
+	return b.Bucket(tasksPath).ForEach(func(k, _ []byte) error {
+		if err := b.Bucket(nameIndexPath).Put(nameIndexKey(string(names.Get(k)), k), nil); err != nil {
+			return err
+		}
+		if v := lastRuns.Get(k); len(v) == 9 {
+			if err := b.Bucket(lastRunTimeIndexPath).Put(lastRunTimeKey(v[1:], k), nil); err != nil {
+				return err
+			}
+		}
+
+		var stm backend.StoreTaskMeta
+		if err := stm.Unmarshal(metas.Get(k)); err != nil {
+			return err
+		}
+		status := backend.TaskStatus(stm.Status)
+		if status == "" {
+			status = backend.TaskEnabled
+		}
+		return setStatusIndex(b, k, status)
+	})
+}
+
+// candidateTaskIDs returns an iterator over the padded IDs of the candidate tasks for params that come after params.After,
+// in ID order. The iterator returns nil once the candidates are exhausted.
+//
+// A name prefix or last run time range is looked up in the name or last run time index.
+// Those indexes are not in ID order, so the IDs in the matching range are sorted before paging.
+// Otherwise the status or last run status index is walked when those are searched, since they are usually smaller than base.
+// Every candidate must still be checked with matchTask.
+func candidateTaskIDs(b, base *bolt.Bucket, params backend.TaskSearchParams) func() []byte {
+	var after []byte
+	if len(params.After) > 0 {
+		after = padID(params.After)
+	}
+
+	if params.NamePrefix != "" {
+		prefix := []byte(params.NamePrefix)
+		c := b.Bucket(nameIndexPath).Cursor()
+		k, _ := c.Seek(prefix)
+		return sortedIndexIDs(c, k, func(k []byte) bool { return bytes.HasPrefix(k, prefix) }, after)
+	}
+
+	if !params.LastRunAfter.IsZero() || !params.LastRunBefore.IsZero() {
+		c := b.Bucket(lastRunTimeIndexPath).Cursor()
+		k, _ := c.First()
+		if !params.LastRunAfter.IsZero() {
+			start := make([]byte, 8)
+			binary.BigEndian.PutUint64(start, uint64(params.LastRunAfter.UnixNano()))
+			k, _ = c.Seek(start)
+		}
+		var end []byte
+		if !params.LastRunBefore.IsZero() {
+			end = make([]byte, 8)
+			binary.BigEndian.PutUint64(end, uint64(params.LastRunBefore.UnixNano()))
+		}
+		return sortedIndexIDs(c, k, func(k []byte) bool { return end == nil || bytes.Compare(k[:8], end) < 0 }, after)
+	}
+
+	idx := base
+	if params.Status != "" {
+		idx = b.Bucket(statusIndexPath).Bucket([]byte(params.Status))
+	} else if params.LastRunStatus != backend.RunQueued {
+		idx = b.Bucket(lastRunStatusIndexPath).Bucket([]byte(params.LastRunStatus.String()))
+	}
+	if idx == nil {
+		return func() []byte { return nil }
+	}
+
+	c := idx.Cursor()
+	k, _ := c.First()
+	if after != nil {
+		k, _ = c.Seek(after)
+		if bytes.Equal(k, after) {
+			k, _ = c.Next()
+		}
+	}
+	return func() []byte {
+		id := k
+		if k != nil {
+			k, _ = c.Next()
+		}
+		return id
+	}
+}
+
+// sortedIndexIDs returns an iterator over the padded task IDs that end the index keys from k onwards, while in reports true.
+// Only IDs after the padded ID after are returned, in ID order.
+func sortedIndexIDs(c *bolt.Cursor, k []byte, in func(k []byte) bool, after []byte) func() []byte {
+	var ids [][]byte
+	for ; k != nil && in(k); k, _ = c.Next() {
+		id := k[len(k)-8:]
+		if after == nil || bytes.Compare(id, after) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i], ids[j]) < 0 })
+	return func() []byte {
+		if len(ids) == 0 {
+			return nil
+		}
+		id := ids[0]
+		ids = ids[1:]
+		return id
+	}
+}
+
+// matchTask reports whether the task with the given padded ID is in base and matches the search parameters.
+// b must be the root bucket.
+func matchTask(b, base *bolt.Bucket, params backend.TaskSearchParams, paddedID []byte) bool {
+	if base.Get(paddedID) == nil {
+		return false
+	}
+
+	if params.NamePrefix != "" || params.NamePattern != nil {
+		if !params.MatchName(string(b.Bucket(nameByTaskID).Get(paddedID))) {
+			return false
+		}
+	}
+
+	if params.Status != "" {
+		if sb := b.Bucket(statusIndexPath).Bucket([]byte(params.Status)); sb == nil || sb.Get(paddedID) == nil {
+			return false
+		}
+	}
+
+	if params.LastRunStatus == backend.RunQueued && params.LastRunAfter.IsZero() && params.LastRunBefore.IsZero() {
+		return true
+	}
+	v := b.Bucket(lastRunByTaskID).Get(paddedID)
+	if len(v) != 9 {
+		// The task has never finished a run.
+		return false
+	}
+	if params.LastRunStatus != backend.RunQueued && backend.RunStatus(v[0]) != params.LastRunStatus {
+		return false
+	}
+	finished := int64(binary.BigEndian.Uint64(v[1:]))
+	if !params.LastRunAfter.IsZero() && finished < params.LastRunAfter.UnixNano() {
+		return false
+	}
+	if !params.LastRunBefore.IsZero() && finished >= params.LastRunBefore.UnixNano() {
+		return false
+	}
+	return true
+}
+
 // Close closes the store
 func (s *Store) Close() error {
 	return s.db.Close()
@@ -590,6 +1261,9 @@ func (s *Store) DeleteUser(ctx context.Context, id platform.ID) error {
 				default:
 				}
 			}
+			if err := deleteTaskIndexes(b, k); err != nil {
+				return err
+			}
 			if err := b.Bucket(tasksPath).Delete(k); err != nil {
 				return err
 			}
@@ -605,6 +1279,12 @@ func (s *Store) DeleteUser(ctx context.Context, id platform.ID) error {
 			if err := b.Bucket(nameByTaskID).Delete(k); err != nil {
 				return err
 			}
+			if err := b.Bucket(authByTaskID).Delete(k); err != nil {
+				return err
+			}
+			if err := deleteScriptRevisions(b, k); err != nil {
+				return err
+			}
 
 			org := b.Bucket(orgByTaskID).Get(k)
 			if len(org) > 0 {
@@ -650,6 +1330,9 @@ func (s *Store) DeleteOrg(ctx context.Context, id platform.ID) error {
 				default:
 				}
 			}
+			if err := deleteTaskIndexes(b, k); err != nil {
+				return err
+			}
 			if err := b.Bucket(tasksPath).Delete(k); err != nil {
 				return err
 			}
@@ -665,6 +1348,12 @@ func (s *Store) DeleteOrg(ctx context.Context, id platform.ID) error {
 			if err := b.Bucket(nameByTaskID).Delete(k); err != nil {
 				return err
 			}
+			if err := b.Bucket(authByTaskID).Delete(k); err != nil {
+				return err
+			}
+			if err := deleteScriptRevisions(b, k); err != nil {
+				return err
+			}
 			user := b.Bucket(userByTaskID).Get(k)
 			if len(user) > 0 {
 				ub := b.Bucket(usersPath).Bucket(user)
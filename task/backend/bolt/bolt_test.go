@@ -1,9 +1,11 @@
 package bolt_test
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	bolt "github.com/coreos/bbolt"
 	_ "github.com/influxdata/platform/query/builtin"
@@ -49,3 +51,170 @@ func TestBoltStore(t *testing.T) {
 		},
 	)(t)
 }
+
+func TestBoltLeaseStore(t *testing.T) {
+	var f *os.File
+	var db *bolt.DB
+	storetest.NewLeaseStoreTest(
+		"boltleasestore",
+		func(t *testing.T) backend.LeaseStore {
+			var err error
+			f, err = ioutil.TempFile("", "influx_bolt_task_lease_store_test")
+			if err != nil {
+				t.Fatalf("failed to create tempfile for test db %v\n", err)
+			}
+			db, err = bolt.Open(f.Name(), os.ModeTemporary, nil)
+			if err != nil {
+				t.Fatalf("failed to open bolt db for test db %v\n", err)
+			}
+			s, err := boltstore.NewLeaseStore(db, "testbucket")
+			if err != nil {
+				t.Fatalf("failed to create new bolt lease store %v\n", err)
+			}
+			return s
+		},
+		func(t *testing.T, s backend.LeaseStore) {
+			if err := db.Close(); err != nil {
+				t.Error(err)
+			}
+			err := os.Remove(f.Name())
+			if err != nil {
+				t.Error(err)
+			}
+		},
+	)(t)
+}
+
+func TestBoltRunStore(t *testing.T) {
+	var f *os.File
+	var db *bolt.DB
+	storetest.NewRunStoreTest(
+		"boltrunstore",
+		func(t *testing.T) (backend.LogWriter, backend.LogReader) {
+			var err error
+			f, err = ioutil.TempFile("", "influx_bolt_task_run_store_test")
+			if err != nil {
+				t.Fatalf("failed to create tempfile for test db %v\n", err)
+			}
+			db, err = bolt.Open(f.Name(), os.ModeTemporary, nil)
+			if err != nil {
+				t.Fatalf("failed to open bolt db for test db %v\n", err)
+			}
+			s, err := boltstore.NewRunStore(db, "testbucket")
+			if err != nil {
+				t.Fatalf("failed to create new bolt run store %v\n", err)
+			}
+			return s, s
+		},
+		func(t *testing.T, w backend.LogWriter, r backend.LogReader) {
+			if err := db.Close(); err != nil {
+				t.Error(err)
+			}
+			err := os.Remove(f.Name())
+			if err != nil {
+				t.Error(err)
+			}
+		},
+	)(t)
+}
+
+func TestBoltNotificationStore(t *testing.T) {
+	var f *os.File
+	var db *bolt.DB
+	storetest.NewNotificationStoreTest(
+		"boltnotificationstore",
+		func(t *testing.T) backend.NotificationStore {
+			var err error
+			f, err = ioutil.TempFile("", "influx_bolt_task_notification_store_test")
+			if err != nil {
+				t.Fatalf("failed to create tempfile for test db %v\n", err)
+			}
+			db, err = bolt.Open(f.Name(), os.ModeTemporary, nil)
+			if err != nil {
+				t.Fatalf("failed to open bolt db for test db %v\n", err)
+			}
+			s, err := boltstore.NewNotificationStore(db, "testbucket")
+			if err != nil {
+				t.Fatalf("failed to create new bolt notification store %v\n", err)
+			}
+			return s
+		},
+		func(t *testing.T, s backend.NotificationStore) {
+			if err := db.Close(); err != nil {
+				t.Error(err)
+			}
+			err := os.Remove(f.Name())
+			if err != nil {
+				t.Error(err)
+			}
+		},
+	)(t)
+}
+
+func TestBoltStore_BuildIndexes(t *testing.T) {
+	f, err := ioutil.TempFile("", "influx_bolt_task_store_test")
+	if err != nil {
+		t.Fatalf("failed to create tempfile for test db %v\n", err)
+	}
+	defer os.Remove(f.Name())
+	db, err := bolt.Open(f.Name(), os.ModeTemporary, nil)
+	if err != nil {
+		t.Fatalf("failed to open bolt db for test db %v\n", err)
+	}
+	defer db.Close()
+
+	s, err := boltstore.New(db, "testbucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	const script = `option task = {
+		name: "rollup",
+		cron: "* * * * *",
+	}
+
+from(db:"test") |> range(start:-1h)`
+	id, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: []byte{1}, User: []byte{2}, Script: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DisableTask(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	finished := time.Unix(1000, 0)
+	if err := s.RecordRunResult(ctx, id, backend.RunSuccess, finished); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a store created before the task indexes existed.
+	if err := db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket([]byte("testbucket"))
+		for _, b := range []string{"name_index", "status_index", "last_run_time_index"} {
+			if err := root.DeleteBucket([]byte("/tasks/v1/" + b)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = boltstore.New(db, "testbucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, err := s.ListTasks(ctx, backend.TaskSearchParams{NamePrefix: "roll", Status: backend.TaskDisabled})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || ts[0].ID.String() != id.String() {
+		t.Fatalf("expected indexes to be rebuilt for existing task, got %v", ts)
+	}
+	ts, err = s.ListTasks(ctx, backend.TaskSearchParams{LastRunAfter: finished})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 || ts[0].ID.String() != id.String() {
+		t.Fatalf("expected last run time index to be rebuilt for existing task, got %v", ts)
+	}
+}
@@ -0,0 +1,165 @@
+package bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/task/backend"
+)
+
+var (
+	leaseNodesPath = []byte(basePath + "lease_nodes")
+	leasesPath     = []byte(basePath + "leases")
+)
+
+// LeaseStore is a backend.LeaseStore based on "github.com/coreos/bbolt".
+// A bolt database can only be opened by one process at a time,
+// so LeaseStore keeps the leases of the scheduler nodes of a single process across restarts.
+// It provides no failover between processes or hosts; that needs a LeaseStore all the nodes can reach.
+type LeaseStore struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+var _ backend.LeaseStore = (*LeaseStore)(nil)
+
+// storedLease is the JSON encoding of a lease. The task ID is the key of the lease.
+type storedLease struct {
+	NodeID  string `json:"nodeId"`
+	Expires int64  `json:"expires"`
+}
+
+// NewLeaseStore returns a LeaseStore that keeps its leases in the given root bucket of db.
+// The root bucket may be shared with a Store.
+func NewLeaseStore(db *bolt.DB, rootBucket string) (*LeaseStore, error) {
+	if db.IsReadOnly() {
+		return nil, ErrDBReadOnly
+	}
+	bucket := []byte(rootBucket)
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists(bucket)
+		if err != nil {
+			return err
+		}
+		for _, b := range [][]byte{leaseNodesPath, leasesPath} {
+			if _, err := root.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &LeaseStore{db: db, bucket: bucket}, nil
+}
+
+// Heartbeat records that nodeID is alive until now+ttl.
+func (s *LeaseStore) Heartbeat(ctx context.Context, nodeID string, now int64, ttl time.Duration) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		v := make([]byte, 8)
+		binary.BigEndian.PutUint64(v, uint64(backend.LeaseExpires(now, ttl)))
+		return tx.Bucket(s.bucket).Bucket(leaseNodesPath).Put([]byte(nodeID), v)
+	})
+}
+
+// LiveNodes returns the IDs of the nodes whose last heartbeat has not expired as of now, sorted.
+func (s *LeaseStore) LiveNodes(ctx context.Context, now int64) ([]string, error) {
+	var nodes []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Bucket(leaseNodesPath).ForEach(func(k, v []byte) error {
+			if int64(binary.BigEndian.Uint64(v)) > now {
+				nodes = append(nodes, string(k))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(nodes)
+	return nodes, nil
+}
+
+// AcquireLease grants nodeID ownership of taskID until now+ttl, unless another node holds an unexpired lease.
+func (s *LeaseStore) AcquireLease(ctx context.Context, taskID platform.ID, nodeID string, now int64, ttl time.Duration) (backend.Lease, error) {
+	l := backend.Lease{
+		TaskID:  append(platform.ID(nil), taskID...),
+		NodeID:  nodeID,
+		Expires: backend.LeaseExpires(now, ttl),
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket).Bucket(leasesPath)
+		key := padID(taskID)
+		if cur, err := decodeLease(b.Get(key)); err != nil {
+			return err
+		} else if cur != nil && cur.NodeID != nodeID && cur.Expires > now {
+			return backend.ErrLeaseHeld
+		}
+
+		v, err := json.Marshal(storedLease{NodeID: l.NodeID, Expires: l.Expires})
+		if err != nil {
+			return err
+		}
+		return b.Put(key, v)
+	})
+	if err != nil {
+		return backend.Lease{}, err
+	}
+	return l, nil
+}
+
+// ReleaseLease gives up nodeID's ownership of taskID.
+func (s *LeaseStore) ReleaseLease(ctx context.Context, taskID platform.ID, nodeID string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket).Bucket(leasesPath)
+		key := padID(taskID)
+		cur, err := decodeLease(b.Get(key))
+		if err != nil {
+			return err
+		}
+		if cur == nil || cur.NodeID != nodeID {
+			return backend.ErrLeaseNotHeld
+		}
+		return b.Delete(key)
+	})
+}
+
+// FindLease returns the current lease for taskID, or nil if no node has acquired it.
+func (s *LeaseStore) FindLease(ctx context.Context, taskID platform.ID) (*backend.Lease, error) {
+	var l *backend.Lease
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cur, err := decodeLease(tx.Bucket(s.bucket).Bucket(leasesPath).Get(padID(taskID)))
+		if err != nil || cur == nil {
+			return err
+		}
+		l = &backend.Lease{
+			TaskID:  append(platform.ID(nil), taskID...),
+			NodeID:  cur.NodeID,
+			Expires: cur.Expires,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// decodeLease decodes a stored lease, returning nil if v is nil.
+func decodeLease(v []byte) (*storedLease, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var l storedLease
+	if err := json.Unmarshal(v, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
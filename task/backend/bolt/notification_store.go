@@ -0,0 +1,147 @@
+package bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/task/backend"
+)
+
+var (
+	notifyFailuresPath   = []byte(basePath + "notify_failures")
+	notifyDeliveriesPath = []byte(basePath + "notify_deliveries")
+)
+
+// NotificationStore is a backend.NotificationStore based on "github.com/coreos/bbolt".
+type NotificationStore struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+var _ backend.NotificationStore = (*NotificationStore)(nil)
+
+// NewNotificationStore returns a NotificationStore that keeps its data in the given root bucket of db.
+// The root bucket may be shared with a Store.
+func NewNotificationStore(db *bolt.DB, rootBucket string) (*NotificationStore, error) {
+	if db.IsReadOnly() {
+		return nil, ErrDBReadOnly
+	}
+	bucket := []byte(rootBucket)
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists(bucket)
+		if err != nil {
+			return err
+		}
+		for _, b := range [][]byte{notifyFailuresPath, notifyDeliveriesPath} {
+			if _, err := root.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &NotificationStore{db: db, bucket: bucket}, nil
+}
+
+// RecordRunResult updates the consecutive failure count of a task after one of its runs finished.
+func (s *NotificationStore) RecordRunResult(ctx context.Context, taskID platform.ID, failed bool) (int, error) {
+	var previous int
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket).Bucket(notifyFailuresPath)
+		key := padID(taskID)
+		if v := b.Get(key); v != nil {
+			previous = int(binary.BigEndian.Uint64(v))
+		}
+		if !failed {
+			return b.Delete(key)
+		}
+		v := make([]byte, 8)
+		binary.BigEndian.PutUint64(v, uint64(previous+1))
+		return b.Put(key, v)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return previous, nil
+}
+
+// AddDelivery records an attempt to deliver a notification about a task, keeping at most limit attempts per endpoint.
+func (s *NotificationStore) AddDelivery(ctx context.Context, taskID platform.ID, d platform.NotificationDelivery, limit int) error {
+	v, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		tb, err := tx.Bucket(s.bucket).Bucket(notifyDeliveriesPath).CreateBucketIfNotExists(padID(taskID))
+		if err != nil {
+			return err
+		}
+		b, err := tb.CreateBucketIfNotExists([]byte(d.URL))
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		if err := b.Put(key, v); err != nil {
+			return err
+		}
+
+		// Discard the oldest deliveries beyond the limit.
+		var keys [][]byte
+		b.ForEach(func(k, _ []byte) error {
+			keys = append(keys, k)
+			return nil
+		})
+		for len(keys) > limit {
+			if err := b.Delete(keys[0]); err != nil {
+				return err
+			}
+			keys = keys[1:]
+		}
+		return nil
+	})
+}
+
+// FindTaskNotifications returns the consecutive failure count of a task and the recent deliveries to its endpoints.
+func (s *NotificationStore) FindTaskNotifications(ctx context.Context, taskID platform.ID) (*platform.TaskNotifications, error) {
+	n := &platform.TaskNotifications{
+		Deliveries: []platform.NotificationDelivery{},
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket(s.bucket)
+		key := padID(taskID)
+		if v := root.Bucket(notifyFailuresPath).Get(key); v != nil {
+			n.ConsecutiveFailures = int(binary.BigEndian.Uint64(v))
+		}
+
+		tb := root.Bucket(notifyDeliveriesPath).Bucket(key)
+		if tb == nil {
+			return nil
+		}
+		// Endpoint buckets are iterated in URL order, and deliveries in the order they were added.
+		return tb.ForEach(func(url, _ []byte) error {
+			return tb.Bucket(url).ForEach(func(_, v []byte) error {
+				var d platform.NotificationDelivery
+				if err := json.Unmarshal(v, &d); err != nil {
+					return err
+				}
+				n.Deliveries = append(n.Deliveries, d)
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
@@ -0,0 +1,281 @@
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/task/backend"
+)
+
+var (
+	runsPath      = []byte(basePath + "runs")
+	taskByRunPath = []byte(basePath + "task_by_run_id")
+)
+
+// RunStore is a backend.LogWriter and backend.LogReader based on "github.com/coreos/bbolt".
+// It keeps the state, log and statistics of each run, so that they survive a restart.
+type RunStore struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+var (
+	_ backend.LogWriter = (*RunStore)(nil)
+	_ backend.LogReader = (*RunStore)(nil)
+)
+
+// NewRunStore returns a RunStore that keeps its runs in the given root bucket of db.
+// The root bucket may be shared with a Store.
+func NewRunStore(db *bolt.DB, rootBucket string) (*RunStore, error) {
+	if db.IsReadOnly() {
+		return nil, ErrDBReadOnly
+	}
+	bucket := []byte(rootBucket)
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists(bucket)
+		if err != nil {
+			return err
+		}
+		for _, b := range [][]byte{runsPath, taskByRunPath} {
+			if _, err := root.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RunStore{db: db, bucket: bucket}, nil
+}
+
+// UpdateRunState sets the run state and the respective time.
+func (s *RunStore) UpdateRunState(ctx context.Context, task *backend.StoreTask, runID platform.ID, when time.Time, state backend.RunStatus) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(s.bucket)
+		b, err := root.Bucket(runsPath).CreateBucketIfNotExists(task.ID)
+		if err != nil {
+			return err
+		}
+
+		run, err := decodeRun(b.Get(runID))
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			run = &platform.Run{ID: runID, ScriptRevision: task.Revision}
+			if err := root.Bucket(taskByRunPath).Put(runID, task.ID); err != nil {
+				return err
+			}
+		}
+
+		whenStr := when.Format(time.RFC3339)
+		switch state {
+		case backend.RunQueued:
+			run.QueuedAt = whenStr
+		case backend.RunStarted:
+			run.StartTime = whenStr
+		case backend.RunFail, backend.RunSuccess, backend.RunCanceled:
+			run.EndTime = whenStr
+		}
+		run.Status = state.String()
+		return putRun(b, run)
+	})
+}
+
+// AddRunLog adds a log line to the run.
+func (s *RunStore) AddRunLog(ctx context.Context, task *backend.StoreTask, runID platform.ID, when time.Time, log string) error {
+	return s.updateRun(task.ID, runID, func(run *platform.Run) {
+		log = fmt.Sprintf("%s: %s", when.Format(time.RFC3339), log)
+		if run.Log != "" {
+			run.Log += "\n"
+		}
+		run.Log += platform.Log(log)
+	})
+}
+
+// SetRunStatistics records the statistics collected while executing the run.
+func (s *RunStore) SetRunStatistics(ctx context.Context, task *backend.StoreTask, runID platform.ID, stats platform.RunStatistics) error {
+	return s.updateRun(task.ID, runID, func(run *platform.Run) {
+		run.Statistics = &stats
+	})
+}
+
+// updateRun applies fn to an existing run of a task.
+func (s *RunStore) updateRun(taskID, runID platform.ID, fn func(*platform.Run)) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket).Bucket(runsPath).Bucket(taskID)
+		if b == nil {
+			return backend.ErrRunNotFound
+		}
+		run, err := decodeRun(b.Get(runID))
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return backend.ErrRunNotFound
+		}
+		fn(run)
+		return putRun(b, run)
+	})
+}
+
+// ListRuns returns a list of runs belonging to a task, ordered by the time they were queued.
+func (s *RunStore) ListRuns(ctx context.Context, runFilter platform.RunFilter) ([]*platform.Run, error) {
+	if runFilter.Task == nil {
+		return nil, errors.New("task is required")
+	}
+
+	runs, err := s.taskRuns(*runFilter.Task)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeCheck := runFilter.BeforeTime != ""
+	afterCheck := runFilter.AfterTime != ""
+
+	afterIndex := 0
+	beforeIndex := len(runs)
+
+	for i, run := range runs {
+		if afterIndex == 0 && runFilter.After != nil && runFilter.After.String() == run.ID.String() {
+			afterIndex = i
+		}
+
+		if run.QueuedAt != "" {
+			if afterCheck && afterIndex == 0 && run.QueuedAt > runFilter.AfterTime {
+				afterIndex = i
+			}
+
+			if beforeCheck && beforeIndex == len(runs) && runFilter.BeforeTime < run.QueuedAt {
+				beforeIndex = i
+				break
+			}
+		}
+	}
+
+	if runFilter.Limit != 0 && beforeIndex-afterIndex > runFilter.Limit {
+		beforeIndex = afterIndex + runFilter.Limit
+	}
+
+	return runs[afterIndex:beforeIndex], nil
+}
+
+// FindRunByID finds a run given a taskID and runID.
+func (s *RunStore) FindRunByID(ctx context.Context, taskID, runID platform.ID) (*platform.Run, error) {
+	var run *platform.Run
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket).Bucket(runsPath).Bucket(taskID)
+		if b == nil {
+			return backend.ErrRunNotFound
+		}
+		var err error
+		run, err = decodeRun(b.Get(runID))
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return backend.ErrRunNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// ListLogs lists logs for a task or a specified run of a task.
+func (s *RunStore) ListLogs(ctx context.Context, logFilter platform.LogFilter) ([]platform.Log, error) {
+	if logFilter.Task == nil && logFilter.Run == nil {
+		return nil, errors.New("task or run is required")
+	}
+
+	if logFilter.Run != nil {
+		var taskID platform.ID
+		err := s.db.View(func(tx *bolt.Tx) error {
+			if id := tx.Bucket(s.bucket).Bucket(taskByRunPath).Get(*logFilter.Run); id != nil {
+				taskID = append(platform.ID(nil), id...)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if taskID == nil {
+			return nil, backend.ErrRunNotFound
+		}
+		run, err := s.FindRunByID(ctx, taskID, *logFilter.Run)
+		if err != nil {
+			return nil, err
+		}
+		return []platform.Log{run.Log}, nil
+	}
+
+	runs, err := s.taskRuns(*logFilter.Task)
+	if err == backend.ErrRunNotFound {
+		return []platform.Log{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]platform.Log, 0, len(runs))
+	for _, run := range runs {
+		logs = append(logs, run.Log)
+	}
+	return logs, nil
+}
+
+// taskRuns returns every run of a task, ordered by the time they were queued.
+func (s *RunStore) taskRuns(taskID platform.ID) ([]*platform.Run, error) {
+	var runs []*platform.Run
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket).Bucket(runsPath).Bucket(taskID)
+		if b == nil {
+			return backend.ErrRunNotFound
+		}
+		return b.ForEach(func(k, v []byte) error {
+			run, err := decodeRun(v)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].QueuedAt < runs[j].QueuedAt
+	})
+	return runs, nil
+}
+
+// decodeRun decodes a stored run, returning nil if v is nil.
+func decodeRun(v []byte) (*platform.Run, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var run platform.Run
+	if err := json.Unmarshal(v, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func putRun(b *bolt.Bucket, run *platform.Run) error {
+	v, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return b.Put(run.ID, v)
+}
@@ -40,13 +40,13 @@ func New(scheduler backend.Scheduler, st backend.Store, opts ...Option) backend.
 	return c
 }
 
-func (c *Coordinator) CreateTask(ctx context.Context, org, user platform.ID, script string) (platform.ID, error) {
-	opt, err := options.FromScript(script)
+func (c *Coordinator) CreateTask(ctx context.Context, req backend.CreateTaskRequest) (platform.ID, error) {
+	opt, err := options.FromScript(req.Script)
 	if err != nil {
 		return nil, err
 	}
 
-	id, err := c.Store.CreateTask(ctx, org, user, script)
+	id, err := c.Store.CreateTask(ctx, req)
 	if err != nil {
 		return id, err
 	}
@@ -67,13 +67,13 @@ func (c *Coordinator) CreateTask(ctx context.Context, org, user platform.ID, scr
 	return id, nil
 }
 
-func (c *Coordinator) ModifyTask(ctx context.Context, id platform.ID, newScript string) error {
+func (c *Coordinator) ModifyTask(ctx context.Context, id, author platform.ID, newScript string) error {
 	opt, err := options.FromScript(newScript)
 	if err != nil {
 		return err
 	}
 
-	if err := c.Store.ModifyTask(ctx, id, newScript); err != nil {
+	if err := c.Store.ModifyTask(ctx, id, author, newScript); err != nil {
 		return err
 	}
 
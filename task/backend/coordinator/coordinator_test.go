@@ -33,7 +33,7 @@ func TestCoordinator(t *testing.T) {
 	orgID := platform.ID("org")
 	usrID := platform.ID("usr")
 	script := `option task = {name: "a task",cron: "* * * * *"} from(db:"test") |> range(start:-1h)`
-	id, err := coord.CreateTask(context.Background(), orgID, usrID, script)
+	id, err := coord.CreateTask(context.Background(), backend.CreateTaskRequest{Org: orgID, User: usrID, Script: script})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -65,7 +65,7 @@ func TestCoordinator(t *testing.T) {
 		t.Fatal("task sent to scheduler doesnt match task created")
 	}
 
-	id, err = coord.CreateTask(context.Background(), orgID, usrID, script)
+	id, err = coord.CreateTask(context.Background(), backend.CreateTaskRequest{Org: orgID, User: usrID, Script: script})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -104,7 +104,7 @@ func TestCoordinator(t *testing.T) {
 	}
 
 	newScript := `option task = {name: "a task",cron: "1 * * * *"} from(db:"test") |> range(start:-2h)`
-	err = coord.ModifyTask(context.Background(), id, newScript)
+	err = coord.ModifyTask(context.Background(), id, []byte{3}, newScript)
 	if err != nil {
 		t.Fatal(err)
 	}
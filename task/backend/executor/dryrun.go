@@ -0,0 +1,187 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/functions"
+	"github.com/influxdata/platform/query/plan"
+	"github.com/influxdata/platform/task/options"
+)
+
+// maxDryRunRows is the maximum number of rows returned from a dry run, across all tables.
+const maxDryRunRows = 1000
+
+type dryRunService struct {
+	svc     query.QueryService
+	buckets platform.BucketService
+}
+
+var _ platform.TaskDryRunService = (*dryRunService)(nil)
+
+// NewDryRunService returns a platform.TaskDryRunService that executes dry runs against svc.
+// If buckets is not nil, the buckets read by a script must exist in the task's organization.
+func NewDryRunService(svc query.QueryService, buckets platform.BucketService) platform.TaskDryRunService {
+	return &dryRunService{svc: svc, buckets: buckets}
+}
+
+func (d *dryRunService) DryRunTask(ctx context.Context, dr platform.TaskDryRun) (*platform.TaskDryRunResult, error) {
+	if len(dr.Organization) == 0 {
+		return nil, fmt.Errorf("missing organization ID")
+	}
+
+	opts, err := options.FromScript(dr.Flux)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if dr.Now != "" {
+		if now, err = time.Parse(time.RFC3339, dr.Now); err != nil {
+			return nil, fmt.Errorf("invalid now: %v", err)
+		}
+	}
+
+	spec, err := query.Compile(ctx, dr.Flux, now)
+	if err != nil {
+		return nil, err
+	}
+	lp, err := plan.NewLogicalPlanner().Plan(spec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := plan.NewPlanner().Plan(lp, nil); err != nil {
+		return nil, err
+	}
+	if err := d.checkBuckets(ctx, dr.Organization, spec); err != nil {
+		return nil, err
+	}
+
+	res := &platform.TaskDryRunResult{
+		Name:     opts.Name,
+		Cron:     opts.Cron,
+		Timezone: opts.Timezone,
+	}
+	if opts.Every != 0 {
+		res.Every = opts.Every.String()
+	}
+	if opts.Offset != 0 {
+		res.Offset = opts.Offset.String()
+	}
+	if dr.Now == "" {
+		return res, nil
+	}
+
+	it, err := d.svc.Query(ctx, &query.Request{
+		OrganizationID: dr.Organization,
+		Compiler: query.SpecCompiler{
+			Spec: functions.YieldSinks(spec),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Cancel()
+
+	rows := 0
+	for it.More() {
+		r := it.Next()
+		if err := r.Tables().Do(func(tbl query.Table) error {
+			t, total, err := readDryRunTable(r.Name(), tbl, maxDryRunRows-rows)
+			if err != nil {
+				return err
+			}
+			rows += len(t.Rows)
+			if len(t.Rows) < total {
+				res.Truncated = true
+			}
+			res.Tables = append(res.Tables, t)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// checkBuckets returns an error if any bucket read by spec does not exist in org.
+func (d *dryRunService) checkBuckets(ctx context.Context, org platform.ID, spec *query.Spec) error {
+	if d.buckets == nil {
+		return nil
+	}
+	return spec.Walk(func(o *query.Operation) error {
+		from, ok := o.Spec.(*functions.FromOpSpec)
+		if !ok {
+			return nil
+		}
+		switch {
+		case len(from.BucketID) > 0:
+			b, err := d.buckets.FindBucketByID(ctx, from.BucketID)
+			if err != nil {
+				return fmt.Errorf("bucket %s: %v", from.BucketID.String(), err)
+			}
+			if b.OrganizationID.String() != org.String() {
+				return fmt.Errorf("bucket %s does not belong to organization %s", from.BucketID.String(), org.String())
+			}
+		case from.Bucket != "":
+			name := from.Bucket
+			if _, err := d.buckets.FindBucket(ctx, platform.BucketFilter{Name: &name, OrganizationID: &org}); err != nil {
+				return fmt.Errorf("bucket %q: %v", name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// readDryRunTable converts at most limit rows of tbl, and returns the total number of rows in tbl.
+func readDryRunTable(result string, tbl query.Table, limit int) (*platform.DryRunTable, int, error) {
+	t := &platform.DryRunTable{
+		Result: result,
+		Rows:   [][]interface{}{},
+	}
+	key := tbl.Key()
+	for _, c := range tbl.Cols() {
+		t.Columns = append(t.Columns, platform.DryRunColumn{
+			Label: c.Label,
+			Type:  c.Type.String(),
+			Group: key.HasCol(c.Label),
+		})
+	}
+
+	total := 0
+	err := tbl.Do(func(cr query.ColReader) error {
+		total += cr.Len()
+		for i := 0; i < cr.Len() && len(t.Rows) < limit; i++ {
+			row := make([]interface{}, len(cr.Cols()))
+			for j, c := range cr.Cols() {
+				if cr.IsNull(i, j) {
+					// Left as nil, so that the preview shows null rather than the zero value of the column type.
+					continue
+				}
+				switch c.Type {
+				case query.TBool:
+					row[j] = cr.Bools(j)[i]
+				case query.TInt:
+					row[j] = cr.Ints(j)[i]
+				case query.TUInt:
+					row[j] = cr.UInts(j)[i]
+				case query.TFloat:
+					row[j] = cr.Floats(j)[i]
+				case query.TString:
+					row[j] = cr.Strings(j)[i]
+				case query.TTime:
+					row[j] = cr.Times(j)[i].Time().UTC().Format(time.RFC3339Nano)
+				}
+			}
+			t.Rows = append(t.Rows, row)
+		}
+		return nil
+	})
+	return t, total, err
+}
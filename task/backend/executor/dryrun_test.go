@@ -0,0 +1,310 @@
+package executor_test
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/control"
+	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/functions"
+	_ "github.com/influxdata/platform/query/functions/sqlite"
+	"github.com/influxdata/platform/task/backend/executor"
+)
+
+const dryRunCSV = `
+#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,string,string,double
+#group,false,false,true,true,false,true,true,false
+#default,_result,,,,,,,
+,result,table,_start,_stop,_time,_measurement,host,_value
+,,0,2018-04-17T00:00:00Z,2018-04-17T00:05:00Z,2018-04-17T00:00:00Z,cpu,A,42
+,,0,2018-04-17T00:00:00Z,2018-04-17T00:05:00Z,2018-04-17T00:00:01Z,cpu,A,43
+`
+
+// dryRunNullCSV has a null value in its second row.
+const dryRunNullCSV = `
+#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,string,string,double
+#group,false,false,true,true,false,true,true,false
+#default,_result,,,,,,,
+,result,table,_start,_stop,_time,_measurement,host,_value
+,,0,2018-04-17T00:00:00Z,2018-04-17T00:05:00Z,2018-04-17T00:00:00Z,cpu,A,42
+,,0,2018-04-17T00:00:00Z,2018-04-17T00:05:00Z,2018-04-17T00:00:01Z,cpu,A,
+`
+
+func newDryRunService(deps execute.Dependencies) platform.TaskDryRunService {
+	svc := query.QueryServiceBridge{
+		AsyncQueryService: control.New(control.Config{
+			ExecutorDependencies: deps,
+			ConcurrencyQuota:     1,
+			MemoryBytesQuota:     1 << 20,
+		}),
+	}
+	return executor.NewDryRunService(svc, nil)
+}
+
+func TestDryRunService(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer ts.Close()
+
+	from, cleanup := writeDryRunCSV(t, dryRunCSV)
+	defer cleanup()
+
+	script := `option task = {name: "dry", every: 1h}
+` + from + `
+	|> range(start: 2018-04-17T00:00:00Z, stop: 2018-04-17T00:05:00Z)
+	|> toHTTP(url: "` + ts.URL + `")`
+
+	svc := newDryRunService(make(execute.Dependencies))
+
+	t.Run("validate", func(t *testing.T) {
+		res, err := svc.DryRunTask(context.Background(), platform.TaskDryRun{Organization: platform.ID("org"), Flux: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Name != "dry" || res.Every != "1h0m0s" {
+			t.Fatalf("unexpected options in result: %+v", res)
+		}
+		if len(res.Tables) != 0 {
+			t.Fatalf("expected no tables without now, got %d", len(res.Tables))
+		}
+	})
+
+	t.Run("execute", func(t *testing.T) {
+		res, err := svc.DryRunTask(context.Background(), platform.TaskDryRun{
+			Organization: platform.ID("org"),
+			Flux:         script,
+			Now:          "2018-04-17T00:05:00Z",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Tables) != 1 {
+			t.Fatalf("expected 1 table, got %d", len(res.Tables))
+		}
+		tbl := res.Tables[0]
+		if !strings.HasPrefix(tbl.Result, "toHTTP") {
+			t.Fatalf("expected the sink's data to be reported under its operation, got result %q", tbl.Result)
+		}
+		if len(tbl.Rows) != 2 {
+			t.Fatalf("expected 2 rows, got %d", len(tbl.Rows))
+		}
+		if res.Truncated {
+			t.Fatal("did not expect result to be truncated")
+		}
+		if n := atomic.LoadInt32(&requests); n != 0 {
+			t.Fatalf("expected sink not to be written to, got %d requests", n)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, script := range map[string]string{
+			"options": from + ` |> range(start: -1h)`,
+			"compile": `option task = {name: "dry", every: 1h} fromCSV(csv: "a") |> nope()`,
+			"plan":    `option task = {name: "dry", every: 1h} ` + from,
+		} {
+			if _, err := svc.DryRunTask(context.Background(), platform.TaskDryRun{Organization: platform.ID("org"), Flux: script}); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+}
+
+// countingPointsWriter is a functions.PointsWriter that counts the points written to it.
+type countingPointsWriter struct {
+	points int32
+}
+
+func (w *countingPointsWriter) WritePoints(_ context.Context, _, _ platform.ID, points []functions.Point) (int64, error) {
+	atomic.AddInt32(&w.points, int32(len(points)))
+	return 0, nil
+}
+
+type staticBucketLookup platform.ID
+
+func (l staticBucketLookup) Lookup(platform.ID, string) (platform.ID, bool) {
+	return platform.ID(l), true
+}
+
+type staticOrganizationLookup platform.ID
+
+func (l staticOrganizationLookup) Lookup(context.Context, string) (platform.ID, bool) {
+	return platform.ID(l), true
+}
+
+// writeDryRunCSV writes the data to a file and returns a fromCSV call that reads it.
+func writeDryRunCSV(t *testing.T, data string) (string, func()) {
+	t.Helper()
+	// Flux string literals cannot span lines, so the data is read from a file.
+	f, err := ioutil.TempFile("", "task_dry_run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return `fromCSV(file: "` + f.Name() + `")`, func() { os.Remove(f.Name()) }
+}
+
+func TestDryRunService_To(t *testing.T) {
+	w := new(countingPointsWriter)
+	deps := make(execute.Dependencies)
+	if err := functions.InjectToDependencies(deps, functions.ToDependencies{
+		PointsWriter:       w,
+		BucketLookup:       staticBucketLookup("bucket"),
+		OrganizationLookup: staticOrganizationLookup("org"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	svc := newDryRunService(deps)
+
+	from, cleanup := writeDryRunCSV(t, dryRunCSV)
+	defer cleanup()
+	script := `option task = {name: "dry", every: 1h}
+` + from + `
+	|> range(start: 2018-04-17T00:00:00Z, stop: 2018-04-17T00:05:00Z)
+	|> to(bucket: "downsampled")`
+
+	res, err := svc.DryRunTask(context.Background(), platform.TaskDryRun{
+		Organization: platform.ID("org"),
+		Flux:         script,
+		Now:          "2018-04-17T00:05:00Z",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Tables) != 1 || !strings.HasPrefix(res.Tables[0].Result, "to") || len(res.Tables[0].Rows) != 2 {
+		t.Fatalf("expected the data to() would write to be reported, got %+v", res.Tables)
+	}
+	if n := atomic.LoadInt32(&w.points); n != 0 {
+		t.Fatalf("expected no points to be written, got %d", n)
+	}
+}
+
+func TestDryRunService_SinkWithChildren(t *testing.T) {
+	w := new(countingPointsWriter)
+	deps := make(execute.Dependencies)
+	if err := functions.InjectToDependencies(deps, functions.ToDependencies{
+		PointsWriter:       w,
+		BucketLookup:       staticBucketLookup("bucket"),
+		OrganizationLookup: staticOrganizationLookup("org"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	svc := newDryRunService(deps)
+
+	from, cleanup := writeDryRunCSV(t, dryRunCSV)
+	defer cleanup()
+	script := `option task = {name: "dry", every: 1h}
+` + from + `
+	|> range(start: 2018-04-17T00:00:00Z, stop: 2018-04-17T00:05:00Z)
+	|> to(bucket: "downsampled")
+	|> map(fn: (r) => ({_time: r._time, _value: r._value * 2.0}))`
+
+	res, err := svc.DryRunTask(context.Background(), platform.TaskDryRun{
+		Organization: platform.ID("org"),
+		Flux:         script,
+		Now:          "2018-04-17T00:05:00Z",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Tables) != 1 || res.Tables[0].Result != "_result" || len(res.Tables[0].Rows) != 2 {
+		t.Fatalf("expected the operations after to() to run, got %+v", res.Tables)
+	}
+	if n := atomic.LoadInt32(&w.points); n != 0 {
+		t.Fatalf("expected no points to be written, got %d", n)
+	}
+}
+
+func TestDryRunService_Nulls(t *testing.T) {
+	from, cleanup := writeDryRunCSV(t, dryRunNullCSV)
+	defer cleanup()
+	script := `option task = {name: "dry", every: 1h}
+` + from + `
+	|> range(start: 2018-04-17T00:00:00Z, stop: 2018-04-17T00:05:00Z)`
+
+	res, err := newDryRunService(make(execute.Dependencies)).DryRunTask(context.Background(), platform.TaskDryRun{
+		Organization: platform.ID("org"),
+		Flux:         script,
+		Now:          "2018-04-17T00:05:00Z",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Tables) != 1 || len(res.Tables[0].Rows) != 2 {
+		t.Fatalf("expected 1 table with 2 rows, got %+v", res.Tables)
+	}
+	tbl := res.Tables[0]
+	j := -1
+	for i, c := range tbl.Columns {
+		if c.Label == "_value" {
+			j = i
+		}
+	}
+	if j < 0 {
+		t.Fatalf("expected a _value column, got %+v", tbl.Columns)
+	}
+	if got := tbl.Rows[0][j]; got != 42.0 {
+		t.Fatalf("expected 42, got %v", got)
+	}
+	if got := tbl.Rows[1][j]; got != nil {
+		t.Fatalf("expected null to be rendered as nil, got %v", got)
+	}
+}
+
+func TestDryRunService_SQLTo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "task_dry_run_sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dsn := filepath.Join(dir, "dry.db")
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE cpu (_start DATETIME, _stop DATETIME, _time DATETIME, _measurement TEXT, host TEXT, _value REAL)`); err != nil {
+		t.Fatal(err)
+	}
+
+	from, cleanup := writeDryRunCSV(t, dryRunCSV)
+	defer cleanup()
+	script := `option task = {name: "dry", every: 1h}
+` + from + `
+	|> range(start: 2018-04-17T00:00:00Z, stop: 2018-04-17T00:05:00Z)
+	|> sql.to(driverName: "sqlite3", dataSourceName: "` + dsn + `", table: "cpu")`
+
+	res, err := newDryRunService(make(execute.Dependencies)).DryRunTask(context.Background(), platform.TaskDryRun{
+		Organization: platform.ID("org"),
+		Flux:         script,
+		Now:          "2018-04-17T00:05:00Z",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Tables) != 1 || !strings.HasPrefix(res.Tables[0].Result, "toSQL") || len(res.Tables[0].Rows) != 2 {
+		t.Fatalf("expected the data sql.to would insert to be reported, got %+v", res.Tables)
+	}
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM cpu`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatalf("expected no rows to be inserted, got %d", n)
+	}
+}
@@ -4,19 +4,31 @@ package executor
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/platform"
 	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/execute"
 	"github.com/influxdata/platform/task/backend"
+	"github.com/influxdata/platform/task/options"
 	"go.uber.org/zap"
 )
 
+// AuthorizationFinder finds the authorizations that runs of tasks execute with.
+// platform.AuthorizationService satisfies it.
+type AuthorizationFinder interface {
+	FindAuthorizationByID(ctx context.Context, id platform.ID) (*platform.Authorization, error)
+}
+
 // queryServiceExecutor is an implementation of backend.Executor that depends on a QueryService.
 type queryServiceExecutor struct {
 	svc    query.QueryService
 	st     backend.Store
+	as     AuthorizationFinder
 	logger *zap.Logger
 }
 
@@ -25,24 +37,82 @@ var _ backend.Executor = (*queryServiceExecutor)(nil)
 // NewQueryServiceExecutor returns a new executor based on the given QueryService.
 // In general, you should prefer NewAsyncQueryServiceExecutor, as that code is smaller and simpler,
 // because asynchronous queries are more in line with the Executor interface.
-func NewQueryServiceExecutor(logger *zap.Logger, svc query.QueryService, st backend.Store) backend.Executor {
-	return &queryServiceExecutor{logger: logger, svc: svc, st: st}
+//
+// Runs execute with the authorization their task was created with, found through as.
+// If as is nil, runs execute without an authorization.
+func NewQueryServiceExecutor(logger *zap.Logger, svc query.QueryService, st backend.Store, as AuthorizationFinder) backend.Executor {
+	return &queryServiceExecutor{logger: logger, svc: svc, st: st, as: as}
 }
 
 func (e *queryServiceExecutor) Execute(ctx context.Context, run backend.QueuedRun) (backend.RunPromise, error) {
-	t, err := e.st.FindTaskByID(ctx, run.TaskID)
+	t, err := findRunTask(ctx, e.st, run)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := options.FromScript(t.Script)
 	if err != nil {
 		return nil, err
 	}
 
-	return newSyncRunPromise(ctx, run, e, t), nil
+	return newSyncRunPromise(ctx, run, e, t, opts), nil
+}
+
+// runRequest returns the request for the query of a run of t.
+// The request carries the authorization the task was created with, so that the run can write data.
+func runRequest(ctx context.Context, as AuthorizationFinder, t *backend.StoreTask, spec *query.Spec) (*query.Request, error) {
+	req := &query.Request{
+		OrganizationID: t.Org,
+		Compiler: query.SpecCompiler{
+			Spec: spec,
+		},
+	}
+	if as == nil || len(t.AuthorizationID) == 0 {
+		return req, nil
+	}
+
+	a, err := as.FindAuthorizationByID(ctx, t.AuthorizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find authorization for task %s: %v", t.ID.String(), err)
+	}
+	req.Authorization = a
+	return req, nil
+}
+
+// findRunTask returns the task for the queued run,
+// with the script of the revision the run was created with.
+func findRunTask(ctx context.Context, st backend.Store, run backend.QueuedRun) (*backend.StoreTask, error) {
+	t, err := st.FindTaskByID(ctx, run.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("task %s not found", run.TaskID.String())
+	}
+
+	if run.Revision == 0 || run.Revision == t.Revision {
+		return t, nil
+	}
+
+	// The task was modified after the run was created.
+	rev, err := st.FindScriptRevision(ctx, run.TaskID, run.Revision)
+	if err != nil {
+		return nil, err
+	}
+	if rev == nil {
+		return nil, fmt.Errorf("revision %d not found for task %s", run.Revision, run.TaskID.String())
+	}
+	t.Script = rev.Script
+	t.Revision = rev.Revision
+	return t, nil
 }
 
 // syncRunPromise implements backend.RunPromise for a synchronous QueryService.
 type syncRunPromise struct {
 	qr     backend.QueuedRun
 	svc    query.QueryService
+	as     AuthorizationFinder
 	t      *backend.StoreTask
+	opts   options.Options
 	ctx    context.Context
 	cancel context.CancelFunc
 	logger *zap.Logger
@@ -56,13 +126,15 @@ type syncRunPromise struct {
 
 var _ backend.RunPromise = (*syncRunPromise)(nil)
 
-func newSyncRunPromise(ctx context.Context, qr backend.QueuedRun, e *queryServiceExecutor, t *backend.StoreTask) *syncRunPromise {
+func newSyncRunPromise(ctx context.Context, qr backend.QueuedRun, e *queryServiceExecutor, t *backend.StoreTask, opts options.Options) *syncRunPromise {
 	ctx, cancel := context.WithCancel(ctx)
 	log, logEnd := logger.NewOperation(e.logger, "Executing task", "execute")
 	rp := &syncRunPromise{
 		qr:     qr,
 		svc:    e.svc,
+		as:     e.as,
 		t:      t,
+		opts:   opts,
 		logger: log,
 		logEnd: logEnd,
 		ctx:    ctx,
@@ -72,6 +144,9 @@ func newSyncRunPromise(ctx context.Context, qr backend.QueuedRun, e *queryServic
 
 	go rp.doQuery()
 	go rp.cancelOnContextDone()
+	if opts.Timeout > 0 {
+		go failOnTimeout(rp.ready, opts.Timeout, rp.finish)
+	}
 
 	return rp
 }
@@ -95,6 +170,17 @@ func (p *syncRunPromise) Cancel() {
 }
 
 func (p *syncRunPromise) finish(res *runResult, err error) {
+	p.finishWith(res, err, nil)
+}
+
+// finishCommit finishes p with res, committing the run's deferred work first if res succeeded.
+// The work is only committed when this call is the one that finishes p,
+// so a run that already timed out or was canceled never commits.
+func (p *syncRunPromise) finishCommit(res *runResult, commits *execute.Committer) {
+	p.finishWith(res, nil, commits)
+}
+
+func (p *syncRunPromise) finishWith(res *runResult, err error, commits *execute.Committer) {
 	p.finishOnce.Do(func() {
 		defer p.logEnd()
 
@@ -103,6 +189,9 @@ func (p *syncRunPromise) finish(res *runResult, err error) {
 		// If afterwards, then p.cancel is just a resource cleanup.
 		defer p.cancel()
 
+		if commits != nil && res.err == nil {
+			res.err = commits.Commit(p.ctx)
+		}
 		p.res, p.err = res, err
 		close(p.ready)
 
@@ -122,27 +211,45 @@ func (p *syncRunPromise) doQuery() {
 		p.finish(nil, err)
 		return
 	}
+	spec.Resources = p.opts.ResourceManagement()
 
-	req := &query.Request{
-		OrganizationID: p.t.Org,
-		Compiler: query.SpecCompiler{
-			Spec: spec,
-		},
+	req, err := runRequest(p.ctx, p.as, p.t, spec)
+	if err != nil {
+		p.finish(nil, err)
+		return
 	}
-	it, err := p.svc.Query(p.ctx, req)
+	// Sources defer work, such as committing consumed offsets, until the run has succeeded.
+	commits := new(execute.Committer)
+	defer commits.Discard()
+	it, err := p.svc.Query(execute.ContextWithCommitter(p.ctx, commits), req)
 	if err != nil {
 		// Assume the error should not be part of the runResult.
 		p.finish(nil, err)
 		return
 	}
 
-	// Drain the result iterator.
+	// Drain the result iterator, counting what each yield produced.
+	var yields []platform.YieldStatistics
+	var readErr error
 	for it.More() {
 		// Is it okay to assume it.Err will be set if the query context is canceled?
-		_ = it.Next()
+		ys, err := readResult(it.Next())
+		if err != nil && readErr == nil {
+			readErr = err
+		}
+		yields = append(yields, ys)
 	}
 
-	p.finish(&runResult{err: it.Err()}, nil)
+	rr := &runResult{err: it.Err()}
+	if rr.err == nil {
+		rr.err = readErr
+	}
+	if s, ok := it.(query.Statisticser); ok {
+		rr.stats = runStatistics(s.Statistics(), yields)
+	} else {
+		rr.stats = runStatistics(query.Statistics{}, yields)
+	}
+	p.finishCommit(rr, commits)
 }
 
 func (p *syncRunPromise) cancelOnContextDone() {
@@ -161,18 +268,26 @@ func (p *syncRunPromise) cancelOnContextDone() {
 type asyncQueryServiceExecutor struct {
 	svc    query.AsyncQueryService
 	st     backend.Store
+	as     AuthorizationFinder
 	logger *zap.Logger
 }
 
 var _ backend.Executor = (*asyncQueryServiceExecutor)(nil)
 
 // NewQueryServiceExecutor returns a new executor based on the given AsyncQueryService.
-func NewAsyncQueryServiceExecutor(logger *zap.Logger, svc query.AsyncQueryService, st backend.Store) backend.Executor {
-	return &asyncQueryServiceExecutor{logger: logger, svc: svc, st: st}
+// Runs execute with the authorization their task was created with, found through as.
+// If as is nil, runs execute without an authorization.
+func NewAsyncQueryServiceExecutor(logger *zap.Logger, svc query.AsyncQueryService, st backend.Store, as AuthorizationFinder) backend.Executor {
+	return &asyncQueryServiceExecutor{logger: logger, svc: svc, st: st, as: as}
 }
 
 func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.QueuedRun) (backend.RunPromise, error) {
-	t, err := e.st.FindTaskByID(ctx, run.TaskID)
+	t, err := findRunTask(ctx, e.st, run)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := options.FromScript(t.Script)
 	if err != nil {
 		return nil, err
 	}
@@ -181,25 +296,28 @@ func (e *asyncQueryServiceExecutor) Execute(ctx context.Context, run backend.Que
 	if err != nil {
 		return nil, err
 	}
+	spec.Resources = opts.ResourceManagement()
 
-	req := &query.Request{
-		OrganizationID: t.Org,
-		Compiler: query.SpecCompiler{
-			Spec: spec,
-		},
+	req, err := runRequest(ctx, e.as, t, spec)
+	if err != nil {
+		return nil, err
 	}
-	q, err := e.svc.Query(ctx, req)
+	// Sources defer work, such as committing consumed offsets, until the run has succeeded.
+	commits := new(execute.Committer)
+	q, err := e.svc.Query(execute.ContextWithCommitter(ctx, commits), req)
 	if err != nil {
+		commits.Discard()
 		return nil, err
 	}
 
-	return newAsyncRunPromise(run, q, e), nil
+	return newAsyncRunPromise(run, q, commits, e, opts.Timeout), nil
 }
 
 // asyncRunPromise implements backend.RunPromise for an AsyncQueryService.
 type asyncRunPromise struct {
-	qr backend.QueuedRun
-	q  query.Query
+	qr      backend.QueuedRun
+	q       query.Query
+	commits *execute.Committer
 
 	logger *zap.Logger
 	logEnd func()
@@ -212,19 +330,24 @@ type asyncRunPromise struct {
 
 var _ backend.RunPromise = (*asyncRunPromise)(nil)
 
-func newAsyncRunPromise(qr backend.QueuedRun, q query.Query, e *asyncQueryServiceExecutor) *asyncRunPromise {
+func newAsyncRunPromise(qr backend.QueuedRun, q query.Query, commits *execute.Committer, e *asyncQueryServiceExecutor, timeout time.Duration) *asyncRunPromise {
 	log, logEnd := logger.NewOperation(e.logger, "Executing task", "execute")
 
 	p := &asyncRunPromise{
-		qr:    qr,
-		q:     q,
-		ready: make(chan struct{}),
+		qr:      qr,
+		q:       q,
+		commits: commits,
+		ready:   make(chan struct{}),
 
 		logger: log,
 		logEnd: logEnd,
 	}
 
 	go p.followQuery()
+	if timeout > 0 {
+		// Finishing the promise cancels the query in followQuery.
+		go failOnTimeout(p.ready, timeout, p.finish)
+	}
 	return p
 }
 
@@ -249,33 +372,64 @@ func (p *asyncRunPromise) Cancel() {
 // followQuery waits for the query to become ready and sets p's results.
 // If the promise is finished somewhere else first, such as if it is canceled,
 // followQuery will return.
+// Every path calls p.q.Done exactly once, as it is always needed after the query is finished.
 func (p *asyncRunPromise) followQuery() {
-	// Always need to call Done after query is finished.
-	defer p.q.Done()
+	// Commits that were not committed belong to a failed run.
+	defer p.commits.Discard()
 
 	select {
 	case <-p.ready:
 		// The promise was finished somewhere else, so we don't need to call p.finish.
 		// But we do need to cancel the query. This could be a no-op.
 		p.q.Cancel()
-	case _, ok := <-p.q.Ready():
+		p.q.Done()
+	case results, ok := <-p.q.Ready():
 		if !ok {
-			// Something went wrong with the query. Set the error in the run result.
+			// Something went wrong with the query. Set the error in the run result,
+			// with the statistics of what the query did before it failed.
 			rr := &runResult{err: p.q.Err()}
+			p.q.Done()
+			rr.stats = runStatistics(p.q.Statistics(), nil)
 			p.finish(rr, nil)
 			return
 		}
 
-		// Otherwise, query was successful.
-		// TODO(mr): collect query statistics, once RunResult interface supports them?
-		p.finish(new(runResult), nil)
+		// Otherwise, query was successful. Read the results so the query can complete.
+		rr := new(runResult)
+		yields := make([]platform.YieldStatistics, 0, len(results))
+		for _, r := range results {
+			ys, err := readResult(r)
+			if err != nil && rr.err == nil {
+				rr.err = err
+			}
+			yields = append(yields, ys)
+		}
+
+		// Statistics are only complete once the query has finished.
+		p.q.Done()
+		rr.stats = runStatistics(p.q.Statistics(), yields)
+		p.finishCommit(rr)
 	}
 }
 
 func (p *asyncRunPromise) finish(res *runResult, err error) {
+	p.finishWith(res, err, false)
+}
+
+// finishCommit finishes p with res, committing the run's deferred work first if res succeeded.
+// The work is only committed when this call is the one that finishes p,
+// so a run that already timed out or was canceled never commits.
+func (p *asyncRunPromise) finishCommit(res *runResult) {
+	p.finishWith(res, nil, true)
+}
+
+func (p *asyncRunPromise) finishWith(res *runResult, err error, commit bool) {
 	p.finishOnce.Do(func() {
 		defer p.logEnd()
 
+		if commit && res.err == nil {
+			res.err = p.commits.Commit(context.Background())
+		}
 		p.res, p.err = res, err
 		close(p.ready)
 
@@ -292,9 +446,56 @@ func (p *asyncRunPromise) finish(res *runResult, err error) {
 type runResult struct {
 	err       error
 	retryable bool
+	stats     platform.RunStatistics
 }
 
 var _ backend.RunResult = (*runResult)(nil)
 
-func (rr *runResult) Err() error        { return rr.err }
-func (rr *runResult) IsRetryable() bool { return rr.retryable }
+func (rr *runResult) Err() error                         { return rr.err }
+func (rr *runResult) IsRetryable() bool                  { return rr.retryable }
+func (rr *runResult) Statistics() platform.RunStatistics { return rr.stats }
+
+// failOnTimeout calls finish with a failed result if ready is not closed before timeout elapses.
+// A run that timed out would most likely time out again, so the result is not retryable.
+func failOnTimeout(ready <-chan struct{}, timeout time.Duration, finish func(*runResult, error)) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-ready:
+		// Nothing to do.
+	case <-t.C:
+		finish(&runResult{err: backend.ErrRunTimedOut, retryable: false}, nil)
+	}
+}
+
+// readResult consumes all the tables in r, counting the tables and rows it produced.
+func readResult(r query.Result) (platform.YieldStatistics, error) {
+	ys := platform.YieldStatistics{Name: r.Name()}
+	err := r.Tables().Do(func(tbl query.Table) error {
+		ys.Tables++
+		return tbl.Do(func(cr query.ColReader) error {
+			ys.Rows += int64(cr.Len())
+			return nil
+		})
+	})
+	return ys, err
+}
+
+// runStatistics combines the query's statistics with the per-yield counts.
+func runStatistics(qs query.Statistics, yields []platform.YieldStatistics) platform.RunStatistics {
+	sort.Slice(yields, func(i, j int) bool {
+		return yields[i].Name < yields[j].Name
+	})
+	return platform.RunStatistics{
+		TotalDuration:   qs.TotalDuration,
+		CompileDuration: qs.CompileDuration,
+		QueueDuration:   qs.QueueDuration,
+		PlanDuration:    qs.PlanDuration,
+		ExecuteDuration: qs.ExecuteDuration,
+		MaxAllocated:    qs.MaxAllocated,
+		BytesWritten:    qs.BytesWritten,
+		PointsWritten:   qs.PointsWritten,
+		Yields:          yields,
+	}
+}
@@ -1,20 +1,25 @@
 package executor_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/influxdata/platform"
 	"github.com/influxdata/platform/query"
 	_ "github.com/influxdata/platform/query/builtin"
+	"github.com/influxdata/platform/query/control"
 	"github.com/influxdata/platform/query/execute"
+	"github.com/influxdata/platform/query/functions"
 	"github.com/influxdata/platform/query/values"
 	"github.com/influxdata/platform/task/backend"
 	"github.com/influxdata/platform/task/backend/executor"
@@ -24,6 +29,7 @@ import (
 type fakeQueryService struct {
 	mu       sync.Mutex
 	queries  map[string]*fakeQuery
+	commits  map[string]*fakeCommit
 	queryErr error
 }
 
@@ -46,7 +52,10 @@ func makeSpecString(q *query.Spec) string {
 }
 
 func newFakeQueryService() *fakeQueryService {
-	return &fakeQueryService{queries: make(map[string]*fakeQuery)}
+	return &fakeQueryService{
+		queries: make(map[string]*fakeQuery),
+		commits: make(map[string]*fakeCommit),
+	}
 }
 
 func (s *fakeQueryService) Query(ctx context.Context, req *query.Request) (query.Query, error) {
@@ -63,11 +72,20 @@ func (s *fakeQueryService) Query(ctx context.Context, req *query.Request) (query
 		return nil, fmt.Errorf("fakeQueryService only supports the query.SpecCompiler, got %T", req.Compiler)
 	}
 
+	// Queries are looked up by their script, so the resources are kept out of the key.
+	spec := *sc.Spec
+	spec.Resources = query.ResourceManagement{}
 	fq := &fakeQuery{
-		wait:  make(chan struct{}),
-		ready: make(chan map[string]query.Result),
+		wait:      make(chan struct{}),
+		ready:     make(chan map[string]query.Result),
+		resources: sc.Spec.Resources,
 	}
-	s.queries[makeSpecString(sc.Spec)] = fq
+	s.queries[makeSpecString(&spec)] = fq
+
+	// Register a commit the way a source of the query would.
+	c := &fakeCommit{closed: make(chan struct{})}
+	execute.CommitterFromContext(ctx).Add(c)
+	s.commits[makeSpecString(&spec)] = c
 
 	go fq.run()
 
@@ -85,6 +103,18 @@ func (s *fakeQueryService) SucceedQuery(script string) {
 	delete(s.queries, spec)
 }
 
+// SucceedQueryBlocked allows the running query matching the given script to return on its Ready channel,
+// but blocks reading its results until release is closed.
+func (s *fakeQueryService) SucceedQueryBlocked(script string, release <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spec := makeSpecString(makeSpec(script))
+	s.queries[spec].release = release
+	close(s.queries[spec].wait)
+	delete(s.queries, spec)
+}
+
 // FailQuery closes the running query's Ready channel and sets its error to the given value.
 func (s *fakeQueryService) FailQuery(script string, forced error) {
 	s.mu.Lock()
@@ -102,6 +132,36 @@ func (s *fakeQueryService) FailNextQuery(forced error) {
 	s.queryErr = forced
 }
 
+// Resources returns the resources requested by the running query matching the given script.
+func (s *fakeQueryService) Resources(script string) query.ResourceManagement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.queries[makeSpecString(makeSpec(script))].resources
+}
+
+// Committed reports whether the commit registered by the last query matching the given script was committed.
+func (s *fakeQueryService) Committed(script string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.commits[makeSpecString(makeSpec(script))].Committed()
+}
+
+// WaitForCommitClosed waits until the commit registered by the last query matching the given script is closed,
+// which happens once the executor is done with the run.
+func (s *fakeQueryService) WaitForCommitClosed(t *testing.T, script string) {
+	s.mu.Lock()
+	c := s.commits[makeSpecString(makeSpec(script))]
+	s.mu.Unlock()
+
+	select {
+	case <-c.closed:
+	case <-time.After(time.Second):
+		t.Fatalf("Commit of query %q was not closed in time", script)
+	}
+}
+
 // WaitForQueryLive ensures that the query has made it into the service.
 // This is particularly useful for the synchronous executor,
 // because the execution starts on a separate goroutine.
@@ -124,21 +184,65 @@ func (s *fakeQueryService) WaitForQueryLive(t *testing.T, script string) {
 	t.Fatalf("Did not see live query %q in time", script)
 }
 
+// fakeCommit is an execute.Commit that records whether it was committed.
+type fakeCommit struct {
+	mu        sync.Mutex
+	committed bool
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (c *fakeCommit) Commit(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.committed = true
+	return nil
+}
+
+func (c *fakeCommit) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *fakeCommit) Committed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.committed
+}
+
+// fakeStatistics are the statistics reported by every fakeQuery.
+var fakeStatistics = query.Statistics{
+	TotalDuration:   time.Second,
+	ExecuteDuration: time.Millisecond,
+	MaxAllocated:    1024,
+	BytesWritten:    64,
+	PointsWritten:   4,
+}
+
 type fakeQuery struct {
 	ready       chan map[string]query.Result
 	wait        chan struct{} // Blocks Ready from returning.
 	forcedError error         // Value to return from Err() method.
+	resources   query.ResourceManagement
+	release     <-chan struct{} // If set, blocks reading the results until closed.
+	done        int32           // Number of calls to Done, which must be called once.
 }
 
 var _ query.Query = (*fakeQuery)(nil)
 
 func (q *fakeQuery) Spec() *query.Spec                     { return nil }
-func (q *fakeQuery) Done()                                 {}
 func (q *fakeQuery) Cancel()                               {}
 func (q *fakeQuery) Err() error                            { return q.forcedError }
-func (q *fakeQuery) Statistics() query.Statistics          { return query.Statistics{} }
+func (q *fakeQuery) Statistics() query.Statistics          { return fakeStatistics }
 func (q *fakeQuery) Ready() <-chan map[string]query.Result { return q.ready }
 
+func (q *fakeQuery) Done() {
+	if atomic.AddInt32(&q.done, 1) > 1 {
+		panic("Done called more than once")
+	}
+}
+
 // run is intended to be run on its own goroutine.
 // It blocks until q.wait is closed, then sends a fake result on the q.ready channel.
 func (q *fakeQuery) run() {
@@ -147,6 +251,7 @@ func (q *fakeQuery) run() {
 
 	if q.forcedError == nil {
 		res := newFakeResult()
+		res.release = q.release
 		q.ready <- map[string]query.Result{
 			res.Name(): res,
 		}
@@ -157,8 +262,9 @@ func (q *fakeQuery) run() {
 
 // fakeResult is a dumb implementation of query.Result that always returns the same values.
 type fakeResult struct {
-	name  string
-	table query.Table
+	name    string
+	table   query.Table
+	release <-chan struct{}
 }
 
 var _ query.Result = (*fakeResult)(nil)
@@ -178,8 +284,13 @@ func newFakeResult() *fakeResult {
 	return &fakeResult{name: "res", table: t}
 }
 
-func (r *fakeResult) Name() string                { return r.name }
-func (r *fakeResult) Tables() query.TableIterator { return tables{r.table} }
+func (r *fakeResult) Name() string { return r.name }
+func (r *fakeResult) Tables() query.TableIterator {
+	if r.release != nil {
+		<-r.release
+	}
+	return tables{r.table}
+}
 
 // tables makes a TableIterator out of a slice of Tables.
 type tables []query.Table
@@ -211,7 +322,7 @@ func createAsyncSystem() *system {
 		name: "AsyncExecutor",
 		svc:  svc,
 		st:   st,
-		ex:   executor.NewAsyncQueryServiceExecutor(zap.NewNop(), svc, st),
+		ex:   executor.NewAsyncQueryServiceExecutor(zap.NewNop(), svc, st, nil),
 	}
 }
 
@@ -228,6 +339,7 @@ func createSyncSystem() *system {
 				AsyncQueryService: svc,
 			},
 			st,
+			nil,
 		),
 	}
 }
@@ -238,6 +350,9 @@ func TestExecutor(t *testing.T) {
 		testExecutorQueryFailure(t, fn)
 		testExecutorPromiseCancel(t, fn)
 		testExecutorServiceError(t, fn)
+		testExecutorScriptRevision(t, fn)
+		testExecutorResources(t, fn)
+		testExecutorTimeoutDuringRead(t, fn)
 	}
 }
 
@@ -250,7 +365,7 @@ const testScript = `option task = {
 func testExecutorQuerySuccess(t *testing.T, fn createSysFn) {
 	sys := fn()
 	t.Run(sys.name+"/QuerySuccess", func(t *testing.T) {
-		tid, err := sys.st.CreateTask(context.Background(), platform.ID("org"), platform.ID("user"), testScript)
+		tid, err := sys.st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: platform.ID("org"), User: platform.ID("user"), Script: testScript})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -286,6 +401,20 @@ func testExecutorQuerySuccess(t *testing.T, fn createSysFn) {
 		if got := res.Err(); got != nil {
 			t.Fatal(got)
 		}
+		expStats := platform.RunStatistics{
+			TotalDuration:   fakeStatistics.TotalDuration,
+			ExecuteDuration: fakeStatistics.ExecuteDuration,
+			MaxAllocated:    fakeStatistics.MaxAllocated,
+			BytesWritten:    fakeStatistics.BytesWritten,
+			PointsWritten:   fakeStatistics.PointsWritten,
+			Yields:          []platform.YieldStatistics{{Name: "res", Tables: 1, Rows: 1}},
+		}
+		if got := res.Statistics(); !reflect.DeepEqual(got, expStats) {
+			t.Fatalf("unexpected statistics: got %#v, want %#v", got, expStats)
+		}
+		if !sys.svc.Committed(testScript) {
+			t.Fatal("expected the commits of a successful run to be committed")
+		}
 
 		res2, err := rp.Wait()
 		if err != nil {
@@ -300,7 +429,7 @@ func testExecutorQuerySuccess(t *testing.T, fn createSysFn) {
 func testExecutorQueryFailure(t *testing.T, fn createSysFn) {
 	sys := fn()
 	t.Run(sys.name+"/QueryFail", func(t *testing.T) {
-		tid, err := sys.st.CreateTask(context.Background(), platform.ID("org"), platform.ID("user"), testScript)
+		tid, err := sys.st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: platform.ID("org"), User: platform.ID("user"), Script: testScript})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -320,13 +449,19 @@ func testExecutorQueryFailure(t *testing.T, fn createSysFn) {
 		if got := res.Err(); got != expErr {
 			t.Fatalf("expected error %v; got %v", expErr, got)
 		}
+		if got := res.Statistics().TotalDuration; got != fakeStatistics.TotalDuration {
+			t.Fatalf("expected the statistics of the failed query, got total duration %v", got)
+		}
+		if sys.svc.Committed(testScript) {
+			t.Fatal("expected the commits of a failed run to be discarded")
+		}
 	})
 }
 
 func testExecutorPromiseCancel(t *testing.T, fn createSysFn) {
 	sys := fn()
 	t.Run(sys.name+"/PromiseCancel", func(t *testing.T) {
-		tid, err := sys.st.CreateTask(context.Background(), platform.ID("org"), platform.ID("user"), testScript)
+		tid, err := sys.st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: platform.ID("org"), User: platform.ID("user"), Script: testScript})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -351,7 +486,7 @@ func testExecutorPromiseCancel(t *testing.T, fn createSysFn) {
 func testExecutorServiceError(t *testing.T, fn createSysFn) {
 	sys := fn()
 	t.Run(sys.name+"/ServiceError", func(t *testing.T) {
-		tid, err := sys.st.CreateTask(context.Background(), platform.ID("org"), platform.ID("user"), testScript)
+		tid, err := sys.st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: platform.ID("org"), User: platform.ID("user"), Script: testScript})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -380,3 +515,220 @@ func testExecutorServiceError(t *testing.T, fn createSysFn) {
 		}
 	})
 }
+
+func testExecutorScriptRevision(t *testing.T, fn createSysFn) {
+	sys := fn()
+	t.Run(sys.name+"/ScriptRevision", func(t *testing.T) {
+		tid, err := sys.st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: platform.ID("org"), User: platform.ID("user"), Script: testScript})
+		if err != nil {
+			t.Fatal(err)
+		}
+		qr, err := sys.st.CreateRun(context.Background(), tid, 123)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Modify the task after the run was queued; the run should still execute the script it was created with.
+		const newScript = `option task = {
+			name: "foo",
+			every: 1m,
+		}
+		from(bucket: "two") |> toHTTP(url: "http://example.com")`
+		if err := sys.st.ModifyTask(context.Background(), tid, platform.ID("user"), newScript); err != nil {
+			t.Fatal(err)
+		}
+
+		rp, err := sys.ex.Execute(context.Background(), qr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sys.svc.WaitForQueryLive(t, testScript)
+		sys.svc.SucceedQuery(testScript)
+		res, err := rp.Wait()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.Err(); got != nil {
+			t.Fatal(got)
+		}
+	})
+}
+
+func testExecutorResources(t *testing.T, fn createSysFn) {
+	sys := fn()
+	t.Run(sys.name+"/Resources", func(t *testing.T) {
+		const script = `option task = {
+			name: "foo",
+			every: 1m,
+			priority: "low",
+			concurrencyQuota: 2,
+			memoryBytesQuota: 1024,
+			timeout: 50ms,
+		}
+		from(bucket: "one") |> toHTTP(url: "http://example.com")`
+		tid, err := sys.st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: platform.ID("org"), User: platform.ID("user"), Script: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+		qr := backend.QueuedRun{TaskID: tid, RunID: platform.ID{1}, Now: 123}
+		rp, err := sys.ex.Execute(context.Background(), qr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sys.svc.WaitForQueryLive(t, script)
+		exp := query.ResourceManagement{Priority: query.Low, ConcurrencyQuota: 2, MemoryBytesQuota: 1024}
+		if got := sys.svc.Resources(script); got != exp {
+			t.Fatalf("unexpected resources: got %#v, want %#v", got, exp)
+		}
+
+		// The query is never unblocked, so the run must time out.
+		res, err := rp.Wait()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.Err(); got != backend.ErrRunTimedOut {
+			t.Fatalf("expected ErrRunTimedOut, got %v", got)
+		}
+		if res.IsRetryable() {
+			t.Fatal("expected timed out run not to be retryable")
+		}
+	})
+}
+
+func testExecutorTimeoutDuringRead(t *testing.T, fn createSysFn) {
+	sys := fn()
+	t.Run(sys.name+"/TimeoutDuringRead", func(t *testing.T) {
+		const script = `option task = {
+			name: "foo",
+			every: 1m,
+			timeout: 1s,
+		}
+		from(bucket: "one") |> toHTTP(url: "http://example.com")`
+		tid, err := sys.st.CreateTask(context.Background(), backend.CreateTaskRequest{Org: platform.ID("org"), User: platform.ID("user"), Script: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+		qr := backend.QueuedRun{TaskID: tid, RunID: platform.ID{1}, Now: 123}
+		rp, err := sys.ex.Execute(context.Background(), qr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The query succeeds, but its results are still being read when the run times out.
+		release := make(chan struct{})
+		sys.svc.WaitForQueryLive(t, script)
+		sys.svc.SucceedQueryBlocked(script, release)
+		res, err := rp.Wait()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.Err(); got != backend.ErrRunTimedOut {
+			t.Fatalf("expected ErrRunTimedOut, got %v", got)
+		}
+
+		// Finishing the read must not commit the work of a run that was reported as failed.
+		close(release)
+		sys.svc.WaitForCommitClosed(t, script)
+		if sys.svc.Committed(script) {
+			t.Fatal("expected the commits of a timed out run to be discarded")
+		}
+	})
+}
+
+// authorizationFinder is an executor.AuthorizationFinder backed by a list of authorizations.
+type authorizationFinder []*platform.Authorization
+
+func (f authorizationFinder) FindAuthorizationByID(_ context.Context, id platform.ID) (*platform.Authorization, error) {
+	for _, a := range f {
+		if bytes.Equal(a.ID, id) {
+			return a, nil
+		}
+	}
+	return nil, errors.New("authorization not found")
+}
+
+func TestExecutor_To(t *testing.T) {
+	from, cleanup := writeDryRunCSV(t, dryRunCSV)
+	defer cleanup()
+	script := `option task = {name: "downsample", every: 1h}
+` + from + `
+	|> range(start: 2018-04-17T00:00:00Z, stop: 2018-04-17T00:05:00Z)
+	|> to(bucket: "downsampled", fieldFn: (r) => ({usage: r._value}))`
+
+	auths := authorizationFinder{
+		{ID: platform.ID("writer"), Token: "writer", Permissions: []platform.Permission{platform.WriteBucketPermission(platform.ID("bucket"))}},
+		{ID: platform.ID("reader"), Token: "reader", Permissions: []platform.Permission{platform.ReadBucketPermission(platform.ID("bucket"))}},
+	}
+
+	for _, tc := range []struct {
+		name    string
+		authID  platform.ID
+		points  int32
+		wantErr string
+	}{
+		{name: "authorized", authID: platform.ID("writer"), points: 2},
+		{name: "unauthorized", authID: platform.ID("reader"), wantErr: `not authorized to write to bucket "downsampled"`},
+		{name: "no authorization", wantErr: `not authorized to write to bucket "downsampled"`},
+	} {
+		w := new(countingPointsWriter)
+		deps := make(execute.Dependencies)
+		if err := functions.InjectToDependencies(deps, functions.ToDependencies{
+			PointsWriter:       w,
+			BucketLookup:       staticBucketLookup("bucket"),
+			OrganizationLookup: staticOrganizationLookup("org"),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		ctrl := control.New(control.Config{
+			ExecutorDependencies: deps,
+			ConcurrencyQuota:     1,
+			MemoryBytesQuota:     1 << 20,
+		})
+		st := backend.NewInMemStore()
+
+		for name, ex := range map[string]backend.Executor{
+			"AsyncExecutor":       executor.NewAsyncQueryServiceExecutor(zap.NewNop(), ctrl, st, auths),
+			"SynchronousExecutor": executor.NewQueryServiceExecutor(zap.NewNop(), query.QueryServiceBridge{AsyncQueryService: ctrl}, st, auths),
+		} {
+			t.Run(name+"/"+tc.name, func(t *testing.T) {
+				atomic.StoreInt32(&w.points, 0)
+				tid, err := st.CreateTask(context.Background(), backend.CreateTaskRequest{
+					Org:             platform.ID("org"),
+					User:            platform.ID("user"),
+					AuthorizationID: tc.authID,
+					Script:          script,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// Depending on the executor, a query that fails to start fails the execution, the promise or the run.
+				var res backend.RunResult
+				rp, err := ex.Execute(context.Background(), backend.QueuedRun{TaskID: tid, RunID: platform.ID{1}, Now: 1523923500})
+				if err == nil {
+					res, err = rp.Wait()
+				}
+				if err == nil {
+					err = res.Err()
+				}
+				if tc.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+						t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n := atomic.LoadInt32(&w.points); n != tc.points {
+					t.Fatalf("expected %d points to be written, got %d", tc.points, n)
+				}
+				if n := res.Statistics().PointsWritten; n != int64(tc.points) {
+					t.Fatalf("expected run statistics to count %d points, got %d", tc.points, n)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/platform"
+)
+
+var _ LeaseStore = (*inmemLeaseStore)(nil)
+
+// inmemLeaseStore is an in-memory LeaseStore.
+// It is only useful for sharing leases among schedulers in a single process, such as in tests.
+type inmemLeaseStore struct {
+	mu sync.Mutex
+
+	// Map of node ID to the unix timestamp when its heartbeat expires.
+	nodes map[string]int64
+
+	// Map of stringified task ID to its lease.
+	leases map[string]Lease
+}
+
+// NewInMemLeaseStore returns a new in-memory lease store.
+func NewInMemLeaseStore() LeaseStore {
+	return &inmemLeaseStore{
+		nodes:  make(map[string]int64),
+		leases: make(map[string]Lease),
+	}
+}
+
+func (s *inmemLeaseStore) Heartbeat(_ context.Context, nodeID string, now int64, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nodes[nodeID] = LeaseExpires(now, ttl)
+	return nil
+}
+
+func (s *inmemLeaseStore) LiveNodes(_ context.Context, now int64) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var nodes []string
+	for n, expires := range s.nodes {
+		if expires > now {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes, nil
+}
+
+func (s *inmemLeaseStore) AcquireLease(_ context.Context, taskID platform.ID, nodeID string, now int64, ttl time.Duration) (Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tid := taskID.String()
+	if l, ok := s.leases[tid]; ok && l.NodeID != nodeID && l.Expires > now {
+		return Lease{}, ErrLeaseHeld
+	}
+
+	l := Lease{
+		TaskID:  append(platform.ID(nil), taskID...),
+		NodeID:  nodeID,
+		Expires: LeaseExpires(now, ttl),
+	}
+	s.leases[tid] = l
+	return l, nil
+}
+
+func (s *inmemLeaseStore) ReleaseLease(_ context.Context, taskID platform.ID, nodeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tid := taskID.String()
+	if l, ok := s.leases[tid]; !ok || l.NodeID != nodeID {
+		return ErrLeaseNotHeld
+	}
+
+	delete(s.leases, tid)
+	return nil
+}
+
+func (s *inmemLeaseStore) FindLease(_ context.Context, taskID platform.ID) (*Lease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.leases[taskID.String()]
+	if !ok {
+		return nil, nil
+	}
+	return &l, nil
+}
@@ -40,7 +40,7 @@ func (r *runReaderWriter) UpdateRunState(ctx context.Context, task *StoreTask, r
 
 	existingRun, ok := r.byRunID[runID.String()]
 	if !ok {
-		run := &platform.Run{ID: runID, Status: status.String()}
+		run := &platform.Run{ID: runID, Status: status.String(), ScriptRevision: task.Revision}
 		timeSetter(run)
 		r.byRunID[runID.String()] = run
 		r.byTaskID[task.ID.String()] = append(r.byTaskID[task.ID.String()], run)
@@ -68,6 +68,18 @@ func (r *runReaderWriter) AddRunLog(ctx context.Context, task *StoreTask, runID
 	return nil
 }
 
+func (r *runReaderWriter) SetRunStatistics(ctx context.Context, task *StoreTask, runID platform.ID, stats platform.RunStatistics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existingRun, ok := r.byRunID[runID.String()]
+	if !ok {
+		return ErrRunNotFound
+	}
+	existingRun.Statistics = &stats
+	return nil
+}
+
 func (r *runReaderWriter) ListRuns(ctx context.Context, runFilter platform.RunFilter) ([]*platform.Run, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
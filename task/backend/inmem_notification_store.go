@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/platform"
+)
+
+var _ NotificationStore = (*inmemNotificationStore)(nil)
+
+// inmemNotificationStore is an in-memory NotificationStore.
+type inmemNotificationStore struct {
+	mu sync.Mutex
+
+	// Map of stringified task ID to the number of consecutive failed runs.
+	failures map[string]int
+
+	// Map of stringified task ID to endpoint URL to the most recent deliveries to that endpoint.
+	deliveries map[string]map[string][]platform.NotificationDelivery
+}
+
+// NewInMemNotificationStore returns a new in-memory notification store.
+func NewInMemNotificationStore() NotificationStore {
+	return &inmemNotificationStore{
+		failures:   make(map[string]int),
+		deliveries: make(map[string]map[string][]platform.NotificationDelivery),
+	}
+}
+
+func (s *inmemNotificationStore) RecordRunResult(_ context.Context, taskID platform.ID, failed bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tid := taskID.String()
+	previous := s.failures[tid]
+	if failed {
+		s.failures[tid] = previous + 1
+	} else {
+		delete(s.failures, tid)
+	}
+	return previous, nil
+}
+
+func (s *inmemNotificationStore) AddDelivery(_ context.Context, taskID platform.ID, d platform.NotificationDelivery, limit int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tid := taskID.String()
+	byURL := s.deliveries[tid]
+	if byURL == nil {
+		byURL = make(map[string][]platform.NotificationDelivery)
+		s.deliveries[tid] = byURL
+	}
+	h := append(byURL[d.URL], d)
+	if len(h) > limit {
+		h = h[len(h)-limit:]
+	}
+	byURL[d.URL] = h
+	return nil
+}
+
+func (s *inmemNotificationStore) FindTaskNotifications(_ context.Context, taskID platform.ID) (*platform.TaskNotifications, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tid := taskID.String()
+	n := &platform.TaskNotifications{
+		ConsecutiveFailures: s.failures[tid],
+		Deliveries:          []platform.NotificationDelivery{},
+	}
+
+	byURL := s.deliveries[tid]
+	urls := make([]string, 0, len(byURL))
+	for url := range byURL {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		n.Deliveries = append(n.Deliveries, byURL[url]...)
+	}
+	return n, nil
+}
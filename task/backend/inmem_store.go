@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/influxdata/platform"
 	"github.com/influxdata/platform/snowflake"
@@ -24,18 +25,36 @@ type inmem struct {
 	tasks []StoreTask
 
 	runners map[string]StoreTaskMeta
+
+	// Map of stringified task ID to the task's script revisions, oldest first.
+	revisions map[string][]ScriptRevision
+
+	// Map of stringified task ID to the result of the task's most recently finished run.
+	lastRuns map[string]runResult
+
+	// Map of stringified task ID to the stringified ID of the task it depends on, for tasks with a dependsOn option.
+	dependsOn map[string]string
+}
+
+// runResult is the recorded outcome of a finished run.
+type runResult struct {
+	status   RunStatus
+	finished time.Time
 }
 
 // NewInMemStore returns a new in-memory store.
 func NewInMemStore() Store {
 	return &inmem{
-		idgen:   snowflake.NewIDGenerator(),
-		runners: map[string]StoreTaskMeta{},
+		idgen:     snowflake.NewIDGenerator(),
+		runners:   map[string]StoreTaskMeta{},
+		revisions: map[string][]ScriptRevision{},
+		lastRuns:  map[string]runResult{},
+		dependsOn: map[string]string{},
 	}
 }
 
-func (s *inmem) CreateTask(_ context.Context, org, user platform.ID, script string) (platform.ID, error) {
-	o, err := StoreValidator.CreateArgs(org, user, script)
+func (s *inmem) CreateTask(_ context.Context, req CreateTaskRequest) (platform.ID, error) {
+	o, err := StoreValidator.CreateArgs(req.Org, req.User, req.Script)
 	if err != nil {
 		return nil, err
 	}
@@ -45,24 +64,32 @@ func (s *inmem) CreateTask(_ context.Context, org, user platform.ID, script stri
 	task := StoreTask{
 		ID: id,
 
-		Org:  org,
-		User: user,
+		Org:  req.Org,
+		User: req.User,
+
+		AuthorizationID: req.AuthorizationID,
 
 		Name: o.Name,
 
-		Script: script,
+		Script:   req.Script,
+		Revision: 1,
 	}
 
 	s.mu.Lock()
 	s.tasks = append(s.tasks, task)
 	s.runners[id.String()] = StoreTaskMeta{MaxConcurrency: int32(o.Concurrency), Status: string(TaskEnabled)}
+	s.revisions[id.String()] = []ScriptRevision{
+		{Revision: 1, Script: req.Script, Author: req.User, Created: time.Now().Unix()},
+	}
+	s.setDependsOn(id, o.DependsOn)
 	s.mu.Unlock()
 
 	return id, nil
 }
 
-func (s *inmem) ModifyTask(_ context.Context, id platform.ID, script string) error {
-	if _, err := StoreValidator.ModifyArgs(id, script); err != nil {
+func (s *inmem) ModifyTask(_ context.Context, id, author platform.ID, script string) error {
+	o, err := StoreValidator.ModifyArgs(id, script)
+	if err != nil {
 		return err
 	}
 
@@ -71,17 +98,61 @@ func (s *inmem) ModifyTask(_ context.Context, id platform.ID, script string) err
 
 	for n, t := range s.tasks {
 		if bytes.Equal(t.ID, id) {
+			t.Name = o.Name
 			t.Script = script
+			t.Revision++
 			s.tasks[n] = t
+
+			s.revisions[id.String()] = append(s.revisions[id.String()], ScriptRevision{
+				Revision: t.Revision,
+				Script:   script,
+				Author:   author,
+				Created:  time.Now().Unix(),
+			})
+			s.setDependsOn(id, o.DependsOn)
 			return nil
 		}
 	}
 	return fmt.Errorf("ModifyTask: record not found for %s", id)
 }
 
+// setDependsOn records the ID of the task that the task with the given ID depends on, if any.
+// s.mu must be locked for writing.
+func (s *inmem) setDependsOn(id platform.ID, dependsOn string) {
+	if dependsOn == "" {
+		delete(s.dependsOn, id.String())
+		return
+	}
+	s.dependsOn[id.String()] = dependsOn
+}
+
+func (s *inmem) ListScriptRevisions(_ context.Context, id platform.ID) ([]ScriptRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revs := s.revisions[id.String()]
+	out := make([]ScriptRevision, len(revs))
+	copy(out, revs)
+	return out, nil
+}
+
+func (s *inmem) FindScriptRevision(_ context.Context, id platform.ID, revision int64) (*ScriptRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, r := range s.revisions[id.String()] {
+		if r.Revision == revision {
+			// Return a copy of the revision.
+			rev := r
+			return &rev, nil
+		}
+	}
+	return nil, nil
+}
+
 func (s *inmem) ListTasks(_ context.Context, params TaskSearchParams) ([]StoreTask, error) {
-	if len(params.Org) > 0 && len(params.User) > 0 {
-		return nil, errors.New("ListTasks: org and user filters are mutually exclusive")
+	if err := StoreValidator.ListArgs(params); err != nil {
+		return nil, err
 	}
 
 	const (
@@ -120,6 +191,24 @@ func (s *inmem) ListTasks(_ context.Context, params TaskSearchParams) ([]StoreTa
 		if len(user) > 0 && !bytes.Equal(user, t.User) {
 			continue
 		}
+		if !params.MatchName(t.Name) {
+			continue
+		}
+		if params.Status != "" && s.runners[t.ID.String()].Status != string(params.Status) {
+			continue
+		}
+		if params.LastRunStatus != RunQueued || !params.LastRunAfter.IsZero() || !params.LastRunBefore.IsZero() {
+			res, ok := s.lastRuns[t.ID.String()]
+			if !ok {
+				continue
+			}
+			if params.LastRunStatus != RunQueued && res.status != params.LastRunStatus {
+				continue
+			}
+			if !params.MatchLastRunTime(res.finished) {
+				continue
+			}
+		}
 
 		out = append(out, t)
 		if len(out) >= lim {
@@ -204,6 +293,9 @@ func (s *inmem) DeleteTask(_ context.Context, id platform.ID) (deleted bool, err
 
 	// Delete entry from slice.
 	s.tasks = append(s.tasks[:idx], s.tasks[idx+1:]...)
+	delete(s.revisions, id.String())
+	delete(s.lastRuns, id.String())
+	delete(s.dependsOn, id.String())
 	return true, nil
 }
 
@@ -226,6 +318,15 @@ func (s *inmem) CreateRun(ctx context.Context, taskID platform.ID, now int64) (Q
 
 	runID := s.idgen.ID()
 
+	s.mu.RLock()
+	for _, t := range s.tasks {
+		if bytes.Equal(t.ID, taskID) {
+			queuedRun.Revision = t.Revision
+			break
+		}
+	}
+	s.mu.RUnlock()
+
 	running := &StoreTaskMetaRun{
 		Now:   now,
 		Try:   1,
@@ -243,6 +344,63 @@ func (s *inmem) CreateRun(ctx context.Context, taskID platform.ID, now int64) (Q
 	return queuedRun, nil
 }
 
+// TriggerDependents queues a run with the given now timestamp for every task that depends on taskID.
+func (s *inmem) TriggerDependents(ctx context.Context, taskID platform.ID, now int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upstream := taskID.String()
+	for id, dependsOn := range s.dependsOn {
+		if dependsOn != upstream {
+			continue
+		}
+		stm, ok := s.runners[id]
+		if !ok {
+			continue
+		}
+		if stm.Trigger(now) {
+			s.runners[id] = stm
+		}
+	}
+	return nil
+}
+
+// CreateTriggeredRun creates a run for the task's oldest queued trigger, if we have not exceeded 'max_concurrency'.
+func (s *inmem) CreateTriggeredRun(ctx context.Context, taskID platform.ID) (QueuedRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stm, ok := s.runners[taskID.String()]
+	if !ok {
+		return QueuedRun{}, errors.New("taskRunner not found")
+	}
+	if len(stm.Triggered) == 0 {
+		return QueuedRun{}, ErrNoRunTriggered
+	}
+	if len(stm.CurrentlyRunning) >= int(stm.MaxConcurrency) {
+		return QueuedRun{}, errors.New("MaxConcurrency reached")
+	}
+
+	now := stm.Triggered[0]
+	stm.Triggered = append([]int64(nil), stm.Triggered[1:]...)
+
+	queuedRun := QueuedRun{TaskID: taskID, RunID: s.idgen.ID(), Now: now}
+	for _, t := range s.tasks {
+		if bytes.Equal(t.ID, taskID) {
+			queuedRun.Revision = t.Revision
+			break
+		}
+	}
+
+	stm.CurrentlyRunning = append(stm.CurrentlyRunning, &StoreTaskMetaRun{
+		Now:   now,
+		Try:   1,
+		RunID: queuedRun.RunID,
+	})
+	s.runners[taskID.String()] = stm
+	return queuedRun, nil
+}
+
 // FinishRun removes runID from the list of running tasks and if its `now` is later then last completed update it.
 func (s *inmem) FinishRun(ctx context.Context, taskID, runID platform.ID) error {
 	stm, ok := s.runners[taskID.String()]
@@ -273,6 +431,22 @@ func (s *inmem) FinishRun(ctx context.Context, taskID, runID platform.ID) error
 	return nil
 }
 
+// RecordRunResult records the status of the task's most recently finished run.
+func (s *inmem) RecordRunResult(ctx context.Context, taskID platform.ID, status RunStatus, finished time.Time) error {
+	if status != RunSuccess && status != RunFail {
+		return fmt.Errorf("RecordRunResult: invalid status %d", status)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.runners[taskID.String()]; !ok {
+		return errors.New("task meta not found")
+	}
+	s.lastRuns[taskID.String()] = runResult{status: status, finished: finished}
+	return nil
+}
+
 func (s *inmem) delete(ctx context.Context, id platform.ID, f func(StoreTask) platform.ID) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -301,6 +475,9 @@ func (s *inmem) delete(ctx context.Context, id platform.ID, f func(StoreTask) pl
 	}
 	for i := range deletingTasks {
 		delete(s.runners, s.tasks[i].ID.String())
+		delete(s.revisions, deletingTasks[i].String())
+		delete(s.lastRuns, deletingTasks[i].String())
+		delete(s.dependsOn, deletingTasks[i].String())
 	}
 	s.tasks = newTasks
 	return nil
@@ -22,3 +22,13 @@ func TestInMemStore(t *testing.T) {
 		func(t *testing.T, s backend.Store) {},
 	)(t)
 }
+
+func TestInMemNotificationStore(t *testing.T) {
+	storetest.NewNotificationStoreTest(
+		"in-mem notification store",
+		func(t *testing.T) backend.NotificationStore {
+			return backend.NewInMemNotificationStore()
+		},
+		func(t *testing.T, s backend.NotificationStore) {},
+	)(t)
+}
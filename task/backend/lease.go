@@ -0,0 +1,457 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/task/options"
+	"go.uber.org/zap"
+)
+
+// ErrLeaseHeld is returned when attempting to acquire a lease that is held by another node.
+var ErrLeaseHeld = errors.New("lease held by another node")
+
+// ErrLeaseNotHeld is returned when attempting to release a lease that the node does not hold.
+var ErrLeaseNotHeld = errors.New("lease not held")
+
+// Lease records which scheduler node owns a task, and until when.
+type Lease struct {
+	TaskID platform.ID
+	NodeID string
+
+	// Expires is the unix timestamp after which the lease may be taken over by another node.
+	Expires int64
+}
+
+// LeaseExpires returns the unix timestamp when a heartbeat or lease made at now with the given TTL expires.
+// The TTL is rounded up to whole seconds, so a TTL that is not a multiple of a second is never cut short.
+func LeaseExpires(now int64, ttl time.Duration) int64 {
+	return now + int64((ttl+time.Second-1)/time.Second)
+}
+
+// LeaseStore persists task ownership among a set of scheduler nodes.
+// All timestamps are unix seconds, supplied by the caller, so that implementations never consult a clock.
+type LeaseStore interface {
+	// Heartbeat records that nodeID is alive until now+ttl.
+	Heartbeat(ctx context.Context, nodeID string, now int64, ttl time.Duration) error
+
+	// LiveNodes returns the IDs of the nodes whose last heartbeat has not expired as of now.
+	LiveNodes(ctx context.Context, now int64) ([]string, error)
+
+	// AcquireLease grants nodeID ownership of taskID until now+ttl.
+	// If nodeID already holds the lease, the lease is renewed.
+	// If another node holds an unexpired lease, AcquireLease returns ErrLeaseHeld.
+	AcquireLease(ctx context.Context, taskID platform.ID, nodeID string, now int64, ttl time.Duration) (Lease, error)
+
+	// ReleaseLease gives up nodeID's ownership of taskID.
+	// If nodeID does not hold the lease, ReleaseLease returns ErrLeaseNotHeld.
+	ReleaseLease(ctx context.Context, taskID platform.ID, nodeID string) error
+
+	// FindLease returns the current lease for taskID.
+	// If no node has ever acquired the lease, the returned lease is nil.
+	FindLease(ctx context.Context, taskID platform.ID) (*Lease, error)
+}
+
+// LeaseManager is a Scheduler that only claims the tasks its node owns.
+//
+// Each LeaseManager identifies itself to a shared LeaseStore by a node ID.
+// Tasks are assigned to the live nodes by rendezvous hashing, so that when a node joins,
+// only the tasks for which it is now the preferred owner move to it.
+// When a node stops heartbeating, its leases expire and the tasks are taken over by the surviving nodes.
+// A node that cannot renew its leases, such as when it cannot reach the LeaseStore,
+// stops scheduling each task once the task's lease has expired, since another node may have taken it over by then.
+//
+// Tick drives both the wrapped Scheduler and the lease bookkeeping;
+// leases are renewed at most once every third of the lease TTL.
+type LeaseManager struct {
+	nodeID string
+	leases LeaseStore
+	st     Store
+	sch    Scheduler
+
+	ttl    time.Duration
+	logger *zap.Logger
+
+	// Latest time seen by Tick or Sync, in unix seconds.
+	// This value must be accessed with sync.Atomic.
+	now int64
+
+	// syncMu ensures only one round of lease bookkeeping runs at a time.
+	syncMu   sync.Mutex
+	lastSync int64
+
+	mu sync.Mutex
+	// Map of stringified task ID to the lease of the task, as last acquired or renewed by this node.
+	owned map[string]ownedTask
+}
+
+// ownedTask is a task claimed into the scheduler of a LeaseManager.
+type ownedTask struct {
+	// Script the task was claimed with.
+	script string
+
+	// Expires is the unix timestamp when the last lease acquired for the task expires.
+	expires int64
+}
+
+var _ Scheduler = (*LeaseManager)(nil)
+
+// LeaseManagerOption is an option for NewLeaseManager.
+type LeaseManagerOption func(*LeaseManager)
+
+// WithLeaseTTL sets how long leases and heartbeats are valid. The default is 30 seconds.
+func WithLeaseTTL(d time.Duration) LeaseManagerOption {
+	return func(m *LeaseManager) {
+		m.ttl = d
+	}
+}
+
+// WithLeaseLogger sets the logger for the lease manager.
+// If not set, the lease manager will use a no-op logger.
+func WithLeaseLogger(logger *zap.Logger) LeaseManagerOption {
+	return func(m *LeaseManager) {
+		m.logger = logger.With(zap.String("svc", "taskd/leases"), zap.String("node_id", m.nodeID))
+	}
+}
+
+// NewLeaseManager returns a LeaseManager for nodeID that claims tasks from st into sch.
+func NewLeaseManager(nodeID string, leases LeaseStore, st Store, sch Scheduler, opts ...LeaseManagerOption) *LeaseManager {
+	m := &LeaseManager{
+		nodeID: nodeID,
+		leases: leases,
+		st:     st,
+		sch:    sch,
+		ttl:    30 * time.Second,
+		logger: zap.NewNop(),
+		now:    time.Now().UTC().Unix(),
+		owned:  make(map[string]ownedTask),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Tick updates the time of the wrapped scheduler, and renews, acquires or releases leases when they are due.
+func (m *LeaseManager) Tick(now int64) {
+	atomic.StoreInt64(&m.now, now)
+	m.sch.Tick(now)
+
+	renewEvery := int64(m.ttl/3) / int64(time.Second)
+	if renewEvery < 1 {
+		renewEvery = 1
+	}
+
+	m.syncMu.Lock()
+	due := m.lastSync == 0 || now-m.lastSync >= renewEvery
+	m.syncMu.Unlock()
+	if !due {
+		return
+	}
+
+	if err := m.Sync(context.Background(), now); err != nil {
+		m.logger.Info("Failed to sync task leases", zap.Error(err))
+	}
+}
+
+// Sync immediately runs one round of lease bookkeeping at the given time:
+// it stops scheduling the tasks whose leases expired before they could be renewed, heartbeats,
+// renews the leases this node should keep, releases the leases that belong elsewhere,
+// and acquires leases on the tasks this node should own.
+func (m *LeaseManager) Sync(ctx context.Context, now int64) error {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+	m.lastSync = now
+	atomic.StoreInt64(&m.now, now)
+
+	// Leases are renewed alongside heartbeats, so an expired lease means this node has not been able to heartbeat
+	// for a whole TTL, and other nodes consider it dead. This happens before heartbeating,
+	// so that the tasks are released even while the LeaseStore is unreachable.
+	m.releaseExpired(now)
+
+	if err := m.leases.Heartbeat(ctx, m.nodeID, now, m.ttl); err != nil {
+		return err
+	}
+
+	nodes, err := m.leases.LiveNodes(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var after platform.ID
+	for {
+		const pageSize = 500
+		tasks, err := m.st.ListTasks(ctx, TaskSearchParams{After: after, PageSize: pageSize})
+		if err != nil {
+			return err
+		}
+
+		for i := range tasks {
+			t := &tasks[i]
+			seen[t.ID.String()] = true
+			if err := m.syncTask(ctx, t, nodes, now); err != nil {
+				m.logger.Info("Failed to sync task lease", zap.String("task_id", t.ID.String()), zap.Error(err))
+			}
+		}
+
+		if len(tasks) < pageSize {
+			break
+		}
+		after = tasks[len(tasks)-1].ID
+	}
+
+	// Anything still owned but no longer in the store was deleted out from under us.
+	for _, tid := range m.ownedIDs() {
+		if seen[tid] {
+			continue
+		}
+		id, err := platform.IDFromString(tid)
+		if err != nil {
+			return err
+		}
+		if err := m.release(ctx, *id); err != nil {
+			m.logger.Info("Failed to release deleted task", zap.String("task_id", tid), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// syncTask reconciles the ownership of a single task.
+func (m *LeaseManager) syncTask(ctx context.Context, t *StoreTask, nodes []string, now int64) error {
+	tid := t.ID.String()
+
+	meta, err := m.st.FindTaskMetaByID(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	owned, isOwned := m.owned[tid]
+	m.mu.Unlock()
+
+	if meta.Status != string(TaskEnabled) || preferredNode(t.ID, nodes) != m.nodeID {
+		if isOwned {
+			return m.release(ctx, t.ID)
+		}
+		return nil
+	}
+
+	// The lease as the previous owner left it, to tell whether that owner's runs can still finish.
+	var prev *Lease
+	if !isOwned {
+		if prev, err = m.leases.FindLease(ctx, t.ID); err != nil {
+			return err
+		}
+	}
+
+	l, err := m.leases.AcquireLease(ctx, t.ID, m.nodeID, now, m.ttl)
+	if err != nil {
+		if err == ErrLeaseHeld {
+			if isOwned {
+				// Someone else took the lease while we thought we had it, so stop scheduling.
+				m.releaseLocal(t.ID)
+			}
+			// The current holder will release it once it sees this node as the preferred owner.
+			return nil
+		}
+		// The lease could not be renewed, but it is still held until it expires.
+		return err
+	}
+
+	if isOwned && owned.script == t.Script {
+		// Renewed.
+		m.mu.Lock()
+		m.owned[tid] = ownedTask{script: t.Script, expires: l.Expires}
+		m.mu.Unlock()
+		return nil
+	}
+
+	opts, err := options.FromScript(t.Script)
+	if err != nil {
+		return err
+	}
+
+	if isOwned {
+		// The script changed on another node. Reclaim so the new schedule takes effect.
+		m.releaseLocal(t.ID)
+	} else if prev != nil && (prev.Expires <= now || prev.NodeID == m.nodeID) {
+		// The previous owner's lease expired, so that node is considered dead, or the previous owner was this node
+		// before it restarted. Either way its runs will never finish, so free their concurrency slots.
+		// A lease given up during a rebalance is deleted instead, and the previous owner finishes its runs itself.
+		for _, r := range meta.CurrentlyRunning {
+			if err := m.st.FinishRun(ctx, t.ID, r.RunID); err != nil {
+				m.logger.Info("Failed to finish orphaned run", zap.String("task_id", tid), zap.Error(err))
+			}
+		}
+	}
+
+	startFrom := meta.LastCompleted
+	if startFrom == 0 {
+		startFrom = now
+	}
+	if err := m.sch.ClaimTask(t, startFrom, &opts); err != nil {
+		_ = m.leases.ReleaseLease(ctx, t.ID, m.nodeID)
+		return err
+	}
+
+	m.mu.Lock()
+	m.owned[tid] = ownedTask{script: t.Script, expires: l.Expires}
+	m.mu.Unlock()
+	return nil
+}
+
+// ClaimTask claims the task into the wrapped scheduler if this node is the task's preferred owner.
+// Otherwise the task is left for the owning node to pick up during its next sync.
+func (m *LeaseManager) ClaimTask(task *StoreTask, startExecutionFrom int64, opts *options.Options) error {
+	ctx := context.Background()
+	now := atomic.LoadInt64(&m.now)
+
+	nodes, err := m.leases.LiveNodes(ctx, now)
+	if err != nil {
+		return err
+	}
+	// This node is alive even if it has not heartbeated yet.
+	if preferredNode(task.ID, append(nodes, m.nodeID)) != m.nodeID {
+		return nil
+	}
+
+	l, err := m.leases.AcquireLease(ctx, task.ID, m.nodeID, now, m.ttl)
+	if err != nil {
+		if err == ErrLeaseHeld {
+			return nil
+		}
+		return err
+	}
+
+	if err := m.sch.ClaimTask(task, startExecutionFrom, opts); err != nil {
+		_ = m.leases.ReleaseLease(ctx, task.ID, m.nodeID)
+		return err
+	}
+
+	m.mu.Lock()
+	m.owned[task.ID.String()] = ownedTask{script: task.Script, expires: l.Expires}
+	m.mu.Unlock()
+	return nil
+}
+
+// ReleaseTask releases the task from the wrapped scheduler and gives up its lease.
+// Releasing a task owned by another node is a no-op; that node releases it during its next sync.
+func (m *LeaseManager) ReleaseTask(taskID platform.ID) error {
+	m.mu.Lock()
+	_, ok := m.owned[taskID.String()]
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	return m.release(context.Background(), taskID)
+}
+
+// Owns reports whether this node currently schedules the given task.
+func (m *LeaseManager) Owns(taskID platform.ID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.owned[taskID.String()]
+	return ok
+}
+
+// ReleaseAll gives up every lease held by this node and marks the node as no longer live,
+// so that other nodes may take over immediately. Call it before shutting down a node.
+func (m *LeaseManager) ReleaseAll(ctx context.Context) error {
+	// A heartbeat with no TTL expires immediately.
+	if err := m.leases.Heartbeat(ctx, m.nodeID, atomic.LoadInt64(&m.now), 0); err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, tid := range m.ownedIDs() {
+		id, err := platform.IDFromString(tid)
+		if err != nil {
+			return err
+		}
+		if err := m.release(ctx, *id); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *LeaseManager) ownedIDs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, 0, len(m.owned))
+	for tid := range m.owned {
+		ids = append(ids, tid)
+	}
+	return ids
+}
+
+// releaseExpired stops scheduling the owned tasks whose leases have expired as of now.
+func (m *LeaseManager) releaseExpired(now int64) {
+	m.mu.Lock()
+	var expired []string
+	for tid, o := range m.owned {
+		if o.expires <= now {
+			expired = append(expired, tid)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, tid := range expired {
+		id, err := platform.IDFromString(tid)
+		if err != nil {
+			continue
+		}
+		m.logger.Info("Task lease expired before it was renewed", zap.String("task_id", tid))
+		m.releaseLocal(*id)
+	}
+}
+
+// release stops scheduling taskID locally and releases its lease.
+func (m *LeaseManager) release(ctx context.Context, taskID platform.ID) error {
+	m.releaseLocal(taskID)
+
+	if err := m.leases.ReleaseLease(ctx, taskID, m.nodeID); err != nil && err != ErrLeaseNotHeld {
+		return fmt.Errorf("error releasing lease: %v", err)
+	}
+	return nil
+}
+
+// releaseLocal stops scheduling taskID locally, without touching its lease.
+func (m *LeaseManager) releaseLocal(taskID platform.ID) {
+	m.mu.Lock()
+	delete(m.owned, taskID.String())
+	m.mu.Unlock()
+
+	if err := m.sch.ReleaseTask(taskID); err != nil && err != ErrTaskNotClaimed {
+		m.logger.Info("Failed to release task", zap.String("task_id", taskID.String()), zap.Error(err))
+	}
+}
+
+// preferredNode returns the node that should own taskID, by rendezvous hashing over nodes.
+// It returns the empty string if nodes is empty.
+func preferredNode(taskID platform.ID, nodes []string) string {
+	var best string
+	var bestScore uint64
+	for _, n := range nodes {
+		h := fnv.New64a()
+		h.Write(taskID)
+		h.Write([]byte(n))
+		if score := h.Sum64(); best == "" || score > bestScore || (score == bestScore && n < best) {
+			best, bestScore = n, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,318 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	_ "github.com/influxdata/platform/query/builtin"
+	"github.com/influxdata/platform/task/backend"
+	"github.com/influxdata/platform/task/backend/storetest"
+	"github.com/influxdata/platform/task/mock"
+)
+
+func TestLeaseManager_Ownership(t *testing.T) {
+	ctx := context.Background()
+	st := backend.NewInMemStore()
+	leases := backend.NewInMemLeaseStore()
+
+	const numTasks = 20
+	taskIDs := make([]platform.ID, numTasks)
+	for i := range taskIDs {
+		script := fmt.Sprintf(`option task = {
+  name: "task%d",
+  every: 1m,
+}
+
+from(db: "test") |> range(start: -1h)`, i)
+		id, err := st.CreateTask(ctx, backend.CreateTaskRequest{Org: platform.ID{1}, User: platform.ID{2}, Script: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+		taskIDs[i] = id
+	}
+
+	newNode := func(nodeID string) *backend.LeaseManager {
+		sch := backend.NewScheduler(mock.NewDesiredState(), mock.NewExecutor(), backend.NopLogWriter{}, 100)
+		return backend.NewLeaseManager(nodeID, leases, st, sch, backend.WithLeaseTTL(30*time.Second))
+	}
+
+	// checkOwners verifies each task is owned by exactly one of the nodes, and returns the number owned by each node.
+	checkOwners := func(nodes ...*backend.LeaseManager) []int {
+		t.Helper()
+		counts := make([]int, len(nodes))
+		for _, id := range taskIDs {
+			owners := 0
+			for i, n := range nodes {
+				if n.Owns(id) {
+					owners++
+					counts[i]++
+				}
+			}
+			if owners != 1 {
+				t.Fatalf("expected task %s to have exactly 1 owner, got %d", id.String(), owners)
+			}
+		}
+		return counts
+	}
+
+	a := newNode("a")
+	if err := a.Sync(ctx, 100); err != nil {
+		t.Fatal(err)
+	}
+	if counts := checkOwners(a); counts[0] != numTasks {
+		t.Fatalf("expected single node to own all %d tasks, got %d", numTasks, counts[0])
+	}
+
+	// Node b joins. It can't take anything until a notices it and hands over.
+	b := newNode("b")
+	if err := b.Sync(ctx, 101); err != nil {
+		t.Fatal(err)
+	}
+	checkOwners(a, b)
+	if err := a.Sync(ctx, 102); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Sync(ctx, 103); err != nil {
+		t.Fatal(err)
+	}
+	counts := checkOwners(a, b)
+	if counts[0] == 0 || counts[1] == 0 {
+		t.Fatalf("expected tasks to be rebalanced across both nodes, got %v", counts)
+	}
+
+	// Renewals keep the same ownership.
+	if err := a.Sync(ctx, 110); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Sync(ctx, 110); err != nil {
+		t.Fatal(err)
+	}
+	if got := checkOwners(a, b); got[0] != counts[0] || got[1] != counts[1] {
+		t.Fatalf("expected ownership to be stable across renewals, got %v, previously %v", got, counts)
+	}
+
+	// Node b stops heartbeating. Before its leases expire, a cannot take over.
+	if err := a.Sync(ctx, 120); err != nil {
+		t.Fatal(err)
+	}
+	checkOwners(a, b)
+
+	// After b's leases expire, a takes over everything.
+	if err := a.Sync(ctx, 150); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range taskIDs {
+		if !a.Owns(id) {
+			t.Fatalf("expected node a to take over task %s", id.String())
+		}
+		l, err := leases.FindLease(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l == nil || l.NodeID != "a" {
+			t.Fatalf("expected node a to hold lease for task %s, got %v", id.String(), l)
+		}
+	}
+
+	// Disabled and deleted tasks are released.
+	if err := st.DisableTask(ctx, taskIDs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.DeleteTask(ctx, taskIDs[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Sync(ctx, 151); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range taskIDs[:2] {
+		if a.Owns(id) {
+			t.Fatalf("expected task %s to be released", id.String())
+		}
+		if l, err := leases.FindLease(ctx, id); err != nil {
+			t.Fatal(err)
+		} else if l != nil {
+			t.Fatalf("expected no lease for task %s, got %v", id.String(), l)
+		}
+	}
+
+	// Releasing all leases lets another node take over immediately.
+	if err := a.ReleaseAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	c := newNode("c")
+	if err := c.Sync(ctx, 152); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range taskIDs[2:] {
+		if !c.Owns(id) {
+			t.Fatalf("expected node c to own task %s after a released all leases", id.String())
+		}
+	}
+}
+
+// unreachableLeaseStore is a LeaseStore that fails every heartbeat and lease acquisition while down is set.
+type unreachableLeaseStore struct {
+	backend.LeaseStore
+	down bool
+}
+
+var errLeaseStoreDown = errors.New("lease store unreachable")
+
+func (s *unreachableLeaseStore) Heartbeat(ctx context.Context, nodeID string, now int64, ttl time.Duration) error {
+	if s.down {
+		return errLeaseStoreDown
+	}
+	return s.LeaseStore.Heartbeat(ctx, nodeID, now, ttl)
+}
+
+func (s *unreachableLeaseStore) AcquireLease(ctx context.Context, taskID platform.ID, nodeID string, now int64, ttl time.Duration) (backend.Lease, error) {
+	if s.down {
+		return backend.Lease{}, errLeaseStoreDown
+	}
+	return s.LeaseStore.AcquireLease(ctx, taskID, nodeID, now, ttl)
+}
+
+func TestLeaseManager_Expiry(t *testing.T) {
+	ctx := context.Background()
+	st := backend.NewInMemStore()
+	leases := &unreachableLeaseStore{LeaseStore: backend.NewInMemLeaseStore()}
+
+	id, err := st.CreateTask(ctx, backend.CreateTaskRequest{Org: platform.ID{1}, User: platform.ID{2}, Script: `option task = {
+  name: "task",
+  every: 1m,
+}
+
+from(db: "test") |> range(start: -1h)`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sch := backend.NewScheduler(mock.NewDesiredState(), mock.NewExecutor(), backend.NopLogWriter{}, 100)
+	m := backend.NewLeaseManager("a", leases, st, sch, backend.WithLeaseTTL(30*time.Second))
+	if err := m.Sync(ctx, 100); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Owns(id) {
+		t.Fatal("expected node to own the task")
+	}
+
+	// While the lease store is unreachable, the task is scheduled until its lease expires.
+	leases.down = true
+	if err := m.Sync(ctx, 129); err != errLeaseStoreDown {
+		t.Fatalf("expected the lease store error, got %v", err)
+	}
+	if !m.Owns(id) {
+		t.Fatal("expected node to own the task until its lease expires")
+	}
+	if err := m.Sync(ctx, 130); err != errLeaseStoreDown {
+		t.Fatalf("expected the lease store error, got %v", err)
+	}
+	if m.Owns(id) {
+		t.Fatal("expected node to stop scheduling the task once its lease expired")
+	}
+
+	// Once the lease store is reachable again, the task is reclaimed.
+	leases.down = false
+	if err := m.Sync(ctx, 131); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Owns(id) {
+		t.Fatal("expected node to reclaim the task")
+	}
+}
+
+func TestLeaseManager_OrphanedRuns(t *testing.T) {
+	ctx := context.Background()
+	st := backend.NewInMemStore()
+	leases := backend.NewInMemLeaseStore()
+
+	id, err := st.CreateTask(ctx, backend.CreateTaskRequest{Org: platform.ID{1}, User: platform.ID{2}, Script: `option task = {
+  name: "task",
+  every: 1m,
+  concurrency: 2,
+}
+
+from(db: "test") |> range(start: -1h)`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newNode := func(nodeID string) *backend.LeaseManager {
+		sch := backend.NewScheduler(mock.NewDesiredState(), mock.NewExecutor(), backend.NopLogWriter{}, 100)
+		return backend.NewLeaseManager(nodeID, leases, st, sch, backend.WithLeaseTTL(30*time.Second))
+	}
+	running := func() int {
+		t.Helper()
+		meta, err := st.FindTaskMetaByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(meta.CurrentlyRunning)
+	}
+
+	a := newNode("a")
+	if err := a.Sync(ctx, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.CreateRun(ctx, id, 60); err != nil {
+		t.Fatal(err)
+	}
+
+	// Node a hands the task over while its run is still executing, so the run keeps its concurrency slot.
+	if err := a.ReleaseAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	b := newNode("b")
+	if err := b.Sync(ctx, 101); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Owns(id) {
+		t.Fatal("expected node b to own the task")
+	}
+	if n := running(); n != 1 {
+		t.Fatalf("expected the run handed over with the task to keep running, got %d running", n)
+	}
+
+	// Node b dies with a run in progress. Once its lease expires, the runs left behind are finished.
+	if _, err := st.CreateRun(ctx, id, 120); err != nil {
+		t.Fatal(err)
+	}
+	c := newNode("c")
+	if err := c.Sync(ctx, 131); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Owns(id) {
+		t.Fatal("expected node c to take over the task")
+	}
+	if n := running(); n != 0 {
+		t.Fatalf("expected the runs of the dead node to be finished, got %d running", n)
+	}
+	// Node c restarts with the same ID before its lease expires. The runs of the previous process are finished.
+	if _, err := st.CreateRun(ctx, id, 180); err != nil {
+		t.Fatal(err)
+	}
+	c = newNode("c")
+	if err := c.Sync(ctx, 140); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Owns(id) {
+		t.Fatal("expected restarted node c to own the task")
+	}
+	if n := running(); n != 0 {
+		t.Fatalf("expected the runs of the previous process to be finished, got %d running", n)
+	}
+}
+
+func TestInMemLeaseStore(t *testing.T) {
+	storetest.NewLeaseStoreTest(
+		"in-mem lease store",
+		func(t *testing.T) backend.LeaseStore {
+			return backend.NewInMemLeaseStore()
+		},
+		func(t *testing.T, s backend.LeaseStore) {},
+	)(t)
+}
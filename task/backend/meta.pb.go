@@ -38,6 +38,9 @@ type StoreTaskMeta struct {
 	LastCompleted    int64               `protobuf:"varint,2,opt,name=last_completed,json=lastCompleted,proto3" json:"last_completed,omitempty"`
 	Status           string              `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
 	CurrentlyRunning []*StoreTaskMetaRun `protobuf:"bytes,4,rep,name=currently_running,json=currentlyRunning" json:"currently_running,omitempty"`
+	// triggered holds the now timestamps of runs triggered by successful runs of the task this task depends on,
+	// for which no run has been created yet, oldest first.
+	Triggered []int64 `protobuf:"varint,5,rep,packed,name=triggered" json:"triggered,omitempty"`
 }
 
 func (m *StoreTaskMeta) Reset()                    { *m = StoreTaskMeta{} }
@@ -73,6 +76,13 @@ func (m *StoreTaskMeta) GetCurrentlyRunning() []*StoreTaskMetaRun {
 	return nil
 }
 
+func (m *StoreTaskMeta) GetTriggered() []int64 {
+	if m != nil {
+		return m.Triggered
+	}
+	return nil
+}
+
 type StoreTaskMetaRun struct {
 	// now represents a unix timestamp
 	Now   int64  `protobuf:"varint,1,opt,name=now,proto3" json:"now,omitempty"`
@@ -153,6 +163,24 @@ func (m *StoreTaskMeta) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if len(m.Triggered) > 0 {
+		dAtA2 := make([]byte, len(m.Triggered)*10)
+		var j1 int
+		for _, num1 := range m.Triggered {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA2[j1] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j1++
+			}
+			dAtA2[j1] = uint8(num)
+			j1++
+		}
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintMeta(dAtA, i, uint64(j1))
+		i += copy(dAtA[i:], dAtA2[:j1])
+	}
 	return i, nil
 }
 
@@ -218,6 +246,13 @@ func (m *StoreTaskMeta) Size() (n int) {
 			n += 1 + l + sovMeta(uint64(l))
 		}
 	}
+	if len(m.Triggered) > 0 {
+		l = 0
+		for _, e := range m.Triggered {
+			l += sovMeta(uint64(e))
+		}
+		n += 1 + sovMeta(uint64(l)) + l
+	}
 	return n
 }
 
@@ -377,6 +412,68 @@ func (m *StoreTaskMeta) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType == 0 {
+				var v int64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMeta
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= (int64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Triggered = append(m.Triggered, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowMeta
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthMeta
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v int64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowMeta
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= (int64(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Triggered = append(m.Triggered, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Triggered", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMeta(dAtA[iNdEx:])
@@ -625,26 +722,27 @@ var (
 func init() { proto.RegisterFile("meta.proto", fileDescriptorMeta) }
 
 var fileDescriptorMeta = []byte{
-	// 323 bytes of a gzipped FileDescriptorProto
+	// 337 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x90, 0x31, 0x4e, 0xf3, 0x30,
-	0x1c, 0xc5, 0x3f, 0x7f, 0xa1, 0x45, 0x35, 0xb4, 0x94, 0x0c, 0x28, 0x30, 0x84, 0xa8, 0x02, 0x91,
-	0x05, 0x57, 0x02, 0x89, 0x03, 0xb4, 0x2c, 0x1d, 0x58, 0x0c, 0x03, 0x62, 0x89, 0x1c, 0xc7, 0x0d,
-	0x51, 0x63, 0xbb, 0x72, 0xfe, 0x16, 0xed, 0x2d, 0x38, 0x16, 0x23, 0x07, 0x40, 0x08, 0x85, 0x8b,
-	0xa0, 0xb8, 0x05, 0x04, 0x13, 0xdb, 0x7b, 0x3f, 0xd9, 0x4f, 0xef, 0xfd, 0x31, 0x96, 0x02, 0x18,
-	0x99, 0x1b, 0x0d, 0xda, 0x3f, 0xe2, 0x5a, 0x92, 0x42, 0x4d, 0x4b, 0xbb, 0xc8, 0x58, 0x43, 0x4b,
-	0x06, 0x53, 0x6d, 0x24, 0x01, 0x56, 0xcd, 0x48, 0xca, 0xf8, 0x4c, 0xa8, 0xec, 0xe0, 0x34, 0x2f,
-	0xe0, 0xde, 0xa6, 0x84, 0x6b, 0x39, 0xcc, 0x75, 0xae, 0x87, 0xee, 0x73, 0x6a, 0xa7, 0xce, 0x39,
-	0xe3, 0xd4, 0x2a, 0x74, 0xf0, 0x82, 0x70, 0xf7, 0x1a, 0xb4, 0x11, 0x37, 0xac, 0x9a, 0x5d, 0x09,
-	0x60, 0xfe, 0x09, 0xde, 0x91, 0x6c, 0x91, 0x70, 0xad, 0xb8, 0x35, 0x46, 0x28, 0xbe, 0x0c, 0x50,
-	0x84, 0xe2, 0x16, 0xed, 0x49, 0xb6, 0x18, 0x7f, 0x53, 0xff, 0x18, 0xf7, 0x4a, 0x56, 0x41, 0xc2,
-	0xb5, 0x9c, 0x97, 0x02, 0x44, 0x16, 0xfc, 0x8f, 0x50, 0xec, 0xd1, 0x6e, 0x43, 0xc7, 0x9f, 0xd0,
-	0xdf, 0xc3, 0xed, 0x0a, 0x18, 0xd8, 0x2a, 0xf0, 0x22, 0x14, 0x77, 0xe8, 0xda, 0xf9, 0x1c, 0xef,
-	0xae, 0xa2, 0xa0, 0x5c, 0x26, 0xc6, 0x2a, 0x55, 0xa8, 0x3c, 0xd8, 0x88, 0xbc, 0x78, 0xeb, 0xec,
-	0x82, 0xfc, 0x65, 0x2a, 0xf9, 0xd1, 0x9b, 0x5a, 0x45, 0xfb, 0x5f, 0x81, 0x74, 0x95, 0x37, 0xb8,
-	0xc5, 0xfd, 0xdf, 0xaf, 0xfc, 0x3e, 0xf6, 0x94, 0x7e, 0x70, 0xa3, 0x3c, 0xda, 0xc8, 0x86, 0x80,
-	0x59, 0xba, 0xfa, 0x5d, 0xda, 0x48, 0x3f, 0xc2, 0x6d, 0x63, 0x55, 0x52, 0x64, 0xae, 0xf4, 0xf6,
-	0xa8, 0x53, 0xbf, 0x1e, 0xb6, 0xa8, 0x55, 0x93, 0x4b, 0xda, 0x32, 0x56, 0x4d, 0xb2, 0xd1, 0xfe,
-	0x53, 0x1d, 0xa2, 0xe7, 0x3a, 0x44, 0x6f, 0x75, 0x88, 0x1e, 0xdf, 0xc3, 0x7f, 0x77, 0x9b, 0xeb,
-	0x5e, 0x69, 0xdb, 0x9d, 0xf6, 0xfc, 0x23, 0x00, 0x00, 0xff, 0xff, 0x66, 0x80, 0xc1, 0x72, 0xbd,
-	0x01, 0x00, 0x00,
+	0x18, 0x86, 0x7f, 0xff, 0x21, 0x45, 0x35, 0xb4, 0x14, 0x0f, 0x28, 0x20, 0x14, 0xa2, 0x0a, 0x44,
+	0x16, 0x52, 0x09, 0x24, 0x0e, 0xd0, 0xb2, 0x74, 0x60, 0x31, 0x0c, 0x88, 0x25, 0x72, 0x1c, 0x37,
+	0x44, 0x4d, 0xec, 0xca, 0xf9, 0x2c, 0xda, 0x5b, 0x70, 0x2c, 0x46, 0x4e, 0x80, 0x50, 0xb8, 0x05,
+	0x13, 0x8a, 0x5b, 0xa8, 0x60, 0x62, 0x7b, 0xdf, 0x47, 0xf2, 0xa3, 0xef, 0x35, 0xc6, 0xa5, 0x00,
+	0x16, 0xcd, 0xb4, 0x02, 0x45, 0x8e, 0xb9, 0x2a, 0xa3, 0x5c, 0x4e, 0x0a, 0x33, 0x4f, 0x59, 0x43,
+	0x0b, 0x06, 0x13, 0xa5, 0xcb, 0x08, 0x58, 0x35, 0x8d, 0x12, 0xc6, 0xa7, 0x42, 0xa6, 0x07, 0x67,
+	0x59, 0x0e, 0x0f, 0x26, 0x89, 0xb8, 0x2a, 0x07, 0x99, 0xca, 0xd4, 0xc0, 0x3e, 0x4e, 0xcc, 0xc4,
+	0x36, 0x5b, 0x6c, 0x5a, 0x4a, 0xfb, 0x1f, 0x08, 0x77, 0x6e, 0x40, 0x69, 0x71, 0xcb, 0xaa, 0xe9,
+	0xb5, 0x00, 0x46, 0x4e, 0xf1, 0x4e, 0xc9, 0xe6, 0x31, 0x57, 0x92, 0x1b, 0xad, 0x85, 0xe4, 0x0b,
+	0x0f, 0x05, 0x28, 0x74, 0x69, 0xb7, 0x64, 0xf3, 0xd1, 0x9a, 0x92, 0x13, 0xdc, 0x2d, 0x58, 0x05,
+	0x31, 0x57, 0xe5, 0xac, 0x10, 0x20, 0x52, 0xef, 0x7f, 0x80, 0x42, 0x87, 0x76, 0x1a, 0x3a, 0xfa,
+	0x82, 0x64, 0x0f, 0xb7, 0x2a, 0x60, 0x60, 0x2a, 0xcf, 0x09, 0x50, 0xd8, 0xa6, 0xab, 0x46, 0x38,
+	0xde, 0x5d, 0xaa, 0xa0, 0x58, 0xc4, 0xda, 0x48, 0x99, 0xcb, 0xcc, 0xdb, 0x08, 0x9c, 0x70, 0xeb,
+	0xfc, 0x32, 0xfa, 0xcb, 0xd4, 0xe8, 0xc7, 0xdd, 0xd4, 0x48, 0xda, 0xfb, 0x16, 0xd2, 0xa5, 0x8f,
+	0x1c, 0xe2, 0x36, 0xe8, 0x3c, 0xcb, 0x84, 0x16, 0xa9, 0xe7, 0x06, 0x4e, 0xe8, 0xd0, 0x35, 0xe8,
+	0xdf, 0xe1, 0xde, 0x6f, 0x07, 0xe9, 0x61, 0x47, 0xaa, 0x47, 0x3b, 0xd9, 0xa1, 0x4d, 0x6c, 0x08,
+	0xe8, 0x85, 0x1d, 0xd7, 0xa1, 0x4d, 0x24, 0x01, 0x6e, 0x69, 0x23, 0xe3, 0x3c, 0xb5, 0x93, 0xb6,
+	0x87, 0xed, 0xfa, 0xf5, 0xc8, 0xa5, 0x46, 0x8e, 0xaf, 0xa8, 0xab, 0x8d, 0x1c, 0xa7, 0xc3, 0xfd,
+	0xe7, 0xda, 0x47, 0x2f, 0xb5, 0x8f, 0xde, 0x6a, 0x1f, 0x3d, 0xbd, 0xfb, 0xff, 0xee, 0x37, 0x57,
+	0x57, 0x27, 0x2d, 0xfb, 0xf1, 0x17, 0x9f, 0x03, 0x00, 0x4a, 0xe8, 0x0f, 0x06, 0xdb, 0x01, 0x00,
+	0x00,
 }
@@ -0,0 +1,296 @@
+// Package notify delivers notifications about the outcome of task runs.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/task/backend"
+	"github.com/influxdata/platform/task/options"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBackoff     = time.Second
+	defaultHistorySize = 100
+
+	// logTailLines is the number of trailing run log lines included in a notification.
+	logTailLines = 10
+)
+
+// Payload is the JSON body POSTed to a notification endpoint.
+type Payload struct {
+	// Event is the event that triggered the notification, options.NotifyOnFailure or options.NotifyOnRecovery.
+	Event string `json:"event"`
+
+	Task PayloadTask `json:"task"`
+	Run  PayloadRun  `json:"run"`
+
+	// Error is the reason the run failed. It is empty for recovery notifications.
+	Error string `json:"error,omitempty"`
+
+	// ConsecutiveFailures is the number of runs in a row that have failed, including this one.
+	// For recovery notifications, it is the number of failures that preceded the successful run.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+
+	// LogTail holds the last lines of the run's log.
+	LogTail []string `json:"logTail,omitempty"`
+}
+
+// PayloadTask identifies the task in a Payload.
+type PayloadTask struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// PayloadRun identifies the run in a Payload.
+type PayloadRun struct {
+	ID           string    `json:"id"`
+	ScheduledFor time.Time `json:"scheduledFor"`
+	Status       string    `json:"status"`
+}
+
+// WebhookNotifier is a backend.RunNotifier that POSTs a Payload to each of a task's notification endpoints.
+// Failed deliveries are retried with exponential backoff.
+// The consecutive failure counts of tasks and the delivery attempts are kept in a backend.NotificationStore,
+// which WebhookNotifier also serves as a platform.TaskNotificationService.
+type WebhookNotifier struct {
+	logger *zap.Logger
+	lr     backend.LogReader
+	store  backend.NotificationStore
+	client *http.Client
+
+	maxAttempts int
+	backoff     time.Duration
+	historySize int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var (
+	_ backend.RunNotifier              = (*WebhookNotifier)(nil)
+	_ platform.TaskNotificationService = (*WebhookNotifier)(nil)
+)
+
+// Option configures a WebhookNotifier.
+type Option func(*WebhookNotifier)
+
+// WithHTTPClient sets the client used to deliver notifications.
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *WebhookNotifier) {
+		n.client = c
+	}
+}
+
+// WithNotificationStore sets the store that keeps failure counts and delivery history.
+// If not set, they are kept in memory and lost on restart.
+func WithNotificationStore(s backend.NotificationStore) Option {
+	return func(n *WebhookNotifier) {
+		n.store = s
+	}
+}
+
+// WithMaxAttempts sets the number of times a notification is attempted before giving up.
+func WithMaxAttempts(attempts int) Option {
+	return func(n *WebhookNotifier) {
+		n.maxAttempts = attempts
+	}
+}
+
+// WithBackoff sets the delay before the first retry. The delay doubles for each subsequent retry.
+func WithBackoff(d time.Duration) Option {
+	return func(n *WebhookNotifier) {
+		n.backoff = d
+	}
+}
+
+// WithHistorySize sets the number of delivery attempts remembered for each endpoint.
+func WithHistorySize(size int) Option {
+	return func(n *WebhookNotifier) {
+		n.historySize = size
+	}
+}
+
+// NewWebhookNotifier returns a new WebhookNotifier.
+// The LogReader is used to include the tail of the run log in notifications.
+func NewWebhookNotifier(logger *zap.Logger, lr backend.LogReader, opts ...Option) *WebhookNotifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &WebhookNotifier{
+		logger:      logger.With(zap.String("svc", "taskd/notify")),
+		lr:          lr,
+		store:       backend.NewInMemNotificationStore(),
+		client:      &http.Client{Timeout: 10 * time.Second},
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+		historySize: defaultHistorySize,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// RunFinished implements backend.RunNotifier.
+func (n *WebhookNotifier) RunFinished(task *backend.StoreTask, endpoints []options.Notification, qr backend.QueuedRun, status backend.RunStatus, runErr error) {
+	tid := task.ID.String()
+
+	previous, err := n.store.RecordRunResult(n.ctx, task.ID, status == backend.RunFail)
+	if err != nil {
+		n.logger.Info("Failed to record run result for notifications", zap.String("task_id", tid), zap.Error(err))
+		return
+	}
+
+	var toNotify []options.Notification
+	var p Payload
+	for _, ep := range endpoints {
+		switch {
+		case status == backend.RunFail && ep.On == options.NotifyOnFailure:
+			if ep.After != 0 && int64(previous+1) != ep.After {
+				continue
+			}
+			p.Event = options.NotifyOnFailure
+			p.ConsecutiveFailures = previous + 1
+		case status == backend.RunSuccess && ep.On == options.NotifyOnRecovery:
+			if previous == 0 {
+				continue
+			}
+			p.Event = options.NotifyOnRecovery
+			p.ConsecutiveFailures = previous
+		default:
+			continue
+		}
+		toNotify = append(toNotify, ep)
+	}
+	if len(toNotify) == 0 {
+		return
+	}
+
+	p.Task = PayloadTask{ID: tid, Name: task.Name}
+	p.Run = PayloadRun{
+		ID:           qr.RunID.String(),
+		ScheduledFor: time.Unix(qr.Now, 0).UTC(),
+		Status:       status.String(),
+	}
+	if runErr != nil {
+		p.Error = runErr.Error()
+	}
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+
+		p.LogTail = n.logTail(task.ID, qr.RunID)
+		body, err := json.Marshal(p)
+		if err != nil {
+			n.logger.Info("Failed to encode notification", zap.Error(err))
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, ep := range toNotify {
+			wg.Add(1)
+			go func(url string) {
+				defer wg.Done()
+				n.deliver(task.ID, url, qr.RunID, p.Event, body)
+			}(ep.URL)
+		}
+		wg.Wait()
+	}()
+}
+
+// FindTaskNotifications implements platform.TaskNotificationService.
+func (n *WebhookNotifier) FindTaskNotifications(ctx context.Context, taskID platform.ID) (*platform.TaskNotifications, error) {
+	return n.store.FindTaskNotifications(ctx, taskID)
+}
+
+// Close stops any pending retries and waits for in-flight deliveries to finish.
+func (n *WebhookNotifier) Close() error {
+	n.cancel()
+	n.wg.Wait()
+	return nil
+}
+
+// logTail returns the last lines of the run's log, or nil if the log can't be read.
+func (n *WebhookNotifier) logTail(taskID, runID platform.ID) []string {
+	run, err := n.lr.FindRunByID(n.ctx, taskID, runID)
+	if err != nil || run == nil || run.Log == "" {
+		return nil
+	}
+	lines := strings.Split(string(run.Log), "\n")
+	if len(lines) > logTailLines {
+		lines = lines[len(lines)-logTailLines:]
+	}
+	return lines
+}
+
+// deliver POSTs body to url, retrying until it succeeds, the error is not retryable, or the attempts run out.
+func (n *WebhookNotifier) deliver(taskID platform.ID, url string, runID platform.ID, event string, body []byte) {
+	backoff := n.backoff
+	for attempt := 1; attempt <= n.maxAttempts; attempt++ {
+		d := platform.NotificationDelivery{
+			URL:     url,
+			Event:   event,
+			RunID:   runID,
+			Attempt: attempt,
+			Time:    time.Now().UTC(),
+		}
+		retryable := n.post(url, body, &d)
+		if err := n.store.AddDelivery(context.Background(), taskID, d, n.historySize); err != nil {
+			n.logger.Info("Failed to record notification delivery", zap.String("task_id", taskID.String()), zap.Error(err))
+		}
+
+		if d.Succeeded() {
+			return
+		}
+		n.logger.Info("Failed to deliver notification",
+			zap.String("task_id", taskID.String()), zap.String("url", url), zap.Int("attempt", attempt), zap.String("error", d.Error))
+		if !retryable || attempt == n.maxAttempts {
+			return
+		}
+
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes a single delivery attempt, recording the outcome in d.
+// It returns whether a failed attempt is worth retrying.
+func (n *WebhookNotifier) post(url string, body []byte, d *platform.NotificationDelivery) (retryable bool) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		d.Error = err.Error()
+		return false
+	}
+	req = req.WithContext(n.ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		d.Error = err.Error()
+		return n.ctx.Err() == nil
+	}
+	resp.Body.Close()
+
+	d.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false
+	}
+	d.Error = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
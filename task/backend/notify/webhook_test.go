@@ -0,0 +1,234 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/task/backend"
+	"github.com/influxdata/platform/task/backend/notify"
+	"github.com/influxdata/platform/task/options"
+	"go.uber.org/zap"
+)
+
+// endpoint is a webhook receiver that responds with the queued status codes, then with 200.
+type endpoint struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	payloads []notify.Payload
+}
+
+func newEndpoint(statuses ...int) *endpoint {
+	e := &endpoint{statuses: statuses}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		var p notify.Payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		e.payloads = append(e.payloads, p)
+
+		status := http.StatusOK
+		if len(e.statuses) > 0 {
+			status, e.statuses = e.statuses[0], e.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	return e
+}
+
+func (e *endpoint) Payloads() []notify.Payload {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]notify.Payload(nil), e.payloads...)
+}
+
+// history returns the delivery attempts to url that the notifier has recorded for the task.
+func history(t *testing.T, n *notify.WebhookNotifier, taskID platform.ID, url string) []platform.NotificationDelivery {
+	t.Helper()
+	tn, err := n.FindTaskNotifications(context.Background(), taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var h []platform.NotificationDelivery
+	for _, d := range tn.Deliveries {
+		if d.URL == url {
+			h = append(h, d)
+		}
+	}
+	return h
+}
+
+// waitForHistory waits until the notifier has recorded n delivery attempts to url.
+func waitForHistory(t *testing.T, n *notify.WebhookNotifier, taskID platform.ID, url string, count int) []platform.NotificationDelivery {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if h := history(t, n, taskID, url); len(h) >= count {
+			return h
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d deliveries to %s, got %d", count, url, len(history(t, n, taskID, url)))
+	return nil
+}
+
+func TestWebhookNotifier_Events(t *testing.T) {
+	ctx := context.Background()
+	task := &backend.StoreTask{ID: platform.ID{1}, Name: "my task"}
+	rw := backend.NewInMemRunReaderWriter()
+
+	every, afterTwo, recovery := newEndpoint(), newEndpoint(), newEndpoint()
+	defer every.Close()
+	defer afterTwo.Close()
+	defer recovery.Close()
+	endpoints := []options.Notification{
+		{URL: every.URL, On: options.NotifyOnFailure},
+		{URL: afterTwo.URL, On: options.NotifyOnFailure, After: 2},
+		{URL: recovery.URL, On: options.NotifyOnRecovery},
+	}
+
+	n := notify.NewWebhookNotifier(zap.NewNop(), rw)
+	defer n.Close()
+
+	finish := func(runID byte, status backend.RunStatus, runErr error) {
+		t.Helper()
+		qr := backend.QueuedRun{TaskID: task.ID, RunID: platform.ID{runID}, Now: 60 * int64(runID)}
+		if err := rw.UpdateRunState(ctx, task, qr.RunID, time.Unix(qr.Now, 0), status); err != nil {
+			t.Fatal(err)
+		}
+		if err := rw.AddRunLog(ctx, task, qr.RunID, time.Unix(qr.Now, 0), "log line"); err != nil {
+			t.Fatal(err)
+		}
+		n.RunFinished(task, endpoints, qr, status, runErr)
+	}
+
+	// A success without prior failures notifies nobody.
+	finish(1, backend.RunSuccess, nil)
+	// Three failures in a row.
+	forced := errors.New("forced failure")
+	finish(2, backend.RunFail, forced)
+	waitForHistory(t, n, task.ID, every.URL, 1)
+	finish(3, backend.RunFail, forced)
+	waitForHistory(t, n, task.ID, every.URL, 2)
+	waitForHistory(t, n, task.ID, afterTwo.URL, 1)
+	finish(4, backend.RunFail, forced)
+	waitForHistory(t, n, task.ID, every.URL, 3)
+	// Then a recovery.
+	finish(5, backend.RunSuccess, nil)
+	waitForHistory(t, n, task.ID, recovery.URL, 1)
+
+	if got := len(every.Payloads()); got != 3 {
+		t.Fatalf("expected 3 failure notifications, got %d", got)
+	}
+
+	p := afterTwo.Payloads()
+	if len(p) != 1 {
+		t.Fatalf("expected exactly 1 notification after 2 consecutive failures, got %d", len(p))
+	}
+	if p[0].Event != options.NotifyOnFailure || p[0].ConsecutiveFailures != 2 || p[0].Error != forced.Error() {
+		t.Fatalf("unexpected failure payload: %+v", p[0])
+	}
+	if p[0].Task.ID != task.ID.String() || p[0].Task.Name != task.Name || p[0].Run.ID != (platform.ID{3}).String() {
+		t.Fatalf("unexpected task or run in payload: %+v", p[0])
+	}
+	if len(p[0].LogTail) != 1 {
+		t.Fatalf("expected log tail with 1 line, got %v", p[0].LogTail)
+	}
+
+	p = recovery.Payloads()
+	if len(p) != 1 {
+		t.Fatalf("expected exactly 1 recovery notification, got %d", len(p))
+	}
+	if p[0].Event != options.NotifyOnRecovery || p[0].ConsecutiveFailures != 3 || p[0].Error != "" {
+		t.Fatalf("unexpected recovery payload: %+v", p[0])
+	}
+}
+
+func TestWebhookNotifier_Retries(t *testing.T) {
+	task := &backend.StoreTask{ID: platform.ID{1}, Name: "my task"}
+	qr := backend.QueuedRun{TaskID: task.ID, RunID: platform.ID{1}, Now: 60}
+
+	flaky := newEndpoint(http.StatusInternalServerError, http.StatusServiceUnavailable)
+	defer flaky.Close()
+	rejecting := newEndpoint(http.StatusBadRequest)
+	defer rejecting.Close()
+	endpoints := []options.Notification{
+		{URL: flaky.URL, On: options.NotifyOnFailure},
+		{URL: rejecting.URL, On: options.NotifyOnFailure},
+	}
+
+	n := notify.NewWebhookNotifier(zap.NewNop(), backend.NopLogReader{}, notify.WithBackoff(time.Millisecond), notify.WithMaxAttempts(5))
+	n.RunFinished(task, endpoints, qr, backend.RunFail, errors.New("forced failure"))
+
+	h := waitForHistory(t, n, task.ID, flaky.URL, 3)
+	for i, d := range h {
+		if d.Attempt != i+1 {
+			t.Fatalf("expected attempt %d, got %d", i+1, d.Attempt)
+		}
+	}
+	if h[0].Succeeded() || h[0].StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected first attempt to fail with 500, got %+v", h[0])
+	}
+	if !h[2].Succeeded() || h[2].StatusCode != http.StatusOK {
+		t.Fatalf("expected third attempt to succeed, got %+v", h[2])
+	}
+
+	// Client errors are not retried.
+	if err := n.Close(); err != nil {
+		t.Fatal(err)
+	}
+	h = history(t, n, task.ID, rejecting.URL)
+	if len(h) != 1 || h[0].Succeeded() || h[0].StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a single rejected delivery, got %+v", h)
+	}
+}
+
+func TestWebhookNotifier_NotificationStore(t *testing.T) {
+	task := &backend.StoreTask{ID: platform.ID{1}, Name: "my task"}
+	store := backend.NewInMemNotificationStore()
+
+	afterThree := newEndpoint()
+	defer afterThree.Close()
+	endpoints := []options.Notification{
+		{URL: afterThree.URL, On: options.NotifyOnFailure, After: 3},
+	}
+	forced := errors.New("forced failure")
+
+	n := notify.NewWebhookNotifier(zap.NewNop(), backend.NopLogReader{}, notify.WithNotificationStore(store))
+	for i := byte(1); i <= 2; i++ {
+		n.RunFinished(task, endpoints, backend.QueuedRun{TaskID: task.ID, RunID: platform.ID{i}, Now: 60 * int64(i)}, backend.RunFail, forced)
+	}
+	if err := n.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A notifier sharing the store, such as after a restart, continues counting failures.
+	n = notify.NewWebhookNotifier(zap.NewNop(), backend.NopLogReader{}, notify.WithNotificationStore(store))
+	defer n.Close()
+	n.RunFinished(task, endpoints, backend.QueuedRun{TaskID: task.ID, RunID: platform.ID{3}, Now: 180}, backend.RunFail, forced)
+	waitForHistory(t, n, task.ID, afterThree.URL, 1)
+
+	p := afterThree.Payloads()
+	if len(p) != 1 || p[0].ConsecutiveFailures != 3 {
+		t.Fatalf("expected a single notification after 3 consecutive failures, got %+v", p)
+	}
+
+	tn, err := n.FindTaskNotifications(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tn.ConsecutiveFailures != 3 {
+		t.Fatalf("expected 3 consecutive failures, got %d", tn.ConsecutiveFailures)
+	}
+}
@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/platform/task/options"
+	"gopkg.in/robfig/cron.v2"
+)
+
+// newSchedule returns the schedule described by the timing fields of opts.
+func newSchedule(opts *options.Options) (cron.Schedule, error) {
+	var sch cron.Schedule
+	if opts.Cron != "" {
+		// Without an explicit time zone, the cron package would evaluate the expression in the local time zone.
+		tz := opts.Timezone
+		if tz == "" {
+			tz = "UTC"
+		}
+		var err error
+		sch, err = cron.Parse("TZ=" + tz + " " + opts.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing cron expression: %v", err)
+		}
+	} else {
+		if opts.Every < time.Second {
+			return nil, errors.New("timing options not set or set too quick")
+		}
+
+		if opts.Every.Truncate(time.Second) != opts.Every {
+			return nil, errors.New("invalid every granularity")
+		}
+		if opts.Offset == 0 {
+			// Keep the behavior of "@every": runs are spaced by a constant delay from when the task was claimed.
+			sch = cron.Every(opts.Every)
+		} else {
+			// An offset is only meaningful relative to fixed boundaries, so align runs to the epoch instead.
+			sch = everySchedule(opts.Every)
+		}
+	}
+
+	if opts.Offset != 0 {
+		sch = offsetSchedule{schedule: sch, offset: opts.Offset}
+	}
+	return sch, nil
+}
+
+// everySchedule is a cron.Schedule that activates at every multiple of its duration since the Unix epoch.
+// Unlike cron.ConstantDelaySchedule, its activation times do not depend on when the schedule was first consulted,
+// so that an offset can be applied to it predictably.
+type everySchedule time.Duration
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	d := int64(time.Duration(s) / time.Second)
+	u := t.Unix()
+	next := u - u%d + d
+	if u < 0 && u%d != 0 {
+		next -= d
+	}
+	return time.Unix(next, 0).In(t.Location())
+}
+
+// offsetSchedule delays every activation of a schedule by a fixed offset.
+type offsetSchedule struct {
+	schedule cron.Schedule
+	offset   time.Duration
+}
+
+func (s offsetSchedule) Next(t time.Time) time.Time {
+	return s.schedule.Next(t.Add(-s.offset)).Add(s.offset)
+}
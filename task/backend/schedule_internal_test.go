@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform/task/options"
+)
+
+func TestNewSchedule(t *testing.T) {
+	utc := func(s string) time.Time {
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	for _, c := range []struct {
+		name string
+		opts options.Options
+		from string
+		exp  string
+	}{
+		{name: "cron defaults to UTC", opts: options.Options{Cron: "0 2 * * *"}, from: "2018-03-20T12:00:00Z", exp: "2018-03-21T02:00:00Z"},
+		{name: "cron in standard time", opts: options.Options{Cron: "0 2 * * *", Timezone: "Europe/Berlin"}, from: "2018-03-23T12:00:00Z", exp: "2018-03-24T01:00:00Z"},
+		{name: "cron in daylight saving time", opts: options.Options{Cron: "0 2 * * *", Timezone: "Europe/Berlin"}, from: "2018-03-26T01:00:00Z", exp: "2018-03-27T00:00:00Z"},
+		{name: "cron with offset", opts: options.Options{Cron: "0 2 * * *", Offset: 30 * time.Minute}, from: "2018-03-20T02:10:00Z", exp: "2018-03-20T02:30:00Z"},
+		{name: "every is a constant delay", opts: options.Options{Every: time.Hour}, from: "2018-03-20T10:20:00Z", exp: "2018-03-20T11:20:00Z"},
+		{name: "every with offset is aligned", opts: options.Options{Every: time.Hour, Offset: time.Second}, from: "2018-03-20T10:20:00Z", exp: "2018-03-20T11:00:01Z"},
+		{name: "every with offset before boundary", opts: options.Options{Every: time.Hour, Offset: 15 * time.Minute}, from: "2018-03-20T10:10:00Z", exp: "2018-03-20T10:15:00Z"},
+		{name: "every with offset after boundary", opts: options.Options{Every: time.Hour, Offset: 15 * time.Minute}, from: "2018-03-20T10:20:00Z", exp: "2018-03-20T11:15:00Z"},
+		{name: "every on boundary", opts: options.Options{Every: time.Minute}, from: "2018-03-20T10:20:00Z", exp: "2018-03-20T10:21:00Z"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			sch, err := newSchedule(&c.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, exp := sch.Next(utc(c.from)).UTC(), utc(c.exp); !got.Equal(exp) {
+				t.Fatalf("expected next run at %v, got %v", exp, got)
+			}
+		})
+	}
+
+	for _, opts := range []options.Options{
+		{},
+		{Every: 500 * time.Millisecond},
+		{Every: 1500 * time.Millisecond},
+		{Cron: "not a cron"},
+	} {
+		if _, err := newSchedule(&opts); err == nil {
+			t.Fatalf("expected error for options %+v", opts)
+		}
+	}
+}
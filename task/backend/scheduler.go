@@ -16,17 +16,34 @@ import (
 )
 
 var ErrRunCanceled = errors.New("run canceled")
+var ErrRunTimedOut = errors.New("run timed out")
 var ErrTaskNotClaimed = errors.New("task not claimed")
 
+// ErrNoRunTriggered is returned by CreateTriggeredRun when the task has no triggered runs waiting to be created.
+var ErrNoRunTriggered = errors.New("no run triggered")
+
 // DesiredState persists the desired state of a run.
 type DesiredState interface {
 	// CreateRun returns a run ID for a task and a now timestamp.
 	// If a run already exists for taskID and now, CreateRun must return an error without queuing a new run.
 	CreateRun(ctx context.Context, taskID platform.ID, now int64) (QueuedRun, error)
 
+	// TriggerDependents queues a run with the given now timestamp for every task that depends on the task with the given ID.
+	// Triggers are persisted with the dependent tasks, so they are not lost if the dependent task is scheduled elsewhere.
+	// A timestamp that is already queued for a task is ignored.
+	TriggerDependents(ctx context.Context, taskID platform.ID, now int64) error
+
+	// CreateTriggeredRun creates a run for the oldest queued trigger of the task, as CreateRun would, and removes that trigger.
+	// If the task has no queued triggers, CreateTriggeredRun returns ErrNoRunTriggered.
+	CreateTriggeredRun(ctx context.Context, taskID platform.ID) (QueuedRun, error)
+
 	// FinishRun indicates that the given run is no longer intended to be executed.
 	// This may be called after a successful or failed execution, or upon cancellation.
 	FinishRun(ctx context.Context, taskID, runID platform.ID) error
+
+	// RecordRunResult records the outcome of a run that succeeded or failed,
+	// so that tasks can be found by the status of their latest run.
+	RecordRunResult(ctx context.Context, taskID platform.ID, status RunStatus, finished time.Time) error
 }
 
 // Executor handles execution of a run.
@@ -43,6 +60,10 @@ type Executor interface {
 type QueuedRun struct {
 	TaskID, RunID platform.ID
 	Now           int64
+
+	// Revision is the revision of the task's script that the run executes.
+	// It is 0 if the task has no recorded revisions.
+	Revision int64
 }
 
 // RunPromise represents an in-progress run whose result is not yet known.
@@ -69,7 +90,17 @@ type RunResult interface {
 	// IsRetryable returns true if the error was non-terminal and the run is eligible for retry.
 	IsRetryable() bool
 
-	// TODO(mr): add more detail here like number of points written, execution time, etc.
+	// Statistics returns details about the execution of the run,
+	// such as how long it took and how much data each yield produced.
+	Statistics() platform.RunStatistics
+}
+
+// RunNotifier is informed when a run finishes, so that it can alert the task's notification endpoints.
+type RunNotifier interface {
+	// RunFinished is called after the final state of a run has been recorded.
+	// status is either RunSuccess or RunFail, and runErr is the cause of a failure.
+	// RunFinished is called from the scheduler's goroutines, so it must not block on delivering notifications.
+	RunFinished(task *StoreTask, endpoints []options.Notification, qr QueuedRun, status RunStatus, runErr error)
 }
 
 // Scheduler accepts tasks and handles their scheduling.
@@ -143,6 +174,34 @@ func WithLogger(logger *zap.Logger) SchedulerOption {
 	}
 }
 
+// WithRunNotifier sets the RunNotifier the scheduler reports finished runs to.
+// If not set, finished runs are only recorded through the LogWriter.
+func WithRunNotifier(n RunNotifier) SchedulerOption {
+	return func(s Scheduler) {
+		switch sched := s.(type) {
+		case *outerScheduler:
+			sched.notifier = n
+		default:
+			panic(fmt.Sprintf("cannot apply WithRunNotifier to Scheduler of type %T", s))
+		}
+	}
+}
+
+// WithTaskMetricsLimit sets how many claimed tasks are labelled individually in the per-task metrics,
+// such as the schedule lag and run duration histograms.
+// Tasks claimed beyond the limit share the "other" label values, which keeps the cardinality of the metrics bounded.
+// If not set, 100 tasks are labelled individually.
+func WithTaskMetricsLimit(n int) SchedulerOption {
+	return func(s Scheduler) {
+		switch sched := s.(type) {
+		case *outerScheduler:
+			sched.metrics.taskLimit = n
+		default:
+			panic(fmt.Sprintf("cannot apply WithTaskMetricsLimit to Scheduler of type %T", s))
+		}
+	}
+}
+
 // NewScheduler returns a new scheduler with the given desired state and the given now UTC timestamp.
 func NewScheduler(desiredState DesiredState, executor Executor, lw LogWriter, now int64, opts ...SchedulerOption) Scheduler {
 	o := &outerScheduler{
@@ -151,6 +210,7 @@ func NewScheduler(desiredState DesiredState, executor Executor, lw LogWriter, no
 		logWriter:    lw,
 		now:          now,
 		tasks:        make(map[string]*taskScheduler),
+		dependents:   make(map[string]map[string]*taskScheduler),
 		logger:       zap.NewNop(),
 		metrics:      newSchedulerMetrics(),
 	}
@@ -166,6 +226,7 @@ type outerScheduler struct {
 	desiredState DesiredState
 	executor     Executor
 	logWriter    LogWriter
+	notifier     RunNotifier
 
 	now       int64
 	logger    *zap.Logger
@@ -176,6 +237,9 @@ type outerScheduler struct {
 	mu sync.Mutex
 
 	tasks map[string]*taskScheduler
+
+	// Map of upstream task ID, to the claimed tasks that depend on it, keyed by their own task ID.
+	dependents map[string]map[string]*taskScheduler
 }
 
 func (s *outerScheduler) Tick(now int64) {
@@ -192,22 +256,15 @@ func (s *outerScheduler) Tick(now int64) {
 func (s *outerScheduler) ClaimTask(task *StoreTask, startExecutionFrom int64, opts *options.Options) (err error) {
 	defer s.metrics.ClaimTask(err == nil)
 
-	timer := opts.Cron
-
-	if timer == "" {
-		if opts.Every < time.Second {
-			return errors.New("timing options not set or set too quick")
-		}
-
-		if opts.Every.Truncate(time.Second) != opts.Every {
-			return errors.New("invalid every granularity")
+	// A task that depends on another task has no schedule of its own.
+	var sch cron.Schedule
+	var skipped *skippedRuns
+	if opts.DependsOn == "" {
+		sch, err = newSchedule(opts)
+		if err != nil {
+			return err
 		}
-		timer = "@every " + opts.Every.String()
-	}
-
-	sch, err := cron.Parse(timer)
-	if err != nil {
-		return fmt.Errorf("error parsing cron expression: %v", err)
+		skipped = s.catchUp(sch, startExecutionFrom, opts.CatchUp)
 	}
 
 	ts := newTaskScheduler(
@@ -215,17 +272,17 @@ func (s *outerScheduler) ClaimTask(task *StoreTask, startExecutionFrom int64, op
 		task,
 		sch,
 		startExecutionFrom,
+		skipped,
 		uint8(opts.Concurrency),
+		int(opts.Retry),
+		opts.Notify,
+		opts.DependsOn,
 	)
 
-	if s.cronTimer != nil {
-		cronID, err := s.cronTimer.AddFunc(timer, func() {
+	if s.cronTimer != nil && sch != nil {
+		ts.cronID = s.cronTimer.Schedule(sch, cron.FuncJob(func() {
 			ts.Start(time.Now().Unix())
-		})
-		if err != nil {
-			return fmt.Errorf("error starting cron timer: %v", err)
-		}
-		ts.cronID = cronID
+		}))
 	}
 
 	s.mu.Lock()
@@ -236,13 +293,62 @@ func (s *outerScheduler) ClaimTask(task *StoreTask, startExecutionFrom int64, op
 	}
 
 	s.tasks[task.ID.String()] = ts
+	if ts.dependsOn != "" {
+		if s.dependents[ts.dependsOn] == nil {
+			s.dependents[ts.dependsOn] = make(map[string]*taskScheduler)
+		}
+		s.dependents[ts.dependsOn][task.ID.String()] = ts
+	}
 
 	s.mu.Unlock()
 
+	s.metrics.TrackTask(task.ID.String(), task.Org.String())
+
 	ts.Start(s.now)
 	return nil
 }
 
+// skippedRuns describes the scheduled runs of a task that the catch-up policy skipped.
+type skippedRuns struct {
+	first, last int64
+	count       int
+	policy      string
+
+	// ID of the run recording the skipped runs, once it has been created.
+	runID platform.ID
+}
+
+// catchUp applies the catch-up policy to the scheduled runs missed between startExecutionFrom and the scheduler's now.
+// It returns the runs to skip, or nil if every missed run should execute.
+func (s *outerScheduler) catchUp(sch cron.Schedule, startExecutionFrom int64, policy string) *skippedRuns {
+	if policy == "" || policy == options.CatchUpAll {
+		return nil
+	}
+
+	now := atomic.LoadInt64(&s.now)
+	var first, beforeLatest, latest int64
+	missed := 0
+	for next := sch.Next(time.Unix(startExecutionFrom, 0).UTC()).Unix(); next <= now; next = sch.Next(time.Unix(next, 0).UTC()).Unix() {
+		if missed == 0 {
+			first = next
+		}
+		beforeLatest, latest = latest, next
+		missed++
+	}
+
+	switch {
+	case missed == 0:
+		return nil
+	case policy == options.CatchUpLatest:
+		if missed == 1 {
+			return nil
+		}
+		return &skippedRuns{first: first, last: beforeLatest, count: missed - 1, policy: policy}
+	default: // options.CatchUpNone.
+		return &skippedRuns{first: first, last: latest, count: missed, policy: policy}
+	}
+}
+
 func (s *outerScheduler) ReleaseTask(taskID platform.ID) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -259,12 +365,38 @@ func (s *outerScheduler) ReleaseTask(taskID platform.ID) error {
 
 	t.Cancel()
 	delete(s.tasks, tid)
+	if t.dependsOn != "" {
+		delete(s.dependents[t.dependsOn], tid)
+		if len(s.dependents[t.dependsOn]) == 0 {
+			delete(s.dependents, t.dependsOn)
+		}
+	}
 
 	s.metrics.ReleaseTask(tid)
 
 	return nil
 }
 
+// triggerDependents queues a run with the given now timestamp,
+// for each task that depends on the task with the given ID.
+// The triggers are persisted through the desired state, so that dependent tasks claimed by other schedulers,
+// or claimed again after a restart, still create their runs on their next tick.
+// Dependent tasks claimed by s are started immediately.
+func (s *outerScheduler) triggerDependents(taskID platform.ID, now int64) {
+	// Use a background context, so that the triggers are recorded even if the upstream task is released meanwhile.
+	if err := s.desiredState.TriggerDependents(context.Background(), taskID, now); err != nil {
+		s.logger.Info("Error triggering dependent tasks", zap.String("task_id", taskID.String()), zap.Int64("now", now), zap.Error(err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ts := range s.dependents[taskID.String()] {
+		ts.Start(atomic.LoadInt64(&s.now))
+	}
+}
+
 func (s *outerScheduler) PrometheusCollectors() []prometheus.Collector {
 	return s.metrics.PrometheusCollectors()
 }
@@ -286,6 +418,12 @@ type taskScheduler struct {
 	// Fixed-length slice of runners.
 	runners []*runner
 
+	// Timing state shared by the runners.
+	tt *taskTimer
+
+	// Hex encoded ID of the task whose successful runs trigger this task, if any.
+	dependsOn string
+
 	// Record updates to run state.
 	logWriter LogWriter
 
@@ -297,16 +435,28 @@ func newTaskScheduler(
 	task *StoreTask,
 	cron cron.Schedule,
 	startExecutionFrom int64,
+	skipped *skippedRuns,
 	concurrencyLimit uint8,
+	retry int,
+	notify []options.Notification,
+	dependsOn string,
 ) *taskScheduler {
-	firstScheduled := cron.Next(time.Unix(startExecutionFrom, 0).UTC()).Unix()
+	if skipped != nil {
+		// Scheduling resumes after the skipped runs, but no run is created until they have been recorded.
+		startExecutionFrom = skipped.last
+	}
+	var firstScheduled int64
+	if cron != nil {
+		firstScheduled = cron.Next(time.Unix(startExecutionFrom, 0).UTC()).Unix()
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	ts := &taskScheduler{
-		task:    task,
-		now:     startExecutionFrom,
-		cancel:  cancel,
-		runners: make([]*runner, concurrencyLimit),
-		logger:  s.logger.With(zap.String("task_id", task.ID.String())),
+		task:      task,
+		now:       startExecutionFrom,
+		cancel:    cancel,
+		runners:   make([]*runner, concurrencyLimit),
+		dependsOn: dependsOn,
+		logger:    s.logger.With(zap.String("task_id", task.ID.String())),
 	}
 
 	tt := &taskTimer{
@@ -315,13 +465,15 @@ func newTaskScheduler(
 
 		nextScheduledRun: firstScheduled,
 		latestInProgress: startExecutionFrom,
+		skipped:          skipped,
 
 		metrics: s.metrics,
 	}
+	ts.tt = tt
 
 	for i := range ts.runners {
 		logger := ts.logger.With(zap.Int("run_slot", i))
-		ts.runners[i] = newRunner(ctx, logger, task, s.desiredState, s.executor, s.logWriter, tt)
+		ts.runners[i] = newRunner(ctx, logger, task, s.desiredState, s.executor, s.logWriter, s.notifier, notify, retry, tt, s.triggerDependents)
 	}
 
 	return ts
@@ -354,6 +506,8 @@ type taskTimer struct {
 	taskNow *int64
 
 	// Schedule of task.
+	// It is nil if the task depends on another task,
+	// in which case its runs are only created for the triggers queued in the desired state.
 	cron cron.Schedule
 
 	metrics *schedulerMetrics
@@ -366,11 +520,40 @@ type taskTimer struct {
 	// Timestamp of the latest run in progress, i.e. a run that has been created.
 	// This value is not affected by a single runner going idle.
 	latestInProgress int64
+
+	// skippedMu serializes recording the runs skipped by the catch-up policy.
+	skippedMu sync.Mutex
+
+	// Runs skipped by the catch-up policy that have not yet been recorded in the desired state, if any.
+	// No scheduled run is created while they are pending,
+	// so that the task's start point in the desired state never moves past unrecorded runs.
+	skipped *skippedRuns
+}
+
+// RecordSkippedRuns records any pending skipped runs with record, and reports whether none remain pending.
+// Only one caller records the skipped runs; if record fails, they remain pending for the next call.
+func (tt *taskTimer) RecordSkippedRuns(record func(*skippedRuns) bool) bool {
+	tt.skippedMu.Lock()
+	defer tt.skippedMu.Unlock()
+
+	if tt.skipped == nil {
+		return true
+	}
+	if !record(tt.skipped) {
+		return false
+	}
+	tt.skipped = nil
+	return true
 }
 
 // NextScheduledRun returns the timestamp of the next run that should be scheduled,
 // and whether it is okay to schedule that run now.
+// A task without a schedule never has a scheduled run.
 func (tt *taskTimer) NextScheduledRun() (int64, bool) {
+	if tt.cron == nil {
+		return 0, false
+	}
+
 	tt.mu.RLock()
 	next := tt.nextScheduledRun
 	tt.mu.RUnlock()
@@ -385,6 +568,10 @@ func (tt *taskTimer) StartRun(now int64) {
 	if now > tt.latestInProgress {
 		tt.latestInProgress = now
 	}
+	if tt.cron == nil {
+		tt.mu.Unlock()
+		return
+	}
 	if tt.latestInProgress == tt.nextScheduledRun {
 		tt.nextScheduledRun = tt.cron.Next(time.Unix(now, 0).UTC()).Unix()
 	} else if tt.latestInProgress > tt.nextScheduledRun {
@@ -409,8 +596,18 @@ type runner struct {
 	executor     Executor
 	logWriter    LogWriter
 
+	// notifier, if set, is told about finished runs, so that it can alert the endpoints in notify.
+	notifier RunNotifier
+	notify   []options.Notification
+
+	// retry is how many times a run is executed again after a failure that is retryable.
+	retry int
+
 	tt *taskTimer
 
+	// triggerDependents is called with the task ID and now timestamp of each successful run.
+	triggerDependents func(taskID platform.ID, now int64)
+
 	logger *zap.Logger
 }
 
@@ -421,17 +618,25 @@ func newRunner(
 	desiredState DesiredState,
 	executor Executor,
 	logWriter LogWriter,
+	notifier RunNotifier,
+	notify []options.Notification,
+	retry int,
 	tt *taskTimer,
+	triggerDependents func(taskID platform.ID, now int64),
 ) *runner {
 	return &runner{
-		ctx:          ctx,
-		state:        new(uint32),
-		task:         task,
-		desiredState: desiredState,
-		executor:     executor,
-		logWriter:    logWriter,
-		tt:           tt,
-		logger:       logger,
+		ctx:               ctx,
+		state:             new(uint32),
+		task:              task,
+		desiredState:      desiredState,
+		executor:          executor,
+		logWriter:         logWriter,
+		notifier:          notifier,
+		notify:            notify,
+		retry:             retry,
+		tt:                tt,
+		triggerDependents: triggerDependents,
+		logger:            logger,
 	}
 }
 
@@ -466,43 +671,174 @@ func (r *runner) Start() {
 // startFromWorking attempts to create a run if one is due, and then begins execution on a separate goroutine.
 // r.state must be runnerWorking when this is called.
 func (r *runner) startFromWorking() {
-	if next, ready := r.tt.NextScheduledRun(); ready {
-		// It's possible that two runners may attempt to create the same run for this "next" timestamp,
-		// but the contract of DesiredState requires that only one succeeds.
-		qr, err := r.desiredState.CreateRun(r.ctx, r.task.ID, next)
+	qr, ok := r.createRun()
+	if !ok {
+		// Wasn't ready for a new run, so we're idle again.
+		atomic.StoreUint32(r.state, runnerIdle)
+		return
+	}
+
+	// Create a new child logger for the individual run.
+	// We can't do r.logger = r.logger.With(zap.String("run_id", qr.RunID.String()) because zap doesn't deduplicate fields,
+	// and we'll quickly end up with many run_ids associated with the log.
+	runLogger := r.logger.With(zap.String("run_id", qr.RunID.String()))
+
+	r.tt.StartRun(qr.Now)
+
+	go r.executeAndWait(qr, runLogger)
+
+	r.updateRunState(qr, RunStarted, runLogger)
+}
+
+// createRun creates the next run of the task, if one is due, and reports whether it created a run.
+func (r *runner) createRun() (QueuedRun, bool) {
+	if r.tt.cron == nil {
+		// The runs of a task without a schedule are created for the triggers queued in the desired state.
+		// Only one runner can create a run for each trigger.
+		qr, err := r.desiredState.CreateTriggeredRun(r.ctx, r.task.ID)
+		if err == ErrNoRunTriggered {
+			return qr, false
+		}
 		if err != nil {
-			r.logger.Info("Failed to create run", zap.Error(err))
-			atomic.StoreUint32(r.state, runnerIdle)
-			return
+			r.logger.Info("Failed to create triggered run", zap.Error(err))
+			return qr, false
 		}
+		return qr, true
+	}
 
-		// Create a new child logger for the individual run.
-		// We can't do r.logger = r.logger.With(zap.String("run_id", qr.RunID.String()) because zap doesn't deduplicate fields,
-		// and we'll quickly end up with many run_ids associated with the log.
-		runLogger := r.logger.With(zap.String("run_id", qr.RunID.String()))
-
-		r.tt.StartRun(next)
+	if !r.tt.RecordSkippedRuns(r.recordSkippedRuns) {
+		return QueuedRun{}, false
+	}
 
-		go r.executeAndWait(qr, runLogger)
+	next, ready := r.tt.NextScheduledRun()
+	if !ready {
+		return QueuedRun{}, false
+	}
 
-		r.updateRunState(qr, RunStarted, runLogger)
-		return
+	// It's possible that two runners may attempt to create the same run for this "next" timestamp,
+	// but the contract of DesiredState requires that only one succeeds.
+	qr, err := r.desiredState.CreateRun(r.ctx, r.task.ID, next)
+	if err != nil {
+		r.logger.Info("Failed to create run", zap.Error(err))
+		return qr, false
 	}
+	return qr, true
+}
 
-	// Wasn't ready for a new run, so we're idle again.
-	atomic.StoreUint32(r.state, runnerIdle)
+// recordSkippedRuns records the runs skipped by the catch-up policy as a single canceled run at sk.last,
+// and reports whether it succeeded. If the run was created but could not be finished, sk.runID is set
+// so that a later call only retries finishing it.
+// Finishing that run marks the skipped runs as completed, so they are not skipped again if the task is claimed later.
+func (r *runner) recordSkippedRuns(sk *skippedRuns) bool {
+	if sk.runID == nil {
+		qr, err := r.desiredState.CreateRun(r.ctx, r.task.ID, sk.last)
+		if err != nil {
+			r.logger.Info("Failed to create run for skipped runs", zap.Error(err))
+			return false
+		}
+		sk.runID = qr.RunID
+
+		now := time.Now()
+		if err := r.logWriter.UpdateRunState(r.ctx, r.task, qr.RunID, now, RunCanceled); err != nil {
+			r.logger.Info("Error updating run state", zap.Stringer("state", RunCanceled), zap.Error(err))
+		}
+		msg := fmt.Sprintf("Skipped %d scheduled runs from %s to %s due to catch-up policy %q",
+			sk.count,
+			time.Unix(sk.first, 0).UTC().Format(time.RFC3339),
+			time.Unix(sk.last, 0).UTC().Format(time.RFC3339),
+			sk.policy,
+		)
+		if err := r.logWriter.AddRunLog(r.ctx, r.task, qr.RunID, now, msg); err != nil {
+			r.logger.Info("Error adding run log", zap.Error(err))
+		}
+	}
+	if err := r.desiredState.FinishRun(r.ctx, r.task.ID, sk.runID); err != nil {
+		r.logger.Info("Failed to finish run for skipped runs", zap.Error(err))
+		return false
+	}
+	return true
 }
 
 func (r *runner) executeAndWait(qr QueuedRun, runLogger *zap.Logger) {
-	rp, err := r.executor.Execute(r.ctx, qr)
-	if err != nil {
-		// TODO(mr): retry? and log error.
+	start := time.Now()
+	labels := r.tt.metrics.StartTaskRun(r.task.ID.String(), time.Unix(qr.Now, 0))
+	finishMetrics := func(s RunStatus, queueWait time.Duration) {
+		r.tt.metrics.FinishTaskRun(labels, s, time.Since(start), queueWait)
+	}
+
+	var res RunResult
+	for retries := 0; ; retries++ {
+		rp, err := r.executor.Execute(r.ctx, qr)
+		if err != nil {
+			// TODO(mr): retry? and log error.
+			finishMetrics(RunFail, 0)
+			atomic.StoreUint32(r.state, runnerIdle)
+			r.updateRunState(qr, RunFail, runLogger)
+			r.notifyFinished(qr, RunFail, err)
+			return
+		}
+
+		res, err = r.wait(rp)
+		if err != nil {
+			if err == ErrRunCanceled {
+				finishMetrics(RunCanceled, 0)
+				_ = r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID)
+				r.updateRunState(qr, RunCanceled, runLogger)
+			} else {
+				runLogger.Info("Failed to wait for execution result", zap.Error(err))
+				finishMetrics(RunFail, 0)
+				// TODO(mr): retry?
+				r.updateRunState(qr, RunFail, runLogger)
+				r.notifyFinished(qr, RunFail, err)
+			}
+			atomic.StoreUint32(r.state, runnerIdle)
+			return
+		}
+
+		// A failure that is not retryable, such as a timeout, would most likely happen again.
+		runErr := res.Err()
+		if runErr == nil || !res.IsRetryable() || retries >= r.retry {
+			break
+		}
+		runLogger.Info("Retrying failed run", zap.Int("retry", retries+1), zap.Error(runErr))
+		r.addRunLog(qr, fmt.Sprintf("Retrying after failed attempt %d: %v", retries+1, runErr), runLogger)
+	}
+
+	// Every run that produced a result records its statistics, whether it succeeded or failed.
+	stats := res.Statistics()
+	r.setRunStatistics(qr, stats, runLogger)
+
+	if err := r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID); err != nil {
+		runLogger.Info("Failed to finish run", zap.Error(err))
+		finishMetrics(RunFail, stats.QueueDuration)
+		// TODO(mr): retry?
+		// Need to think about what it means if there was an error finishing a run.
 		atomic.StoreUint32(r.state, runnerIdle)
 		r.updateRunState(qr, RunFail, runLogger)
+		r.notifyFinished(qr, RunFail, err)
 		return
 	}
 
+	if runErr := res.Err(); runErr != nil {
+		runLogger.Info("Run failed", zap.Error(runErr))
+		finishMetrics(RunFail, stats.QueueDuration)
+		r.updateRunState(qr, RunFail, runLogger)
+		r.notifyFinished(qr, RunFail, runErr)
+	} else {
+		finishMetrics(RunSuccess, stats.QueueDuration)
+		r.updateRunState(qr, RunSuccess, runLogger)
+		r.notifyFinished(qr, RunSuccess, nil)
+		r.triggerDependents(qr.TaskID, qr.Now)
+	}
+
+	// Check again if there is a new run available, without returning to idle state.
+	r.startFromWorking()
+}
+
+// wait waits for the result of rp, canceling rp if the runner's context is canceled first.
+func (r *runner) wait(rp RunPromise) (RunResult, error) {
 	ready := make(chan struct{})
+	defer close(ready)
 	go func() {
 		// If the runner's context is canceled, cancel the RunPromise.
 		select {
@@ -514,44 +850,51 @@ func (r *runner) executeAndWait(qr QueuedRun, runLogger *zap.Logger) {
 		}
 	}()
 
-	// TODO(mr): handle res.IsRetryable().
-	_, err = rp.Wait()
-	close(ready)
+	return rp.Wait()
+}
+
+func (r *runner) addRunLog(qr QueuedRun, log string, runLogger *zap.Logger) {
+	// Same short time limit as in updateRunState.
+	ctx, cancel := context.WithTimeout(r.ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := r.logWriter.AddRunLog(ctx, r.task, qr.RunID, time.Now(), log); err != nil {
+		runLogger.Info("Error adding run log", zap.Error(err))
+	}
+}
+
+// notifyFinished records the outcome of a run, and reports it to the notifier if there is one.
+func (r *runner) notifyFinished(qr QueuedRun, s RunStatus, runErr error) {
+	// Same short time limit as in updateRunState.
+	ctx, cancel := context.WithTimeout(r.ctx, 10*time.Millisecond)
+	err := r.desiredState.RecordRunResult(ctx, qr.TaskID, s, time.Now())
+	cancel()
 	if err != nil {
-		if err == ErrRunCanceled {
-			_ = r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID)
-			r.updateRunState(qr, RunCanceled, runLogger)
-		} else {
-			runLogger.Info("Failed to wait for execution result", zap.Error(err))
-			// TODO(mr): retry?
-			r.updateRunState(qr, RunFail, runLogger)
-		}
-		atomic.StoreUint32(r.state, runnerIdle)
-		return
+		r.logger.Info("Error recording run result", zap.String("run_id", qr.RunID.String()), zap.Error(err))
 	}
 
-	if err := r.desiredState.FinishRun(r.ctx, qr.TaskID, qr.RunID); err != nil {
-		runLogger.Info("Failed to finish run", zap.Error(err))
-		// TODO(mr): retry?
-		// Need to think about what it means if there was an error finishing a run.
-		atomic.StoreUint32(r.state, runnerIdle)
-		r.updateRunState(qr, RunFail, runLogger)
+	if r.notifier == nil {
 		return
 	}
-	r.updateRunState(qr, RunSuccess, runLogger)
+	r.notifier.RunFinished(r.task, r.notify, qr, s, runErr)
+}
 
-	// Check again if there is a new run available, without returning to idle state.
-	r.startFromWorking()
+func (r *runner) setRunStatistics(qr QueuedRun, stats platform.RunStatistics, runLogger *zap.Logger) {
+	// Same short time limit as in updateRunState.
+	ctx, cancel := context.WithTimeout(r.ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := r.logWriter.SetRunStatistics(ctx, r.task, qr.RunID, stats); err != nil {
+		runLogger.Info("Error setting run statistics", zap.Error(err))
+	}
 }
 
 func (r *runner) updateRunState(qr QueuedRun, s RunStatus, runLogger *zap.Logger) {
 	switch s {
 	case RunStarted:
-		r.tt.metrics.StartRun(r.task.ID.String())
+		r.tt.metrics.StartRun()
 	case RunSuccess:
-		r.tt.metrics.FinishRun(r.task.ID.String(), true)
+		r.tt.metrics.FinishRun(true)
 	case RunFail, RunCanceled:
-		r.tt.metrics.FinishRun(r.task.ID.String(), false)
+		r.tt.metrics.FinishRun(false)
 	default:
 		// We are deliberately not handling RunQueued yet.
 		// There is not really a notion of being queued in this runner architecture.
@@ -562,7 +905,15 @@ func (r *runner) updateRunState(qr QueuedRun, s RunStatus, runLogger *zap.Logger
 	// If we start seeing errors from this, we know the time limit is too short or the system is overloaded.
 	ctx, cancel := context.WithTimeout(r.ctx, 10*time.Millisecond)
 	defer cancel()
-	if err := r.logWriter.UpdateRunState(ctx, r.task, qr.RunID, time.Now(), s); err != nil {
+	// Record the run against the script revision it executes,
+	// which may differ from r.task if the task was modified after the run was created.
+	task := r.task
+	if qr.Revision != task.Revision {
+		t := *task
+		t.Revision = qr.Revision
+		task = &t
+	}
+	if err := r.logWriter.UpdateRunState(ctx, task, qr.RunID, time.Now(), s); err != nil {
 		runLogger.Info("Error updating run state", zap.Stringer("state", s), zap.Error(err))
 	}
 }
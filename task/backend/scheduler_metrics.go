@@ -1,6 +1,17 @@
 package backend
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultTaskMetricsLimit is the default number of tasks that are labelled individually in the per-task metrics.
+const defaultTaskMetricsLimit = 100
+
+// otherTasksLabel is the task_id and org label value shared by tasks beyond the per-task metrics limit.
+const otherTasksLabel = "other"
 
 // schedulerMetrics is a collection of metrics relating to task scheduling.
 // All of its methods which accept task IDs, take them as strings,
@@ -9,11 +20,24 @@ type schedulerMetrics struct {
 	totalRunsComplete *prometheus.CounterVec
 	totalRunsActive   prometheus.Gauge
 
-	runsComplete *prometheus.CounterVec
-	runsActive   *prometheus.GaugeVec
-
 	claimsComplete *prometheus.CounterVec
 	claimsActive   prometheus.Gauge
+
+	// Per-task metrics, labelled by task ID, and by organization except for runsComplete and runsActive.
+	// To bound their cardinality, only the first taskLimit claimed tasks are labelled individually,
+	// and the remaining tasks share the otherTasksLabel label values.
+	runsComplete        *prometheus.CounterVec
+	runsActive          *prometheus.GaugeVec
+	scheduleLag         *prometheus.HistogramVec
+	runDuration         *prometheus.HistogramVec
+	queueWait           *prometheus.HistogramVec
+	taskRunsRunning     *prometheus.GaugeVec
+	consecutiveFailures *prometheus.GaugeVec
+
+	mu        sync.Mutex
+	taskLimit int
+	labelled  map[string]string // Task ID to organization ID, for the tasks with their own labels.
+	failures  map[string]int    // Task ID to consecutive failed runs, for the tasks with their own labels.
 }
 
 func newSchedulerMetrics() *schedulerMetrics {
@@ -38,13 +62,13 @@ func newSchedulerMetrics() *schedulerMetrics {
 			Namespace: namespace,
 			Subsystem: subsystem,
 			Name:      "runs_complete",
-			Help:      "Number of runs completed, split out by task ID and success or failure.",
+			Help:      "Number of runs completed, split out by task ID and success or failure. Tasks beyond the per-task limit share the task ID \"other\".",
 		}, []string{"task_id", "status"}),
 		runsActive: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: namespace,
 			Subsystem: subsystem,
 			Name:      "runs_active",
-			Help:      "Total number of runs that have started but not yet completed, split out by task ID.",
+			Help:      "Total number of runs that have started but not yet completed, split out by task ID. Tasks beyond the per-task limit share the task ID \"other\".",
 		}, []string{"task_id"}),
 
 		claimsComplete: prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -59,6 +83,44 @@ func newSchedulerMetrics() *schedulerMetrics {
 			Name:      "claims_active",
 			Help:      "Total number of claims currently held.",
 		}),
+
+		scheduleLag: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "schedule_lag_seconds",
+			Help:      "Seconds between the scheduled time of a run and when it started, split out by task ID and organization.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+		}, []string{"task_id", "org"}),
+		runDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "run_duration_seconds",
+			Help:      "Seconds from the start of a run until it finished, split out by task ID and organization.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		}, []string{"task_id", "org"}),
+		queueWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "run_queue_wait_seconds",
+			Help:      "Seconds that the query of a run waited for resources, split out by task ID and organization.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, []string{"task_id", "org"}),
+		taskRunsRunning: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "task_runs_running",
+			Help:      "Number of runs that have started but not yet completed, split out by task ID and organization.",
+		}, []string{"task_id", "org"}),
+		consecutiveFailures: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "task_consecutive_failures",
+			Help:      "Number of runs that failed since the last successful run, split out by task ID and organization. Not reported for tasks beyond the per-task limit.",
+		}, []string{"task_id", "org"}),
+
+		taskLimit: defaultTaskMetricsLimit,
+		labelled:  make(map[string]string),
+		failures:  make(map[string]int),
 	}
 }
 
@@ -71,24 +133,91 @@ func (sm *schedulerMetrics) PrometheusCollectors() []prometheus.Collector {
 		sm.runsActive,
 		sm.claimsComplete,
 		sm.claimsActive,
+		sm.scheduleLag,
+		sm.runDuration,
+		sm.queueWait,
+		sm.taskRunsRunning,
+		sm.consecutiveFailures,
 	}
 }
 
-// StartRun adjusts the metrics to indicate a run is in progress for the given task ID.
-func (sm *schedulerMetrics) StartRun(tid string) {
-	sm.totalRunsActive.Inc()
-	sm.runsActive.WithLabelValues(tid).Inc()
+// TrackTask assigns the per-task metric labels of a newly claimed task.
+// The task is labelled by its own ID and organization, unless the limit of individually labelled tasks has been reached.
+func (sm *schedulerMetrics) TrackTask(tid, org string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if len(sm.labelled) < sm.taskLimit {
+		sm.labelled[tid] = org
+	}
 }
 
-// FinishRun adjusts the metrics to indicate a run is no longer in progress for the given task ID.
-func (sm *schedulerMetrics) FinishRun(tid string, succeeded bool) {
-	status := statusString(succeeded)
+// StartRun adjusts the metrics to indicate a run is in progress.
+// The per-task run counts are adjusted by StartTaskRun.
+func (sm *schedulerMetrics) StartRun() {
+	sm.totalRunsActive.Inc()
+}
 
+// FinishRun adjusts the metrics to indicate a run is no longer in progress.
+// The per-task run counts are adjusted by FinishTaskRun.
+func (sm *schedulerMetrics) FinishRun(succeeded bool) {
 	sm.totalRunsActive.Dec()
-	sm.totalRunsComplete.WithLabelValues(status).Inc()
+	sm.totalRunsComplete.WithLabelValues(statusString(succeeded)).Inc()
+}
+
+// taskRunLabels are the per-task metric labels of a run, assigned when the run starts.
+type taskRunLabels struct {
+	tid, id, org string
+	labelled     bool // Whether id and org are the task's own labels.
+}
 
-	sm.runsActive.WithLabelValues(tid).Dec()
-	sm.runsComplete.WithLabelValues(tid, status).Inc()
+// StartTaskRun records that a run scheduled at the given time has started for the given task ID.
+// The returned labels must be passed to FinishTaskRun when the run finishes.
+func (sm *schedulerMetrics) StartTaskRun(tid string, scheduled time.Time) taskRunLabels {
+	l := taskRunLabels{tid: tid, id: otherTasksLabel, org: otherTasksLabel}
+	sm.mu.Lock()
+	if org, ok := sm.labelled[tid]; ok {
+		l.id, l.org, l.labelled = tid, org, true
+	}
+	sm.mu.Unlock()
+
+	sm.runsActive.WithLabelValues(l.id).Inc()
+	sm.scheduleLag.WithLabelValues(l.id, l.org).Observe(time.Since(scheduled).Seconds())
+	sm.taskRunsRunning.WithLabelValues(l.id, l.org).Inc()
+	return l
+}
+
+// FinishTaskRun records that a run has finished after running for duration,
+// having waited queueWait for query resources.
+// A failed run increments the task's consecutive failures, a successful run resets them, and a canceled run leaves them unchanged.
+func (sm *schedulerMetrics) FinishTaskRun(l taskRunLabels, status RunStatus, duration, queueWait time.Duration) {
+	failures := 0
+	if l.labelled {
+		sm.mu.Lock()
+		if _, ok := sm.labelled[l.tid]; !ok {
+			// The task was released while the run was in progress, and its metrics were deleted.
+			sm.mu.Unlock()
+			return
+		}
+		switch status {
+		case RunSuccess:
+			sm.failures[l.tid] = 0
+		case RunFail:
+			sm.failures[l.tid]++
+		}
+		failures = sm.failures[l.tid]
+		sm.mu.Unlock()
+	}
+
+	sm.runsActive.WithLabelValues(l.id).Dec()
+	sm.runsComplete.WithLabelValues(l.id, statusString(status == RunSuccess)).Inc()
+	sm.taskRunsRunning.WithLabelValues(l.id, l.org).Dec()
+	sm.runDuration.WithLabelValues(l.id, l.org).Observe(duration.Seconds())
+	sm.queueWait.WithLabelValues(l.id, l.org).Observe(queueWait.Seconds())
+	// A count of consecutive failures is meaningless across many tasks, so it is only reported for labelled tasks.
+	if l.labelled {
+		sm.consecutiveFailures.WithLabelValues(l.id, l.org).Set(float64(failures))
+	}
 }
 
 // ClaimTask adjusts the metrics to indicate the result of an attempted claim.
@@ -105,9 +234,22 @@ func (sm *schedulerMetrics) ClaimTask(succeeded bool) {
 // We are not (currently) tracking failed releases, so only call this on a successful release.
 func (sm *schedulerMetrics) ReleaseTask(tid string) {
 	sm.claimsActive.Dec()
-	sm.runsActive.DeleteLabelValues(tid)
-	sm.runsComplete.DeleteLabelValues(tid, statusString(true))
-	sm.runsComplete.DeleteLabelValues(tid, statusString(false))
+
+	sm.mu.Lock()
+	org, ok := sm.labelled[tid]
+	delete(sm.labelled, tid)
+	delete(sm.failures, tid)
+	sm.mu.Unlock()
+	if ok {
+		sm.runsActive.DeleteLabelValues(tid)
+		sm.runsComplete.DeleteLabelValues(tid, statusString(true))
+		sm.runsComplete.DeleteLabelValues(tid, statusString(false))
+		sm.scheduleLag.DeleteLabelValues(tid, org)
+		sm.runDuration.DeleteLabelValues(tid, org)
+		sm.queueWait.DeleteLabelValues(tid, org)
+		sm.taskRunsRunning.DeleteLabelValues(tid, org)
+		sm.consecutiveFailures.DeleteLabelValues(tid, org)
+	}
 }
 
 func statusString(succeeded bool) string {
@@ -1,8 +1,11 @@
 package backend_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -188,7 +191,11 @@ func TestScheduler_RunLog(t *testing.T) {
 	}
 
 	// Finish with success.
-	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	stats := platform.RunStatistics{
+		TotalDuration: time.Second,
+		Yields:        []platform.YieldStatistics{{Name: "_result", Tables: 2, Rows: 10}},
+	}
+	promises[0].Finish(mock.NewRunResult(nil, false).WithStatistics(stats), nil)
 	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
 		t.Fatal(err)
 	}
@@ -205,6 +212,10 @@ func TestScheduler_RunLog(t *testing.T) {
 		t.Fatalf("expected run to be success, got %s", got)
 	}
 
+	if got := runs[0].Statistics; got == nil || !reflect.DeepEqual(*got, stats) {
+		t.Fatalf("expected run statistics %#v, got %#v", stats, got)
+	}
+
 	// Create a new run, but fail this time.
 	s.Tick(7)
 	promises, err = e.PollForNumberRunning(task.ID, 1)
@@ -280,6 +291,50 @@ func TestScheduler_RunLog(t *testing.T) {
 	}
 }
 
+func TestScheduler_FailedRunStatistics(t *testing.T) {
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	rl := backend.NewInMemRunReaderWriter()
+	s := backend.NewScheduler(d, e, rl, 5)
+
+	task := &backend.StoreTask{
+		ID: platform.ID{1},
+	}
+	if err := s.ClaimTask(task, 5, &options.Options{Every: time.Second, Concurrency: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Tick(6)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The statistics of a failed run are what help most to find out why it failed.
+	stats := platform.RunStatistics{
+		TotalDuration: time.Second,
+		Yields:        []platform.YieldStatistics{{Name: "_result", Tables: 1, Rows: 3}},
+	}
+	promises[0].Finish(mock.NewRunResult(errors.New("forced failure"), false).WithStatistics(stats), nil)
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := rl.ListRuns(context.Background(), platform.RunFilter{Task: &task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(runs); got != 1 {
+		t.Fatalf("expected 1 run, got %d", got)
+	}
+	if got := runs[0].Status; got != backend.RunFail.String() {
+		t.Fatalf("expected run to be failure, got %s", got)
+	}
+	if got := runs[0].Statistics; got == nil || !reflect.DeepEqual(*got, stats) {
+		t.Fatalf("expected run statistics %#v, got %#v", stats, got)
+	}
+}
+
 func TestScheduler_Metrics(t *testing.T) {
 	d := mock.NewDesiredState()
 	e := mock.NewExecutor()
@@ -399,3 +454,507 @@ func TestScheduler_Metrics(t *testing.T) {
 		t.Fatalf("expected 0 claims active, got %v", got)
 	}
 }
+
+// notification is a single call to chanNotifier.RunFinished.
+type notification struct {
+	endpoints []options.Notification
+	status    backend.RunStatus
+	err       error
+}
+
+// chanNotifier is a backend.RunNotifier that sends every finished run on a channel.
+type chanNotifier chan notification
+
+func (n chanNotifier) RunFinished(_ *backend.StoreTask, endpoints []options.Notification, _ backend.QueuedRun, status backend.RunStatus, runErr error) {
+	n <- notification{endpoints: endpoints, status: status, err: runErr}
+}
+
+func TestScheduler_RunNotifier(t *testing.T) {
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	n := make(chanNotifier, 1)
+	s := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5, backend.WithRunNotifier(n))
+
+	task := &backend.StoreTask{
+		ID: platform.ID{1},
+	}
+	endpoints := []options.Notification{{URL: "http://example.com", On: options.NotifyOnFailure}}
+	opts := &options.Options{Every: time.Second, Concurrency: 1, Notify: endpoints}
+	if err := s.ClaimTask(task, 5, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	receive := func() notification {
+		t.Helper()
+		select {
+		case got := <-n:
+			return got
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for notification")
+		}
+		return notification{}
+	}
+
+	// A query error in the run result fails the run.
+	s.Tick(6)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forced := errors.New("query failed")
+	promises[0].Finish(mock.NewRunResult(forced, false), nil)
+	got := receive()
+	if got.status != backend.RunFail || got.err != forced {
+		t.Fatalf("expected failure with %v, got %v with %v", forced, got.status, got.err)
+	}
+	if !reflect.DeepEqual(got.endpoints, endpoints) {
+		t.Fatalf("expected endpoints %v, got %v", endpoints, got.endpoints)
+	}
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Tick(7)
+	promises, err = e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	if got := receive(); got.status != backend.RunSuccess || got.err != nil {
+		t.Fatalf("expected success, got %v with %v", got.status, got.err)
+	}
+}
+
+func TestScheduler_RecordRunResult(t *testing.T) {
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	s := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5)
+
+	task := &backend.StoreTask{
+		ID: platform.ID{1},
+	}
+	opts := &options.Options{Every: time.Second, Concurrency: 1}
+	if err := s.ClaimTask(task, 5, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	pollForResult := func(exp backend.RunStatus) {
+		t.Helper()
+		for i := 0; i < 50; i++ {
+			if got, ok := d.LastResult(task.ID); ok && got == exp {
+				return
+			}
+			time.Sleep(2 * time.Millisecond)
+		}
+		got, _ := d.LastResult(task.ID)
+		t.Fatalf("expected last result %v, got %v", exp, got)
+	}
+
+	s.Tick(6)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(mock.NewRunResult(errors.New("query failed"), false), nil)
+	pollForResult(backend.RunFail)
+	if _, err := e.PollForNumberRunning(task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Tick(7)
+	promises, err = e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	pollForResult(backend.RunSuccess)
+}
+
+func TestScheduler_CatchUp(t *testing.T) {
+	for _, c := range []struct {
+		policy  string
+		created int    // Number of runs created, not including the run recording the skipped runs.
+		log     string // Expected log of the run recording the skipped runs, if any.
+	}{
+		{policy: options.CatchUpAll, created: 7},
+		{policy: options.CatchUpLatest, created: 1, log: `Skipped 6 scheduled runs from 1970-01-01T00:00:04Z to 1970-01-01T00:00:09Z due to catch-up policy "latest"`},
+		{policy: options.CatchUpNone, created: 0, log: `Skipped 7 scheduled runs from 1970-01-01T00:00:04Z to 1970-01-01T00:00:10Z due to catch-up policy "none"`},
+	} {
+		t.Run(c.policy, func(t *testing.T) {
+			d := mock.NewDesiredState()
+			e := mock.NewExecutor()
+			rl := backend.NewInMemRunReaderWriter()
+			s := backend.NewScheduler(d, e, rl, 10)
+
+			// The runs for 4 through 10 were missed.
+			task := &backend.StoreTask{ID: platform.ID{1}}
+			opts := &options.Options{Every: time.Second, Concurrency: 99, CatchUp: c.policy}
+			if err := s.ClaimTask(task, 3, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := e.PollForNumberRunning(task.ID, c.created); err != nil {
+				t.Fatal(err)
+			}
+
+			runs, err := rl.ListRuns(context.Background(), platform.RunFilter{Task: &task.ID})
+			if err != nil && err != backend.ErrRunNotFound {
+				t.Fatal(err)
+			}
+
+			var skipped []*platform.Run
+			for _, r := range runs {
+				if r.Status == backend.RunCanceled.String() {
+					skipped = append(skipped, r)
+				}
+			}
+			if c.log == "" {
+				if len(skipped) != 0 {
+					t.Fatalf("expected no runs to be skipped, got %d canceled runs", len(skipped))
+				}
+				return
+			}
+			if len(skipped) != 1 {
+				t.Fatalf("expected 1 canceled run recording the skipped runs, got %d", len(skipped))
+			}
+			if got := string(skipped[0].Log); !strings.HasSuffix(got, c.log) {
+				t.Fatalf("unexpected log: got %q, want suffix %q", got, c.log)
+			}
+		})
+	}
+}
+
+func TestScheduler_CatchUpCreateRunFails(t *testing.T) {
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	rl := backend.NewInMemRunReaderWriter()
+	s := backend.NewScheduler(d, e, rl, 10)
+
+	// The runs for 4 through 10 were missed, but the skipped runs can't be recorded yet,
+	// such as when the task is at its concurrency limit.
+	d.CreateRunError(errors.New("forced failure"))
+	task := &backend.StoreTask{ID: platform.ID{1}}
+	opts := &options.Options{Every: time.Second, Concurrency: 99, CatchUp: options.CatchUpNone}
+	if err := s.ClaimTask(task, 3, opts); err != nil {
+		t.Fatal(err)
+	}
+	s.Tick(11)
+	if _, err := rl.ListRuns(context.Background(), platform.RunFilter{Task: &task.ID}); err != backend.ErrRunNotFound {
+		t.Fatalf("expected no runs while the skipped runs can't be recorded, got error %v", err)
+	}
+	if n := len(d.CreatedFor(task.ID)); n != 0 {
+		t.Fatalf("expected no runs to be created, got %d", n)
+	}
+
+	// Once the skipped runs are recorded, scheduling resumes after them.
+	d.CreateRunError(nil)
+	s.Tick(11)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if now := promises[0].Run().Now; now != 11 {
+		t.Fatalf("expected run for 11, got %d", now)
+	}
+
+	runs, err := rl.ListRuns(context.Background(), platform.RunFilter{Task: &task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var skipped int
+	for _, r := range runs {
+		if r.Status == backend.RunCanceled.String() {
+			skipped++
+		}
+	}
+	if skipped != 1 {
+		t.Fatalf("expected 1 canceled run recording the skipped runs, got %d", skipped)
+	}
+}
+
+func TestScheduler_DependsOn(t *testing.T) {
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	s := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5)
+
+	upstream := &backend.StoreTask{ID: platform.ID{1}}
+	if err := s.ClaimTask(upstream, 5, &options.Options{Every: time.Second, Concurrency: 1}); err != nil {
+		t.Fatal(err)
+	}
+	downstream := &backend.StoreTask{ID: platform.ID{2}}
+	downstreamOpts := &options.Options{DependsOn: upstream.ID.String(), Concurrency: 1}
+	d.SetDependsOn(downstream.ID, upstream.ID)
+	if err := s.ClaimTask(downstream, 5, downstreamOpts); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed upstream run does not trigger the downstream task.
+	s.Tick(6)
+	promises, err := e.PollForNumberRunning(upstream.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(mock.NewRunResult(errors.New("query failed"), false), nil)
+	if _, err := e.PollForNumberRunning(upstream.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.PollForNumberRunning(downstream.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// A successful upstream run triggers the downstream task with the same now.
+	s.Tick(7)
+	promises, err = e.PollForNumberRunning(upstream.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	promises, err = e.PollForNumberRunning(downstream.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if now := promises[0].Run().Now; now != 7 {
+		t.Fatalf("expected downstream run for now 7, got %d", now)
+	}
+
+	// Once released, the downstream task is no longer run by this scheduler.
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	if _, err := e.PollForNumberRunning(downstream.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReleaseTask(downstream.ID); err != nil {
+		t.Fatal(err)
+	}
+	s.Tick(8)
+	promises, err = e.PollForNumberRunning(upstream.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises[0].Finish(mock.NewRunResult(nil, false), nil)
+	if _, err := e.PollForNumberRunning(upstream.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.PollForNumberRunning(downstream.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The trigger is kept in the desired state, so another scheduler that claims the downstream task runs it.
+	s2 := backend.NewScheduler(d, e, backend.NopLogWriter{}, 8)
+	if err := s2.ClaimTask(downstream, 8, downstreamOpts); err != nil {
+		t.Fatal(err)
+	}
+	s2.Tick(9)
+	promises, err = e.PollForNumberRunning(downstream.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if now := promises[0].Run().Now; now != 8 {
+		t.Fatalf("expected downstream run for now 8, got %d", now)
+	}
+}
+
+func TestScheduler_TaskMetrics(t *testing.T) {
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	s := backend.NewScheduler(d, e, backend.NopLogWriter{}, 5, backend.WithTaskMetricsLimit(1))
+
+	reg := prom.NewRegistry()
+	reg.MustRegister(s.(prom.PrometheusCollector).PrometheusCollectors()...)
+
+	// Only the first task is labelled individually.
+	task1 := &backend.StoreTask{ID: platform.ID{1}, Org: platform.ID{9}}
+	task2 := &backend.StoreTask{ID: platform.ID{2}, Org: platform.ID{9}}
+	opts := &options.Options{Every: time.Second, Concurrency: 1}
+	for _, task := range []*backend.StoreTask{task1, task2} {
+		if err := s.ClaimTask(task, 5, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	labels1 := map[string]string{"task_id": task1.ID.String(), "org": task1.Org.String()}
+	otherLabels := map[string]string{"task_id": "other", "org": "other"}
+
+	s.Tick(6)
+	promises1, err := e.PollForNumberRunning(task1.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	promises2, err := e.PollForNumberRunning(task2.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mfs := promtest.MustGather(t, reg)
+	for _, l := range []map[string]string{labels1, otherLabels} {
+		m := promtest.MustFindMetric(t, mfs, "task_scheduler_task_runs_running", l)
+		if got := *m.Gauge.Value; got != 1 {
+			t.Fatalf("expected 1 run running for %v, got %v", l, got)
+		}
+		m = promtest.MustFindMetric(t, mfs, "task_scheduler_schedule_lag_seconds", l)
+		if got := *m.Histogram.SampleCount; got != 1 {
+			t.Fatalf("expected 1 schedule lag sample for %v, got %v", l, got)
+		}
+	}
+	if m := promtest.FindMetric(mfs, "task_scheduler_schedule_lag_seconds", map[string]string{"task_id": task2.ID.String(), "org": task2.Org.String()}); m != nil {
+		t.Fatalf("expected task beyond the limit not to be labelled individually, got %v", m)
+	}
+
+	promises1[0].Finish(mock.NewRunResult(errors.New("query failed"), false), nil)
+	promises2[0].Finish(mock.NewRunResult(nil, false).WithStatistics(platform.RunStatistics{QueueDuration: time.Second}), nil)
+	if _, err := e.PollForNumberRunning(task1.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.PollForNumberRunning(task2.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	mfs = promtest.MustGather(t, reg)
+	m := promtest.MustFindMetric(t, mfs, "task_scheduler_task_consecutive_failures", labels1)
+	if got := *m.Gauge.Value; got != 1 {
+		t.Fatalf("expected 1 consecutive failure, got %v", got)
+	}
+	if m := promtest.FindMetric(mfs, "task_scheduler_task_consecutive_failures", otherLabels); m != nil {
+		t.Fatalf("expected no consecutive failures for tasks beyond the limit, got %v", m)
+	}
+	for _, l := range []map[string]string{labels1, otherLabels} {
+		m := promtest.MustFindMetric(t, mfs, "task_scheduler_task_runs_running", l)
+		if got := *m.Gauge.Value; got != 0 {
+			t.Fatalf("expected 0 runs running for %v, got %v", l, got)
+		}
+		m = promtest.MustFindMetric(t, mfs, "task_scheduler_run_duration_seconds", l)
+		if got := *m.Histogram.SampleCount; got != 1 {
+			t.Fatalf("expected 1 run duration sample for %v, got %v", l, got)
+		}
+	}
+	m = promtest.MustFindMetric(t, mfs, "task_scheduler_run_queue_wait_seconds", otherLabels)
+	if got := *m.Histogram.SampleSum; got != 1 {
+		t.Fatalf("expected 1 second of queue wait, got %v", got)
+	}
+	m = promtest.MustFindMetric(t, mfs, "task_scheduler_runs_complete", map[string]string{"task_id": task1.ID.String(), "status": "failure"})
+	if got := *m.Counter.Value; got != 1 {
+		t.Fatalf("expected 1 failed run, got %v", got)
+	}
+	m = promtest.MustFindMetric(t, mfs, "task_scheduler_runs_complete", map[string]string{"task_id": "other", "status": "success"})
+	if got := *m.Counter.Value; got != 1 {
+		t.Fatalf("expected 1 successful run for tasks beyond the limit, got %v", got)
+	}
+	if m := promtest.FindMetric(mfs, "task_scheduler_runs_complete", map[string]string{"task_id": task2.ID.String(), "status": "success"}); m != nil {
+		t.Fatalf("expected completed runs of a task beyond the limit not to be labelled individually, got %v", m)
+	}
+	if m := promtest.FindMetric(mfs, "task_scheduler_runs_active", map[string]string{"task_id": task2.ID.String()}); m != nil {
+		t.Fatalf("expected active runs of a task beyond the limit not to be labelled individually, got %v", m)
+	}
+
+	// Per-task labels are removed after the task is released.
+	if err := s.ReleaseTask(task1.ID); err != nil {
+		t.Fatal(err)
+	}
+	mfs = promtest.MustGather(t, reg)
+	for _, name := range []string{
+		"task_scheduler_schedule_lag_seconds",
+		"task_scheduler_run_duration_seconds",
+		"task_scheduler_task_runs_running",
+		"task_scheduler_task_consecutive_failures",
+	} {
+		if m := promtest.FindMetric(mfs, name, labels1); m != nil {
+			t.Fatalf("expected %s to be removed after releasing a task, got %v", name, m)
+		}
+	}
+}
+
+func TestScheduler_RetryableRun(t *testing.T) {
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	rl := backend.NewInMemRunReaderWriter()
+	s := backend.NewScheduler(d, e, rl, 5)
+
+	task := &backend.StoreTask{
+		ID: platform.ID{1},
+	}
+	if err := s.ClaimTask(task, 5, &options.Options{Every: time.Second, Concurrency: 1, Retry: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Tick(6)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runID := promises[0].Run().RunID
+
+	// A retryable failure executes the same run again.
+	promises[0].Finish(mock.NewRunResult(errors.New("transient failure"), true), nil)
+	var retried *mock.RunPromise
+	for i := 0; i < 20 && retried == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+		if running := e.RunningFor(task.ID); len(running) == 1 && running[0] != promises[0] {
+			retried = running[0]
+		}
+	}
+	if retried == nil {
+		t.Fatal("expected the run to be retried")
+	}
+	if got := retried.Run().RunID; !bytes.Equal(got, runID) {
+		t.Fatalf("expected run %s to be retried, got run %s", runID.String(), got.String())
+	}
+
+	// The run has used up its one retry.
+	retried.Finish(mock.NewRunResult(errors.New("transient failure"), true), nil)
+	run := pollForRunStatus(t, rl, task.ID, runID, backend.RunFail)
+	if !strings.Contains(string(run.Log), "Retrying after failed attempt 1") {
+		t.Fatalf("expected the retry to be logged, got log %q", run.Log)
+	}
+	if got := len(e.RunningFor(task.ID)); got != 0 {
+		t.Fatalf("expected no more attempts, got %d running", got)
+	}
+}
+
+func TestScheduler_TimedOutRunNotRetried(t *testing.T) {
+	d := mock.NewDesiredState()
+	e := mock.NewExecutor()
+	rl := backend.NewInMemRunReaderWriter()
+	s := backend.NewScheduler(d, e, rl, 5)
+
+	task := &backend.StoreTask{
+		ID: platform.ID{1},
+	}
+	if err := s.ClaimTask(task, 5, &options.Options{Every: time.Second, Concurrency: 1, Retry: 3, Timeout: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Tick(6)
+	promises, err := e.PollForNumberRunning(task.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Executors report a timed out run as not retryable.
+	promises[0].Finish(mock.NewRunResult(backend.ErrRunTimedOut, false), nil)
+	run := pollForRunStatus(t, rl, task.ID, promises[0].Run().RunID, backend.RunFail)
+	if strings.Contains(string(run.Log), "Retrying") {
+		t.Fatalf("expected the timed out run not to be retried, got log %q", run.Log)
+	}
+	if got := len(e.RunningFor(task.ID)); got != 0 {
+		t.Fatalf("expected no second attempt, got %d running", got)
+	}
+}
+
+// pollForRunStatus waits for the run to have the given status, and returns it.
+func pollForRunStatus(t *testing.T, rl backend.LogReader, taskID, runID platform.ID, status backend.RunStatus) *platform.Run {
+	t.Helper()
+	var run *platform.Run
+	for i := 0; i < 20; i++ {
+		if i > 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		var err error
+		run, err = rl.FindRunByID(context.Background(), taskID, runID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run.Status == status.String() {
+			return run
+		}
+	}
+	t.Fatalf("expected run to have status %s, got %s", status.String(), run.Status)
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -57,11 +59,19 @@ func (r RunStatus) String() string {
 // Store is the interface around persisted tasks.
 type Store interface {
 	// CreateTask saves the given task.
-	CreateTask(ctx context.Context, org, user platform.ID, script string) (platform.ID, error)
+	// The script is stored as the task's first revision, authored by req.User.
+	CreateTask(ctx context.Context, req CreateTaskRequest) (platform.ID, error)
 
-	// ModifyTask updates the script of an existing task.
+	// ModifyTask updates the script of an existing task, storing it as a new revision by author.
 	// It returns an error if there was no task matching the given ID.
-	ModifyTask(ctx context.Context, id platform.ID, newScript string) error
+	ModifyTask(ctx context.Context, id, author platform.ID, newScript string) error
+
+	// ListScriptRevisions returns every revision of the task's script, oldest first.
+	ListScriptRevisions(ctx context.Context, id platform.ID) ([]ScriptRevision, error)
+
+	// FindScriptRevision returns the given revision of the task's script.
+	// If the task or revision does not exist, the returned revision is nil.
+	FindScriptRevision(ctx context.Context, id platform.ID, revision int64) (*ScriptRevision, error)
 
 	// ListTasks lists the tasks in the store that match the search params.
 	ListTasks(ctx context.Context, params TaskSearchParams) ([]StoreTask, error)
@@ -86,11 +96,23 @@ type Store interface {
 	DeleteTask(ctx context.Context, id platform.ID) (deleted bool, err error)
 
 	// CreateRun adds `now` to the task's metaData if we have not exceeded 'max_concurrency'.
+	// The returned run is tied to the task's current script revision.
 	CreateRun(ctx context.Context, taskID platform.ID, now int64) (QueuedRun, error)
 
+	// TriggerDependents queues a run with the given now timestamp for every task whose dependsOn option is taskID.
+	TriggerDependents(ctx context.Context, taskID platform.ID, now int64) error
+
+	// CreateTriggeredRun creates a run for the task's oldest queued trigger, and removes that trigger.
+	// It returns ErrNoRunTriggered if the task has no queued triggers.
+	CreateTriggeredRun(ctx context.Context, taskID platform.ID) (QueuedRun, error)
+
 	// FinishRun removes runID from the list of running tasks and if its `now` is later then last completed update it.
 	FinishRun(ctx context.Context, taskID, runID platform.ID) error
 
+	// RecordRunResult records the status of the task's most recently finished run.
+	// Only RunSuccess and RunFail are valid statuses.
+	RecordRunResult(ctx context.Context, taskID platform.ID, status RunStatus, finished time.Time) error
+
 	// DeleteOrg deletes the org.
 	DeleteOrg(ctx context.Context, orgID platform.ID) error
 
@@ -104,10 +126,14 @@ type Store interface {
 // LogWriter writes task logs and task state changes to a store.
 type LogWriter interface {
 	// UpdateRunState sets the run state and the respective time.
+	// When a run is first recorded, it is associated with task.Revision.
 	UpdateRunState(ctx context.Context, task *StoreTask, runID platform.ID, when time.Time, state RunStatus) error
 
 	// AddRunLog adds a log line to the run.
 	AddRunLog(ctx context.Context, task *StoreTask, runID platform.ID, when time.Time, log string) error
+
+	// SetRunStatistics records the statistics collected while executing the run.
+	SetRunStatistics(ctx context.Context, task *StoreTask, runID platform.ID, stats platform.RunStatistics) error
 }
 
 // NopLogWriter is a LogWriter that doesn't do anything when its methods are called.
@@ -122,6 +148,10 @@ func (NopLogWriter) AddRunLog(context.Context, *StoreTask, platform.ID, time.Tim
 	return nil
 }
 
+func (NopLogWriter) SetRunStatistics(context.Context, *StoreTask, platform.ID, platform.RunStatistics) error {
+	return nil
+}
+
 // LogReader reads log information and log data from a store.
 type LogReader interface {
 	// ListRuns returns a list of runs belonging to a task.
@@ -161,12 +191,46 @@ type TaskSearchParams struct {
 	// Return tasks starting after this ID.
 	After platform.ID
 
+	// Return tasks whose name starts with this prefix. May be empty.
+	NamePrefix string
+
+	// Return tasks whose name matches this expression. May be nil.
+	NamePattern *regexp.Regexp
+
+	// Return tasks with this status. May be empty.
+	Status TaskStatus
+
+	// Return tasks whose most recently finished run has this status.
+	// Must be RunSuccess or RunFail, or the zero value to match any task.
+	LastRunStatus RunStatus
+
+	// Return tasks whose most recently finished run finished at or after LastRunAfter,
+	// and before LastRunBefore. Zero times are ignored.
+	// Tasks that have never finished a run do not match when either time is set.
+	LastRunAfter, LastRunBefore time.Time
+
 	// Size of each page. Must be non-negative.
 	// If zero, the implementation picks an appropriate default page size.
 	// Valid page sizes are implementation-dependent.
 	PageSize int
 }
 
+// MatchName reports whether name satisfies the NamePrefix and NamePattern parameters.
+func (p TaskSearchParams) MatchName(name string) bool {
+	if !strings.HasPrefix(name, p.NamePrefix) {
+		return false
+	}
+	return p.NamePattern == nil || p.NamePattern.MatchString(name)
+}
+
+// MatchLastRunTime reports whether a run finished at t satisfies the LastRunAfter and LastRunBefore parameters.
+func (p TaskSearchParams) MatchLastRunTime(t time.Time) bool {
+	if !p.LastRunAfter.IsZero() && t.Before(p.LastRunAfter) {
+		return false
+	}
+	return p.LastRunBefore.IsZero() || t.Before(p.LastRunBefore)
+}
+
 // StoreTask is a stored representation of a Task.
 type StoreTask struct {
 	ID platform.ID
@@ -174,11 +238,62 @@ type StoreTask struct {
 	// IDs for the owning organization and user.
 	Org, User platform.ID
 
+	// ID of the authorization that runs of the task execute with.
+	// It is empty for tasks created without an authorization.
+	AuthorizationID platform.ID
+
 	// The user-supplied name of the Task.
 	Name string
 
 	// The script content of the task.
 	Script string
+
+	// The revision of Script. Revisions start at 1 and increase with each modification.
+	// It is 0 for tasks created before script revisions were recorded.
+	Revision int64
+}
+
+// CreateTaskRequest is the set of arguments for creating a task.
+type CreateTaskRequest struct {
+	// IDs for the owning organization and user.
+	Org, User platform.ID
+
+	// ID of the authorization that runs of the task execute with.
+	// It is optional; runs of a task without an authorization may not write data.
+	AuthorizationID platform.ID
+
+	// The script content of the task.
+	Script string
+}
+
+// ScriptRevision is a stored version of a task's script.
+type ScriptRevision struct {
+	// Revision numbers start at 1 and increase by 1 for each modification of the task.
+	Revision int64
+
+	Script string
+
+	// Author is the ID of the user who submitted this revision.
+	Author platform.ID
+
+	// Created is the unix timestamp when the revision was submitted.
+	Created int64
+}
+
+// Trigger queues a triggered run with the given now timestamp in stm.Triggered, keeping it ordered oldest first.
+// It returns false if the timestamp was already queued.
+// stm.Triggered is replaced rather than modified in place, so copies of stm are unaffected.
+func (stm *StoreTaskMeta) Trigger(now int64) bool {
+	i := sort.Search(len(stm.Triggered), func(i int) bool { return stm.Triggered[i] >= now })
+	if i < len(stm.Triggered) && stm.Triggered[i] == now {
+		return false
+	}
+
+	triggered := make([]int64, 0, len(stm.Triggered)+1)
+	triggered = append(triggered, stm.Triggered[:i]...)
+	triggered = append(triggered, now)
+	stm.Triggered = append(triggered, stm.Triggered[i:]...)
+	return true
 }
 
 // StoreValidator is a package-level StoreValidation, so that you can write
@@ -218,6 +333,31 @@ func (StoreValidation) CreateArgs(org, user platform.ID, script string) (options
 	return o, nil
 }
 
+// ListArgs returns an error if the search parameters are invalid for listing tasks.
+func (StoreValidation) ListArgs(params TaskSearchParams) error {
+	if len(params.Org) > 0 && len(params.User) > 0 {
+		return errors.New("ListTasks: org and user filters are mutually exclusive")
+	}
+
+	switch params.Status {
+	case "", TaskEnabled, TaskDisabled:
+	default:
+		return fmt.Errorf("ListTasks: invalid status %q", params.Status)
+	}
+
+	switch params.LastRunStatus {
+	case RunQueued, RunSuccess, RunFail:
+	default:
+		return fmt.Errorf("ListTasks: invalid last run status %d", params.LastRunStatus)
+	}
+
+	if !params.LastRunAfter.IsZero() && !params.LastRunBefore.IsZero() && !params.LastRunAfter.Before(params.LastRunBefore) {
+		return errors.New("ListTasks: LastRunAfter must be before LastRunBefore")
+	}
+
+	return nil
+}
+
 // ModifyArgs returns the script's parsed options,
 // and an error if any of the provided fields are invalid for modifying a task.
 func (StoreValidation) ModifyArgs(taskID platform.ID, script string) (options.Options, error) {
@@ -244,3 +384,17 @@ func (StoreValidation) ModifyArgs(taskID platform.ID, script string) (options.Op
 
 	return o, nil
 }
+
+// NotificationStore persists the consecutive failure counts of tasks
+// and the history of the notifications delivered about them, so that both survive a restart.
+type NotificationStore interface {
+	platform.TaskNotificationService
+
+	// RecordRunResult updates the consecutive failure count of a task after one of its runs finished.
+	// It returns the count from before the run.
+	RecordRunResult(ctx context.Context, taskID platform.ID, failed bool) (previous int, err error)
+
+	// AddDelivery records an attempt to deliver a notification about a task.
+	// At most limit attempts are kept for each endpoint URL; older attempts are discarded.
+	AddDelivery(ctx context.Context, taskID platform.ID, d platform.NotificationDelivery, limit int) error
+}
@@ -0,0 +1,143 @@
+package storetest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/task/backend"
+)
+
+type CreateLeaseStoreFunc func(*testing.T) backend.LeaseStore
+type DestroyLeaseStoreFunc func(*testing.T, backend.LeaseStore)
+
+func NewLeaseStoreTest(name string, clf CreateLeaseStoreFunc, dlf DestroyLeaseStoreFunc) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Run(name, func(t *testing.T) {
+			t.Run("Lease", func(t *testing.T) {
+				leaseTest(t, clf, dlf)
+			})
+			t.Run("Heartbeat", func(t *testing.T) {
+				leaseHeartbeatTest(t, clf, dlf)
+			})
+			t.Run("TTL", func(t *testing.T) {
+				leaseTTLTest(t, clf, dlf)
+			})
+		})
+	}
+}
+
+func leaseTest(t *testing.T, clf CreateLeaseStoreFunc, dlf DestroyLeaseStoreFunc) {
+	ctx := context.Background()
+	s := clf(t)
+	defer dlf(t, s)
+	id := platform.ID{1}
+
+	if _, err := s.AcquireLease(ctx, id, "a", 10, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AcquireLease(ctx, id, "b", 14, 5*time.Second); err != backend.ErrLeaseHeld {
+		t.Fatalf("expected ErrLeaseHeld, got %v", err)
+	}
+
+	// Renewal extends the lease.
+	if _, err := s.AcquireLease(ctx, id, "a", 14, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AcquireLease(ctx, id, "b", 16, 5*time.Second); err != backend.ErrLeaseHeld {
+		t.Fatalf("expected ErrLeaseHeld after renewal, got %v", err)
+	}
+
+	// Expired leases can be taken over.
+	l, err := s.AcquireLease(ctx, id, "b", 19, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.NodeID != "b" || l.Expires != 24 {
+		t.Fatalf("unexpected lease after takeover: %+v", l)
+	}
+
+	if err := s.ReleaseLease(ctx, id, "a"); err != backend.ErrLeaseNotHeld {
+		t.Fatalf("expected ErrLeaseNotHeld, got %v", err)
+	}
+	if err := s.ReleaseLease(ctx, id, "b"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func leaseHeartbeatTest(t *testing.T, clf CreateLeaseStoreFunc, dlf DestroyLeaseStoreFunc) {
+	ctx := context.Background()
+	s := clf(t)
+	defer dlf(t, s)
+
+	if err := s.Heartbeat(ctx, "a", 10, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Heartbeat(ctx, "b", 12, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := s.LiveNodes(ctx, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0] != "b" {
+		t.Fatalf("expected only node b to be live, got %v", nodes)
+	}
+}
+
+func leaseTTLTest(t *testing.T, clf CreateLeaseStoreFunc, dlf DestroyLeaseStoreFunc) {
+	ctx := context.Background()
+	s := clf(t)
+	defer dlf(t, s)
+
+	// TTLs are rounded up to whole seconds, rather than truncated.
+	l, err := s.AcquireLease(ctx, platform.ID{1}, "a", 10, 1500*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Expires != 12 {
+		t.Fatalf("expected lease to expire at 12, got %d", l.Expires)
+	}
+	if _, err := s.AcquireLease(ctx, platform.ID{2}, "a", 10, 500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AcquireLease(ctx, platform.ID{2}, "b", 10, 500*time.Millisecond); err != backend.ErrLeaseHeld {
+		t.Fatalf("expected ErrLeaseHeld for a sub-second lease, got %v", err)
+	}
+
+	if err := s.Heartbeat(ctx, "a", 10, 500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := s.LiveNodes(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0] != "a" {
+		t.Fatalf("expected node a to be live after a sub-second heartbeat, got %v", nodes)
+	}
+
+	// A heartbeat without a TTL expires immediately.
+	if err := s.Heartbeat(ctx, "a", 10, 0); err != nil {
+		t.Fatal(err)
+	}
+	if nodes, err := s.LiveNodes(ctx, 10); err != nil {
+		t.Fatal(err)
+	} else if len(nodes) != 0 {
+		t.Fatalf("expected no live nodes, got %v", nodes)
+	}
+
+	found, err := s.FindLease(ctx, platform.ID{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || found.NodeID != "a" || found.Expires != 12 || !bytes.Equal(found.TaskID, platform.ID{1}) {
+		t.Fatalf("unexpected lease: %+v", found)
+	}
+	if found, err := s.FindLease(ctx, platform.ID{3}); err != nil {
+		t.Fatal(err)
+	} else if found != nil {
+		t.Fatalf("expected no lease, got %+v", found)
+	}
+}
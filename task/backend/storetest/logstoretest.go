@@ -23,6 +23,9 @@ func NewRunStoreTest(name string, crf CreateRunStoreFunc, drf DestroyRunStoreFun
 			t.Run("RunLog", func(t *testing.T) {
 				runLogTest(t, crf, drf)
 			})
+			t.Run("RunStatistics", func(t *testing.T) {
+				runStatisticsTest(t, crf, drf)
+			})
 			t.Run("ListRuns", func(t *testing.T) {
 				listRunsTest(t, crf, drf)
 			})
@@ -98,6 +101,47 @@ func updateRunState(t *testing.T, crf CreateRunStoreFunc, drf DestroyRunStoreFun
 	}
 }
 
+func runStatisticsTest(t *testing.T, crf CreateRunStoreFunc, drf DestroyRunStoreFunc) {
+	writer, reader := crf(t)
+	defer drf(t, writer, reader)
+
+	task := &backend.StoreTask{
+		ID:  platform.ID([]byte("ab01ab01ab01ab01")),
+		Org: platform.ID([]byte("ab01ab01ab01ab05")),
+	}
+	runID := platform.ID([]byte("run"))
+	stats := platform.RunStatistics{
+		TotalDuration:   3 * time.Second,
+		ExecuteDuration: 2 * time.Second,
+		MaxAllocated:    4096,
+		BytesWritten:    512,
+		PointsWritten:   16,
+		Yields: []platform.YieldStatistics{
+			{Name: "a", Tables: 1, Rows: 10},
+			{Name: "b", Tables: 0, Rows: 0},
+		},
+	}
+
+	if err := writer.SetRunStatistics(context.Background(), task, runID, stats); err == nil {
+		t.Fatal("shouldn't be able to set statistics on non existing run")
+	}
+
+	if err := writer.UpdateRunState(context.Background(), task, runID, time.Unix(1, 0), backend.RunStarted); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.SetRunStatistics(context.Background(), task, runID, stats); err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := reader.FindRunByID(context.Background(), task.ID, runID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Statistics == nil || !reflect.DeepEqual(*run.Statistics, stats) {
+		t.Fatalf("expected statistics %+v, got %+v", stats, run.Statistics)
+	}
+}
+
 func runLogTest(t *testing.T, crf CreateRunStoreFunc, drf DestroyRunStoreFunc) {
 	writer, reader := crf(t)
 	defer drf(t, writer, reader)
@@ -0,0 +1,136 @@
+package storetest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/task/backend"
+)
+
+type CreateNotificationStoreFunc func(*testing.T) backend.NotificationStore
+type DestroyNotificationStoreFunc func(*testing.T, backend.NotificationStore)
+
+func NewNotificationStoreTest(name string, cnf CreateNotificationStoreFunc, dnf DestroyNotificationStoreFunc) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Run(name, func(t *testing.T) {
+			t.Run("RunResult", func(t *testing.T) {
+				notificationRunResultTest(t, cnf, dnf)
+			})
+			t.Run("Deliveries", func(t *testing.T) {
+				notificationDeliveriesTest(t, cnf, dnf)
+			})
+		})
+	}
+}
+
+func notificationRunResultTest(t *testing.T, cnf CreateNotificationStoreFunc, dnf DestroyNotificationStoreFunc) {
+	ctx := context.Background()
+	s := cnf(t)
+	defer dnf(t, s)
+	id, other := platform.ID{1}, platform.ID{2}
+
+	for i, exp := range []int{0, 1, 2} {
+		previous, err := s.RecordRunResult(ctx, id, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if previous != exp {
+			t.Fatalf("failure %d: expected %d previous failures, got %d", i, exp, previous)
+		}
+	}
+	if _, err := s.RecordRunResult(ctx, other, true); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := s.FindTaskNotifications(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.ConsecutiveFailures != 3 {
+		t.Fatalf("expected 3 consecutive failures, got %d", n.ConsecutiveFailures)
+	}
+
+	// A success resets the count.
+	previous, err := s.RecordRunResult(ctx, id, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous != 3 {
+		t.Fatalf("expected 3 previous failures, got %d", previous)
+	}
+	n, err = s.FindTaskNotifications(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.ConsecutiveFailures != 0 {
+		t.Fatalf("expected consecutive failures to be reset, got %d", n.ConsecutiveFailures)
+	}
+
+	// Other tasks are unaffected.
+	n, err = s.FindTaskNotifications(ctx, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.ConsecutiveFailures != 1 {
+		t.Fatalf("expected 1 consecutive failure for other task, got %d", n.ConsecutiveFailures)
+	}
+}
+
+func notificationDeliveriesTest(t *testing.T, cnf CreateNotificationStoreFunc, dnf DestroyNotificationStoreFunc) {
+	ctx := context.Background()
+	s := cnf(t)
+	defer dnf(t, s)
+	id := platform.ID{1}
+
+	n, err := s.FindTaskNotifications(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.ConsecutiveFailures != 0 || len(n.Deliveries) != 0 {
+		t.Fatalf("expected no notifications for new task, got %+v", n)
+	}
+
+	delivery := func(url string, attempt int) platform.NotificationDelivery {
+		d := platform.NotificationDelivery{
+			URL:     url,
+			Event:   "failure",
+			RunID:   platform.ID{byte(attempt)},
+			Attempt: attempt,
+			Time:    time.Unix(int64(attempt), 0).UTC(),
+		}
+		if attempt%2 == 1 {
+			d.StatusCode = 500
+			d.Error = "unexpected status code 500"
+		} else {
+			d.StatusCode = 200
+		}
+		return d
+	}
+
+	// Only the 2 most recent deliveries of each endpoint are kept.
+	for attempt := 1; attempt <= 3; attempt++ {
+		for _, url := range []string{"http://b", "http://a"} {
+			if err := s.AddDelivery(ctx, id, delivery(url, attempt), 2); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := s.AddDelivery(ctx, platform.ID{2}, delivery("http://a", 1), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err = s.FindTaskNotifications(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []platform.NotificationDelivery{
+		delivery("http://a", 2), delivery("http://a", 3),
+		delivery("http://b", 2), delivery("http://b", 3),
+	}
+	if !reflect.DeepEqual(n.Deliveries, exp) {
+		t.Fatalf("expected deliveries:\n%+v\ngot:\n%+v", exp, n.Deliveries)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -31,6 +33,9 @@ func NewStoreTest(name string, cf CreateStoreFunc, df DestroyStoreFunc, funcName
 			"DeleteTask",
 			"CreateRun",
 			"FinishRun",
+			"TriggeredRun",
+			"ScriptRevisions",
+			"ListTasksFilter",
 		}
 	}
 	availableFuncs := map[string]TestFunc{
@@ -43,6 +48,9 @@ func NewStoreTest(name string, cf CreateStoreFunc, df DestroyStoreFunc, funcName
 		"DeleteTask":        testStoreDelete,
 		"CreateRun":         testStoreCreateRun,
 		"FinishRun":         testStoreFinishRun,
+		"TriggeredRun":      testStoreTriggeredRun,
+		"ScriptRevisions":   testStoreScriptRevisions,
+		"ListTasksFilter":   testStoreListTasksFilter,
 		"DeleteOrg":         testStoreDeleteOrg,
 		"DeleteUser":        testStoreDeleteUser,
 	}
@@ -76,7 +84,7 @@ from(db:"test") |> range(start:-1h)`
 	t.Run("happy path", func(t *testing.T) {
 		s := create(t)
 		defer destroy(t, s)
-		if _, err := s.CreateTask(context.Background(), []byte{1}, []byte{2}, script); err != nil {
+		if _, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: []byte{1}, User: []byte{2}, Script: script}); err != nil {
 			t.Fatal(err)
 		}
 	})
@@ -94,7 +102,7 @@ from(db:"test") |> range(start:-1h)`
 			s := create(t)
 			defer destroy(t, s)
 
-			if _, err := s.CreateTask(context.Background(), args.org, args.user, args.script); err == nil {
+			if _, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: args.org, User: args.user, Script: args.script}); err == nil {
 				t.Fatal("expected error but did not receive one")
 			}
 		})
@@ -125,11 +133,11 @@ from(bucket:"y") |> range(start:-1h)`
 		s := create(t)
 		defer destroy(t, s)
 
-		id, err := s.CreateTask(context.Background(), []byte{1}, []byte{2}, script)
+		id, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: []byte{1}, User: []byte{2}, Script: script})
 		if err != nil {
 			t.Fatal(err)
 		}
-		if err := s.ModifyTask(context.Background(), id, script2); err != nil {
+		if err := s.ModifyTask(context.Background(), id, []byte{3}, script2); err != nil {
 			t.Fatal(err)
 		}
 
@@ -156,7 +164,7 @@ from(bucket:"y") |> range(start:-1h)`
 			s := create(t)
 			defer destroy(t, s)
 
-			if err := s.ModifyTask(context.Background(), args.id, args.script); err == nil {
+			if err := s.ModifyTask(context.Background(), args.id, []byte{3}, args.script); err == nil {
 				t.Fatal("expected error but did not receive one")
 			}
 		})
@@ -177,7 +185,7 @@ from(db:"test") |> range(start:-1h)`
 		orgID := []byte{1}
 		userID := []byte{2}
 
-		id, err := s.CreateTask(context.Background(), orgID, userID, script)
+		id, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: orgID, User: userID, Script: script})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -220,7 +228,7 @@ from(db:"test") |> range(start:-1h)`
 			t.Fatalf("expected no results for bad user ID, got %d result(s)", len(ts))
 		}
 
-		newID, err := s.CreateTask(context.Background(), orgID, userID, script)
+		newID, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: orgID, User: userID, Script: script})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -260,7 +268,7 @@ from(db:"test") |> range(start:-1h)`
 			tasks[i].name = fmt.Sprintf("my_bucket_%d", i)
 			tasks[i].script = fmt.Sprintf(script, i, i)
 
-			id, err := s.CreateTask(context.Background(), orgID, userID, tasks[i].script)
+			id, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: orgID, User: userID, Script: tasks[i].script})
 			if err != nil {
 				t.Fatalf("failed to create task %d: %v", i, err)
 			}
@@ -336,8 +344,9 @@ from(db:"test") |> range(start:-1h)`
 
 		org := []byte{1}
 		user := []byte{2}
+		auth := []byte{3}
 
-		id, err := s.CreateTask(context.Background(), org, user, script)
+		id, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: org, User: user, AuthorizationID: auth, Script: script})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -356,6 +365,9 @@ from(db:"test") |> range(start:-1h)`
 		if !bytes.Equal(task.User, user) {
 			t.Fatalf("unexpected user: got %v, exp %v", task.User, user)
 		}
+		if !bytes.Equal(task.AuthorizationID, auth) {
+			t.Fatalf("unexpected authorization: got %v, exp %v", task.AuthorizationID, auth)
+		}
 		if task.Name != "a task" {
 			t.Fatalf("unexpected name %q", task.Name)
 		}
@@ -391,7 +403,7 @@ from(db:"test") |> range(start:-1h)`
 	org := []byte{1}
 	user := []byte{2}
 
-	id, err := s.CreateTask(context.Background(), org, user, script)
+	id, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: org, User: user, Script: script})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -457,7 +469,7 @@ func testStoreTaskEnableDisable(t *testing.T, create CreateStoreFunc, destroy De
 	org := []byte{1}
 	user := []byte{2}
 
-	id, err := s.CreateTask(context.Background(), org, user, script)
+	id, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: org, User: user, Script: script})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -510,7 +522,7 @@ from(db:"test") |> range(start:-1h)`
 		s := create(t)
 		defer destroy(t, s)
 
-		id, err := s.CreateTask(context.Background(), []byte{1}, []byte{2}, script)
+		id, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: []byte{1}, User: []byte{2}, Script: script})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -553,7 +565,7 @@ from(db:"test") |> range(start:-1h)`
 	s := create(t)
 	defer destroy(t, s)
 
-	task, err := s.CreateTask(context.Background(), []byte{1}, []byte{2}, script)
+	task, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: []byte{1}, User: []byte{2}, Script: script})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -588,7 +600,7 @@ from(db:"test") |> range(start:-1h)`
 	s := create(t)
 	defer destroy(t, s)
 
-	task, err := s.CreateTask(context.Background(), []byte{1}, []byte{2}, script)
+	task, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: []byte{1}, User: []byte{2}, Script: script})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -607,6 +619,302 @@ from(db:"test") |> range(start:-1h)`
 	}
 }
 
+func testStoreTriggeredRun(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
+	const upstreamScript = `option task = {
+		name: "upstream",
+		cron: "* * * * *",
+	}
+
+from(db:"test") |> range(start:-1h)`
+	const downstreamFmt = `option task = {
+		name: "downstream",
+		dependsOn: "%s",
+	}
+
+from(db:"test") |> range(start:-1h)`
+	s := create(t)
+	defer destroy(t, s)
+	ctx := context.Background()
+
+	upstream, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: []byte{1}, User: []byte{2}, Script: upstreamScript})
+	if err != nil {
+		t.Fatal(err)
+	}
+	downstream, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: []byte{1}, User: []byte{2}, Script: fmt.Sprintf(downstreamFmt, upstream.String())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CreateTriggeredRun(ctx, downstream); err != backend.ErrNoRunTriggered {
+		t.Fatalf("expected ErrNoRunTriggered before any trigger, got %v", err)
+	}
+
+	// Triggers are kept oldest first, without duplicates.
+	for _, now := range []int64{5, 3, 5} {
+		if err := s.TriggerDependents(ctx, upstream, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	meta, err := s.FindTaskMetaByID(ctx, downstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(meta.Triggered, []int64{3, 5}) {
+		t.Fatalf("unexpected triggers: %v", meta.Triggered)
+	}
+	meta, err = s.FindTaskMetaByID(ctx, upstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meta.Triggered) != 0 {
+		t.Fatalf("upstream task should not be triggered, got %v", meta.Triggered)
+	}
+
+	run, err := s.CreateTriggeredRun(ctx, downstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.TaskID.String() != downstream.String() || run.Now != 3 {
+		t.Fatalf("unexpected run for task %s and now %d", run.TaskID.String(), run.Now)
+	}
+	if _, err := s.CreateTriggeredRun(ctx, downstream); err == nil || !strings.Contains(err.Error(), "MaxConcurrency") {
+		t.Fatalf("expected error for MaxConcurrency, got %v", err)
+	}
+	if err := s.FinishRun(ctx, downstream, run.RunID); err != nil {
+		t.Fatal(err)
+	}
+
+	run, err = s.CreateTriggeredRun(ctx, downstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Now != 5 {
+		t.Fatalf("expected run for now 5, got %d", run.Now)
+	}
+	if err := s.FinishRun(ctx, downstream, run.RunID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateTriggeredRun(ctx, downstream); err != backend.ErrNoRunTriggered {
+		t.Fatalf("expected ErrNoRunTriggered after every trigger ran, got %v", err)
+	}
+
+	// A task that no longer depends on the upstream task is no longer triggered.
+	if err := s.ModifyTask(ctx, downstream, []byte{2}, strings.Replace(upstreamScript, "upstream", "downstream", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.TriggerDependents(ctx, upstream, 7); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateTriggeredRun(ctx, downstream); err != backend.ErrNoRunTriggered {
+		t.Fatalf("expected ErrNoRunTriggered after removing dependsOn, got %v", err)
+	}
+}
+
+func testStoreScriptRevisions(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
+	const script = `option task = {
+		name: "a task",
+		cron: "* * * * *",
+	}
+
+from(db:"test") |> range(start:-1h)`
+	const script2 = `option task = {
+		name: "a task",
+		cron: "* * * * *",
+	}
+
+from(db:"test") |> range(start:-2h)`
+	s := create(t)
+	defer destroy(t, s)
+
+	id, err := s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: []byte{1}, User: []byte{2}, Script: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := s.FindTaskByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Revision != 1 {
+		t.Fatalf("expected new task to be at revision 1, got %d", task.Revision)
+	}
+
+	if err := s.ModifyTask(context.Background(), id, []byte{3}, script2); err != nil {
+		t.Fatal(err)
+	}
+	task, err = s.FindTaskByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Revision != 2 {
+		t.Fatalf("expected modified task to be at revision 2, got %d", task.Revision)
+	}
+
+	revs, err := s.ListScriptRevisions(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(revs))
+	}
+	if revs[0].Revision != 1 || revs[0].Script != script || revs[0].Author.String() != platform.ID([]byte{2}).String() {
+		t.Fatalf("unexpected first revision: %+v", revs[0])
+	}
+	if revs[1].Revision != 2 || revs[1].Script != script2 || revs[1].Author.String() != platform.ID([]byte{3}).String() {
+		t.Fatalf("unexpected second revision: %+v", revs[1])
+	}
+
+	rev, err := s.FindScriptRevision(context.Background(), id, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev == nil || rev.Script != script {
+		t.Fatalf("expected to find revision 1, got %+v", rev)
+	}
+	rev, err = s.FindScriptRevision(context.Background(), id, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev != nil {
+		t.Fatalf("expected missing revision to be nil, got %+v", rev)
+	}
+
+	run, err := s.CreateRun(context.Background(), id, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Revision != 2 {
+		t.Fatalf("expected run to be associated with revision 2, got %d", run.Revision)
+	}
+}
+
+func testStoreListTasksFilter(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
+	const scriptFmt = `option task = {
+		name: %q,
+		cron: "* * * * *",
+	}
+
+from(db:"test") |> range(start:-1h)`
+	s := create(t)
+	defer destroy(t, s)
+	ctx := context.Background()
+
+	names := []string{"rollup_cpu", "rollup_mem", "alert_cpu", "rollup_disk"}
+	ids := make([]platform.ID, len(names))
+	for i, name := range names {
+		id, err := s.CreateTask(ctx, backend.CreateTaskRequest{Org: []byte{1}, User: []byte{2}, Script: fmt.Sprintf(scriptFmt, name)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = id
+	}
+
+	if err := s.DisableTask(ctx, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Unix(1000, 0)
+	for _, r := range []struct {
+		i        int
+		status   backend.RunStatus
+		finished time.Time
+	}{
+		{i: 0, status: backend.RunSuccess, finished: base},
+		{i: 1, status: backend.RunSuccess, finished: base.Add(time.Hour)},
+		{i: 2, status: backend.RunSuccess, finished: base.Add(2 * time.Hour)},
+		// The latest result replaces the earlier one.
+		{i: 0, status: backend.RunFail, finished: base.Add(3 * time.Hour)},
+	} {
+		if err := s.RecordRunResult(ctx, ids[r.i], r.status, r.finished); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.RecordRunResult(ctx, ids[3], backend.RunStarted, base); err == nil {
+		t.Fatal("expected error recording a run that has not finished")
+	}
+
+	for _, tc := range []struct {
+		name   string
+		params backend.TaskSearchParams
+		exp    []int
+	}{
+		{name: "name prefix", params: backend.TaskSearchParams{NamePrefix: "rollup_"}, exp: []int{0, 1, 3}},
+		{name: "name pattern", params: backend.TaskSearchParams{NamePattern: regexp.MustCompile("_cpu$")}, exp: []int{0, 2}},
+		{name: "name prefix and pattern", params: backend.TaskSearchParams{NamePrefix: "rollup_", NamePattern: regexp.MustCompile("_cpu$")}, exp: []int{0}},
+		{name: "status", params: backend.TaskSearchParams{Status: backend.TaskDisabled}, exp: []int{1}},
+		{name: "status and prefix", params: backend.TaskSearchParams{Status: backend.TaskEnabled, NamePrefix: "rollup_"}, exp: []int{0, 3}},
+		{name: "last run status", params: backend.TaskSearchParams{LastRunStatus: backend.RunFail}, exp: []int{0}},
+		{name: "last run before", params: backend.TaskSearchParams{LastRunBefore: base.Add(2 * time.Hour)}, exp: []int{1}},
+		{name: "last run window", params: backend.TaskSearchParams{LastRunAfter: base.Add(time.Hour), LastRunBefore: base.Add(3 * time.Hour)}, exp: []int{1, 2}},
+		{name: "last run after", params: backend.TaskSearchParams{LastRunAfter: base.Add(2 * time.Hour), LastRunStatus: backend.RunSuccess}, exp: []int{2}},
+		{name: "after ID", params: backend.TaskSearchParams{NamePrefix: "rollup_", After: ids[0]}, exp: []int{1, 3}},
+		{name: "org", params: backend.TaskSearchParams{Org: []byte{1}, NamePrefix: "alert_"}, exp: []int{2}},
+		{name: "page size", params: backend.TaskSearchParams{NamePrefix: "rollup_", PageSize: 2}, exp: []int{0, 1}},
+		{name: "status page", params: backend.TaskSearchParams{Status: backend.TaskEnabled, PageSize: 2}, exp: []int{0, 2}},
+		{name: "status next page", params: backend.TaskSearchParams{Status: backend.TaskEnabled, After: ids[2], PageSize: 2}, exp: []int{3}},
+		{name: "name prefix next page", params: backend.TaskSearchParams{NamePrefix: "rollup_", After: ids[1], PageSize: 2}, exp: []int{3}},
+		{name: "last run time page", params: backend.TaskSearchParams{LastRunAfter: base.Add(time.Hour), After: ids[0], PageSize: 1}, exp: []int{1}},
+		{name: "last run status after ID", params: backend.TaskSearchParams{LastRunStatus: backend.RunSuccess, After: ids[1]}, exp: []int{2}},
+		{name: "no match", params: backend.TaskSearchParams{NamePrefix: "nope"}, exp: nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ts, err := s.ListTasks(ctx, tc.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ts) != len(tc.exp) {
+				t.Fatalf("expected %d tasks, got %d: %v", len(tc.exp), len(ts), ts)
+			}
+			for i, j := range tc.exp {
+				if ts[i].ID.String() != ids[j].String() {
+					t.Fatalf("expected task %d to be %q, got %q", i, names[j], ts[i].Name)
+				}
+			}
+		})
+	}
+
+	t.Run("renamed task", func(t *testing.T) {
+		if err := s.ModifyTask(ctx, ids[2], []byte{2}, fmt.Sprintf(scriptFmt, "rollup_alerts")); err != nil {
+			t.Fatal(err)
+		}
+		ts, err := s.ListTasks(ctx, backend.TaskSearchParams{NamePrefix: "rollup_a"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ts) != 1 || ts[0].ID.String() != ids[2].String() || ts[0].Name != "rollup_alerts" {
+			t.Fatalf("expected renamed task to match new name, got %v", ts)
+		}
+		ts, err = s.ListTasks(ctx, backend.TaskSearchParams{NamePrefix: "alert_"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ts) != 0 {
+			t.Fatalf("expected renamed task not to match old name, got %v", ts)
+		}
+	})
+
+	t.Run("deleted task", func(t *testing.T) {
+		if _, err := s.DeleteTask(ctx, ids[0]); err != nil {
+			t.Fatal(err)
+		}
+		ts, err := s.ListTasks(ctx, backend.TaskSearchParams{LastRunStatus: backend.RunFail})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ts) != 0 {
+			t.Fatalf("expected deleted task not to be listed, got %v", ts)
+		}
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		if _, err := s.ListTasks(ctx, backend.TaskSearchParams{Status: "unknown"}); err == nil {
+			t.Fatal("expected error for unknown status")
+		}
+		if _, err := s.ListTasks(ctx, backend.TaskSearchParams{LastRunStatus: backend.RunCanceled}); err == nil {
+			t.Fatal("expected error for last run status that is never recorded")
+		}
+	})
+}
+
 func testStoreDeleteUser(t *testing.T, create CreateStoreFunc, destroy DestroyStoreFunc) {
 	s := create(t)
 	defer destroy(t, s)
@@ -672,7 +980,7 @@ from(db:"test") |> range(start:-1h)`
 				user := make(platform.ID, 8)
 				binary.BigEndian.PutUint64(org, orgInt)
 				binary.BigEndian.PutUint64(user, userInt)
-				if id, err = s.CreateTask(context.Background(), org, user, script); err != nil {
+				if id, err = s.CreateTask(context.Background(), backend.CreateTaskRequest{Org: org, User: user, Script: script}); err != nil {
 					t.Fatal(err)
 				}
 				if filter(userInt, orgInt) {
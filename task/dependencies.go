@@ -0,0 +1,135 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/influxdata/platform"
+	"github.com/influxdata/platform/task/backend"
+	"github.com/influxdata/platform/task/options"
+)
+
+// dependencyPageSize is the page size used when listing every task of an organization.
+const dependencyPageSize = 500
+
+// checkDependsOn returns an error if the task with the given ID, in the given organization,
+// cannot depend on the task identified by dependsOn.
+// id is nil for a task that has not been created yet, which cannot be part of a cycle.
+func checkDependsOn(ctx context.Context, st backend.Store, org, id platform.ID, dependsOn string) error {
+	if dependsOn == "" {
+		return nil
+	}
+
+	var upstream platform.ID
+	if err := upstream.DecodeFromString(dependsOn); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for {
+		if len(id) > 0 && bytes.Equal(upstream, id) {
+			return fmt.Errorf("dependsOn task %s would create a dependency cycle", dependsOn)
+		}
+		if seen[upstream.String()] {
+			// The upstream tasks already form a cycle that does not include this task.
+			return fmt.Errorf("dependsOn task %s is part of a dependency cycle", dependsOn)
+		}
+		seen[upstream.String()] = true
+
+		t, err := st.FindTaskByID(ctx, upstream)
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			return fmt.Errorf("dependsOn task %s not found", upstream.String())
+		}
+		if !bytes.Equal(t.Org, org) {
+			return fmt.Errorf("dependsOn task %s belongs to a different organization", upstream.String())
+		}
+
+		opts, err := options.FromScript(t.Script)
+		if err != nil {
+			return err
+		}
+		if opts.DependsOn == "" {
+			return nil
+		}
+		if err := upstream.DecodeFromString(opts.DependsOn); err != nil {
+			return err
+		}
+	}
+}
+
+// findDependencies builds the dependency graph of the task with the given ID.
+func findDependencies(ctx context.Context, st backend.Store, id platform.ID) (*platform.TaskDependencies, error) {
+	t, err := st.FindTaskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("task %s not found", id.String())
+	}
+
+	// Dependencies are confined to an organization, so only its tasks need to be considered.
+	tasks := make(map[string]platform.TaskDependency)
+	children := make(map[string][]string)
+	params := backend.TaskSearchParams{Org: t.Org, PageSize: dependencyPageSize}
+	for {
+		ts, err := st.ListTasks(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range ts {
+			opts, err := options.FromScript(t.Script)
+			if err != nil {
+				return nil, err
+			}
+			dep := platform.TaskDependency{ID: t.ID, Name: t.Name}
+			if opts.DependsOn != "" {
+				if err := dep.DependsOn.DecodeFromString(opts.DependsOn); err != nil {
+					return nil, err
+				}
+				children[opts.DependsOn] = append(children[opts.DependsOn], t.ID.String())
+			}
+			tasks[t.ID.String()] = dep
+		}
+		if len(ts) < dependencyPageSize {
+			break
+		}
+		params.After = ts[len(ts)-1].ID
+	}
+
+	deps := &platform.TaskDependencies{
+		TaskID:     id,
+		Upstream:   []platform.TaskDependency{},
+		Downstream: []platform.TaskDependency{},
+	}
+
+	// Follow the chain of dependsOn options upward, stopping at a cycle or at a task that no longer exists.
+	seen := map[string]bool{id.String(): true}
+	for up := tasks[id.String()].DependsOn; len(up) > 0; up = tasks[up.String()].DependsOn {
+		dep, ok := tasks[up.String()]
+		if !ok || seen[up.String()] {
+			break
+		}
+		seen[up.String()] = true
+		deps.Upstream = append(deps.Upstream, dep)
+	}
+
+	// Walk the dependent tasks breadth first.
+	seen = map[string]bool{id.String(): true}
+	queue := children[id.String()]
+	for len(queue) > 0 {
+		tid := queue[0]
+		queue = queue[1:]
+		if seen[tid] {
+			continue
+		}
+		seen[tid] = true
+		deps.Downstream = append(deps.Downstream, tasks[tid])
+		queue = append(queue, children[tid]...)
+	}
+
+	return deps, nil
+}
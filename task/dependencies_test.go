@@ -0,0 +1,71 @@
+package task_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/platform"
+	_ "github.com/influxdata/platform/query/builtin"
+	"github.com/influxdata/platform/task"
+	"github.com/influxdata/platform/task/backend"
+)
+
+func TestPlatformAdapter_Dependencies(t *testing.T) {
+	ctx := context.Background()
+	svc := task.PlatformAdapter(backend.NewInMemStore(), backend.NopLogReader{})
+	org := platform.ID("org")
+	script := func(name, timing string) string {
+		return fmt.Sprintf("option task = {name: %q, %s}\nfrom(bucket: \"b\") |> range(start: -1m)", name, timing)
+	}
+	create := func(name, timing string) *platform.Task {
+		t.Helper()
+		tsk := &platform.Task{Organization: org, Owner: platform.User{ID: platform.ID("user")}, Flux: script(name, timing)}
+		if err := svc.CreateTask(ctx, tsk); err != nil {
+			t.Fatal(err)
+		}
+		return tsk
+	}
+
+	// a triggers b, which triggers c.
+	a := create("a", "every: 1m")
+	b := create("b", fmt.Sprintf("dependsOn: %q", a.ID.String()))
+	c := create("c", fmt.Sprintf("dependsOn: %q", b.ID.String()))
+	if !reflect.DeepEqual(b.DependsOn, a.ID) {
+		t.Fatalf("expected b to depend on %s, got %s", a.ID.String(), b.DependsOn.String())
+	}
+
+	deps, err := svc.FindTaskDependencies(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &platform.TaskDependencies{
+		TaskID:     b.ID,
+		Upstream:   []platform.TaskDependency{{ID: a.ID, Name: "a"}},
+		Downstream: []platform.TaskDependency{{ID: c.ID, Name: "c", DependsOn: b.ID}},
+	}
+	if !reflect.DeepEqual(deps, exp) {
+		t.Fatalf("unexpected dependencies: got %#v, want %#v", deps, exp)
+	}
+
+	// Making a depend on c would create a cycle.
+	flux := script("a", fmt.Sprintf("dependsOn: %q", c.ID.String()))
+	if _, err := svc.UpdateTask(ctx, a.ID, platform.TaskUpdate{Flux: &flux}); err == nil {
+		t.Fatal("expected error for dependency cycle")
+	}
+	flux = script("a", fmt.Sprintf("dependsOn: %q", a.ID.String()))
+	if _, err := svc.UpdateTask(ctx, a.ID, platform.TaskUpdate{Flux: &flux}); err == nil {
+		t.Fatal("expected error for a task depending on itself")
+	}
+
+	// The upstream task must exist in the same organization.
+	missing := &platform.Task{Organization: org, Owner: platform.User{ID: platform.ID("user")}, Flux: script("d", `dependsOn: "00000000000000ff"`)}
+	if err := svc.CreateTask(ctx, missing); err == nil {
+		t.Fatal("expected error for missing upstream task")
+	}
+	other := &platform.Task{Organization: platform.ID("other"), Owner: platform.User{ID: platform.ID("user")}, Flux: script("e", fmt.Sprintf("dependsOn: %q", a.ID.String()))}
+	if err := svc.CreateTask(ctx, other); err == nil {
+		t.Fatal("expected error for upstream task in another organization")
+	}
+}
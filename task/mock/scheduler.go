@@ -130,6 +130,17 @@ type DesiredState struct {
 
 	// Map of stringified, concatenated task and platform ID, to runs that have been created.
 	created map[string]backend.QueuedRun
+
+	// Map of stringified task ID to the status of the last finished run.
+	results map[string]backend.RunStatus
+
+	// Map of stringified task ID to the stringified ID of the task it depends on.
+	dependsOn map[string]string
+
+	// Map of stringified task ID to the now timestamps of its triggered runs that have not been created, oldest first.
+	triggered map[string][]int64
+
+	createRunError error
 }
 
 var _ backend.DesiredState = (*DesiredState)(nil)
@@ -138,14 +149,43 @@ func NewDesiredState() *DesiredState {
 	return &DesiredState{
 		runIDs:  make(map[string]uint32),
 		created: make(map[string]backend.QueuedRun),
+		results: make(map[string]backend.RunStatus),
+
+		dependsOn: make(map[string]string),
+		triggered: make(map[string][]int64),
 	}
 }
 
+// SetDependsOn records that the task with the given ID depends on the task with the ID upstream,
+// as the dependsOn option of the task's script would in a real store.
+func (d *DesiredState) SetDependsOn(taskID, upstream platform.ID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.dependsOn[taskID.String()] = upstream.String()
+}
+
+// CreateRunError sets an error to be returned by d.CreateRun, if err is not nil.
+func (d *DesiredState) CreateRunError(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.createRunError = err
+}
+
 // TODO(mr): inject a way to treat CreateRun as blocking?
 func (d *DesiredState) CreateRun(_ context.Context, taskID platform.ID, now int64) (backend.QueuedRun, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	if d.createRunError != nil {
+		return backend.QueuedRun{}, d.createRunError
+	}
+	return d.createRun(taskID, now), nil
+}
+
+// createRun creates a run for taskID and now. d.mu must be locked.
+func (d *DesiredState) createRun(taskID platform.ID, now int64) backend.QueuedRun {
 	tid := taskID.String()
 	d.runIDs[tid]++
 
@@ -159,7 +199,37 @@ func (d *DesiredState) CreateRun(_ context.Context, taskID platform.ID, now int6
 
 	d.created[tid+platform.ID(runID).String()] = qr
 
-	return qr, nil
+	return qr
+}
+
+func (d *DesiredState) TriggerDependents(_ context.Context, taskID platform.ID, now int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	upstream := taskID.String()
+	for tid, dependsOn := range d.dependsOn {
+		if dependsOn != upstream {
+			continue
+		}
+		stm := backend.StoreTaskMeta{Triggered: d.triggered[tid]}
+		stm.Trigger(now)
+		d.triggered[tid] = stm.Triggered
+	}
+	return nil
+}
+
+func (d *DesiredState) CreateTriggeredRun(_ context.Context, taskID platform.ID) (backend.QueuedRun, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tid := taskID.String()
+	triggered := d.triggered[tid]
+	if len(triggered) == 0 {
+		return backend.QueuedRun{}, backend.ErrNoRunTriggered
+	}
+	d.triggered[tid] = triggered[1:]
+
+	return d.createRun(taskID, triggered[0]), nil
 }
 
 func (d *DesiredState) FinishRun(_ context.Context, taskID, runID platform.ID) error {
@@ -170,6 +240,24 @@ func (d *DesiredState) FinishRun(_ context.Context, taskID, runID platform.ID) e
 	return nil
 }
 
+func (d *DesiredState) RecordRunResult(_ context.Context, taskID platform.ID, status backend.RunStatus, _ time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.results[taskID.String()] = status
+	return nil
+}
+
+// LastResult returns the status most recently recorded for the given task ID,
+// and whether any status has been recorded.
+func (d *DesiredState) LastResult(taskID platform.ID) (backend.RunStatus, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s, ok := d.results[taskID.String()]
+	return s, ok
+}
+
 func (d *DesiredState) CreatedFor(taskID platform.ID) []backend.QueuedRun {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -232,7 +320,10 @@ func (e *Executor) Execute(_ context.Context, run backend.QueuedRun) (backend.Ru
 	go func() {
 		res, _ := rp.Wait()
 		e.mu.Lock()
-		delete(e.running, id)
+		// A retried run may already be executing again under the same ID.
+		if e.running[id] == rp {
+			delete(e.running, id)
+		}
 		e.finished[id] = res
 		e.mu.Unlock()
 	}()
@@ -321,6 +412,7 @@ func (p *RunPromise) Finish(r backend.RunResult, err error) {
 type RunResult struct {
 	err         error
 	isRetryable bool
+	stats       platform.RunStatistics
 }
 
 var _ backend.RunResult = (*RunResult)(nil)
@@ -336,3 +428,13 @@ func (rr *RunResult) Err() error {
 func (rr *RunResult) IsRetryable() bool {
 	return rr.isRetryable
 }
+
+// WithStatistics sets the statistics reported by rr, and returns rr.
+func (rr *RunResult) WithStatistics(stats platform.RunStatistics) *RunResult {
+	rr.stats = stats
+	return rr
+}
+
+func (rr *RunResult) Statistics() platform.RunStatistics {
+	return rr.stats
+}
@@ -3,10 +3,14 @@ package options
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/influxdata/platform"
 	"github.com/influxdata/platform/query"
+	"github.com/influxdata/platform/query/semantic"
+	"github.com/influxdata/platform/query/values"
 )
 
 // optionCache is enabled for tests, to work around https://github.com/influxdata/platform/issues/484.
@@ -23,6 +27,7 @@ func EnableScriptCacheForTest() {
 
 const maxConcurrency = 100
 const maxRetry = 10
+const maxConcurrencyQuota = 100
 
 // Options are the task-related options that can be specified in a Flux script.
 type Options struct {
@@ -32,15 +37,104 @@ type Options struct {
 	// Cron is a cron style time schedule that can be used in place of Every.
 	Cron string
 
+	// Timezone is the IANA time zone name in which Cron is evaluated, such as "Europe/Berlin".
+	// If empty, Cron is evaluated in UTC.
+	Timezone string
+
 	// Every represents a fixed period to repeat execution.
+	// Runs are spaced Every apart from when the task is claimed,
+	// unless Offset is set, in which case they are aligned to multiples of Every since the Unix epoch.
 	Every time.Duration
 
+	// DependsOn is the hex encoded ID of another task, used in place of Cron or Every.
+	// The task runs after each successful run of that task, with the same now time.
+	DependsOn string
+
+	// Offset shifts every scheduled run later by a fixed duration.
+	// When used with Every, it must be less than Every.
+	Offset time.Duration
+
 	// Delay represents a delay before execution.
 	Delay time.Duration
 
 	Concurrency int64
 
 	Retry int64
+
+	// Notify lists the endpoints to notify about failing and recovering runs.
+	Notify []Notification
+
+	// Priority is the query priority of each run; lower values run first.
+	// It is declared as an integer, or as "high" or "low".
+	Priority query.Priority
+
+	// ConcurrencyQuota is the number of workers allowed to process each run.
+	// If zero, the query planner picks the concurrency.
+	ConcurrencyQuota int64
+
+	// MemoryBytesQuota is the number of bytes of memory each run may allocate.
+	// If zero, memory is unlimited.
+	MemoryBytesQuota int64
+
+	// Timeout is the maximum duration of each run.
+	// A run that exceeds it is failed and not retried.
+	// If zero, runs have no time limit.
+	Timeout time.Duration
+
+	// CatchUp is the policy for scheduled runs that were missed while the task was not being scheduled,
+	// such as while the server was down. It is one of CatchUpAll, CatchUpLatest or CatchUpNone.
+	// If empty, CatchUpAll is used.
+	CatchUp string
+}
+
+// Catch-up policies for missed scheduled runs.
+const (
+	// CatchUpAll runs every missed scheduled instant.
+	CatchUpAll = "all"
+
+	// CatchUpLatest runs only the most recent missed scheduled instant.
+	CatchUpLatest = "latest"
+
+	// CatchUpNone skips every missed scheduled instant.
+	CatchUpNone = "none"
+)
+
+// ResourceManagement returns the query resource limits for each run of the task.
+func (o Options) ResourceManagement() query.ResourceManagement {
+	return query.ResourceManagement{
+		Priority:         o.Priority,
+		ConcurrencyQuota: int(o.ConcurrencyQuota),
+		MemoryBytesQuota: o.MemoryBytesQuota,
+	}
+}
+
+// Events that trigger a Notification.
+const (
+	// NotifyOnFailure notifies about failed runs.
+	NotifyOnFailure = "failure"
+
+	// NotifyOnRecovery notifies about the first successful run after one or more failed runs.
+	NotifyOnRecovery = "recovery"
+)
+
+// Notification is a webhook endpoint to notify about the outcome of task runs.
+// It is declared in the task option as an element of the notify array, for example:
+//
+//	notify: [{url: "https://example.com/hook", on: "failure", after: 3}]
+//
+// Flux requires every element of an array to have the same type,
+// so when entries are mixed, each should set url, on and after; use after: 0 for recovery entries.
+type Notification struct {
+	// URL is the address that receives the notification as a JSON POST request.
+	URL string
+
+	// On is the event that triggers the notification, either NotifyOnFailure or NotifyOnRecovery.
+	On string
+
+	// After only applies to NotifyOnFailure.
+	// If zero, every failed run is notified.
+	// Otherwise, a single notification is sent when a task has failed After times in a row.
+	After int64
 }
 
 // FromScript extracts Options from a Flux script.
@@ -77,11 +171,26 @@ func FromScript(script string) (Options, error) {
 
 	crVal, cronOK := optObject.Get("cron")
 	everyVal, everyOK := optObject.Get("every")
+	dependsOnVal, dependsOnOK := optObject.Get("dependsOn")
 	if cronOK && everyOK {
 		return opt, errors.New("cannot use both cron and every in task options")
 	}
-	if !cronOK && !everyOK {
-		return opt, errors.New("cron or every is required")
+	if dependsOnOK && (cronOK || everyOK) {
+		return opt, errors.New("cannot use dependsOn with cron or every in task options")
+	}
+	if !cronOK && !everyOK && !dependsOnOK {
+		return opt, errors.New("cron, every or dependsOn is required")
+	}
+
+	if dependsOnOK {
+		if dependsOnVal.Type().Kind() != semantic.String {
+			return opt, errors.New("dependsOn must be a string")
+		}
+		var id platform.ID
+		if err := id.DecodeFromString(dependsOnVal.Str()); err != nil || len(id) == 0 {
+			return opt, fmt.Errorf("invalid dependsOn task ID %q", dependsOnVal.Str())
+		}
+		opt.DependsOn = id.String()
 	}
 
 	if cronOK {
@@ -91,6 +200,37 @@ func FromScript(script string) (Options, error) {
 		opt.Every = everyVal.Duration().Duration()
 	}
 
+	if tzVal, ok := optObject.Get("timezone"); ok {
+		if !cronOK {
+			return opt, errors.New("timezone can only be used with cron")
+		}
+		if tzVal.Type().Kind() != semantic.String {
+			return opt, errors.New("timezone must be a string")
+		}
+		tz := tzVal.Str()
+		if _, err := time.LoadLocation(tz); err != nil || tz == "" || tz == "Local" {
+			return opt, fmt.Errorf("invalid timezone %q", tz)
+		}
+		opt.Timezone = tz
+	}
+
+	if offsetVal, ok := optObject.Get("offset"); ok {
+		if dependsOnOK {
+			return opt, errors.New("offset cannot be used with dependsOn")
+		}
+		if offsetVal.Type().Kind() != semantic.Duration {
+			return opt, errors.New("offset must be a duration")
+		}
+		offset := offsetVal.Duration().Duration()
+		if offset < 0 {
+			return opt, errors.New("offset must not be negative")
+		}
+		if everyOK && offset >= opt.Every {
+			return opt, errors.New("offset must be less than every")
+		}
+		opt.Offset = offset
+	}
+
 	if delayVal, ok := optObject.Get("delay"); ok {
 		opt.Delay = delayVal.Duration().Duration()
 	}
@@ -117,6 +257,83 @@ func FromScript(script string) (Options, error) {
 		opt.Retry = retry
 	}
 
+	if notifyVal, ok := optObject.Get("notify"); ok {
+		notify, err := notificationsFromValue(notifyVal)
+		if err != nil {
+			return opt, err
+		}
+		opt.Notify = notify
+	}
+
+	if priorityVal, ok := optObject.Get("priority"); ok {
+		switch priorityVal.Type().Kind() {
+		case semantic.Int:
+			priority := priorityVal.Int()
+			if priority < int64(query.High) || priority > int64(query.Low) {
+				return opt, errors.New("priority must be a non-negative 32 bit integer")
+			}
+			opt.Priority = query.Priority(priority)
+		case semantic.String:
+			s := priorityVal.Str()
+			if s != "high" && s != "low" {
+				return opt, fmt.Errorf("invalid priority %q, must be an integer or \"high\" or \"low\"", s)
+			}
+			if err := opt.Priority.UnmarshalText([]byte(s)); err != nil {
+				return opt, err
+			}
+		default:
+			return opt, errors.New("priority must be an integer or a string")
+		}
+	}
+
+	if quotaVal, ok := optObject.Get("concurrencyQuota"); ok {
+		if quotaVal.Type().Kind() != semantic.Int {
+			return opt, errors.New("concurrencyQuota must be an integer")
+		}
+		quota := quotaVal.Int()
+		if quota > maxConcurrencyQuota {
+			return opt, errors.New("concurrencyQuota exceeded max concurrency quota")
+		}
+		if quota < 1 {
+			return opt, errors.New("atleast 1 concurrencyQuota required")
+		}
+		opt.ConcurrencyQuota = quota
+	}
+
+	if quotaVal, ok := optObject.Get("memoryBytesQuota"); ok {
+		if quotaVal.Type().Kind() != semantic.Int {
+			return opt, errors.New("memoryBytesQuota must be an integer")
+		}
+		quota := quotaVal.Int()
+		if quota < 1 {
+			return opt, errors.New("memoryBytesQuota must be positive")
+		}
+		opt.MemoryBytesQuota = quota
+	}
+
+	if timeoutVal, ok := optObject.Get("timeout"); ok {
+		if timeoutVal.Type().Kind() != semantic.Duration {
+			return opt, errors.New("timeout must be a duration")
+		}
+		timeout := timeoutVal.Duration().Duration()
+		if timeout <= 0 {
+			return opt, errors.New("timeout must be positive")
+		}
+		opt.Timeout = timeout
+	}
+
+	if catchUpVal, ok := optObject.Get("catchUp"); ok {
+		if catchUpVal.Type().Kind() != semantic.String {
+			return opt, errors.New("catchUp must be a string")
+		}
+		switch c := catchUpVal.Str(); c {
+		case CatchUpAll, CatchUpLatest, CatchUpNone:
+			opt.CatchUp = c
+		default:
+			return opt, fmt.Errorf("invalid catchUp %q, must be %q, %q or %q", c, CatchUpAll, CatchUpLatest, CatchUpNone)
+		}
+	}
+
 	if optionCache != nil {
 		optionCacheMu.Lock()
 		optionCache[script] = opt
@@ -125,3 +342,52 @@ func FromScript(script string) (Options, error) {
 
 	return opt, nil
 }
+
+// notificationsFromValue converts the notify task option, an array of objects, into Notifications.
+func notificationsFromValue(v values.Value) ([]Notification, error) {
+	if v.Type().Kind() != semantic.Array {
+		return nil, errors.New("notify must be an array")
+	}
+	arr := v.Array()
+	notify := make([]Notification, 0, arr.Len())
+	for i := 0; i < arr.Len(); i++ {
+		elem := arr.Get(i)
+		if elem.Type().Kind() != semantic.Object {
+			return nil, fmt.Errorf("notify[%d] must be an object", i)
+		}
+		obj := elem.Object()
+
+		n := Notification{On: NotifyOnFailure}
+		urlVal, ok := obj.Get("url")
+		if !ok || urlVal.Type().Kind() != semantic.String || urlVal.Str() == "" {
+			return nil, fmt.Errorf("notify[%d] requires a url", i)
+		}
+		n.URL = urlVal.Str()
+
+		if onVal, ok := obj.Get("on"); ok {
+			if onVal.Type().Kind() != semantic.String {
+				return nil, fmt.Errorf("notify[%d].on must be a string", i)
+			}
+			n.On = onVal.Str()
+		}
+		if n.On != NotifyOnFailure && n.On != NotifyOnRecovery {
+			return nil, fmt.Errorf("notify[%d].on must be %q or %q", i, NotifyOnFailure, NotifyOnRecovery)
+		}
+
+		if afterVal, ok := obj.Get("after"); ok {
+			if afterVal.Type().Kind() != semantic.Int {
+				return nil, fmt.Errorf("notify[%d].after must be an integer", i)
+			}
+			n.After = afterVal.Int()
+			if n.After < 0 {
+				return nil, fmt.Errorf("notify[%d].after must not be negative", i)
+			}
+			if n.After != 0 && n.On != NotifyOnFailure {
+				return nil, fmt.Errorf("notify[%d].after only applies to %q notifications", i, NotifyOnFailure)
+			}
+		}
+
+		notify = append(notify, n)
+	}
+	return notify, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/platform/query"
 	_ "github.com/influxdata/platform/query/builtin"
 	"github.com/influxdata/platform/task/options"
 )
@@ -74,3 +75,146 @@ func TestFromScript(t *testing.T) {
 		}
 	}
 }
+
+func TestFromScript_Schedule(t *testing.T) {
+	const body = `from(db: "test") |> range(start:-1h)`
+	for _, c := range []struct {
+		timing    string
+		exp       options.Options
+		shouldErr bool
+	}{
+		{
+			timing: `cron: "0 2 * * *", timezone: "Europe/Berlin"`,
+			exp:    options.Options{Name: "name", Cron: "0 2 * * *", Timezone: "Europe/Berlin", Concurrency: 1, Retry: 1},
+		},
+		{
+			timing: `cron: "0 2 * * *", offset: 10m`,
+			exp:    options.Options{Name: "name", Cron: "0 2 * * *", Offset: 10 * time.Minute, Concurrency: 1, Retry: 1},
+		},
+		{
+			timing: `every: 1h, offset: 15m`,
+			exp:    options.Options{Name: "name", Every: time.Hour, Offset: 15 * time.Minute, Concurrency: 1, Retry: 1},
+		},
+		{
+			timing: `dependsOn: "0000000000000001"`,
+			exp:    options.Options{Name: "name", DependsOn: "0000000000000001", Concurrency: 1, Retry: 1},
+		},
+		{timing: `dependsOn: "0000000000000001", every: 1h`, shouldErr: true},
+		{timing: `dependsOn: "0000000000000001", offset: 1m`, shouldErr: true},
+		{timing: `dependsOn: "not hex"`, shouldErr: true},
+		{timing: `dependsOn: ""`, shouldErr: true},
+		{timing: `cron: "0 2 * * *", timezone: "Not/AZone"`, shouldErr: true},
+		{timing: `cron: "0 2 * * *", timezone: ""`, shouldErr: true},
+		{timing: `cron: "0 2 * * *", timezone: "Local"`, shouldErr: true},
+		{timing: `every: 1h, timezone: "Europe/Berlin"`, shouldErr: true},
+		{timing: `every: 1h, offset: 1h`, shouldErr: true},
+		{timing: `every: 1h, offset: -5m`, shouldErr: true},
+		{timing: `cron: "0 2 * * *", timezone: 1`, shouldErr: true},
+		{timing: `every: 1h, offset: 5`, shouldErr: true},
+		{timing: `every: 1h, offset: "5m"`, shouldErr: true},
+	} {
+		script := fmt.Sprintf("option task = {\n  name: \"name\",\n  %s,\n}\n\n%s", c.timing, body)
+		o, err := options.FromScript(script)
+		if c.shouldErr {
+			if err == nil {
+				t.Fatalf("timing %s should have errored but didn't", c.timing)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("timing %s should not have errored, but got %v", c.timing, err)
+		}
+		if !cmp.Equal(o, c.exp) {
+			t.Fatalf("timing %s got unexpected result -got/+exp\n%s", c.timing, cmp.Diff(o, c.exp))
+		}
+	}
+}
+
+func TestFromScript_Notify(t *testing.T) {
+	const body = `from(db: "test") |> range(start:-1h)`
+	for _, c := range []struct {
+		notify    string
+		exp       []options.Notification
+		shouldErr bool
+	}{
+		{
+			notify: `[{url: "http://example.com/a"}]`,
+			exp:    []options.Notification{{URL: "http://example.com/a", On: options.NotifyOnFailure}},
+		},
+		{
+			notify: `[{url: "http://example.com/a", on: "failure", after: 3}, {url: "http://example.com/b", on: "recovery", after: 0}]`,
+			exp: []options.Notification{
+				{URL: "http://example.com/a", On: options.NotifyOnFailure, After: 3},
+				{URL: "http://example.com/b", On: options.NotifyOnRecovery},
+			},
+		},
+		{notify: `"http://example.com/a"`, shouldErr: true},
+		{notify: `[{on: "failure"}]`, shouldErr: true},
+		{notify: `[{url: "http://example.com/a", on: "success"}]`, shouldErr: true},
+		{notify: `[{url: "http://example.com/a", on: "recovery", after: 2}]`, shouldErr: true},
+		{notify: `[{url: "http://example.com/a", after: -1}]`, shouldErr: true},
+	} {
+		script := fmt.Sprintf("option task = {\n  name: \"name\",\n  every: 1m,\n  notify: %s,\n}\n\n%s", c.notify, body)
+		o, err := options.FromScript(script)
+		if c.shouldErr {
+			if err == nil {
+				t.Fatalf("notify %s should have errored but didn't", c.notify)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("notify %s should not have errored, but got %v", c.notify, err)
+		}
+		if !cmp.Equal(o.Notify, c.exp) {
+			t.Fatalf("notify %s got unexpected result -got/+exp\n%s", c.notify, cmp.Diff(o.Notify, c.exp))
+		}
+	}
+}
+
+func TestFromScript_Resources(t *testing.T) {
+	const body = `from(db: "test") |> range(start:-1h)`
+	for _, c := range []struct {
+		resources string
+		exp       options.Options
+		shouldErr bool
+	}{
+		{
+			resources: `priority: 10, concurrencyQuota: 2, memoryBytesQuota: 1048576, timeout: 30s`,
+			exp:       options.Options{Priority: 10, ConcurrencyQuota: 2, MemoryBytesQuota: 1048576, Timeout: 30 * time.Second},
+		},
+		{resources: `priority: "low"`, exp: options.Options{Priority: query.Low}},
+		{resources: `priority: "high"`, exp: options.Options{Priority: query.High}},
+		{resources: `priority: "medium"`, shouldErr: true},
+		{resources: `priority: -1`, shouldErr: true},
+		{resources: `concurrencyQuota: 0`, shouldErr: true},
+		{resources: `concurrencyQuota: 1000`, shouldErr: true},
+		{resources: `concurrencyQuota: "2"`, shouldErr: true},
+		{resources: `memoryBytesQuota: 0`, shouldErr: true},
+		{resources: `memoryBytesQuota: 1.5`, shouldErr: true},
+		{resources: `timeout: 0s`, shouldErr: true},
+		{resources: `timeout: "5m"`, shouldErr: true},
+		{resources: `timeout: 300`, shouldErr: true},
+		{resources: `catchUp: "latest"`, exp: options.Options{CatchUp: options.CatchUpLatest}},
+		{resources: `catchUp: "some"`, shouldErr: true},
+	} {
+		script := fmt.Sprintf("option task = {\n  name: \"name\",\n  every: 1m,\n  %s,\n}\n\n%s", c.resources, body)
+		o, err := options.FromScript(script)
+		if c.shouldErr {
+			if err == nil {
+				t.Fatalf("resources %s should have errored but didn't", c.resources)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("resources %s should not have errored, but got %v", c.resources, err)
+		}
+		c.exp.Name, c.exp.Every, c.exp.Concurrency, c.exp.Retry = "name", time.Minute, 1, 1
+		if !cmp.Equal(o, c.exp) {
+			t.Fatalf("resources %s got unexpected result -got/+exp\n%s", c.resources, cmp.Diff(o, c.exp))
+		}
+		rm := o.ResourceManagement()
+		if rm.Priority != c.exp.Priority || rm.ConcurrencyQuota != int(c.exp.ConcurrencyQuota) || rm.MemoryBytesQuota != c.exp.MemoryBytesQuota {
+			t.Fatalf("resources %s got unexpected resource management %+v", c.resources, rm)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/influxdata/platform"
 	"github.com/influxdata/platform/task/backend"
@@ -12,7 +14,7 @@ import (
 
 // PlatformAdapter wraps a task.Store into the platform.TaskService interface.
 func PlatformAdapter(s backend.Store, r backend.LogReader) platform.TaskService {
-	return pAdapter{s: s}
+	return pAdapter{s: s, r: r}
 }
 
 type pAdapter struct {
@@ -27,7 +29,14 @@ func (p pAdapter) FindTaskByID(ctx context.Context, id platform.ID) (*platform.T
 	if err != nil {
 		return nil, err
 	}
-	return toPlatformTask(*t)
+	pt, err := toPlatformTask(*t)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.setStatus(ctx, pt); err != nil {
+		return nil, err
+	}
+	return pt, nil
 }
 
 func (p pAdapter) FindTasks(ctx context.Context, filter platform.TaskFilter) ([]*platform.Task, int, error) {
@@ -43,6 +52,9 @@ func (p pAdapter) FindTasks(ctx context.Context, filter platform.TaskFilter) ([]
 	if filter.After != nil {
 		params.After = *filter.After
 	}
+	if err := setSearchFilters(&params, filter); err != nil {
+		return nil, 0, err
+	}
 	ts, err := p.s.ListTasks(ctx, params)
 	if err != nil {
 		return nil, 0, err
@@ -54,25 +66,97 @@ func (p pAdapter) FindTasks(ctx context.Context, filter platform.TaskFilter) ([]
 		if err != nil {
 			return nil, 0, err
 		}
+		if err := p.setStatus(ctx, pts[i]); err != nil {
+			return nil, 0, err
+		}
 	}
 
 	totalResults := len(pts) // TODO(mr): don't lie about the total results. Update ListTasks signature?
 	return pts, totalResults, nil
 }
 
+// setStatus sets the status of t from the task's stored metadata.
+func (p pAdapter) setStatus(ctx context.Context, t *platform.Task) error {
+	meta, err := p.s.FindTaskMetaByID(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	t.Status = meta.Status
+	return nil
+}
+
+// setSearchFilters converts the name, status and last run filters of filter into params.
+func setSearchFilters(params *backend.TaskSearchParams, filter platform.TaskFilter) error {
+	params.NamePrefix = filter.NamePrefix
+	if filter.NameRegex != "" {
+		re, err := regexp.Compile(filter.NameRegex)
+		if err != nil {
+			return fmt.Errorf("invalid name regex: %v", err)
+		}
+		params.NamePattern = re
+	}
+
+	switch backend.TaskStatus(filter.Status) {
+	case "", backend.TaskEnabled, backend.TaskDisabled:
+		params.Status = backend.TaskStatus(filter.Status)
+	default:
+		return fmt.Errorf("invalid status %q", filter.Status)
+	}
+
+	switch filter.LastRunStatus {
+	case "":
+	case backend.RunSuccess.String():
+		params.LastRunStatus = backend.RunSuccess
+	case backend.RunFail.String():
+		params.LastRunStatus = backend.RunFail
+	default:
+		return fmt.Errorf("invalid last run status %q", filter.LastRunStatus)
+	}
+
+	if filter.LastRunAfterTime != "" {
+		t, err := time.Parse(time.RFC3339, filter.LastRunAfterTime)
+		if err != nil {
+			return fmt.Errorf("invalid last run after time: %v", err)
+		}
+		params.LastRunAfter = t
+	}
+	if filter.LastRunBeforeTime != "" {
+		t, err := time.Parse(time.RFC3339, filter.LastRunBeforeTime)
+		if err != nil {
+			return fmt.Errorf("invalid last run before time: %v", err)
+		}
+		params.LastRunBefore = t
+	}
+
+	return nil
+}
+
 func (p pAdapter) CreateTask(ctx context.Context, t *platform.Task) error {
 	opts, err := options.FromScript(t.Flux)
 	if err != nil {
 		return err
 	}
+	if err := checkDependsOn(ctx, p.s, t.Organization, nil, opts.DependsOn); err != nil {
+		return err
+	}
 
-	id, err := p.s.CreateTask(ctx, t.Organization, t.Owner.ID, t.Flux)
+	id, err := p.s.CreateTask(ctx, backend.CreateTaskRequest{
+		Org:             t.Organization,
+		User:            t.Owner.ID,
+		AuthorizationID: t.AuthorizationID,
+		Script:          t.Flux,
+	})
 	if err != nil {
 		return err
 	}
 	t.ID = id
 	t.Every = opts.Every.String()
 	t.Cron = opts.Cron
+	if opts.DependsOn != "" {
+		if err := t.DependsOn.DecodeFromString(opts.DependsOn); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -98,8 +182,11 @@ func (p pAdapter) UpdateTask(ctx context.Context, id platform.ID, upd platform.T
 		}
 		task.Every = opts.Every.String()
 		task.Cron = opts.Cron
+		if err := p.checkModifiedDependsOn(ctx, id, opts.DependsOn); err != nil {
+			return nil, err
+		}
 
-		if err := p.s.ModifyTask(ctx, id, task.Flux); err != nil {
+		if err := p.s.ModifyTask(ctx, id, upd.Author, task.Flux); err != nil {
 			return nil, err
 		}
 	}
@@ -144,34 +231,118 @@ func (p pAdapter) FindRuns(ctx context.Context, filter platform.RunFilter) ([]*p
 	return runs, len(runs), err
 }
 
-func (p pAdapter) FindRunByID(ctx context.Context, id platform.ID) (*platform.Run, error) {
-	// TODO(lh): the inmem FindRunByID method doesnt need the taskId but we will need it PlatformAdapter
-	// this call to the store is a filler until platform.TaskService gets the update to add the id
-	return p.r.FindRunByID(ctx, platform.ID([]byte("replace")), id)
+func (p pAdapter) FindRunByID(ctx context.Context, taskID, runID platform.ID) (*platform.Run, error) {
+	return p.r.FindRunByID(ctx, taskID, runID)
 }
 
-func (p pAdapter) RetryRun(ctx context.Context, id platform.ID) (*platform.Run, error) {
+func (p pAdapter) RetryRun(ctx context.Context, taskID, runID platform.ID) (*platform.Run, error) {
 	return nil, errors.New("not yet implemented")
 }
 
+func (p pAdapter) FindTaskRevisions(ctx context.Context, id platform.ID) ([]*platform.TaskRevision, error) {
+	revs, err := p.s.ListScriptRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*platform.TaskRevision, len(revs))
+	for i, r := range revs {
+		out[i] = toPlatformTaskRevision(r)
+	}
+	return out, nil
+}
+
+func (p pAdapter) RollbackTask(ctx context.Context, id platform.ID, revision int64, author platform.ID) (*platform.Task, error) {
+	rev, err := p.s.FindScriptRevision(ctx, id, revision)
+	if err != nil {
+		return nil, err
+	}
+	if rev == nil {
+		return nil, fmt.Errorf("revision %d not found for task %s", revision, id.String())
+	}
+
+	opts, err := options.FromScript(rev.Script)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkModifiedDependsOn(ctx, id, opts.DependsOn); err != nil {
+		return nil, err
+	}
+
+	if err := p.s.ModifyTask(ctx, id, author, rev.Script); err != nil {
+		return nil, err
+	}
+
+	return p.FindTaskByID(ctx, id)
+}
+
+func (p pAdapter) FindTaskDependencies(ctx context.Context, id platform.ID) (*platform.TaskDependencies, error) {
+	return findDependencies(ctx, p.s, id)
+}
+
+// checkModifiedDependsOn checks the dependsOn option of a new script for the existing task with the given ID.
+func (p pAdapter) checkModifiedDependsOn(ctx context.Context, id platform.ID, dependsOn string) error {
+	if dependsOn == "" {
+		return nil
+	}
+
+	t, err := p.s.FindTaskByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("task %s not found", id.String())
+	}
+	return checkDependsOn(ctx, p.s, t.Org, id, dependsOn)
+}
+
 func toPlatformTask(t backend.StoreTask) (*platform.Task, error) {
 	opts, err := options.FromScript(t.Script)
 	if err != nil {
 		return nil, err
 	}
 
+	var offset string
+	if opts.Offset != 0 {
+		offset = opts.Offset.String()
+	}
+
+	var dependsOn platform.ID
+	if opts.DependsOn != "" {
+		if err := dependsOn.DecodeFromString(opts.DependsOn); err != nil {
+			return nil, err
+		}
+	}
+
 	return &platform.Task{
 		ID:           t.ID,
 		Organization: t.Org,
 		Name:         t.Name,
-		Status:       "", // TODO: set and update status
+		Status:       "", // Set by pAdapter.setStatus.
 		Owner: platform.User{
 			ID:   append([]byte(nil), t.User...), // Copy just in case.
 			Name: "",                             // TODO(mr): how to get owner name?
 		},
-		Flux:  t.Script,
-		Every: opts.Every.String(),
-		Cron:  opts.Cron,
-		Last:  platform.Run{}, // TODO(mr): how to get last run info?
+		AuthorizationID: t.AuthorizationID,
+		Flux:            t.Script,
+		Every:           opts.Every.String(),
+		Cron:            opts.Cron,
+		Timezone:        opts.Timezone,
+		Offset:          offset,
+		DependsOn:       dependsOn,
+		Revision:        t.Revision,
+		Last:            platform.Run{}, // TODO(mr): how to get last run info?
 	}, nil
 }
+
+func toPlatformTaskRevision(r backend.ScriptRevision) *platform.TaskRevision {
+	rev := &platform.TaskRevision{
+		Revision: r.Revision,
+		Flux:     r.Script,
+		Author:   r.Author,
+	}
+	if r.Created != 0 {
+		rev.Created = time.Unix(r.Created, 0).UTC().Format(time.RFC3339)
+	}
+	return rev
+}